@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"strconv"
+)
+
+func (suite *IntegrationTestSuite) TestListDevices() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+
+	revokedPublicKey := dao.UserPublicKey{
+		UserId:     userRecord.Id,
+		KeyId:      "67890",
+		PublicKey:  "revoked_public_key",
+		DeviceName: utils.Pointer("Old phone"),
+	}
+	err := suite.TestDB.Create(&revokedPublicKey).Error
+	suite.Require().NoError(err, "Failed to insert revoked userPublicKey record")
+	err = dao.UserPublicKey{}.Revoke(revokedPublicKey.ID, handler.DEVICE_REVOKED_BY_CUSTOMER, clock.Now())
+	suite.Require().NoError(err, "Failed to revoke userPublicKey record")
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/account/devices", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	customContext := security.GenerateLoggedInRegisteredUserContext(userRecord.Id, userPublicKey.PublicKey, c)
+
+	err = handler.ListDevices(customContext)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	var responseBody response.ListDevicesResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to unmarshal response")
+
+	suite.Require().Len(responseBody.Devices, 1, "Revoked devices should not be listed")
+	suite.Require().Equal(userPublicKey.ID, responseBody.Devices[0].Id)
+	suite.Require().True(responseBody.Devices[0].IsCurrentDevice, "Device used for the request should be marked as current")
+	suite.Require().Equal(0, responseBody.DeviceLimit, "Devices should be unlimited by default")
+}
+
+func (suite *IntegrationTestSuite) TestRevokeDevice() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodDelete, "/account/devices/"+strconv.FormatUint(userPublicKey.ID, 10), nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/account/devices/:id")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.FormatUint(userPublicKey.ID, 10))
+
+	customContext := security.GenerateLoggedInRegisteredUserContext(userRecord.Id, userPublicKey.PublicKey, c)
+
+	err := handler.RevokeDevice(customContext)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	_, errResponse := dao.RequireUserPublicKey(userRecord.Id, userPublicKey.PublicKey)
+	suite.Require().NotNil(errResponse, "Revoked key should be refused")
+	suite.Require().Equal(constant.DEVICE_REVOKED, errResponse.ErrorCode)
+
+	loginKey, err := dao.UserPublicKey{}.FindUserPublicRecord(userRecord.Id, userPublicKey.PublicKey)
+	suite.Require().NoError(err)
+	suite.Require().Nil(loginKey, "Revoked key should be treated as unregistered on login")
+}
+
+func (suite *IntegrationTestSuite) TestRevokedDeviceSessionIsRefused() {
+	h := suite.newHandler()
+	e := handler.NewEcho()
+	h.BuildRoutes(e, "", "test")
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+	token, err := security.GenerateOnboardedJwt(userRecord.Id, userPublicKey.PublicKey, nil)
+	suite.Require().NoError(err, "Failed to generate JWT token")
+
+	listDevices := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/account/devices", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := listDevices()
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.NotEmpty(rec.Header().Get("Authorization"), "An active device's token should be reset")
+
+	err = dao.UserPublicKey{}.Revoke(userPublicKey.ID, handler.DEVICE_REVOKED_BY_CUSTOMER, clock.Now())
+	suite.Require().NoError(err, "Failed to revoke userPublicKey record")
+
+	rec = listDevices()
+	suite.Require().Equal(http.StatusUnauthorized, rec.Code, "A revoked device's token should be refused")
+	suite.Empty(rec.Header().Get("Authorization"), "A revoked device's token shouldn't be reset")
+
+	var errResponse response.ErrorResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &errResponse))
+	suite.Equal(constant.DEVICE_REVOKED, errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) TestRevokeDeviceOfAnotherUser() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+
+	otherUserRecord := suite.createTestUser(PartialMasterUserRecordDao{Email: utils.Pointer("other@example.com")})
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodDelete, "/account/devices/"+strconv.FormatUint(userPublicKey.ID, 10), nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/account/devices/:id")
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.FormatUint(userPublicKey.ID, 10))
+
+	customContext := security.GenerateLoggedInRegisteredUserContext(otherUserRecord.Id, "other_public_key", c)
+
+	err := handler.RevokeDevice(customContext)
+	suite.Require().Error(err, "Handler should return an error")
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Require().Equal(http.StatusNotFound, errResponse.StatusCode)
+
+	_, requireErr := dao.RequireUserPublicKey(userRecord.Id, userPublicKey.PublicKey)
+	suite.Require().Nil(requireErr, "Key should still be active")
+}
+
+func (suite *IntegrationTestSuite) TestRegisterUserDevice() {
+	suite.configEmail()
+	h := suite.newHandler()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	userPublicKey := dao.UserPublicKey{
+		UserId:         userRecord.Id,
+		KeyId:          "12345",
+		PublicKey:      "new_public_key",
+		DeviceName:     utils.Pointer("Pixel 8"),
+		DevicePlatform: utils.Pointer("android"),
+		RegisteredIP:   utils.Pointer("1.1.1.1"),
+	}
+
+	errResponse := h.RegisterUserDevice(context.Background(), &userRecord, &userPublicKey)
+	suite.Require().Nil(errResponse, "Device registration should succeed")
+
+	suite.WaitForJobsDone(1)
+
+	devices, err := dao.UserPublicKey{}.FindActiveByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(devices, 1)
+}
+
+func (suite *IntegrationTestSuite) TestRegisterUserDeviceWithoutLimit() {
+	suite.configEmail()
+	h := suite.newHandler()
+
+	// Customers from before device limits can have any number of keys
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	for i := range 3 {
+		legacyPublicKey := dao.UserPublicKey{
+			UserId:    userRecord.Id,
+			KeyId:     "legacy_key_" + strconv.Itoa(i),
+			PublicKey: "legacy_public_key_" + strconv.Itoa(i),
+		}
+		suite.Require().NoError(suite.TestDB.Create(&legacyPublicKey).Error, "Failed to insert legacy userPublicKey record")
+	}
+
+	userPublicKey := dao.UserPublicKey{
+		UserId:    userRecord.Id,
+		KeyId:     "67890",
+		PublicKey: "new_public_key",
+	}
+
+	errResponse := h.RegisterUserDevice(context.Background(), &userRecord, &userPublicKey)
+	suite.Require().Nil(errResponse, "Devices should be unlimited by default")
+
+	suite.WaitForJobsDone(1)
+
+	devices, err := dao.UserPublicKey{}.FindActiveByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Len(devices, 4)
+}
+
+func (suite *IntegrationTestSuite) TestRegisterUserDeviceLimitReached() {
+	h := suite.newHandler()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserPublicKeyRecord(userRecord.Id)
+
+	err := suite.TestDB.Model(&userRecord).Update("device_limit", 1).Error
+	suite.Require().NoError(err, "Failed to set device limit")
+	userRecord.DeviceLimit = utils.Pointer(1)
+
+	userPublicKey := dao.UserPublicKey{
+		UserId:    userRecord.Id,
+		KeyId:     "67890",
+		PublicKey: "second_public_key",
+	}
+
+	errResponse := h.RegisterUserDevice(context.Background(), &userRecord, &userPublicKey)
+	suite.Require().NotNil(errResponse, "Device registration should fail")
+	suite.Require().Equal(constant.DEVICE_LIMIT_REACHED, errResponse.ErrorCode)
+	suite.Require().Equal(http.StatusConflict, errResponse.StatusCode)
+}
@@ -0,0 +1,272 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupMockForS3 points the S3 client at a server that accepts uploads, recording the path
+// of each object put
+func SetupMockForS3(suite *IntegrationTestSuite, uploadedPaths *[]string) *httptest.Server {
+	mockS3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			suite.T().Errorf("Expected a PUT request to S3, got: %s %s", r.Method, r.URL.Path)
+		}
+		*uploadedPaths = append(*uploadedPaths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	awsSession := session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(mockS3Server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("access-key", "secret-key", ""),
+	}))
+	utils.S3Client = s3.New(awsSession)
+	return mockS3Server
+}
+
+func (suite *IntegrationTestSuite) newAccountDisputeContext(method string, path string, disputeId string, body *bytes.Buffer, contentType string, userId string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(method, strings.Replace(path, ":id", disputeId, 1), body)
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath(path)
+	c.SetParamNames("id")
+	c.SetParamValues(disputeId)
+	return security.GenerateLoggedInRegisteredUserContext(userId, "publicKey", c), rec
+}
+
+func (suite *IntegrationTestSuite) TestListDisputes() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/account/disputes", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ListDisputes(security.GenerateLoggedInRegisteredUserContext(userRecord.Id, "publicKey", c))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	var responseBody response.ListDisputesResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to unmarshal response")
+
+	suite.Require().Len(responseBody.Disputes, 1)
+	suite.Require().Equal(transactionDispute.Id, responseBody.Disputes[0].Id)
+	suite.Require().Equal(constant.DISPUTE_SUBMITTED, responseBody.Disputes[0].Status)
+	suite.Require().Equal(int64(1457), *responseBody.Disputes[0].AmountCents)
+	suite.Require().True(responseBody.Disputes[0].CanWithdraw, "Open dispute should be withdrawable")
+}
+
+func (suite *IntegrationTestSuite) TestGetDispute() {
+	var uploadedPaths []string
+	defer SetupMockForS3(suite, &uploadedPaths).Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+
+	now := clock.Now()
+	submitted, underReview := constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW
+	suite.Require().NoError(dao.TransactionDisputeEventDao{}.Create(suite.TestDB, transactionDispute.Id, dao.DISPUTE_EVENT_SUBMITTED, &submitted, dao.DISPUTE_ACTOR_CUSTOMER, nil, now))
+	suite.Require().NoError(dao.TransactionDisputeEventDao{}.Create(suite.TestDB, transactionDispute.Id, dao.DISPUTE_EVENT_NOTIFICATION_QUEUED, nil, dao.DISPUTE_ACTOR_SYSTEM, nil, now))
+	suite.Require().NoError(dao.TransactionDisputeEventDao{}.Create(suite.TestDB, transactionDispute.Id, dao.DISPUTE_EVENT_REVIEW_STARTED, &underReview, "operator@dreamfi.com", nil, now))
+	_, err := dao.TransactionDisputeMessageDao{}.Create(suite.TestDB, transactionDispute.Id, "operator@dreamfi.com", "Could you send us the receipt?", now)
+	suite.Require().NoError(err)
+	err = dao.TransactionDisputeEvidenceDao{}.Create(suite.TestDB, &dao.TransactionDisputeEvidenceDao{
+		Id:                   "0f2b8f6e-3c1c-4d1e-9d44-2a4f3c1b7e10",
+		TransactionDisputeId: transactionDispute.Id,
+		FileName:             "receipt.pdf",
+		ContentType:          "application/pdf",
+		SizeBytes:            2048,
+		S3Key:                "disputes/" + transactionDispute.Id + "/0f2b8f6e-3c1c-4d1e-9d44-2a4f3c1b7e10.pdf",
+		UploadedBy:           dao.DISPUTE_ACTOR_CUSTOMER,
+		CreatedAt:            now,
+	})
+	suite.Require().NoError(err)
+
+	c, rec := suite.newAccountDisputeContext(http.MethodGet, "/account/disputes/:id", transactionDispute.Id, &bytes.Buffer{}, "", userRecord.Id)
+	err = handler.GetDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	var responseBody response.DisputeDetailResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to unmarshal response")
+
+	suite.Require().Equal(transactionDispute.Id, responseBody.Id)
+	suite.Require().Len(responseBody.Timeline, 2, "Only status changes should be on the timeline")
+	suite.Require().Equal(constant.DISPUTE_SUBMITTED, responseBody.Timeline[0].Status)
+	suite.Require().Equal(constant.DISPUTE_UNDER_REVIEW, responseBody.Timeline[1].Status)
+	suite.Require().Len(responseBody.Messages, 1)
+	suite.Require().Equal("support", responseBody.Messages[0].Author, "Operators should not be named to the customer")
+	suite.Require().Len(responseBody.Evidence, 1)
+	suite.Require().Equal("receipt.pdf", responseBody.Evidence[0].FileName)
+	suite.Require().Contains(responseBody.Evidence[0].Url, "X-Amz-Signature", "Evidence should be served with a pre-signed url")
+	suite.Require().Empty(uploadedPaths)
+}
+
+func (suite *IntegrationTestSuite) TestGetDispute_OtherUsersDispute() {
+	owner := suite.createTestUser(PartialMasterUserRecordDao{})
+	otherUser := suite.createTestUser(PartialMasterUserRecordDao{Email: utils.Pointer("other@example.com"), MobileNo: utils.Pointer("+14155550123")})
+	transactionDispute := suite.createTransactionDisputeRecord(owner.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	c, _ := suite.newAccountDisputeContext(http.MethodGet, "/account/disputes/:id", transactionDispute.Id, &bytes.Buffer{}, "", otherUser.Id)
+	err := handler.GetDispute(c)
+	suite.Require().Error(err, "Handler should return an error")
+
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Require().Equal(http.StatusNotFound, errResponse.StatusCode, "Expected status code 404 Not Found")
+}
+
+func (suite *IntegrationTestSuite) TestAddDisputeMessage() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	c, rec := suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/messages", transactionDispute.Id, bytes.NewBufferString(`{"message": "  The merchant never shipped my order.  "}`), echo.MIMEApplicationJSON, userRecord.Id)
+	err := handler.AddDisputeMessage(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusCreated, rec.Code, "Expected status code 201 Created")
+
+	messages, err := dao.TransactionDisputeMessageDao{}.FindByTransactionDisputeId(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(messages, 1)
+	suite.Require().Equal(dao.DISPUTE_ACTOR_CUSTOMER, messages[0].Author)
+	suite.Require().Equal("The merchant never shipped my order.", messages[0].Body)
+	suite.Require().Equal([]string{dao.DISPUTE_EVENT_MESSAGE_ADDED}, suite.disputeEventActions(transactionDispute.Id))
+}
+
+func (suite *IntegrationTestSuite) TestAddDisputeMessage_ResolvedDispute() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_RESOLVED_MERCHANT, clock.Now())
+
+	c, _ := suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/messages", transactionDispute.Id, bytes.NewBufferString(`{"message": "I disagree."}`), echo.MIMEApplicationJSON, userRecord.Id)
+	err := handler.AddDisputeMessage(c)
+	suite.Require().Error(err, "Handler should return an error")
+
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Require().Equal(http.StatusConflict, errResponse.StatusCode, "Expected status code 409 Conflict")
+	suite.Require().Equal(constant.DISPUTE_CLOSED, errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) newEvidenceUploadBody(fileName string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fileName)
+	suite.Require().NoError(err)
+	_, err = part.Write(content)
+	suite.Require().NoError(err)
+	suite.Require().NoError(writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func (suite *IntegrationTestSuite) TestUploadDisputeEvidence() {
+	var uploadedPaths []string
+	defer SetupMockForS3(suite, &uploadedPaths).Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	body, contentType := suite.newEvidenceUploadBody("screenshot.png", png)
+
+	c, rec := suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/evidence", transactionDispute.Id, body, contentType, userRecord.Id)
+	err := handler.UploadDisputeEvidence(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusCreated, rec.Code, "Expected status code 201 Created")
+
+	var responseBody response.DisputeEvidenceResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to unmarshal response")
+	suite.Require().Equal("screenshot.png", responseBody.FileName)
+	suite.Require().Equal("image/png", responseBody.ContentType)
+	suite.Require().Equal(int64(len(png)), responseBody.SizeBytes)
+	suite.Require().NotEmpty(responseBody.Url)
+
+	evidence, err := dao.TransactionDisputeEvidenceDao{}.FindByTransactionDisputeId(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(evidence, 1)
+	suite.Require().Equal("disputes/"+transactionDispute.Id+"/"+evidence[0].Id+".png", evidence[0].S3Key)
+	suite.Require().Equal([]string{"/" + config.Config.Aws.BucketName + "/" + evidence[0].S3Key}, uploadedPaths, "The file should be uploaded to S3 under its key")
+	suite.Require().Equal([]string{dao.DISPUTE_EVENT_EVIDENCE_UPLOADED}, suite.disputeEventActions(transactionDispute.Id))
+}
+
+func (suite *IntegrationTestSuite) TestUploadDisputeEvidence_UnsupportedFileType() {
+	var uploadedPaths []string
+	defer SetupMockForS3(suite, &uploadedPaths).Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	// Named like an image, but the content is checked rather than the name
+	body, contentType := suite.newEvidenceUploadBody("receipt.png", []byte("<html><script>alert(1)</script></html>"))
+
+	c, _ := suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/evidence", transactionDispute.Id, body, contentType, userRecord.Id)
+	err := handler.UploadDisputeEvidence(c)
+	suite.Require().Error(err, "Handler should return an error")
+
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Require().Equal(http.StatusBadRequest, errResponse.StatusCode, "Expected status code 400 Bad Request")
+	suite.Require().Equal(constant.DISPUTE_EVIDENCE_INVALID_FILE, errResponse.ErrorCode)
+	suite.Require().Empty(uploadedPaths, "Nothing should be uploaded to S3")
+}
+
+func (suite *IntegrationTestSuite) TestWithdrawDispute() {
+	suite.configEmail()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	c, rec := suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/withdraw", transactionDispute.Id, bytes.NewBufferString(`{"reason": "The merchant refunded me"}`), echo.MIMEApplicationJSON, userRecord.Id)
+	err := suite.newHandler().WithdrawDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	var responseBody response.DisputeResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to unmarshal response")
+	suite.Require().Equal(constant.DISPUTE_WITHDRAWN, responseBody.Status)
+	suite.Require().False(responseBody.CanWithdraw)
+
+	withdrawn, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_WITHDRAWN, withdrawn.Status)
+	suite.Require().Equal(dao.DISPUTE_ACTOR_CUSTOMER, *withdrawn.ResolvedBy)
+	suite.Require().Equal("The merchant refunded me", *withdrawn.ResolutionReason)
+	suite.Require().Nil(withdrawn.VoidCreditTransaction, "There was no credit to reverse")
+
+	// A withdrawn dispute cannot be withdrawn again
+	c, _ = suite.newAccountDisputeContext(http.MethodPost, "/account/disputes/:id/withdraw", transactionDispute.Id, &bytes.Buffer{}, "", userRecord.Id)
+	err = suite.newHandler().WithdrawDispute(c)
+	suite.Require().Error(err, "Handler should return an error")
+
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Require().Equal(http.StatusConflict, errResponse.StatusCode, "Expected status code 409 Conflict")
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testPullBalancePolicy = plaid.PullBalancePolicy{
+	MaxAge:         time.Hour,
+	RefreshTimeout: 5 * time.Second,
+	BufferCents:    5_00,
+}
+
+// linkBalanceItem links the item mockoon serves balances for, whose checking account
+// ending in 9602 has $50.00 available
+func (suite *IntegrationTestSuite) linkBalanceItem(ps plaid.PlaidService, userId string) dao.PlaidItemDao {
+	plaidItemId := "j91ByvRRqwuGBygwnB8Au8j6ZvmjKAt1wB4a2"
+	unencryptedAccessToken := "access-sandbox-1b7e6039-337b-34d7-a3cd-7e13e379c0b2"
+	suite.Require().NoError(ps.InsertItem(userId, plaidItemId, unencryptedAccessToken))
+	suite.Require().NoError(ps.InitialAccountsGetRequest(userId, plaidItemId, unencryptedAccessToken))
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(plaidItemId)
+	suite.Require().NoError(err)
+	return *item
+}
+
+func (suite *IntegrationTestSuite) TestCheckPullBalance_FreshBalance() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	suite.linkBalanceItem(ps, userId)
+
+	check, err := ps.CheckPullBalance(context.Background(), suite.riverClient, userId, uuid.NewString(), "1111119602", 45_00, testPullBalancePolicy, clock.Now())
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_ALLOWED, check.Decision, "$45.00 is within $50.00 less the $5.00 buffer")
+	suite.Nil(check.Reason)
+	suite.False(check.Refreshed, "A fresh balance shouldn't be refreshed")
+	suite.Equal(int64(50_00), *check.AvailableBalanceCents)
+	suite.NotNil(check.PlaidAccountId)
+
+	payloadId := uuid.NewString()
+	check, err = ps.CheckPullBalance(context.Background(), suite.riverClient, userId, payloadId, "1111119602", 45_01, testPullBalancePolicy, clock.Now())
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, check.Decision)
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS, *check.Reason)
+
+	stored, err := dao.AchPullBalanceCheckDao{}.FindByPayloadId(payloadId)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(stored, "The check should be recorded against the pull")
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, stored.Decision)
+	suite.Equal(int64(45_01), stored.AmountCents)
+	suite.Equal(int64(5_00), stored.BufferCents)
+}
+
+func (suite *IntegrationTestSuite) TestCheckPullBalance_StaleBalanceIsRefreshed() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	now := clock.Now()
+	staleAt := now.Add(-2 * time.Hour)
+	err := suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Updates(map[string]any{"balance_refreshed_at": staleAt, "available_balance_cents": 1_000_00}).Error
+	suite.Require().NoError(err)
+
+	check, err := ps.CheckPullBalance(context.Background(), suite.riverClient, userId, uuid.NewString(), "1111119602", 100_00, testPullBalancePolicy, now)
+	suite.Require().NoError(err)
+	suite.True(check.Refreshed)
+	suite.Equal(int64(50_00), *check.AvailableBalanceCents, "The pull should be checked against the refreshed balance")
+	suite.True(check.BalanceRefreshedAt.After(staleAt))
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, check.Decision)
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS, *check.Reason)
+
+	// A balance that can't be refreshed can't be relied on
+	suite.Require().NoError(suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Update("balance_refreshed_at", staleAt).Error)
+	suite.Require().NoError(dao.PlaidItemDao{}.SetItemError(item.PlaidItemID, "ITEM_LOGIN_REQUIRED"))
+	check, err = ps.CheckPullBalance(context.Background(), suite.riverClient, userId, uuid.NewString(), "1111119602", 10_00, testPullBalancePolicy, now)
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, check.Decision)
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE, *check.Reason)
+}
+
+func (suite *IntegrationTestSuite) TestCheckPullBalance_NotLinked() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	suite.linkBalanceItem(ps, userId)
+
+	payloadId := uuid.NewString()
+	check, err := ps.CheckPullBalance(context.Background(), suite.riverClient, userId, payloadId, "1111111234", 10_00, testPullBalancePolicy, clock.Now())
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, check.Decision, "Pulls from accounts that aren't linked should be rejected")
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_NOT_LINKED, *check.Reason)
+	suite.Nil(check.PlaidAccountId)
+
+	stored, err := dao.AchPullBalanceCheckDao{}.FindByPayloadId(payloadId)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(stored, "The rejection should be recorded against the pull")
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, stored.Decision)
+}
+
+func (suite *IntegrationTestSuite) TestCheckPullBalance_AmbiguousMask() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	// Like a second bank's account that ends in the same four digits
+	err := suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ? AND mask = ?", item.PlaidItemID, "9603").Update("mask", "9602").Error
+	suite.Require().NoError(err)
+
+	check, err := ps.CheckPullBalance(context.Background(), suite.riverClient, userId, uuid.NewString(), "1111119602", 10_00, testPullBalancePolicy, clock.Now())
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_REJECTED, check.Decision, "The balance could be read from the wrong account")
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE, *check.Reason)
+	suite.Nil(check.PlaidAccountId)
+	suite.Nil(check.AvailableBalanceCents)
+}
+
+func (suite *IntegrationTestSuite) TestCheckPullBalance_Unchecked() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	// Like an account verified with micro-deposits
+	suite.Require().NoError(suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Updates(map[string]any{"balance_refreshed_at": nil, "available_balance_cents": nil}).Error)
+	check, err := ps.CheckPullBalance(context.Background(), suite.riverClient, userId, uuid.NewString(), "1111119602", 10_00, testPullBalancePolicy, clock.Now())
+	suite.Require().NoError(err)
+	suite.Equal(dao.ACH_PULL_BALANCE_UNCHECKED, check.Decision)
+	suite.Equal(dao.ACH_PULL_BALANCE_REASON_NO_BALANCE, *check.Reason)
+	suite.NotNil(check.PlaidAccountId)
+}
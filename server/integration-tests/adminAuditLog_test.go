@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/handler"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) auditLogEntries(entityType string, entityId string) []dao.AdminAuditLogDao {
+	entries, _, err := dao.AdminAuditLogDao{}.Search(dao.AdminAuditLogFilter{Query: entityId, EntityType: entityType}, 100, 0)
+	suite.Require().NoError(err)
+	return entries
+}
+
+func (suite *IntegrationTestSuite) TestAdminAuditLogRecordsDisputeTransition() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionStartReview, "")
+	c.Response().Header().Set(echo.HeaderXRequestID, "req-123")
+
+	err := adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the dispute")
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id)
+	suite.Require().Len(entries, 1)
+	entry := entries[0]
+	suite.Require().Equal(admin.AUDIT_ACTION_DISPUTE_PREFIX+string(dispute.ActionStartReview), entry.Action)
+	suite.Require().Equal("auth0|operator", entry.ActorId)
+	suite.Require().Equal("operator@dreamfi.com", entry.ActorEmail)
+	suite.Require().Equal(userRecord.Id, *entry.UserId)
+	suite.Require().Equal("req-123", *entry.RequestId)
+	suite.Require().Equal(dao.ADMIN_AUDIT_LOG_GENESIS_HASH, entry.PrevHash)
+
+	var changes map[string]map[string]any
+	suite.Require().NoError(json.Unmarshal([]byte(entry.Changes), &changes))
+	suite.Require().Equal(map[string]any{"before": constant.DISPUTE_SUBMITTED, "after": constant.DISPUTE_UNDER_REVIEW}, changes["status"])
+	suite.Require().NotContains(changes, "amount_cents", "Unchanged fields should not be recorded")
+}
+
+func (suite *IntegrationTestSuite) TestAdminAuditLogRedactsCustomerPII() {
+	defer SetupMockForLedger(suite).Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	value, err := json.Marshal(dao.UpdateFullNameRequest{FirstName: "Jane", LastName: "Doe"})
+	suite.Require().NoError(err)
+	update := suite.createDemographicUpdateRecord(value, constant.DEMOGRAPHIC_UPDATE_FULL_NAME, userRecord.Id)
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDemographicUpdateReviewContext(update.Id, "approve", "")
+	err = adminHandler.ApproveDemographicUpdate(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the update")
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_DEMOGRAPHIC_UPDATE, update.Id)
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal(admin.AUDIT_ACTION_DEMOGRAPHIC_UPDATE_APPROVED, entries[0].Action)
+	suite.Require().NotContains(entries[0].Changes, "Jane", "Names should not be written to the audit log")
+
+	var changes map[string]map[string]any
+	suite.Require().NoError(json.Unmarshal([]byte(entries[0].Changes), &changes))
+	suite.Require().Equal(map[string]any{"before": "[redacted]", "after": "[redacted]"}, changes["first_name"])
+	suite.Require().Equal(constant.DEMOGRAPHIC_UPDATE_ACCEPTED, changes["status"]["after"])
+}
+
+func (suite *IntegrationTestSuite) TestAdminAuditLogChainVerifiesAndIsAppendOnly() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	for range 3 {
+		transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+		c, _ := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionStartReview, "")
+		suite.Require().NoError(adminHandler.TransitionDispute(c))
+	}
+
+	verification, err := dao.AdminAuditLogDao{}.VerifyChain()
+	suite.Require().NoError(err)
+	suite.Require().Equal(3, verification.EntriesChecked)
+	suite.Require().Nil(verification.BrokenAtId, "An untouched chain should verify")
+
+	// The trigger aborts the statement, so run each attempt in a savepoint
+	for _, statement := range []string{"UPDATE admin_audit_log SET actor_email = 'someone@dreamfi.com'", "DELETE FROM admin_audit_log"} {
+		suite.Require().NoError(suite.TestDB.Exec("SAVEPOINT audit_log_append_only").Error)
+		err = suite.TestDB.Exec(statement).Error
+		suite.Require().ErrorContains(err, "admin_audit_log is append-only")
+		suite.Require().NoError(suite.TestDB.Exec("ROLLBACK TO SAVEPOINT audit_log_append_only").Error)
+	}
+}
+
+func (suite *IntegrationTestSuite) TestAdminExportAuditLog() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, _ := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionStartReview, "")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit-log/export.csv?entity="+dao.ADMIN_AUDIT_ENTITY_DISPUTE, nil)
+	rec := httptest.NewRecorder()
+	err := admin.ExportAuditLog(newAdminContext(e.NewContext(req, rec)))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	suite.Require().Len(lines, 2, "Expected the header and one entry")
+	suite.Require().True(strings.HasPrefix(lines[0], "id,created_at,actor_id"))
+	suite.Require().Contains(lines[1], transactionDispute.Id)
+}
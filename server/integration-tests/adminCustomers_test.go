@@ -0,0 +1,101 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/security"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func newAdminContext(c echo.Context) *security.AdminUserContext {
+	return newAdminContextAs(c, "auth0|operator", "operator@dreamfi.com", "sandbox-operations", "operations-admin")
+}
+
+func newAdminContextAs(c echo.Context, userId string, email string, roles ...string) *security.AdminUserContext {
+	return &security.AdminUserContext{
+		Context:     c,
+		UserID:      userId,
+		Email:       email,
+		Name:        "Test Operator",
+		Roles:       roles,
+		Permissions: security.AdminPermissionsForRoles(roles),
+	}
+}
+
+func (suite *IntegrationTestSuite) TestAdminSearchCustomers() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/customers?q="+userRecord.Email, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := admin.SearchCustomers(newAdminContext(c))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.Require().Contains(rec.Body.String(), "/admin/customers/"+userRecord.Id, "Expected matching customer to be linked")
+}
+
+func (suite *IntegrationTestSuite) TestAdminCustomerDetail() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/customers/"+userRecord.Id, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId")
+	c.SetParamNames("userId")
+	c.SetParamValues(userRecord.Id)
+
+	err := admin.CustomerDetail(newAdminContext(c))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.Require().Contains(rec.Body.String(), userRecord.Email)
+	suite.Require().Contains(rec.Body.String(), "/devices/"+strconv.FormatUint(userPublicKey.ID, 10)+"/revoke", "Expected active device to be revocable")
+}
+
+func (suite *IntegrationTestSuite) TestAdminCustomerDetail_NotFound() {
+	userId := uuid.New().String()
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/customers/"+userId, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId")
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+
+	err := admin.CustomerDetail(newAdminContext(c))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusNotFound, rec.Code, "Expected status code 404 Not Found")
+}
+
+func (suite *IntegrationTestSuite) TestAdminRevokeCustomerDevice() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+	deviceId := strconv.FormatUint(userPublicKey.ID, 10)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/customers/"+userRecord.Id+"/devices/"+deviceId+"/revoke", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId/devices/:deviceId/revoke")
+	c.SetParamNames("userId", "deviceId")
+	c.SetParamValues(userRecord.Id, deviceId)
+
+	err := admin.RevokeCustomerDevice(newAdminContext(c))
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the customer")
+
+	revokedKey, err := dao.UserPublicKey{}.FindByIdForUser(userPublicKey.ID, userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().True(revokedKey.IsRevoked(), "Device should be revoked")
+	suite.Require().Equal("operator@dreamfi.com", *revokedKey.RevokedBy, "Operator should be recorded as revoker")
+}
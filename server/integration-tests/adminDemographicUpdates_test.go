@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"process-api/pkg/admin"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) newDemographicUpdateReviewContext(demographicUpdateId, action, reason string) (echo.Context, *httptest.ResponseRecorder) {
+	form := url.Values{}
+	form.Set("reason", reason)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/demographic-updates/"+demographicUpdateId+"/"+action, strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/demographic-updates/:id/" + action)
+	c.SetParamNames("id")
+	c.SetParamValues(demographicUpdateId)
+	return newAdminContext(c), rec
+}
+
+func (suite *IntegrationTestSuite) TestAdminApproveDemographicUpdate() {
+	defer SetupMockForLedger(suite).Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	value, err := json.Marshal(dao.UpdateFullNameRequest{FirstName: "Jane", LastName: "Doe"})
+	suite.Require().NoError(err)
+	update := suite.createDemographicUpdateRecord(value, constant.DEMOGRAPHIC_UPDATE_FULL_NAME, userRecord.Id)
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDemographicUpdateReviewContext(update.Id, "approve", "")
+
+	err = adminHandler.ApproveDemographicUpdate(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the update")
+
+	updatedUser, err := dao.MasterUserRecordDao{}.FindOneByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal("Jane", updatedUser.FirstName)
+	suite.Require().Equal("Doe", updatedUser.LastName)
+
+	reviewedUpdate, err := dao.DemographicUpdatesDao{}.FindById(update.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DEMOGRAPHIC_UPDATE_ACCEPTED, reviewedUpdate.Status)
+	suite.Require().Equal("operator@dreamfi.com", *reviewedUpdate.ReviewedBy)
+
+	events, err := dao.DemographicUpdateEventDao{}.FindByDemographicUpdateId(update.Id)
+	suite.Require().NoError(err)
+	actions := make([]string, len(events))
+	for i, event := range events {
+		actions[i] = event.Action
+	}
+	suite.Require().Contains(actions, dao.DEMOGRAPHIC_UPDATE_EVENT_LEDGER_UPDATED)
+	suite.Require().Contains(actions, dao.DEMOGRAPHIC_UPDATE_EVENT_APPROVED)
+	suite.Require().Contains(actions, dao.DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_QUEUED)
+
+	// A second review of the same update is refused
+	c, rec = suite.newDemographicUpdateReviewContext(update.Id, "reject", "Duplicate")
+	err = adminHandler.RejectDemographicUpdate(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusConflict, rec.Code, "Expected status code 409 Conflict")
+}
+
+func (suite *IntegrationTestSuite) TestAdminRejectDemographicUpdate() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	value, err := json.Marshal(dao.UpdateCustomerAddressRequest{StreetAddress: "1 Main St", ZipCode: "94105", City: "San Francisco", State: "CA"})
+	suite.Require().NoError(err)
+	update := suite.createDemographicUpdateRecord(value, constant.DEMOGRAPHIC_UPDATE_ADDRESS, userRecord.Id)
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDemographicUpdateReviewContext(update.Id, "reject", "Proof of address did not match")
+
+	err = adminHandler.RejectDemographicUpdate(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the update")
+
+	unchangedUser, err := dao.MasterUserRecordDao{}.FindOneByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(userRecord.StreetAddress, unchangedUser.StreetAddress, "Rejected address should not be applied")
+
+	reviewedUpdate, err := dao.DemographicUpdatesDao{}.FindById(update.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DEMOGRAPHIC_UPDATE_REJECTED, reviewedUpdate.Status)
+	suite.Require().Equal("Proof of address did not match", *reviewedUpdate.ReviewReason)
+}
+
+func (suite *IntegrationTestSuite) TestAdminRejectDemographicUpdate_RequiresReason() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	value, err := json.Marshal(dao.UpdateFullNameRequest{FirstName: "Jane", LastName: "Doe"})
+	suite.Require().NoError(err)
+	update := suite.createDemographicUpdateRecord(value, constant.DEMOGRAPHIC_UPDATE_FULL_NAME, userRecord.Id)
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDemographicUpdateReviewContext(update.Id, "reject", " ")
+
+	err = adminHandler.RejectDemographicUpdate(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Expected status code 400 Bad Request")
+
+	pendingUpdate, err := dao.DemographicUpdatesDao{}.FindById(update.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DEMOGRAPHIC_UPDATE_PENDING, pendingUpdate.Status)
+}
@@ -0,0 +1,235 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/handler"
+	"process-api/pkg/logging"
+	"process-api/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) createTransactionDisputeRecord(userId string, status string, submittedAt time.Time) dao.TransactionDisputeDao {
+	provisionalCreditDueAt, resolutionDueAt := dispute.Deadlines(submittedAt)
+	transactionDispute := dao.TransactionDisputeDao{
+		Id:                     uuid.New().String(),
+		Status:                 status,
+		TransactionIdentifier:  "ledger.ach.transfer_ach_pull_" + uuid.New().String(),
+		Reason:                 "Duplicate transaction",
+		Details:                "I was charged twice.",
+		UserId:                 userId,
+		CreatedAt:              submittedAt,
+		AmountCents:            utils.Pointer(int64(1457)),
+		ProvisionalCreditDueAt: &provisionalCreditDueAt,
+		ResolutionDueAt:        &resolutionDueAt,
+	}
+	err := suite.TestDB.Create(&transactionDispute).Error
+	suite.Require().NoError(err, "Failed to insert transaction dispute record")
+	return transactionDispute
+}
+
+func (suite *IntegrationTestSuite) newDisputeActionContext(disputeId string, action dispute.Action, reason string) (echo.Context, *httptest.ResponseRecorder) {
+	form := url.Values{}
+	form.Set("reason", reason)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/disputes/"+disputeId+"/"+string(action), strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/disputes/:id/:action")
+	c.SetParamNames("id", "action")
+	c.SetParamValues(disputeId, string(action))
+	return newAdminContext(c), rec
+}
+
+// approveDisputeAction approves the dispute action awaiting approval as a second operator
+func (suite *IntegrationTestSuite) approveDisputeAction(adminHandler admin.Handler, disputeId string, action dispute.Action) *httptest.ResponseRecorder {
+	pending, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, disputeId)
+	suite.Require().NoError(err)
+	suite.Require().Len(pending, 1, "The action should be awaiting approval")
+	suite.Require().Equal(admin.AUDIT_ACTION_DISPUTE_PREFIX+string(action), pending[0].Action)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/approvals/"+pending[0].Id+"/approve", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/approvals/:id/approve")
+	c.SetParamNames("id")
+	c.SetParamValues(pending[0].Id)
+
+	err = adminHandler.ApproveRequest(newAdminContextAs(c, "auth0|checker", "checker@dreamfi.com", "sandbox-operations", "operations-disputes"))
+	suite.Require().NoError(err, "Handler should not return an error")
+	return rec
+}
+
+func (suite *IntegrationTestSuite) disputeEventActions(disputeId string) []string {
+	events, err := dao.TransactionDisputeEventDao{}.FindByTransactionDisputeId(disputeId)
+	suite.Require().NoError(err)
+	actions := make([]string, len(events))
+	for i, event := range events {
+		actions[i] = event.Action
+	}
+	return actions
+}
+
+func (suite *IntegrationTestSuite) TestAdminDisputeProvisionalCreditThenResolveForMerchant() {
+	defer SetupMockForLedger(suite).Close()
+	suite.configEmail()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
+	suite.createUserAccountCard(userRecord.Id)
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, clock.Now())
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionProvisionalCredit, "")
+	err := adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the dispute")
+
+	requested, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_SUBMITTED, requested.Status, "The credit should wait for a second operator's approval")
+
+	rec = suite.approveDisputeAction(adminHandler, transactionDispute.Id, dispute.ActionProvisionalCredit)
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the approvals")
+
+	credited, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_PROVISIONALLY_CREDITED, credited.Status)
+	suite.Require().NotNil(credited.ProvisionalCreditTransaction, "The ledger reference of the credit should be stored")
+	suite.Require().Equal("ledger.paymentv2.provisional_credit_1758673957303644368", *credited.ProvisionalCreditTransaction)
+	suite.Require().NotNil(credited.CreditedAt)
+
+	c, rec = suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionResolveMerchant, "The merchant provided proof of delivery")
+	err = adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the dispute")
+
+	resolved, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_RESOLVED_MERCHANT, resolved.Status)
+	suite.Require().NotNil(resolved.VoidCreditTransaction, "The credit should be reversed")
+	suite.Require().NotNil(resolved.VoidedAt)
+	suite.Require().Equal("operator@dreamfi.com", *resolved.ResolvedBy)
+	suite.Require().Equal("The merchant provided proof of delivery", *resolved.ResolutionReason)
+
+	actions := suite.disputeEventActions(transactionDispute.Id)
+	suite.Require().Contains(actions, dao.DISPUTE_EVENT_CREDIT_ISSUED)
+	suite.Require().Contains(actions, dao.DISPUTE_EVENT_PROVISIONALLY_CREDITED)
+	suite.Require().Contains(actions, dao.DISPUTE_EVENT_CREDIT_REVERSED)
+	suite.Require().Contains(actions, dao.DISPUTE_EVENT_RESOLVED_MERCHANT)
+	suite.Require().Contains(actions, dao.DISPUTE_EVENT_NOTIFICATION_QUEUED)
+
+	// A resolved dispute cannot be reopened
+	c, rec = suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionStartReview, "")
+	err = adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusConflict, rec.Code, "Expected status code 409 Conflict")
+}
+
+func (suite *IntegrationTestSuite) TestDisputeTransitionDoesNotCreditTwice() {
+	suite.configEmail()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	// A credit posted by an attempt whose status change then failed
+	err := suite.TestDB.Model(&transactionDispute).Updates(map[string]any{
+		"provisional_credit_transaction": "ledger.paymentv2.provisional_credit_earlier",
+		"credited_at":                    clock.Now(),
+	}).Error
+	suite.Require().NoError(err)
+
+	// No ledger is mocked, so posting another credit would fail the transition
+	disputeService := dispute.Service{RiverClient: suite.riverClient}
+	credited, err := disputeService.Transition(context.Background(), logging.Logger, transactionDispute.Id, dispute.ActionProvisionalCredit, "operator@dreamfi.com", "")
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_PROVISIONALLY_CREDITED, credited.Status)
+	suite.Require().Equal("ledger.paymentv2.provisional_credit_earlier", *credited.ProvisionalCreditTransaction)
+	suite.Require().NotContains(suite.disputeEventActions(transactionDispute.Id), dao.DISPUTE_EVENT_CREDIT_ISSUED)
+}
+
+func (suite *IntegrationTestSuite) TestAdminDisputeResolveForCustomerWithoutProvisionalCredit() {
+	defer SetupMockForLedger(suite).Close()
+	suite.configEmail()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
+	suite.createUserAccountCard(userRecord.Id)
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionResolveCustomer, "")
+
+	err := adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the dispute")
+	rec = suite.approveDisputeAction(adminHandler, transactionDispute.Id, dispute.ActionResolveCustomer)
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the approvals")
+
+	resolved, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_RESOLVED_CUSTOMER, resolved.Status)
+	suite.Require().NotNil(resolved.ProvisionalCreditTransaction, "The customer should be credited when the dispute is resolved")
+	suite.Require().Nil(resolved.VoidCreditTransaction)
+	suite.Require().NotNil(resolved.ResolvedAt)
+}
+
+func (suite *IntegrationTestSuite) TestAdminDisputeResolveForMerchant_RequiresReason() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionResolveMerchant, " ")
+
+	err := adminHandler.TransitionDispute(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Expected status code 400 Bad Request")
+
+	unchanged, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_UNDER_REVIEW, unchanged.Status)
+}
+
+func (suite *IntegrationTestSuite) TestEscalateDisputesNearingDeadline() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	now := clock.Now()
+
+	// Submitted two weeks ago, so the provisional credit is due about now
+	nearingCredit := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, now.AddDate(0, 0, -14))
+	fresh := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, now)
+	credited := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_PROVISIONALLY_CREDITED, now.AddDate(0, 0, -14))
+
+	err := dispute.EscalateDisputesNearingDeadline(now)
+	suite.Require().NoError(err)
+
+	escalated, err := dao.TransactionDisputeDao{}.FindById(nearingCredit.Id)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(escalated.ProvisionalCreditEscalatedAt, "Dispute nearing its provisional credit deadline should be escalated")
+	suite.Require().Nil(escalated.ResolutionEscalatedAt, "Resolution deadline is still weeks away")
+	suite.Require().Equal([]string{dao.DISPUTE_EVENT_PROVISIONAL_CREDIT_ESCALATED}, suite.disputeEventActions(nearingCredit.Id))
+
+	notEscalated, err := dao.TransactionDisputeDao{}.FindById(fresh.Id)
+	suite.Require().NoError(err)
+	suite.Require().Nil(notEscalated.ProvisionalCreditEscalatedAt, "New dispute should not be escalated")
+
+	creditedDispute, err := dao.TransactionDisputeDao{}.FindById(credited.Id)
+	suite.Require().NoError(err)
+	suite.Require().Nil(creditedDispute.ProvisionalCreditEscalatedAt, "Credited dispute no longer has a provisional credit deadline")
+
+	// Each deadline is escalated once
+	err = dispute.EscalateDisputesNearingDeadline(now)
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.disputeEventActions(nearingCredit.Id), 1)
+}
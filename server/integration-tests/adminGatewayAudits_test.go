@@ -0,0 +1,103 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/audit/audittest"
+	"process-api/pkg/handler"
+	"process-api/pkg/utils"
+	"strings"
+	"time"
+
+	api "process-api/pkg/audit/api/v1"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (suite *IntegrationTestSuite) newGatewayAuditsContext(path string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	return newAdminContext(e.NewContext(req, rec)), rec
+}
+
+func (suite *IntegrationTestSuite) startFakeGatewayAudits() *audittest.Server {
+	requestedAt := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	server, err := audittest.NewServer(
+		audittest.Audit{Id: "100000000034052", Audit: &api.HttpAudit{
+			RequestId:        "req-customer",
+			Method:           "POST",
+			Url:              "https://ledger.example.com/jsonrpc",
+			ResponseStatus:   200,
+			RequestBody:      []byte(`{"method":"GetAccount"}`),
+			RequestTimestamp: timestamppb.New(requestedAt),
+		}},
+		audittest.Audit{Id: "100000000099999", Audit: &api.HttpAudit{
+			RequestId:        "req-other",
+			Method:           "POST",
+			Url:              "https://ledger.example.com/jsonrpc",
+			ResponseStatus:   500,
+			RequestTimestamp: timestamppb.New(requestedAt),
+		}},
+	)
+	suite.Require().NoError(err)
+	return server
+}
+
+func (suite *IntegrationTestSuite) TestAdminGatewayAuditsForCustomer() {
+	server := suite.startFakeGatewayAudits()
+	defer server.Close()
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
+	adminHandler := admin.Handler{RiverClient: suite.riverClient, AuditClient: server.Client()}
+
+	c, rec := suite.newGatewayAuditsContext("/admin/gateway-audits?customer=" + userRecord.Id + "&from=2025-11-01T00:00")
+	err := adminHandler.GatewayAudits(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().Contains(rec.Body.String(), "req-customer")
+	suite.Require().NotContains(rec.Body.String(), "req-other", "Another customer's audits should not be listed")
+
+	requests := server.Requests()
+	suite.Require().Len(requests, 1)
+	suite.Require().Equal("100000000034052", requests[0].GetId(), "Audits should be queried by ledger customer number")
+	suite.Require().Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), requests[0].GetFrom().AsTime())
+	suite.Require().Equal(int64(1), requests[0].GetPageNumber())
+}
+
+func (suite *IntegrationTestSuite) TestAdminGatewayAudits_InvalidTime() {
+	server := suite.startFakeGatewayAudits()
+	defer server.Close()
+
+	adminHandler := admin.Handler{AuditClient: server.Client()}
+	c, rec := suite.newGatewayAuditsContext("/admin/gateway-audits?requestId=req-other&from=yesterday")
+	err := adminHandler.GatewayAudits(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Expected status code 400 Bad Request")
+	suite.Require().Empty(server.Requests(), "The gateway should not be queried")
+}
+
+func (suite *IntegrationTestSuite) TestAdminExportGatewayAuditsByRequestId() {
+	server := suite.startFakeGatewayAudits()
+	defer server.Close()
+
+	adminHandler := admin.Handler{AuditClient: server.Client()}
+	c, rec := suite.newGatewayAuditsContext("/admin/gateway-audits/export.csv?requestId=req-other")
+	err := adminHandler.ExportGatewayAudits(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	suite.Require().Len(lines, 2, "Expected the header and one audit")
+	suite.Require().True(strings.HasPrefix(lines[0], "request_id,request_timestamp"))
+	suite.Require().True(strings.HasPrefix(lines[1], "req-other,2025-11-01T12:00:00Z"))
+}
+
+func (suite *IntegrationTestSuite) TestAdminGatewayAudits_GatewayUnavailable() {
+	c, rec := suite.newGatewayAuditsContext("/admin/gateway-audits?requestId=req-other")
+	err := (&admin.Handler{}).GatewayAudits(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusServiceUnavailable, rec.Code, "Expected status code 503 Service Unavailable")
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/handler"
+	"process-api/pkg/security"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) newCustomerDetailContext(userId string, query string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/customers/"+userId+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId")
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+	return c, rec
+}
+
+func (suite *IntegrationTestSuite) TestAdminDisputeCreditRequestsApproval() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionProvisionalCredit, "Investigation needs more time")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "Expected redirect back to the dispute")
+
+	pending, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(pending, 1)
+	suite.Require().Equal("operator@dreamfi.com", pending[0].RequestedByEmail)
+	suite.Require().Equal(string(security.ADMIN_PERMISSION_MONEY_CREDIT), pending[0].Permission)
+	suite.Require().Equal("Investigation needs more time", *pending[0].Reason)
+
+	// Requesting the same action again is refused while the first request is pending
+	c, rec = suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionProvisionalCredit, "")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+	suite.Require().Equal(http.StatusConflict, rec.Code, "Expected status code 409 Conflict")
+}
+
+func (suite *IntegrationTestSuite) TestAdminApproveRequest_RequiresSecondOperator() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+
+	c, _ := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionResolveCustomer, "")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+	pending, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(pending, 1)
+
+	approve := func(adminCtx func(echo.Context) *security.AdminUserContext) *httptest.ResponseRecorder {
+		e := handler.NewEcho()
+		req := httptest.NewRequest(http.MethodPost, "/admin/approvals/"+pending[0].Id+"/approve", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/admin/approvals/:id/approve")
+		c.SetParamNames("id")
+		c.SetParamValues(pending[0].Id)
+		suite.Require().NoError(adminHandler.ApproveRequest(adminCtx(c)))
+		return rec
+	}
+
+	rec := approve(newAdminContext)
+	suite.Require().Equal(http.StatusForbidden, rec.Code, "The requesting operator should not approve their own request")
+
+	rec = approve(func(c echo.Context) *security.AdminUserContext {
+		return newAdminContextAs(c, "auth0|support", "support@dreamfi.com", "sandbox-operations", "operations-support")
+	})
+	suite.Require().Equal(http.StatusForbidden, rec.Code, "An operator without money:credit should not approve a credit")
+
+	unchanged, err := dao.TransactionDisputeDao{}.FindById(transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.DISPUTE_UNDER_REVIEW, unchanged.Status)
+	request, err := dao.AdminApprovalRequestDao{}.FindById(pending[0].Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.ADMIN_APPROVAL_PENDING, request.Status)
+}
+
+func (suite *IntegrationTestSuite) TestAdminApproveRequest_StaleActionFails() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+
+	c, _ := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionProvisionalCredit, "")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+
+	// The dispute is resolved for the merchant before the credit is approved
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionResolveMerchant, "The merchant provided proof of delivery")
+	suite.Require().NoError(adminHandler.TransitionDispute(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+
+	pending, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(pending, 1)
+	rec = suite.approveDisputeAction(adminHandler, transactionDispute.Id, dispute.ActionProvisionalCredit)
+	suite.Require().Equal(http.StatusConflict, rec.Code, "Expected status code 409 Conflict")
+
+	request, err := dao.AdminApprovalRequestDao{}.FindById(pending[0].Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(constant.ADMIN_APPROVAL_FAILED, request.Status)
+	suite.Require().Equal("checker@dreamfi.com", *request.ReviewedByEmail)
+	suite.Require().NotNil(request.Error)
+}
+
+func (suite *IntegrationTestSuite) TestAdminTransitionDispute_RequiresMoneyCredit() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+
+	c, rec := suite.newDisputeActionContext(transactionDispute.Id, dispute.ActionProvisionalCredit, "")
+	adminCtx := c.(*security.AdminUserContext)
+	adminCtx.Permissions = []security.AdminPermission{security.ADMIN_PERMISSION_CUSTOMERS_READ, security.ADMIN_PERMISSION_DISPUTES_RESOLVE}
+
+	suite.Require().NoError(adminHandler.TransitionDispute(adminCtx))
+	suite.Require().Equal(http.StatusForbidden, rec.Code, "Expected status code 403 Forbidden")
+	pending, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id)
+	suite.Require().NoError(err)
+	suite.Require().Empty(pending, "No approval should be requested")
+}
+
+func (suite *IntegrationTestSuite) TestAdminCustomerDetail_MasksPIIUntilRevealed() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserPublicKeyRecord(userRecord.Id)
+
+	readOnly := func(c echo.Context) *security.AdminUserContext {
+		return newAdminContextAs(c, "auth0|reader", "reader@dreamfi.com", "sandbox-operations")
+	}
+
+	c, rec := suite.newCustomerDetailContext(userRecord.Id, "")
+	suite.Require().NoError(admin.CustomerDetail(readOnly(c)))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().NotContains(rec.Body.String(), userRecord.Email, "Email should be masked")
+	suite.Require().NotContains(rec.Body.String(), userRecord.StreetAddress, "Address should be masked")
+	suite.Require().NotContains(rec.Body.String(), "Reveal personal details", "Reveal should not be offered without permission")
+	suite.Require().NotContains(rec.Body.String(), ">Revoke<", "Revoke should not be offered without permission")
+
+	c, rec = suite.newCustomerDetailContext(userRecord.Id, "?reveal=pii")
+	suite.Require().NoError(admin.CustomerDetail(readOnly(c)))
+	suite.Require().Equal(http.StatusForbidden, rec.Code, "Expected status code 403 Forbidden")
+
+	c, rec = suite.newCustomerDetailContext(userRecord.Id, "?reveal=pii")
+	suite.Require().NoError(admin.CustomerDetail(newAdminContext(c)))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().Contains(rec.Body.String(), userRecord.Email)
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_CUSTOMER, userRecord.Id)
+	suite.Require().Len(entries, 1, "Revealing PII should be audited")
+	suite.Require().Equal(admin.AUDIT_ACTION_CUSTOMER_PII_REVEALED, entries[0].Action)
+	suite.Require().Equal("operator@dreamfi.com", entries[0].ActorEmail)
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/compliance"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/salesforce"
+	"process-api/pkg/security"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) newComplianceHoldContext(userId string, action string, form url.Values) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/customers/"+userId+"/compliance-hold"+action, strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId/compliance-hold" + action)
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+	return newAdminContextAs(c, "auth0|compliance", "compliance@dreamfi.com", "sandbox-operations", "operations-compliance"), rec
+}
+
+func (suite *IntegrationTestSuite) TestComplianceHoldBlocksAchPull() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	_, err := compliance.Place(logging.Logger, userRecord.Id, compliance.PlaceRequest{Reason: "Suspected fraud", Via: constant.COMPLIANCE_HOLD_VIA_ADMIN, PlacedBy: "compliance@dreamfi.com"}, clock.Now())
+	suite.Require().NoError(err)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/account/accounts/ach/pull", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	reached := false
+	err = security.ComplianceHoldMiddleware(func(c echo.Context) error {
+		reached = true
+		return nil
+	})(security.GenerateLoggedInRegisteredUserContext(userRecord.Id, "publicKey", c))
+
+	errResp, ok := err.(*response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type *response.ErrorResponse")
+	suite.Require().Equal(http.StatusForbidden, errResp.StatusCode)
+	suite.Require().Equal(constant.COMPLIANCE_HOLD, errResp.ErrorCode)
+	suite.Require().False(reached, "The transfer should not be attempted")
+}
+
+func (suite *IntegrationTestSuite) TestAdminPlaceAndReleaseComplianceHold() {
+	defer SetupMockForLedger(suite).Close()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+
+	c, rec := suite.newComplianceHoldContext(userRecord.Id, "", url.Values{"reason": {"Suspected account takeover"}, "mirror_to_ledger": {"true"}})
+	suite.Require().NoError(admin.PlaceComplianceHold(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+
+	hold, err := dao.ComplianceHoldDao{}.FindActiveForUser(userRecord.Id, clock.Now())
+	suite.Require().NoError(err)
+	suite.Require().NotNil(hold, "The hold should be active")
+	suite.Require().Equal("compliance@dreamfi.com", hold.PlacedBy)
+	suite.Require().NotNil(hold.LedgerMirroredAt, "The hold should be mirrored to the ledger")
+	suite.Require().Nil(hold.LedgerError)
+
+	c, rec = suite.newComplianceHoldContext(userRecord.Id, "", url.Values{"reason": {"Again"}})
+	suite.Require().NoError(admin.PlaceComplianceHold(c))
+	suite.Require().Equal(http.StatusConflict, rec.Code, "A second hold should be refused")
+
+	c, rec = suite.newComplianceHoldContext(userRecord.Id, "/release", url.Values{})
+	suite.Require().NoError(admin.ReleaseComplianceHold(c))
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Releasing should need a reason")
+
+	c, rec = suite.newComplianceHoldContext(userRecord.Id, "/release", url.Values{"reason": {"Customer verified"}})
+	suite.Require().NoError(admin.ReleaseComplianceHold(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+	suite.Require().Nil(dao.RequireNoComplianceHold(userRecord.Id), "Money movement should be allowed again")
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD, hold.Id)
+	suite.Require().Len(entries, 2)
+	actions := []string{entries[0].Action, entries[1].Action}
+	suite.Require().ElementsMatch([]string{admin.AUDIT_ACTION_COMPLIANCE_HOLD_PLACED, admin.AUDIT_ACTION_COMPLIANCE_HOLD_RELEASED}, actions)
+	for _, entry := range entries {
+		suite.Require().Equal("compliance@dreamfi.com", entry.ActorEmail)
+		suite.Require().Equal(userRecord.Id, *entry.UserId)
+	}
+}
+
+func (suite *IntegrationTestSuite) TestComplianceHoldExpires() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	now := clock.Now()
+	expiresAt := now.Add(time.Hour)
+	hold, err := compliance.Place(logging.Logger, userRecord.Id, compliance.PlaceRequest{Reason: "Pending review", ExpiresAt: &expiresAt, Via: constant.COMPLIANCE_HOLD_VIA_ADMIN, PlacedBy: "compliance@dreamfi.com"}, now)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(compliance.ReleaseExpired(now))
+	active, err := dao.ComplianceHoldDao{}.FindActiveForUser(userRecord.Id, now)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(active, "A hold should stay active until it expires")
+
+	suite.Require().NoError(compliance.ReleaseExpired(expiresAt))
+	holds, err := dao.ComplianceHoldDao{}.FindByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(holds, 1)
+	suite.Require().Equal(hold.Id, holds[0].Id)
+	suite.Require().NotNil(holds[0].ReleasedAt, "An expired hold should be released")
+	suite.Require().Equal(constant.COMPLIANCE_HOLD_RELEASED_BY_SYSTEM, *holds[0].ReleasedBy)
+}
+
+func (suite *IntegrationTestSuite) newSalesforceComplianceHoldContext(ledgerAccountID string, method string, body string, scope string) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := newSalesforceContext(method, "/api/salesforce/accounts/"+ledgerAccountID+"/compliance-hold", body, scope)
+	c.Request().Header.Set(salesforce.HeaderSalesforceAgent, "agent@dreamfi.com")
+	c.Request().Header.Set(security.HeaderIdempotencyKey, uuid.New().String())
+	c.SetPath("/api/salesforce/accounts/:ledgerAccountID/compliance-hold")
+	c.SetParamNames("ledgerAccountID")
+	c.SetParamValues(ledgerAccountID)
+	return c, rec
+}
+
+func (suite *IntegrationTestSuite) TestSalesforcePlaceComplianceHold() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+	ledgerAccountID := "500400026990"
+	suite.Require().NoError(suite.TestDB.Model(&dao.UserAccountCardDao{}).Where("user_id = ?", userRecord.Id).Update("account_id", ledgerAccountID).Error)
+
+	c, _ := suite.newSalesforceComplianceHoldContext(ledgerAccountID, http.MethodPut, `{"reason": "Reported stolen card"}`, "read:compliance_hold")
+	err := salesforce.AgentAction("write:compliance_hold")(salesforce.SalesforcePlaceComplianceHold)(c)
+	errResp, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusForbidden, errResp.StatusCode, "Placing a hold should need the write scope")
+
+	c, rec := suite.newSalesforceComplianceHoldContext(ledgerAccountID, http.MethodPut, `{"reason": "Reported stolen card"}`, "read:compliance_hold write:compliance_hold")
+	suite.Require().NoError(salesforce.AgentAction("write:compliance_hold")(salesforce.SalesforcePlaceComplianceHold)(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var responseBody salesforce.ComplianceHoldResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Require().True(responseBody.Active)
+	suite.Require().Equal(constant.COMPLIANCE_HOLD_VIA_SALESFORCE, responseBody.PlacedVia)
+	suite.Require().Equal("agent@dreamfi.com", responseBody.PlacedBy)
+
+	hold, err := dao.ComplianceHoldDao{}.FindActiveForUser(userRecord.Id, clock.Now())
+	suite.Require().NoError(err)
+	suite.Require().NotNil(hold)
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD, hold.Id)
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal("salesforce|salesforce-client@clients", entries[0].ActorId)
+	suite.Require().Equal("agent@dreamfi.com", entries[0].ActorEmail)
+	suite.Require().Equal("salesforce", entries[0].ActorRoles)
+
+	c, rec = suite.newSalesforceComplianceHoldContext(ledgerAccountID, http.MethodDelete, `{"reason": "Card recovered"}`, "write:compliance_hold")
+	suite.Require().NoError(salesforce.AgentAction("write:compliance_hold")(salesforce.SalesforceReleaseComplianceHold)(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Require().False(responseBody.Active)
+}
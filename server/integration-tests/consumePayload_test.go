@@ -3,23 +3,41 @@ package test
 import (
 	"process-api/pkg/clock"
 	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/model/response"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var testPayloadType = "test_payload"
+
+// consumePayload runs the checks and the consumption that consumeSignedPayload does
+// around verifying the signature
+func (suite *IntegrationTestSuite) consumePayload(userId, payloadId, payloadType string) (*dao.SignablePayloadDao, *response.ErrorResponse) {
+	payloadRecord, errResponse := dao.RequireConsumablePayload(userId, payloadId, payloadType)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+	if errResponse := dao.MarkPayloadConsumed(payloadRecord); errResponse != nil {
+		return nil, errResponse
+	}
+	return payloadRecord, nil
+}
+
 func (suite *IntegrationTestSuite) TestConsumePayload_Success() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
-		Id:      payloadId,
-		Payload: `{"test": "data"}`,
-		UserId:  &user.Id,
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, err := dao.ConsumePayload(user.Id, payloadId)
+	result, err := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Require().Nil(err)
 	suite.Equal(payloadId, result.Id)
@@ -31,7 +49,7 @@ func (suite *IntegrationTestSuite) TestConsumePayload_NotFound() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	nonExistentPayloadId := uuid.New().String()
 
-	result, err := dao.ConsumePayload(user.Id, nonExistentPayloadId)
+	result, err := suite.consumePayload(user.Id, nonExistentPayloadId, testPayloadType)
 
 	suite.Nil(result)
 	suite.Equal("PAYLOAD_NOT_FOUND", err.ErrorCode)
@@ -44,14 +62,15 @@ func (suite *IntegrationTestSuite) TestConsumePayload_WrongUser() {
 	user2 := suite.createTestUser(PartialMasterUserRecordDao{Email: &user2Email, MobileNo: &user2MobileNo})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
-		Id:      payloadId,
-		Payload: `{"test": "data"}`,
-		UserId:  &user1.Id,
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user1.Id,
+		PayloadType: &testPayloadType,
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, errResponse := dao.ConsumePayload(user2.Id, payloadId)
+	result, errResponse := suite.consumePayload(user2.Id, payloadId, testPayloadType)
 
 	suite.Nil(result)
 	suite.Equal("PAYLOAD_NOT_FOUND", errResponse.ErrorCode)
@@ -62,15 +81,16 @@ func (suite *IntegrationTestSuite) TestConsumePayload_AlreadyConsumed() {
 	payloadId := uuid.New().String()
 	consumedTime := clock.Now().Add(-1 * time.Minute)
 	payload := dao.SignablePayloadDao{
-		Id:         payloadId,
-		Payload:    `{"test": "data"}`,
-		UserId:     &user.Id,
-		ConsumedAt: &consumedTime,
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
+		ConsumedAt:  &consumedTime,
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, errResponse := dao.ConsumePayload(user.Id, payloadId)
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Nil(result)
 	suite.Equal("PAYLOAD_ALREADY_CONSUMED", errResponse.ErrorCode)
@@ -80,15 +100,16 @@ func (suite *IntegrationTestSuite) TestConsumePayload_Expired() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
-		Id:        payloadId,
-		Payload:   `{"test": "data"}`,
-		UserId:    &user.Id,
-		CreatedAt: clock.Now().Add(-5 * time.Minute),
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
+		CreatedAt:   clock.Now().Add(-5 * time.Minute),
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, errResponse := dao.ConsumePayload(user.Id, payloadId)
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Nil(result)
 	suite.Equal("PAYLOAD_EXPIRED", errResponse.ErrorCode)
@@ -98,19 +119,20 @@ func (suite *IntegrationTestSuite) TestConsumePayload_ReplayAttack() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
-		Id:      payloadId,
-		Payload: `{"test": "data"}`,
-		UserId:  &user.Id,
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result1, err1 := dao.ConsumePayload(user.Id, payloadId)
+	result1, err1 := suite.consumePayload(user.Id, payloadId, testPayloadType)
 	suite.Require().Nil(err1)
 	suite.NotNil(result1)
 	suite.NotNil(result1.ConsumedAt)
 
-	result2, errRepsonse2 := dao.ConsumePayload(user.Id, payloadId)
+	result2, errRepsonse2 := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Nil(result2)
 	suite.Equal("PAYLOAD_ALREADY_CONSUMED", errRepsonse2.ErrorCode)
@@ -120,33 +142,121 @@ func (suite *IntegrationTestSuite) TestConsumePayload_BoundaryExpiration() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
-		Id:        payloadId,
-		Payload:   `{"test": "data"}`,
-		UserId:    &user.Id,
-		CreatedAt: clock.Now().Add(-3*time.Minute - time.Second),
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
+		CreatedAt:   clock.Now().Add(-3*time.Minute - time.Second),
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, errResponse := dao.ConsumePayload(user.Id, payloadId)
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Nil(result)
 	suite.Equal("PAYLOAD_EXPIRED", errResponse.ErrorCode)
 }
 
 func (suite *IntegrationTestSuite) TestConsumePayload_NullUserId() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+	payloadId := uuid.New().String()
+	payload := dao.SignablePayloadDao{
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      nil,
+		PayloadType: &testPayloadType,
+	}
+	err := suite.TestDB.Create(&payload).Error
+	suite.Require().NoError(err)
+
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
+
+	suite.Nil(result)
+	suite.Equal("PAYLOAD_NOT_FOUND", errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) TestConsumePayload_TypeMismatch() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+	payloadId := uuid.New().String()
+	payloadType := ledger.AchCreditPayload.Name
+	payload := dao.SignablePayloadDao{
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &payloadType,
+	}
+	err := suite.TestDB.Create(&payload).Error
+	suite.Require().NoError(err)
+
+	result, errResponse := suite.consumePayload(user.Id, payloadId, ledger.AchDebitPayload.Name)
+
+	suite.Nil(result)
+	suite.Equal("PAYLOAD_TYPE_MISMATCH", errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) TestConsumePayload_UntypedPayload() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	payloadId := uuid.New().String()
 	payload := dao.SignablePayloadDao{
 		Id:      payloadId,
 		Payload: `{"test": "data"}`,
-		UserId:  nil,
+		UserId:  &user.Id,
 	}
 	err := suite.TestDB.Create(&payload).Error
 	suite.Require().NoError(err)
 
-	result, errResponse := dao.ConsumePayload(user.Id, payloadId)
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
 
 	suite.Nil(result)
-	suite.Equal("PAYLOAD_NOT_FOUND", errResponse.ErrorCode)
+	suite.Equal("PAYLOAD_TYPE_MISMATCH", errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) TestConsumePayload_ExpiresAt() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+	payloadId := uuid.New().String()
+	expiresAt := clock.Now().Add(-time.Second)
+	payload := dao.SignablePayloadDao{
+		Id:          payloadId,
+		Payload:     `{"test": "data"}`,
+		UserId:      &user.Id,
+		PayloadType: &testPayloadType,
+		ExpiresAt:   &expiresAt,
+	}
+	err := suite.TestDB.Create(&payload).Error
+	suite.Require().NoError(err)
+
+	result, errResponse := suite.consumePayload(user.Id, payloadId, testPayloadType)
+
+	suite.Nil(result)
+	suite.Equal("PAYLOAD_EXPIRED", errResponse.ErrorCode)
+}
+
+func (suite *IntegrationTestSuite) TestDeleteExpiredUnconsumedPayloads() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+	now := clock.Now()
+	expired := now.Add(-time.Minute)
+	notExpired := now.Add(time.Minute)
+
+	expiredPayload := dao.SignablePayloadDao{Id: uuid.New().String(), Payload: `{}`, UserId: &user.Id, PayloadType: &testPayloadType, ExpiresAt: &expired}
+	consumedPayload := dao.SignablePayloadDao{Id: uuid.New().String(), Payload: `{}`, UserId: &user.Id, PayloadType: &testPayloadType, ExpiresAt: &expired, ConsumedAt: &expired}
+	activePayload := dao.SignablePayloadDao{Id: uuid.New().String(), Payload: `{}`, UserId: &user.Id, PayloadType: &testPayloadType, ExpiresAt: &notExpired}
+	for _, payload := range []*dao.SignablePayloadDao{&expiredPayload, &consumedPayload, &activePayload} {
+		suite.Require().NoError(suite.TestDB.Create(payload).Error)
+	}
+
+	deleted, err := dao.DeleteExpiredUnconsumedPayloads(now)
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), deleted)
+
+	remaining, err := dao.SignablePayloadDao{}.FindById(expiredPayload.Id)
+	suite.Require().NoError(err)
+	suite.Nil(remaining, "Expired unconsumed payload should be deleted")
+
+	remaining, err = dao.SignablePayloadDao{}.FindById(consumedPayload.Id)
+	suite.Require().NoError(err)
+	suite.NotNil(remaining, "Consumed payload should be kept")
+
+	remaining, err = dao.SignablePayloadDao{}.FindById(activePayload.Id)
+	suite.Require().NoError(err)
+	suite.NotNil(remaining, "Unexpired payload should be kept")
 }
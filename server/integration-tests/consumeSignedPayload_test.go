@@ -0,0 +1,76 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/constant"
+	"process-api/pkg/crypto"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/ledger"
+	"process-api/pkg/model/request"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+
+	"github.com/google/uuid"
+)
+
+func (suite *IntegrationTestSuite) TestGetStatementRejectsInvalidSignature() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	jsonPayloadBytes, err := json.Marshal(ledger.GetStatementRequest{Id: "35123"})
+	suite.Require().NoError(err, "Failed to marshal test payload")
+	jsonPayload := string(jsonPayloadBytes)
+
+	payloadId := uuid.New().String()
+	payloadRecord := dao.SignablePayloadDao{
+		Id:          payloadId,
+		Payload:     jsonPayload,
+		UserId:      &userRecord.Id,
+		PayloadType: &ledger.GetStatementPayload.Name,
+	}
+	err = suite.TestDB.Create(&payloadRecord).Error
+	suite.Require().NoError(err, "Failed to insert test payload")
+
+	userPublicKey, _ := suite.createSigningUserPublicKeyRecord(userRecord.Id)
+
+	// Signed by a key that is not registered to the user
+	_, otherPrivateKey, err := crypto.CreateKeys()
+	suite.Require().NoError(err, "Failed to create key pair")
+
+	requestBody, _ := json.Marshal(request.LedgerApiRequest{
+		Signature: suite.signPayload(jsonPayload, otherPrivateKey),
+		Mfp:       "test_mfp",
+		PayloadId: payloadId,
+	})
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/get-statement", bytes.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/get-statement")
+
+	customContext := security.GenerateLoggedInRegisteredUserContext(userRecord.Id, userPublicKey.PublicKey, c)
+
+	err = handler.GetStatement(customContext)
+	suite.Require().Error(err, "Handler should return an error")
+
+	var errResponse *response.ErrorResponse
+	suite.Require().ErrorAs(err, &errResponse)
+	suite.Require().Equal(constant.INVALID_SIGNATURE, errResponse.ErrorCode)
+	suite.Require().Equal(http.StatusUnauthorized, errResponse.StatusCode)
+
+	storedPayload, err := dao.SignablePayloadDao{}.FindById(payloadId)
+	suite.Require().NoError(err)
+	suite.Require().Nil(storedPayload.ConsumedAt, "Payload with an invalid signature must not be consumed")
+
+	signatureUses, err := dao.SignatureUseDao{}.FindByUserPublicKeyId(userPublicKey.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(signatureUses, 1)
+	suite.Require().False(signatureUses[0].IsValid)
+	suite.Require().Equal(payloadId, signatureUses[0].PayloadId)
+	suite.Require().Equal("/get-statement", signatureUses[0].Endpoint)
+}
@@ -2,13 +2,14 @@ package test
 
 import (
 	"process-api/pkg/db/dao"
+	"time"
 )
 
 func (suite *IntegrationTestSuite) TestCreateSignablePayloadForUser_Success() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	testPayload := map[string]interface{}{"key": "value"}
 
-	result, err := dao.CreateSignablePayloadForUser(user.Id, testPayload)
+	result, err := dao.CreateSignablePayloadForUser(user.Id, testPayloadType, time.Minute, testPayload)
 
 	suite.Require().Nil(err)
 
@@ -18,13 +19,15 @@ func (suite *IntegrationTestSuite) TestCreateSignablePayloadForUser_Success() {
 	suite.Equal(user.Id, *payloadRecord.UserId)
 	suite.Equal(result.Payload, payloadRecord.Payload)
 	suite.Nil(payloadRecord.ConsumedAt)
+	suite.Equal(testPayloadType, *payloadRecord.PayloadType)
+	suite.WithinDuration(payloadRecord.CreatedAt.Add(time.Minute), *payloadRecord.ExpiresAt, 5*time.Second)
 }
 
 func (suite *IntegrationTestSuite) TestCreateSignablePayloadForUser_UnmarshalablePayload() {
 	user := suite.createTestUser(PartialMasterUserRecordDao{})
 	testPayload := make(chan int)
 
-	result, err := dao.CreateSignablePayloadForUser(user.Id, testPayload)
+	result, err := dao.CreateSignablePayloadForUser(user.Id, testPayloadType, time.Minute, testPayload)
 
 	suite.Nil(result)
 	suite.Require().NotNil(err)
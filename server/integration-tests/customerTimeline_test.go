@@ -0,0 +1,151 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/timeline"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// createTimelineActivity gives the user a login, an OTP, a verification call, a device
+// revoked by an operator and a submitted dispute, an hour apart from oldest to newest
+func (suite *IntegrationTestSuite) createTimelineActivity(userRecord dao.MasterUserRecordDao) time.Time {
+	start := clock.Now().Add(-6 * time.Hour).Truncate(time.Second)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	suite.Require().NoError(suite.TestDB.Create(&dao.MasterUserLoginDao{Id: uuid.New().String(), UserId: userRecord.Id, IP: "203.0.113.7", CreatedAt: at(0)}).Error)
+	suite.Require().NoError(suite.TestDB.Create(&dao.MasterUserOtpDao{
+		OtpId:     uuid.New().String(),
+		Otp:       "123456",
+		OtpStatus: constant.OTP_VERIFIED,
+		OtpType:   constant.SMS,
+		IP:        "203.0.113.7",
+		MobileNo:  userRecord.MobileNo,
+		UserId:    userRecord.Id,
+		ApiPath:   "/account/devices",
+		CreatedAt: at(1),
+	}).Error)
+	suite.Require().NoError(suite.TestDB.Create(&dao.MasterUserCallRecordDao{CallSid: "CA" + uuid.New().String(), CallStatus: "completed", To: userRecord.MobileNo}).Error)
+	suite.Require().NoError(suite.TestDB.Exec(`UPDATE master_user_call_record SET created_at = ? WHERE "to" = ?`, at(2), userRecord.MobileNo).Error)
+
+	device := suite.createUserPublicKeyRecord(userRecord.Id)
+	suite.Require().NoError(suite.TestDB.Exec("UPDATE user_public_keys SET created_at = ?, revoked_at = ?, revoked_by = ? WHERE id = ?", at(3), at(4), "operator@dreamfi.com", device.ID).Error)
+
+	transactionDispute := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_SUBMITTED, at(5))
+	suite.Require().NoError(dao.TransactionDisputeEventDao{}.Create(suite.TestDB, transactionDispute.Id, dao.DISPUTE_EVENT_SUBMITTED, &transactionDispute.Status, dao.DISPUTE_ACTOR_CUSTOMER, nil, at(5)))
+	return start
+}
+
+func (suite *IntegrationTestSuite) newCustomerTimelineContext(userId string, query string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/admin/customers/"+userId+"/timeline"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId/timeline")
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+	return newAdminContext(c), rec
+}
+
+func (suite *IntegrationTestSuite) TestAdminCustomerTimeline() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createTimelineActivity(userRecord)
+
+	c, rec := suite.newCustomerTimelineContext(userRecord.Id, "")
+	err := admin.CustomerTimeline(c)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	var positions []int
+	for _, summary := range []string{"Dispute of transaction", "Device removed", "Device registered", "Verification call, completed", "Verification code sent by sms, verified", "Logged in"} {
+		position := strings.Index(body, summary)
+		suite.Require().NotEqual(-1, position, "Expected %q in the timeline", summary)
+		positions = append(positions, position)
+	}
+	suite.Require().IsIncreasing(positions, "Events should be listed newest first")
+	suite.Require().Contains(body, "operator@dreamfi.com", "The operator who revoked the device should be shown")
+	suite.Require().NotContains(body, "123456", "The one-time code should never be shown")
+
+	c, rec = suite.newCustomerTimelineContext(userRecord.Id, "?category=login&category=call")
+	suite.Require().NoError(admin.CustomerTimeline(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().Contains(rec.Body.String(), "Logged in")
+	suite.Require().Contains(rec.Body.String(), "Verification call")
+	suite.Require().NotContains(rec.Body.String(), "Device registered", "Other categories should be filtered out")
+}
+
+func (suite *IntegrationTestSuite) TestAdminCustomerTimeline_InvalidFilter() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	c, rec := suite.newCustomerTimelineContext(userRecord.Id, "?category=phone_calls")
+	suite.Require().NoError(admin.CustomerTimeline(c))
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Expected status code 400 Bad Request")
+
+	c, rec = suite.newCustomerTimelineContext(userRecord.Id, "?from=yesterday")
+	suite.Require().NoError(admin.CustomerTimeline(c))
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "Expected status code 400 Bad Request")
+}
+
+func (suite *IntegrationTestSuite) listSecurityActivity(userId string, query string) (response.ListSecurityActivityResponse, error) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodGet, "/account/security-activity"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var responseBody response.ListSecurityActivityResponse
+	if err := handler.ListSecurityActivity(security.GenerateLoggedInRegisteredUserContext(userId, "publicKey", c)); err != nil {
+		return responseBody, err
+	}
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Require().NotContains(rec.Body.String(), "operator@dreamfi.com", "Operators should not be named to the customer")
+	return responseBody, nil
+}
+
+func (suite *IntegrationTestSuite) TestListSecurityActivity() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	start := suite.createTimelineActivity(userRecord)
+
+	responseBody, err := suite.listSecurityActivity(userRecord.Id, "")
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Nil(responseBody.NextBefore)
+
+	var types []string
+	for _, activity := range responseBody.Activity {
+		types = append(types, activity.Type)
+	}
+	suite.Require().Equal([]string{timeline.EVENT_DEVICE_REVOKED, timeline.EVENT_DEVICE_REGISTERED, timeline.EVENT_OTP_SENT, timeline.EVENT_LOGIN}, types, "Only security activity should be listed, newest first")
+	suite.Require().True(start.Equal(responseBody.Activity[3].At))
+	suite.Require().Equal("203.0.113.7", *responseBody.Activity[3].Ip)
+
+	responseBody, err = suite.listSecurityActivity(userRecord.Id, "?category=device")
+	suite.Require().NoError(err)
+	suite.Require().Len(responseBody.Activity, 2)
+}
+
+func (suite *IntegrationTestSuite) TestListSecurityActivity_RejectsOtherCategories() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	_, err := suite.listSecurityActivity(userRecord.Id, "?category=transaction")
+	errResp, ok := err.(response.BadRequestErrors)
+	suite.Require().True(ok, "Expected error of type response.BadRequestErrors")
+	suite.Require().Equal("category", errResp.Errors[0].FieldName)
+
+	_, err = suite.listSecurityActivity(userRecord.Id, "?before=yesterday")
+	errResp, ok = err.(response.BadRequestErrors)
+	suite.Require().True(ok, "Expected error of type response.BadRequestErrors")
+	suite.Require().Equal("before", errResp.Errors[0].FieldName)
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/request"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+func (suite *IntegrationTestSuite) TestGetDeviceRegistrationOtp() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	requestBody, err := json.Marshal(request.GetDeviceRegistrationOtpRequest{Type: constant.SMS})
+	suite.Require().NoError(err, "Failed to marshall request body")
+
+	config.Config.Otp.OtpExpiryDuration = 300000
+	config.Config.Twilio.From = "example_from_address"
+	config.Config.Otp.OtpDigits = 6
+	config.Config.Twilio.ApiBase = "http://localhost:5003"
+	config.Config.Twilio.AuthToken = "fakekeyformock"
+	config.Config.Twilio.AccountSid = "ACffffffffffffffffffffffffffffffff"
+	utils.InitializeTwilioClient(config.Config.Twilio)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/register-device/otp", bytes.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	customContext := security.GenerateLoggedInUnregisteredUserContext(userRecord.Id, c)
+
+	err = handler.GetDeviceRegistrationOtp(customContext)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var responseBody response.OtpResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to parse response body")
+
+	var otpRecord dao.MasterUserOtpDao
+	err = suite.TestDB.Model(&dao.MasterUserOtpDao{}).Where("otp_id = ?", responseBody.OtpId).First(&otpRecord).Error
+	suite.Require().NoError(err, "Failed to fetch otp record from database")
+	suite.Equal(userRecord.Id, otpRecord.UserId)
+	suite.Equal("/register-device/otp", otpRecord.ApiPath)
+	suite.Equal(config.Config.Otp.OtpExpiryDuration, responseBody.OtpExpiryDuration)
+}
+
+func (suite *IntegrationTestSuite) TestChallengeDeviceRegistrationOtp() {
+	defer SetupMockForLedger(suite).Close()
+	suite.configEmail()
+	h := suite.newHandler()
+
+	unfreeze := clock.FreezeNow()
+	defer unfreeze()
+	config.Config.Otp.OtpExpiryDuration = 300000
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	userOtpRecord := suite.createDeviceRegistrationOtp(userRecord.Id)
+
+	requestBody, err := json.Marshal(request.ChallengeDeviceRegistrationOtpRequest{
+		OtpId:          userOtpRecord.OtpId,
+		OtpValue:       userOtpRecord.Otp,
+		PublicKey:      "new_public_key",
+		DeviceName:     "Pixel 8",
+		DevicePlatform: "android",
+	})
+	suite.Require().NoError(err, "Failed to marshall request body")
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/register-device/otp/verify", bytes.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	customContext := security.GenerateLoggedInUnregisteredUserContext(userRecord.Id, c)
+
+	err = h.ChallengeDeviceRegistrationOtp(customContext)
+	suite.Require().NoError(err, "Handler should not return an error")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.NotEmpty(rec.Header().Get("Authorization"), "Registered device should be issued a token")
+
+	var responseBody response.ChallengeDeviceRegistrationOtpResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	suite.Require().NoError(err, "Failed to parse response body")
+	suite.Equal("ledger_provided_key_id", responseBody.KeyId)
+
+	suite.WaitForJobsDone(1)
+
+	devices, err := dao.UserPublicKey{}.FindActiveByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(devices, 1)
+	suite.Equal("new_public_key", devices[0].PublicKey)
+	suite.Equal("Pixel 8", *devices[0].DeviceName)
+	suite.Equal("android", *devices[0].DevicePlatform)
+	suite.NotNil(devices[0].RegisteredIP)
+
+	apiKey, err := utils.DecryptKmsBinary(devices[0].KmsEncryptedApiKey)
+	suite.Require().NoError(err)
+	suite.Equal("e6b45dead7e946f9b25fe319106b3312", apiKey)
+}
+
+func (suite *IntegrationTestSuite) TestChallengeDeviceRegistrationOtpLimitReached() {
+	defer SetupMockForLedger(suite).Close()
+	h := suite.newHandler()
+
+	config.Config.Otp.OtpExpiryDuration = 300000
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserPublicKeyRecord(userRecord.Id)
+	err := suite.TestDB.Model(&userRecord).Update("device_limit", 1).Error
+	suite.Require().NoError(err, "Failed to set device limit")
+	userOtpRecord := suite.createDeviceRegistrationOtp(userRecord.Id)
+
+	requestBody, err := json.Marshal(request.ChallengeDeviceRegistrationOtpRequest{
+		OtpId:     userOtpRecord.OtpId,
+		OtpValue:  userOtpRecord.Otp,
+		PublicKey: "second_public_key",
+	})
+	suite.Require().NoError(err, "Failed to marshall request body")
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/register-device/otp/verify", bytes.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	customContext := security.GenerateLoggedInUnregisteredUserContext(userRecord.Id, c)
+
+	err = h.ChallengeDeviceRegistrationOtp(customContext)
+	suite.Require().Error(err, "Handler should return an error")
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Equal(constant.DEVICE_LIMIT_REACHED, errResponse.ErrorCode)
+
+	devices, err := dao.UserPublicKey{}.FindActiveByUserId(userRecord.Id)
+	suite.Require().NoError(err)
+	suite.Len(devices, 1, "No device should be added past the limit")
+
+	var otpRecord dao.MasterUserOtpDao
+	err = suite.TestDB.Model(&dao.MasterUserOtpDao{}).Where("otp_id = ?", userOtpRecord.OtpId).First(&otpRecord).Error
+	suite.Require().NoError(err, "Failed to fetch otp record from database")
+	suite.Equal(constant.OTP_SENT, otpRecord.OtpStatus, "The OTP shouldn't be used up when the limit is reached")
+}
+
+func (suite *IntegrationTestSuite) createDeviceRegistrationOtp(userId string) dao.MasterUserOtpDao {
+	userOtpRecord := dao.MasterUserOtpDao{
+		OtpId:     uuid.New().String(),
+		Otp:       "123456",
+		OtpStatus: constant.OTP_SENT,
+		MobileNo:  "1234567890",
+		ApiPath:   "/register-device/otp",
+		UserId:    userId,
+		IP:        "1.1.1.1",
+		CreatedAt: clock.Now(),
+	}
+	err := suite.TestDB.Select("otp_id", "otp", "otp_status", "mobile_no", "api_path", "user_id", "ip", "created_at").Create(&userOtpRecord).Error
+	suite.Require().NoError(err, "Failed to insert otp record")
+	return userOtpRecord
+}
@@ -30,17 +30,18 @@ func (suite *IntegrationTestSuite) TestGetStatement() {
 
 	payloadId := uuid.New().String()
 	payloadRecord := dao.SignablePayloadDao{
-		Id:      payloadId,
-		Payload: jsonPayload,
-		UserId:  &userRecord.Id,
+		Id:          payloadId,
+		Payload:     jsonPayload,
+		UserId:      &userRecord.Id,
+		PayloadType: &ledger.GetStatementPayload.Name,
 	}
 	err = suite.TestDB.Create(&payloadRecord).Error
 	suite.Require().NoError(err, "Failed to insert test payload")
 
-	userPublicKey := suite.createUserPublicKeyRecord(userRecord.Id)
+	userPublicKey, privateKey := suite.createSigningUserPublicKeyRecord(userRecord.Id)
 
 	listStatementsRequest := request.LedgerApiRequest{
-		Signature: "test_signature",
+		Signature: suite.signPayload(jsonPayload, privateKey),
 		Mfp:       "test_mfp",
 		PayloadId: payloadId,
 	}
@@ -65,7 +65,7 @@ func (suite *IntegrationTestSuite) TestGetUserDetailsAndDemographicUpdateStatus(
 	suite.Equal("pending", responseBody.AddressStatus)
 }
 
-func (suite *IntegrationTestSuite) createDemographicUpdateRecord(value json.RawMessage, demographicUpdateType, userId string) {
+func (suite *IntegrationTestSuite) createDemographicUpdateRecord(value json.RawMessage, demographicUpdateType, userId string) dao.DemographicUpdatesDao {
 	id := uuid.New().String()
 	demographicUpdate := dao.DemographicUpdatesDao{
 		Id:           id,
@@ -77,4 +77,5 @@ func (suite *IntegrationTestSuite) createDemographicUpdateRecord(value json.RawM
 
 	err := db.DB.Select("id", "type", "status", "updated_value", "user_id").Create(&demographicUpdate).Error
 	suite.Require().NoError(err, "Failed to create test demographic update record")
+	return demographicUpdate
 }
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) newIdempotentEcho(userId string, handlerFunc echo.HandlerFunc) *echo.Echo {
+	setUserId := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", userId)
+			return next(c)
+		}
+	}
+
+	e := handler.NewEcho()
+	e.POST("/transfer", handlerFunc, setUserId, security.IdempotencyMiddleware)
+	return e
+}
+
+func (suite *IntegrationTestSuite) postIdempotent(e *echo.Echo, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/transfer", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(security.HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *IntegrationTestSuite) TestIdempotencyMiddleware_ReplaysResponse() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	calls := 0
+	e := suite.newIdempotentEcho(user.Id, func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]int{"transfer": calls})
+	})
+
+	first := suite.postIdempotent(e, "key-1", `{"amount":100}`)
+	suite.Equal(http.StatusCreated, first.Code)
+
+	retry := suite.postIdempotent(e, "key-1", `{"amount":100}`)
+	suite.Equal(http.StatusCreated, retry.Code)
+	suite.Equal(first.Body.String(), retry.Body.String())
+	suite.Equal("true", retry.Header().Get(security.HeaderIdempotentReplayed))
+	suite.Equal(1, calls, "Retry must not run the handler again")
+
+	suite.postIdempotent(e, "key-2", `{"amount":100}`)
+	suite.Equal(2, calls, "A new key runs the handler")
+
+	suite.postIdempotent(e, "", `{"amount":100}`)
+	suite.Equal(3, calls, "Requests without a key are not deduplicated")
+}
+
+func (suite *IntegrationTestSuite) TestIdempotencyMiddleware_ReplaysClientErrors() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	calls := 0
+	e := suite.newIdempotentEcho(user.Id, func(c echo.Context) error {
+		calls++
+		return response.ErrorResponse{ErrorCode: "INSUFFICIENT_FUNDS", StatusCode: http.StatusConflict}
+	})
+
+	first := suite.postIdempotent(e, "key-1", `{"amount":100}`)
+	suite.Equal(http.StatusConflict, first.Code)
+
+	retry := suite.postIdempotent(e, "key-1", `{"amount":100}`)
+	suite.Equal(http.StatusConflict, retry.Code)
+	suite.Contains(retry.Body.String(), "INSUFFICIENT_FUNDS")
+	suite.Equal(1, calls)
+}
+
+func (suite *IntegrationTestSuite) TestIdempotencyMiddleware_RejectsDifferentBody() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	e := suite.newIdempotentEcho(user.Id, func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+
+	suite.Equal(http.StatusCreated, suite.postIdempotent(e, "key-1", `{"amount":100}`).Code)
+
+	rec := suite.postIdempotent(e, "key-1", `{"amount":5000}`)
+	suite.Equal(http.StatusUnprocessableEntity, rec.Code)
+	suite.Contains(rec.Body.String(), "IDEMPOTENCY_KEY_REUSED")
+}
+
+func (suite *IntegrationTestSuite) TestIdempotencyMiddleware_ReleasesKeyOnServerError() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	calls := 0
+	e := suite.newIdempotentEcho(user.Id, func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			return response.ErrorResponse{ErrorCode: "INTERNAL_SERVER_ERROR", StatusCode: http.StatusInternalServerError}
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	suite.Equal(http.StatusInternalServerError, suite.postIdempotent(e, "key-1", `{"amount":100}`).Code)
+
+	var count int
+	suite.TestDB.Model(dao.IdempotencyKeyDao{}).Where("user_id = ?", user.Id).Count(&count)
+	suite.Equal(0, count, "Server errors must not be stored")
+
+	suite.Equal(http.StatusCreated, suite.postIdempotent(e, "key-1", `{"amount":100}`).Code)
+	suite.Equal(2, calls)
+}
+
+func (suite *IntegrationTestSuite) TestIdempotencyMiddleware_RejectsConcurrentRetry() {
+	user := suite.createTestUser(PartialMasterUserRecordDao{})
+
+	var retry *httptest.ResponseRecorder
+	var e *echo.Echo
+	e = suite.newIdempotentEcho(user.Id, func(c echo.Context) error {
+		if retry == nil {
+			retry = suite.postIdempotent(e, "key-1", `{"amount":100}`)
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	suite.Equal(http.StatusCreated, suite.postIdempotent(e, "key-1", `{"amount":100}`).Code)
+	suite.Require().NotNil(retry)
+	suite.Equal(http.StatusConflict, retry.Code)
+	suite.Contains(retry.Body.String(), "IDEMPOTENCY_REQUEST_IN_PROGRESS")
+}
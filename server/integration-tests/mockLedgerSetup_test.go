@@ -373,6 +373,17 @@ func SetupMockForLedger(suite *IntegrationTestSuite) *httptest.Server {
 							"Status": "SUSPENDED"
 						}
 					}`
+			case "ACTIVE":
+				responseBody = `{
+						"id": "1",
+						"result": {
+							"CustomerId": "100000000006001",
+							"AccountNumber": "400320588344662",
+							"InstitutionId": "101115315",
+							"Name": "General Account",
+							"Status": "ACTIVE"
+						}
+					}`
 			}
 
 		case ("ledger.CARD.request"):
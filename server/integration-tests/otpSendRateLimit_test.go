@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) configOtpSendRateLimits(perUser, perMobileNo, perIp, perCountryPrefix int) {
+	config.Config.Otp.SendRateLimits = config.OtpSendRateLimitConfigs{
+		PerUser:          config.RateLimitRule{Limit: perUser, Window: 3600000},
+		PerMobileNo:      config.RateLimitRule{Limit: perMobileNo, Window: 3600000},
+		PerIp:            config.RateLimitRule{Limit: perIp, Window: 3600000},
+		PerCountryPrefix: config.RateLimitRule{Limit: perCountryPrefix, Window: 3600000},
+	}
+	config.Config.Otp.OtpExpiryDuration = 300000
+	config.Config.Otp.OtpDigits = 6
+	config.Config.Twilio.From = "example_from_address"
+	config.Config.Twilio.ApiBase = "http://localhost:5003"
+	config.Config.Twilio.AuthToken = "fakekeyformock"
+	config.Config.Twilio.AccountSid = "ACffffffffffffffffffffffffffffffff"
+	utils.InitializeTwilioClient(config.Config.Twilio)
+}
+
+func (suite *IntegrationTestSuite) sendRecoverOnboardingOtp(userId string, otpType string) (*httptest.ResponseRecorder, error) {
+	requestBody, err := json.Marshal(handler.RecoverOnboardingOtpRequest{Type: otpType})
+	suite.Require().NoError(err, "Failed to marshall request body")
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/recover-onboarding/send-otp", bytes.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.10")
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetPath("/recover-onboarding/send-otp")
+
+	return rec, handler.RecoverOnboardingOTP(security.GenerateRecoverOnboardingUserContext(userId, c))
+}
+
+func (suite *IntegrationTestSuite) TestOtpSendRateLimit_PerUser() {
+	suite.configOtpSendRateLimits(2, 100, 100, 1000)
+
+	userStatus := constant.USER_CREATED
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{UserStatus: &userStatus})
+
+	for range 2 {
+		rec, err := suite.sendRecoverOnboardingOtp(userRecord.Id, constant.SMS)
+		suite.Require().NoError(err)
+		suite.Equal(http.StatusOK, rec.Code)
+	}
+
+	rec, err := suite.sendRecoverOnboardingOtp(userRecord.Id, constant.SMS)
+	suite.Require().Error(err)
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Equal(http.StatusTooManyRequests, errResponse.StatusCode)
+	suite.Equal(constant.OTP_SEND_RATE_LIMITED, errResponse.ErrorCode)
+	suite.NotEmpty(rec.Header().Get("Retry-After"), "Retry-After must be set when a limit trips")
+
+	var count int
+	suite.TestDB.Model(dao.OtpSendEventDao{}).Where("user_id = ?", userRecord.Id).Count(&count)
+	suite.Equal(2, count, "Rejected sends must not be recorded")
+}
+
+func (suite *IntegrationTestSuite) TestOtpSendRateLimit_PerIpAcrossUsers() {
+	suite.configOtpSendRateLimits(100, 100, 1, 1000)
+
+	userStatus := constant.USER_CREATED
+	firstUser := suite.createTestUser(PartialMasterUserRecordDao{UserStatus: &userStatus})
+	secondUser := suite.createTestUser(PartialMasterUserRecordDao{
+		UserStatus: &userStatus,
+		Email:      utils.Pointer("seconduser@gmail.com"),
+		MobileNo:   utils.Pointer("+14015550199"),
+	})
+
+	rec, err := suite.sendRecoverOnboardingOtp(firstUser.Id, constant.SMS)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	_, err = suite.sendRecoverOnboardingOtp(secondUser.Id, constant.SMS)
+	suite.Require().Error(err)
+	errResponse, ok := err.(response.ErrorResponse)
+	suite.Require().True(ok, "Expected an ErrorResponse")
+	suite.Equal(http.StatusTooManyRequests, errResponse.StatusCode)
+}
+
+func (suite *IntegrationTestSuite) TestOtpSendRateLimit_WindowSlides() {
+	unfreeze := clock.FreezeNow()
+	defer unfreeze()
+
+	suite.configOtpSendRateLimits(1, 100, 100, 1000)
+
+	userStatus := constant.USER_CREATED
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{UserStatus: &userStatus})
+
+	oldEvent := dao.OtpSendEventDao{
+		UserId:    userRecord.Id,
+		IP:        "203.0.113.10",
+		ApiPath:   "/recover-onboarding/send-otp",
+		OtpType:   constant.SMS,
+		CreatedAt: clock.Now().Add(-61 * time.Minute),
+	}
+	suite.Require().NoError(dao.OtpSendEventDao{}.Create(suite.TestDB, &oldEvent))
+
+	rec, err := suite.sendRecoverOnboardingOtp(userRecord.Id, constant.SMS)
+	suite.Require().NoError(err, "Sends outside the window must not count")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	recentEvent := dao.OtpSendEventDao{
+		UserId:    userRecord.Id,
+		IP:        "203.0.113.10",
+		ApiPath:   "/recover-onboarding/send-otp",
+		OtpType:   constant.SMS,
+		CreatedAt: clock.Now().Add(-50 * time.Minute),
+	}
+	suite.Require().NoError(suite.TestDB.Delete(dao.OtpSendEventDao{}, "user_id = ?", userRecord.Id).Error)
+	suite.Require().NoError(dao.OtpSendEventDao{}.Create(suite.TestDB, &recentEvent))
+
+	rec, err = suite.sendRecoverOnboardingOtp(userRecord.Id, constant.SMS)
+	suite.Require().Error(err)
+	suite.Equal("600", rec.Header().Get("Retry-After"), "Retry-After is when the oldest send leaves the window")
+}
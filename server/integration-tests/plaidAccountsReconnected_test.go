@@ -46,6 +46,8 @@ func (suite *IntegrationTestSuite) TestPlaidAccountsReconnected_Success() {
 	suite.Require().NotNil(updatedAccount, "Could not get updated account")
 	suite.Require().Empty(updatedAccount.ItemError, "Did not clear item error")
 	suite.Require().False(updatedAccount.IsPendingDisconnect, "Did not clear isPendingDisconnect")
+	suite.NotNil(updatedAccount.RelinkedAt, "Did not record the item was relinked")
+	suite.Equal(0, updatedAccount.RelinkRemindersSent, "Did not start relink reminders over")
 }
 
 func (suite *IntegrationTestSuite) TestPlaidAccountsReconnected_NotFound() {
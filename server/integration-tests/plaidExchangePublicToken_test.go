@@ -70,6 +70,8 @@ func (suite *IntegrationTestSuite) TestPlaidExchangePublicTokenSuccess() {
 	suite.Equal(plaid_go.AccountSubtype("checking"), plaidAccount.Subtype)
 	suite.Equal(int64(10000), *plaidAccount.AvailableBalanceCents)
 	suite.Equal("9600", *plaidAccount.Mask)
+	// mockoon has no identity for this item, so its owner can't be matched
+	suite.Equal(dao.IDENTITY_MATCH_REVIEW, *plaidAccount.IdentityMatchStatus, "An account without an owner name should be sent for review")
 }
 
 func (suite *IntegrationTestSuite) TestPlaidExchangePublicTokenFailure() {
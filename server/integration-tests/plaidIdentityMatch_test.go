@@ -0,0 +1,156 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testIdentityMatchPolicy = plaid.IdentityMatchPolicy{ApproveScore: 0.85, ReviewScore: 0.5}
+
+// linkIdentityItem links the item mockoon serves identity for, whose accounts ending in
+// 9602 and 9603 are owned by Alberta Bobbeth Charleson of 2992 Cameron Road, Malakoff, NY
+func (suite *IntegrationTestSuite) linkIdentityItem(ps plaid.PlaidService, userId string) []dao.PlaidAccountDao {
+	plaidItemId := "j91ByvRRqwuGBygwnB8Au8j6ZvmjKAt1wB4a2"
+	unencryptedAccessToken := "access-sandbox-1b7e6039-337b-34d7-a3cd-7e13e379c0b2"
+	suite.Require().NoError(ps.InsertItem(userId, plaidItemId, unencryptedAccessToken))
+	suite.Require().NoError(ps.InitialAccountsGetRequest(userId, plaidItemId, unencryptedAccessToken))
+	suite.Require().NoError(ps.GetIdentity(userId, plaidItemId, unencryptedAccessToken))
+	suite.Require().NoError(ps.MatchAccountOwners(userId, plaidItemId, testIdentityMatchPolicy, clock.Now()))
+
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForItem(userId, plaidItemId)
+	suite.Require().NoError(err)
+	suite.Require().Len(accounts, 2)
+	return accounts
+}
+
+func (suite *IntegrationTestSuite) newPlaidAccountOwnerContext(userId, accountId, decision string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/customers/"+userId+"/plaid-accounts/"+accountId+"/"+decision, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/admin/customers/:userId/plaid-accounts/:id/" + decision)
+	c.SetParamNames("userId", "id")
+	c.SetParamValues(userId, accountId)
+	return newAdminContextAs(c, "auth0|compliance", "compliance@dreamfi.com", "sandbox-operations", "operations-compliance"), rec
+}
+
+func (suite *IntegrationTestSuite) TestMatchAccountOwners_Approved() {
+	h, userId := suite.beforePlaid()
+	err := suite.TestDB.Model(&dao.MasterUserRecordDao{}).Where("id = ?", userId).Updates(map[string]any{
+		"first_name":     "Alberta",
+		"last_name":      "Charleson",
+		"street_address": "2992 Cameron Rd",
+		"apartment_no":   "",
+		"city":           "Malakoff",
+		"state":          "NY",
+		"zip_code":       "14236",
+	}).Error
+	suite.Require().NoError(err)
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+
+	for _, account := range suite.linkIdentityItem(ps, userId) {
+		suite.Equal("2992 Cameron Road, Malakoff, NY, 14236", *account.PrimaryOwnerAddress, "The primary address should be stored")
+		suite.Equal(1.0, *account.IdentityNameScore, "The middle name should be ignored")
+		suite.Equal(1.0, *account.IdentityAddressScore)
+		suite.Equal(dao.IDENTITY_MATCH_APPROVED, *account.IdentityMatchStatus)
+		suite.NotNil(account.IdentityMatchedAt)
+	}
+	suite.Nil(dao.RequireAccountOwnerVerified(userId, "1111119602"))
+}
+
+func (suite *IntegrationTestSuite) TestMatchAccountOwners_RejectedUntilApproved() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+
+	accounts := suite.linkIdentityItem(ps, userId)
+	for _, account := range accounts {
+		suite.Equal(0.0, *account.IdentityNameScore, "Test Bar shouldn't match Alberta Bobbeth Charleson")
+		suite.Equal(dao.IDENTITY_MATCH_REJECTED, *account.IdentityMatchStatus)
+	}
+
+	errResponse := dao.RequireAccountOwnerVerified(userId, "1111119602")
+	suite.Require().NotNil(errResponse, "Pulls from a third party's account should be blocked")
+	suite.Equal(http.StatusForbidden, errResponse.StatusCode)
+	suite.Equal(constant.ACCOUNT_OWNER_NOT_VERIFIED, errResponse.ErrorCode)
+	errResponse = dao.RequireAccountOwnerVerified(userId, "1111111234")
+	suite.Require().NotNil(errResponse, "Pulls from accounts that aren't linked should be blocked")
+	suite.Equal(constant.EXTERNAL_ACCOUNT_NOT_LINKED, errResponse.ErrorCode)
+
+	checking := accounts[0]
+	if *checking.Mask != "9602" {
+		checking = accounts[1]
+	}
+	c, rec := suite.newPlaidAccountOwnerContext(userId, checking.ID, "reject")
+	suite.Require().NoError(admin.RejectPlaidAccountOwner(c))
+	suite.Equal(http.StatusConflict, rec.Code, "A rejected account shouldn't be rejected again")
+
+	c, rec = suite.newPlaidAccountOwnerContext(userId, checking.ID, "approve")
+	suite.Require().NoError(admin.ApprovePlaidAccountOwner(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+	suite.Nil(dao.RequireAccountOwnerVerified(userId, "1111119602"), "An approved account should allow pulls")
+	suite.NotNil(dao.RequireAccountOwnerVerified(userId, "1111119603"), "The other account should still be blocked")
+
+	reviewed, err := dao.PlaidAccountDao{}.GetAccountForUserByID(userId, checking.ID)
+	suite.Require().NoError(err)
+	suite.Equal("compliance@dreamfi.com", *reviewed.IdentityReviewedBy)
+	suite.NotNil(reviewed.IdentityReviewedAt)
+
+	c, rec = suite.newPlaidAccountOwnerContext(userId, checking.ID, "approve")
+	suite.Require().NoError(admin.ApprovePlaidAccountOwner(c))
+	suite.Equal(http.StatusConflict, rec.Code, "An approved account doesn't need review")
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_PLAID_ACCOUNT, checking.ID)
+	suite.Require().Len(entries, 1)
+	suite.Equal(admin.AUDIT_ACTION_PLAID_ACCOUNT_OWNER_APPROVED, entries[0].Action)
+	suite.Equal(userId, *entries[0].UserId)
+}
+
+func (suite *IntegrationTestSuite) TestRequireAccountOwnerVerified_UnmatchedAndLegacy() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	errResponse := dao.RequireAccountOwnerVerified(userId, "1111119602")
+	suite.Require().NotNil(errResponse, "Accounts whose owner wasn't matched should be blocked")
+	suite.Equal(constant.ACCOUNT_OWNER_NOT_VERIFIED, errResponse.ErrorCode)
+
+	err := suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Update("identity_match_status", dao.IDENTITY_MATCH_LEGACY).Error
+	suite.Require().NoError(err)
+	suite.Nil(dao.RequireAccountOwnerVerified(userId, "1111119602"), "Accounts linked before matching should be let through")
+}
+
+func (suite *IntegrationTestSuite) TestApprovePlaidAccountOwner_Unmatched() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForItem(userId, item.PlaidItemID)
+	suite.Require().NoError(err)
+	checking := accounts[0]
+	if *checking.Mask != "9602" {
+		checking = accounts[1]
+	}
+
+	c, rec := suite.newPlaidAccountOwnerContext(userId, checking.ID, "approve")
+	suite.Require().NoError(admin.ApprovePlaidAccountOwner(c))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code, "An operator should be able to approve an account that couldn't be matched")
+	suite.Nil(dao.RequireAccountOwnerVerified(userId, "1111119602"))
+
+	other := accounts[1]
+	if other.ID == checking.ID {
+		other = accounts[0]
+	}
+	suite.Require().NoError(suite.TestDB.Model(&other).Update("identity_match_status", dao.IDENTITY_MATCH_LEGACY).Error)
+	c, rec = suite.newPlaidAccountOwnerContext(userId, other.ID, "reject")
+	suite.Require().NoError(admin.RejectPlaidAccountOwner(c))
+	suite.Equal(http.StatusConflict, rec.Code, "Legacy accounts don't need review")
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+var testItemHealthPolicy = plaid.ItemHealthPolicy{
+	ConsentExpiryWarning: 7 * 24 * time.Hour,
+	StaleBalanceAfter:    7 * 24 * time.Hour,
+	ReminderInterval:     3 * 24 * time.Hour,
+	MaxReminders:         2,
+}
+
+func (suite *IntegrationTestSuite) relinkEmailJobs() []plaid.RelinkEmailJobArgs {
+	jobs, err := suite.riverClient.JobList(context.Background(), river.NewJobListParams().Kinds(plaid.RelinkEmailJobArgs{}.Kind()))
+	suite.Require().NoError(err)
+	var args []plaid.RelinkEmailJobArgs
+	for _, job := range jobs.Jobs {
+		var jobArgs plaid.RelinkEmailJobArgs
+		suite.Require().NoError(json.Unmarshal(job.EncodedArgs, &jobArgs))
+		args = append(args, jobArgs)
+	}
+	return args
+}
+
+func (suite *IntegrationTestSuite) TestPlaidItemHealth_RemindsUntilReconnected() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+	suite.Require().NoError(dao.PlaidItemDao{}.SetItemError(item.PlaidItemID, "ITEM_LOGIN_REQUIRED"))
+
+	now := clock.Now()
+	snapshot, err := ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now)
+	suite.Require().NoError(err)
+	suite.Equal(1, snapshot.TotalItems)
+	suite.Equal(0, snapshot.HealthyItems)
+	suite.Equal(1, snapshot.LoginRequiredItems)
+	suite.Equal(1, snapshot.RemindersSent)
+	suite.WaitForJobsDone(1)
+
+	emails := suite.relinkEmailJobs()
+	suite.Require().Len(emails, 1)
+	suite.Equal(plaid.RELINK_REASON_LOGIN_REQUIRED, emails[0].Reason)
+	suite.Contains(emails[0].RelinkURL, "accountId=", "The reminder should link to the app's reconnect flow for one of the item's accounts")
+
+	// Running again before the reminder interval passes doesn't remind again
+	_, err = ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now.Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.Len(suite.relinkEmailJobs(), 1)
+
+	// Reminders are repeated up to the policy's maximum
+	for _, days := range []int{3, 6, 9} {
+		_, err = ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now.AddDate(0, 0, days))
+		suite.Require().NoError(err)
+	}
+	suite.WaitForJobsDone(1)
+	suite.Len(suite.relinkEmailJobs(), 2)
+
+	// Reconnecting the item stops the reminders and starts them over
+	suite.Require().NoError(dao.PlaidItemDao{}.ClearItemError(item.PlaidItemID))
+	suite.Require().NoError(dao.PlaidItemDao{}.MarkRelinked(item.PlaidItemID, now.AddDate(0, 0, 10)))
+	snapshot, err = ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now.AddDate(0, 0, 12))
+	suite.Require().NoError(err)
+	suite.Equal(1, snapshot.HealthyItems)
+	suite.Equal(0, snapshot.RemindersSent)
+
+	updated, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Equal(0, updated.RelinkRemindersSent)
+	suite.Nil(updated.RelinkRemindedAt)
+}
+
+func (suite *IntegrationTestSuite) TestPlaidItemHealth_ConsentExpiringAndStaleBalance() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+
+	now := clock.Now()
+	staleAt := now.AddDate(0, 0, -10)
+	err := suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Update("balance_refreshed_at", staleAt).Error
+	suite.Require().NoError(err)
+
+	// A stale balance is counted, but isn't worth a reminder on its own
+	snapshot, err := ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now)
+	suite.Require().NoError(err)
+	suite.Equal(1, snapshot.StaleBalanceItems)
+	suite.Equal(0, snapshot.RemindersSent)
+	suite.Empty(suite.relinkEmailJobs())
+
+	consentExpiresAt := now.AddDate(0, 0, 3)
+	suite.Require().NoError(dao.PlaidItemDao{}.SetConsentExpirationTime(item.PlaidItemID, &consentExpiresAt))
+	snapshot, err = ps.CheckItemHealth(context.Background(), suite.riverClient, testItemHealthPolicy, now)
+	suite.Require().NoError(err)
+	suite.Equal(1, snapshot.ConsentExpiringItems)
+	suite.Equal(1, snapshot.StaleBalanceItems)
+	suite.Equal(1, snapshot.RemindersSent)
+	suite.WaitForJobsDone(1)
+
+	emails := suite.relinkEmailJobs()
+	suite.Require().Len(emails, 1)
+	suite.Equal(plaid.RELINK_REASON_EXPIRING, emails[0].Reason)
+
+	// Both runs were on the same day, so there is one snapshot with both runs' reminders
+	snapshots, err := dao.PlaidItemHealthSnapshotDao{}.FindRecent(10)
+	suite.Require().NoError(err)
+	suite.Require().Len(snapshots, 1)
+	suite.Equal(1, snapshots[0].ConsentExpiringItems)
+	suite.Equal(1, snapshots[0].RemindersSent)
+}
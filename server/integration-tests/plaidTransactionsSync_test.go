@@ -0,0 +1,116 @@
+package test
+
+import (
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"time"
+)
+
+// All hardcoded values are set from mockoon
+const (
+	transactionsPlaidItemID      = "j91ByvRRqwuGBygwnB8Au8j6ZvmjKAt1wB4e0"
+	transactionsPlaidAccessToken = "access-sandbox-1b7e6039-337b-34d7-a3cd-7e13e379c0e0"
+)
+
+func (suite *IntegrationTestSuite) plaidTransactionsByID(userId string) map[string]dao.PlaidTransactionDao {
+	transactions, err := dao.PlaidTransactionDao{}.FindForUser(userId, clock.Now().AddDate(-1, 0, 0))
+	suite.Require().NoError(err)
+	byID := make(map[string]dao.PlaidTransactionDao, len(transactions))
+	for _, transaction := range transactions {
+		byID[transaction.PlaidTransactionId] = transaction
+	}
+	return byID
+}
+
+func (suite *IntegrationTestSuite) TestPlaidTransactionsSync() {
+	h := suite.newHandler()
+	h.Config.Plaid.TransactionsEnabled = true
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	err := ps.InsertItem(userRecord.Id, transactionsPlaidItemID, transactionsPlaidAccessToken)
+	suite.Require().NoError(err)
+
+	webhookPayload := plaid.WebhookPayload{
+		WebhookType: "TRANSACTIONS",
+		WebhookCode: "SYNC_UPDATES_AVAILABLE",
+		ItemID:      transactionsPlaidItemID,
+	}
+	rec := suite.sendPlaidWebhook(h, webhookPayload)
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
+
+	transactions := suite.plaidTransactionsByID(userRecord.Id)
+	suite.Require().Len(transactions, 3, "Both pages of the initial update should be stored")
+	payroll := transactions["4zBRq1Qem4uAPnoyKjJNTRQpQddM4ztlo1PLD"]
+	suite.Equal(int64(150000), payroll.AmountCents, "Money into the account should be positive")
+	suite.Equal("INCOME", *payroll.CategoryPrimary)
+	suite.Equal("INCOME_WAGES", *payroll.CategoryDetailed)
+	suite.Equal(time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC), payroll.Date.UTC())
+	rent := transactions["8zBRq1Qem4uAPnoyKjJNTRQpQddM4ztlo1PLE"]
+	suite.Equal(int64(-120000), rent.AmountCents, "Money out of the account should be negative")
+	suite.True(transactions["lPNjeW1nR6CDn5okmGQ6hEpMo4lLNoSrzqDje"].Pending)
+
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(transactionsPlaidItemID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(item.TransactionsCursor)
+	suite.Equal("cursor-update-1", *item.TransactionsCursor, "The cursor should be where the last page left off")
+	suite.NotNil(item.TransactionsSyncedAt)
+
+	rec = suite.sendPlaidWebhook(h, webhookPayload)
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
+
+	transactions = suite.plaidTransactionsByID(userRecord.Id)
+	suite.Require().Len(transactions, 3)
+	suite.NotContains(transactions, "lPNjeW1nR6CDn5okmGQ6hEpMo4lLNoSrzqDje", "The removed pending transaction should be deleted")
+	posted := transactions["pPNjeW1nR6CDn5okmGQ6hEpMo4lLNoSrzqDjf"]
+	suite.False(posted.Pending)
+	suite.Equal("lPNjeW1nR6CDn5okmGQ6hEpMo4lLNoSrzqDje", *posted.PendingTransactionId)
+	suite.Equal(int64(-125000), transactions["8zBRq1Qem4uAPnoyKjJNTRQpQddM4ztlo1PLE"].AmountCents, "The modified transaction should be updated")
+
+	item, err = dao.PlaidItemDao{}.GetItemByPlaidItemID(transactionsPlaidItemID)
+	suite.Require().NoError(err)
+	suite.Equal("cursor-update-2", *item.TransactionsCursor)
+
+	err = ps.UnlinkItem(userRecord.Id, transactionsPlaidItemID, transactionsPlaidAccessToken)
+	suite.Require().NoError(err)
+	suite.Empty(suite.plaidTransactionsByID(userRecord.Id), "Unlinking the item should delete its transactions")
+}
+
+func (suite *IntegrationTestSuite) TestPlaidTransactionsSync_Disabled() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	err := ps.InsertItem(userRecord.Id, transactionsPlaidItemID, transactionsPlaidAccessToken)
+	suite.Require().NoError(err)
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "TRANSACTIONS",
+		WebhookCode: "SYNC_UPDATES_AVAILABLE",
+		ItemID:      transactionsPlaidItemID,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(transactionsPlaidItemID)
+	suite.Require().NoError(err)
+	suite.Nil(item.TransactionsCursor, "No sync should run while transactions are disabled")
+	suite.Empty(suite.plaidTransactionsByID(userRecord.Id))
+}
+
+func (suite *IntegrationTestSuite) TestPlaidTransactionsSync_SkipsItemWithError() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	err := ps.InsertItem(userRecord.Id, transactionsPlaidItemID, transactionsPlaidAccessToken)
+	suite.Require().NoError(err)
+	err = dao.PlaidItemDao{}.SetItemError(transactionsPlaidItemID, "ITEM_LOGIN_REQUIRED")
+	suite.Require().NoError(err)
+
+	result, err := ps.SyncTransactions(transactionsPlaidItemID, clock.Now())
+	suite.Require().NoError(err)
+	suite.Zero(result.Upserted)
+	suite.Empty(suite.plaidTransactionsByID(userRecord.Id))
+}
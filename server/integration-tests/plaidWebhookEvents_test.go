@@ -0,0 +1,270 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/riverqueue/river"
+)
+
+// All hardcoded values are set from mockoon
+const (
+	webhookEventsPlaidItemID      = "DWVAAPWq4RHGlEaNyGKRTAnPLaEmo8Cvq7nc0"
+	webhookEventsPlaidAccessToken = "access-sandbox-1b7e6039-337b-34d7-a3cd-7e13e379c0c0"
+)
+
+// plaidWebhookEvents returns the stored webhooks for the item, newest first
+func (suite *IntegrationTestSuite) plaidWebhookEvents(plaidItemID string) []dao.PlaidWebhookEventDao {
+	events, _, err := dao.PlaidWebhookEventDao{}.Search(dao.PlaidWebhookEventFilter{PlaidItemId: plaidItemID}, 100, 0)
+	suite.Require().NoError(err)
+	return events
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_StoredWithOutcome() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "ERROR",
+		ItemID:      item.PlaidItemID,
+		Error:       &plaid.WebhookPayloadError{ErrorType: "ITEM_ERROR", ErrorCode: "ITEM_LOGIN_REQUIRED"},
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
+
+	events := suite.plaidWebhookEvents(item.PlaidItemID)
+	suite.Require().Len(events, 1)
+	suite.Equal("ITEM", events[0].WebhookType)
+	suite.Equal("ERROR", events[0].WebhookCode)
+	suite.Equal(dao.PLAID_WEBHOOK_EVENT_PROCESSED, events[0].Status)
+	suite.Equal(1, events[0].Attempts)
+	suite.NotNil(events[0].ProcessedAt)
+	suite.Contains(events[0].Payload, "ITEM_LOGIN_REQUIRED", "The webhook should be stored as Plaid sent it")
+
+	// The user was emailed when the item broke, and isn't emailed again while it stays broken
+	rec = suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "ERROR",
+		ItemID:      item.PlaidItemID,
+		Error:       &plaid.WebhookPayloadError{ErrorType: "ITEM_ERROR", ErrorCode: "ITEM_LOGIN_REQUIRED"},
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	rec = suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "WEBHOOK_UPDATE_ACKNOWLEDGED",
+		ItemID:      item.PlaidItemID,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	events = suite.plaidWebhookEvents(item.PlaidItemID)
+	suite.Require().Len(events, 3)
+	statuses := map[string]int{}
+	for _, event := range events {
+		statuses[event.Status]++
+	}
+	suite.Equal(map[string]int{dao.PLAID_WEBHOOK_EVENT_PROCESSED: 2, dao.PLAID_WEBHOOK_EVENT_IGNORED: 1}, statuses)
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_NewAccountsAvailable() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+	suite.Require().False(item.NewAccountsAvailable)
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "NEW_ACCOUNTS_AVAILABLE",
+		ItemID:      item.PlaidItemID,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	updated, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.True(updated.NewAccountsAvailable, "The item should be marked as having new accounts")
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_AuthDefaultUpdate() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "AUTH",
+		WebhookCode: "DEFAULT_UPDATE",
+		ItemID:      item.PlaidItemID,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	events := suite.plaidWebhookEvents(item.PlaidItemID)
+	suite.Require().Len(events, 1)
+	suite.Equal(dao.PLAID_WEBHOOK_EVENT_PROCESSED, events[0].Status, "The item's accounts should be refreshed")
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_PendingExpirationNotifiesOnce() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.createPlaidItemWithCheckingAndSavingsAccounts(ps, userRecord)
+
+	consentExpirationTime := clock.Now().AddDate(0, 0, 7).Truncate(time.Second)
+	payload := plaid.WebhookPayload{
+		WebhookType:           "ITEM",
+		WebhookCode:           "PENDING_EXPIRATION",
+		ItemID:                item.PlaidItemID,
+		ConsentExpirationTime: &consentExpirationTime,
+	}
+	rec := suite.sendPlaidWebhook(h, payload)
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
+
+	updated, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(updated.ConsentExpirationTime)
+	suite.True(consentExpirationTime.Equal(*updated.ConsentExpirationTime))
+	suite.Equal(1, updated.RelinkRemindersSent, "The email should count as a relink reminder")
+
+	rec = suite.sendPlaidWebhook(h, payload)
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	jobs, err := suite.riverClient.JobList(context.Background(), river.NewJobListParams().Kinds(plaid.RelinkEmailJobArgs{}.Kind()))
+	suite.Require().NoError(err)
+	suite.Len(jobs.Jobs, 1, "The user should only be emailed when the item starts expiring")
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_UnknownItemRetried() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+
+	// Plaid can tell us about an item before the exchange that stores it is done
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "NEW_ACCOUNTS_AVAILABLE",
+		ItemID:      webhookEventsPlaidItemID,
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	events := suite.plaidWebhookEvents(webhookEventsPlaidItemID)
+	suite.Require().Len(events, 1)
+	event := events[0]
+	suite.Require().Equal(dao.PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM, event.Status)
+	suite.Require().NotNil(event.NextAttemptAt)
+
+	now := clock.Now()
+	retried, err := ps.RetryDueWebhookEvents(context.Background(), suite.riverClient, now)
+	suite.Require().NoError(err)
+	suite.Zero(retried, "The event shouldn't be retried before it is due")
+
+	err = ps.InsertItem(userRecord.Id, webhookEventsPlaidItemID, webhookEventsPlaidAccessToken)
+	suite.Require().NoError(err)
+
+	retried, err = ps.RetryDueWebhookEvents(context.Background(), suite.riverClient, event.NextAttemptAt.Add(time.Second))
+	suite.Require().NoError(err)
+	suite.Equal(1, retried)
+
+	retriedEvent, err := dao.PlaidWebhookEventDao{}.FindById(event.Id)
+	suite.Require().NoError(err)
+	suite.Equal(dao.PLAID_WEBHOOK_EVENT_PROCESSED, retriedEvent.Status)
+	suite.Equal(2, retriedEvent.Attempts)
+	suite.Nil(retriedEvent.NextAttemptAt)
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(webhookEventsPlaidItemID)
+	suite.Require().NoError(err)
+	suite.True(item.NewAccountsAvailable)
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_UnknownItemFailsAfterRetries() {
+	h := suite.newHandler()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "LOGIN_REPAIRED",
+		ItemID:      "unknown-item-id",
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+
+	event := suite.plaidWebhookEvents("unknown-item-id")[0]
+	for event.Status == dao.PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM {
+		_, err := ps.RetryDueWebhookEvents(context.Background(), suite.riverClient, event.NextAttemptAt.Add(time.Second))
+		suite.Require().NoError(err)
+		updated, err := dao.PlaidWebhookEventDao{}.FindById(event.Id)
+		suite.Require().NoError(err)
+		suite.Require().Greater(updated.Attempts, event.Attempts, "Each due retry should be attempted")
+		event = *updated
+	}
+
+	suite.Equal(dao.PLAID_WEBHOOK_EVENT_FAILED, event.Status, "The event should fail once it runs out of retries")
+	suite.Equal(6, event.Attempts)
+	suite.Require().NotNil(event.Error)
+	suite.Contains(*event.Error, "plaid item not found")
+	suite.Nil(event.NextAttemptAt)
+}
+
+func (suite *IntegrationTestSuite) TestPlaidWebhookEvents_AdminRetry() {
+	h := suite.newHandler()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+
+	rec := suite.sendPlaidWebhook(h, plaid.WebhookPayload{
+		WebhookType: "ITEM",
+		WebhookCode: "ERROR",
+		ItemID:      webhookEventsPlaidItemID,
+		Error:       &plaid.WebhookPayloadError{ErrorType: "ITEM_ERROR", ErrorCode: "ITEM_LOGIN_REQUIRED"},
+	})
+	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	event := suite.plaidWebhookEvents(webhookEventsPlaidItemID)[0]
+	suite.Require().Equal(dao.PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM, event.Status)
+
+	err := ps.InsertItem(userRecord.Id, webhookEventsPlaidItemID, webhookEventsPlaidAccessToken)
+	suite.Require().NoError(err)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/plaid-webhooks/"+event.Id+"/retry", strings.NewReader(url.Values{}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(event.Id)
+	adminHandler := admin.Handler{RiverClient: suite.riverClient, Plaid: h.Plaid}
+	suite.Require().NoError(adminHandler.RetryPlaidWebhookEvent(newAdminContextAs(c, "auth0|admin", "admin@dreamfi.com", "sandbox-operations", "operations-admin")))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+	suite.WaitForJobsDone(1)
+
+	retried, err := dao.PlaidWebhookEventDao{}.FindById(event.Id)
+	suite.Require().NoError(err)
+	suite.Equal(dao.PLAID_WEBHOOK_EVENT_PROCESSED, retried.Status)
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(webhookEventsPlaidItemID)
+	suite.Require().NoError(err)
+	suite.Equal("ITEM_LOGIN_REQUIRED", *item.ItemError)
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_PLAID_WEBHOOK_EVENT, event.Id)
+	suite.Require().Len(entries, 1)
+	suite.Equal(admin.AUDIT_ACTION_PLAID_WEBHOOK_EVENT_RETRIED, entries[0].Action)
+	suite.Require().NotNil(entries[0].UserId)
+	suite.Equal(userRecord.Id, *entries[0].UserId)
+
+	// A processed event can't be retried
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest(http.MethodPost, "/admin/plaid-webhooks/"+event.Id+"/retry", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues(event.Id)
+	suite.Require().NoError(adminHandler.RetryPlaidWebhookEvent(newAdminContextAs(c, "auth0|admin", "admin@dreamfi.com", "sandbox-operations", "operations-admin")))
+	suite.Equal(http.StatusConflict, rec.Code)
+}
@@ -53,6 +53,8 @@ func (suite *IntegrationTestSuite) TestPlaidWebhookHandler_ValidWebhook_Success(
 
 	rec := suite.sendPlaidWebhook(h, webhookPayload)
 	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	// The user is emailed to relink the item
+	suite.WaitForJobsDone(1)
 
 	var itemNowPendingExpiration dao.PlaidItemDao
 	err := suite.TestDB.Where("plaid_item_id = ?", item.PlaidItemID).First(&itemNowPendingExpiration).Error
@@ -193,6 +195,7 @@ func (suite *IntegrationTestSuite) TestPlaidWebhookHandler_PendingExpiration_Suc
 
 	rec := suite.sendPlaidWebhook(h, webhookPayload)
 	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
 
 	var itemNowPendingExpiration dao.PlaidItemDao
 	err = suite.TestDB.Where("plaid_item_id = ?", item.PlaidItemID).First(&itemNowPendingExpiration).Error
@@ -214,6 +217,7 @@ func (suite *IntegrationTestSuite) TestPlaidWebhookHandler_PendingDisconnect_Suc
 
 	rec := suite.sendPlaidWebhook(h, webhookPayload)
 	suite.Require().Equal(http.StatusOK, rec.Code, "Expected status code 200 OK")
+	suite.WaitForJobsDone(1)
 
 	var itemNowPendingDisconnect dao.PlaidItemDao
 	err := suite.TestDB.Where("plaid_item_id = ?", item.PlaidItemID).First(&itemNowPendingDisconnect).Error
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/handler"
+	"process-api/pkg/security"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (suite *IntegrationTestSuite) newRateLimitedEcho(limit int) *echo.Echo {
+	config.Config.RateLimits = map[string]config.RateLimitRule{
+		"test_route": {Limit: limit, Window: 60000},
+	}
+
+	e := handler.NewEcho()
+	e.GET("/limited", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, security.RateLimit("test_route", security.RateLimitByIP))
+	return e
+}
+
+func (suite *IntegrationTestSuite) requestRateLimited(e *echo.Echo, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.Header.Set(echo.HeaderXRealIP, ip)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *IntegrationTestSuite) TestRateLimitMiddleware_RejectsOverLimit() {
+	windowStart := time.Now().Truncate(time.Minute)
+	unfreeze := clock.Freeze(windowStart.Add(15 * time.Second))
+	defer unfreeze()
+
+	e := suite.newRateLimitedEcho(2)
+
+	rec := suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("2", rec.Header().Get(security.HeaderRateLimitLimit))
+	suite.Equal("1", rec.Header().Get(security.HeaderRateLimitRemaining))
+	suite.Equal("45", rec.Header().Get(security.HeaderRateLimitReset))
+
+	rec = suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("0", rec.Header().Get(security.HeaderRateLimitRemaining))
+
+	rec = suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusTooManyRequests, rec.Code)
+	suite.Equal("45", rec.Header().Get(echo.HeaderRetryAfter))
+	suite.Contains(rec.Body.String(), "RATE_LIMIT_EXCEEDED")
+
+	rec = suite.requestRateLimited(e, "203.0.113.2")
+	suite.Equal(http.StatusOK, rec.Code, "Other keys must not share the counter")
+}
+
+func (suite *IntegrationTestSuite) TestRateLimitMiddleware_WeighsPreviousWindow() {
+	windowStart := time.Now().Truncate(time.Minute)
+	unfreeze := clock.Freeze(windowStart.Add(50 * time.Second))
+	e := suite.newRateLimitedEcho(4)
+	for range 4 {
+		suite.Equal(http.StatusOK, suite.requestRateLimited(e, "203.0.113.1").Code)
+	}
+	unfreeze()
+
+	// A quarter into the next window, three quarters of the previous window's 4 requests still count
+	unfreeze = clock.Freeze(windowStart.Add(75 * time.Second))
+	defer unfreeze()
+
+	rec := suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("0", rec.Header().Get(security.HeaderRateLimitRemaining))
+
+	rec = suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusTooManyRequests, rec.Code)
+}
+
+func (suite *IntegrationTestSuite) TestRateLimitMiddleware_UnconfiguredRouteIsNotLimited() {
+	config.Config.RateLimits = map[string]config.RateLimitRule{}
+
+	e := handler.NewEcho()
+	e.GET("/limited", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, security.RateLimit("test_route", security.RateLimitByIP))
+
+	rec := suite.requestRateLimited(e, "203.0.113.1")
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Empty(rec.Header().Get(security.HeaderRateLimitLimit))
+}
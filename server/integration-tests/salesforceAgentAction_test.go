@@ -0,0 +1,167 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/compliance"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/salesforce"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"time"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// newSalesforceAgentContext builds a request for an agent action on a customer, made by
+// agent@dreamfi.com with a fresh Idempotency-Key
+func newSalesforceAgentContext(userId string, action string, body string, scope string) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := newSalesforceContext(http.MethodPost, "/api/salesforce/customers/"+userId+"/"+action, body, scope)
+	c.Request().Header.Set(salesforce.HeaderSalesforceAgent, "agent@dreamfi.com")
+	c.Request().Header.Set(security.HeaderIdempotencyKey, uuid.New().String())
+	c.SetPath("/api/salesforce/customers/:userId/" + action)
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+	return c, rec
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceAgentActionRequiresAgentAndIdempotencyKey() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	lock := salesforce.AgentAction("write:card")(salesforce.SalesforceLockCustomerCard)
+
+	c, _ := newSalesforceAgentContext(userRecord.Id, "card/lock", "", "write:card")
+	c.Request().Header.Del(salesforce.HeaderSalesforceAgent)
+	errResp, ok := lock(c).(response.BadRequestErrors)
+	suite.Require().True(ok, "An action without an agent should be refused")
+	suite.Require().Equal(salesforce.HeaderSalesforceAgent, errResp.Errors[0].FieldName)
+
+	c, _ = newSalesforceAgentContext(userRecord.Id, "card/lock", "", "write:card")
+	c.Request().Header.Set(salesforce.HeaderSalesforceAgent, "not-an-email")
+	_, ok = lock(c).(response.BadRequestErrors)
+	suite.Require().True(ok, "An agent that is not an email should be refused")
+
+	c, _ = newSalesforceAgentContext(userRecord.Id, "card/lock", "", "write:card")
+	c.Request().Header.Del(security.HeaderIdempotencyKey)
+	errResp, ok = lock(c).(response.BadRequestErrors)
+	suite.Require().True(ok, "An action without an Idempotency-Key should be refused")
+	suite.Require().Equal(security.HeaderIdempotencyKey, errResp.Errors[0].FieldName)
+
+	c, _ = newSalesforceAgentContext(uuid.New().String(), "card/lock", "", "write:card")
+	notFound, ok := lock(c).(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusNotFound, notFound.StatusCode)
+
+	c, _ = newSalesforceAgentContext(userRecord.Id, "card/lock", "", "read:card")
+	forbidden, ok := lock(c).(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusForbidden, forbidden.StatusCode, "Locking a card should need the write scope")
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceLockCustomerCard() {
+	defer SetupMockForLedger(suite).Close()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	account := suite.createUserAccountCard(userRecord.Id)
+
+	// The agent the token was issued for takes precedence over the header
+	c, rec := newSalesforceAgentContext(userRecord.Id, "card/lock", "", "write:card")
+	claims := c.Get("salesforce_claims").(*validator.ValidatedClaims)
+	claims.CustomClaims.(*salesforce.SalesforceClaims).AgentEmail = "token-agent@dreamfi.com"
+	idempotencyKey := c.Request().Header.Get(security.HeaderIdempotencyKey)
+	suite.Require().NoError(salesforce.AgentAction("write:card")(salesforce.SalesforceLockCustomerCard)(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var responseBody salesforce.CardStatusResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Require().Equal("TEMPRORY_BLOCKED_BY_CLIENT", responseBody.CardStatus)
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_CARD, account.CardId)
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal("card.locked", entries[0].Action)
+	suite.Require().Equal("token-agent@dreamfi.com", entries[0].ActorEmail)
+	suite.Require().Equal("salesforce|salesforce-client@clients", entries[0].ActorId)
+
+	// A retry replays the first response rather than locking the card again
+	c, rec = newSalesforceAgentContext(userRecord.Id, "card/lock", "", "write:card")
+	c.Request().Header.Set(security.HeaderIdempotencyKey, idempotencyKey)
+	suite.Require().NoError(salesforce.AgentAction("write:card")(salesforce.SalesforceLockCustomerCard)(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().Equal("true", rec.Header().Get(security.HeaderIdempotentReplayed))
+	suite.Require().Len(suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_CARD, account.CardId), 1, "A replayed lock should not be audited again")
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceUnlockCustomerCardOnComplianceHold() {
+	defer SetupMockForLedger(suite).Close()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+	_, err := compliance.Place(logging.Logger, userRecord.Id, compliance.PlaceRequest{Reason: "Suspected fraud", Via: constant.COMPLIANCE_HOLD_VIA_ADMIN, PlacedBy: "compliance@dreamfi.com"}, clock.Now())
+	suite.Require().NoError(err)
+
+	c, rec := newSalesforceAgentContext(userRecord.Id, "card/unlock", "", "write:card")
+	suite.Require().NoError(salesforce.AgentAction("write:card")(salesforce.SalesforceUnlockCustomerCard)(c))
+	suite.Require().Equal(http.StatusForbidden, rec.Code, "A card should not be unlocked while the customer is on compliance hold")
+	suite.Require().Contains(rec.Body.String(), constant.COMPLIANCE_HOLD)
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceSubmitCustomerDispute() {
+	defer SetupMockForLedger(suite).Close()
+	suite.configEmail()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
+	salesforceHandler := salesforce.Handler{RiverClient: suite.riverClient, Env: "test"}
+
+	body := `{"transactionId": "ledger.ach.transfer_ach_pull_1755001708162912900", "reason": "Duplicate transaction", "details": "Customer called about a double charge"}`
+	c, rec := newSalesforceAgentContext(userRecord.Id, "disputes", body, "write:disputes")
+	suite.Require().NoError(salesforce.AgentAction("write:disputes")(salesforceHandler.SalesforceSubmitCustomerDispute)(c))
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	var responseBody salesforce.Dispute
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Require().Equal(constant.DISPUTE_SUBMITTED, responseBody.Status)
+	suite.Require().Equal(int64(7000), *responseBody.AmountCents)
+
+	events, err := dao.TransactionDisputeEventDao{}.FindByTransactionDisputeId(responseBody.Id)
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(events)
+	suite.Require().Equal(dao.DISPUTE_EVENT_SUBMITTED, events[0].Action)
+	suite.Require().Equal("agent@dreamfi.com", events[0].Actor, "The dispute should be attributed to the agent")
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_DISPUTE, responseBody.Id)
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal("dispute.submitted", entries[0].Action)
+
+	c, rec = newSalesforceAgentContext(userRecord.Id, "disputes", body, "write:disputes")
+	suite.Require().NoError(salesforce.AgentAction("write:disputes")(salesforceHandler.SalesforceSubmitCustomerDispute)(c))
+	suite.Require().Equal(http.StatusConflict, rec.Code, "A transaction should only be disputed once")
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceResendCustomerStatementEmail() {
+	suite.configEmail()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+	suite.Require().NoError(suite.TestDB.Model(&dao.UserAccountCardDao{}).Where("user_id = ?", userRecord.Id).Update("account_id", "500400026990").Error)
+	salesforceHandler := salesforce.Handler{RiverClient: suite.riverClient, Env: "test"}
+	resend := salesforce.AgentAction("write:statements")(salesforceHandler.SalesforceResendCustomerStatementEmail)
+
+	thisMonth := clock.Now()
+	c, rec := newSalesforceAgentContext(userRecord.Id, "statements/resend", `{"month": "`+thisMonth.Month().String()+`", "year": "`+thisMonth.Format("2006")+`"}`, "write:statements")
+	suite.Require().NoError(resend(c))
+	suite.Require().Equal(http.StatusBadRequest, rec.Code, "The statement for the current month is not issued yet")
+
+	lastMonth := time.Date(thisMonth.Year(), thisMonth.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	c, rec = newSalesforceAgentContext(userRecord.Id, "statements/resend", `{"month": "`+lastMonth.Month().String()+`", "year": "`+lastMonth.Format("2006")+`"}`, "write:statements")
+	suite.Require().NoError(resend(c))
+	suite.Require().Equal(http.StatusAccepted, rec.Code)
+
+	suite.WaitForJobsDone(1)
+
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_STATEMENT, "500400026990/"+lastMonth.Format("2006-01"))
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal("statement_email.resent", entries[0].Action)
+	suite.Require().Equal("agent@dreamfi.com", entries[0].ActorEmail)
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/model/response"
+	"process-api/pkg/salesforce"
+	"strings"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// newSalesforceContext is a request from the Salesforce integration whose token grants
+// scope
+func newSalesforceContext(method string, target string, body string, scope string) (echo.Context, *httptest.ResponseRecorder) {
+	e := handler.NewEcho()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	claims := &validator.ValidatedClaims{CustomClaims: &salesforce.SalesforceClaims{Scope: scope}}
+	claims.RegisteredClaims.Subject = "salesforce-client@clients"
+	c.Set("salesforce_claims", claims)
+	return c, rec
+}
+
+func newSalesforceCustomerContext(userId string, resource string, scope string) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := newSalesforceContext(http.MethodGet, "/api/salesforce/customers/"+userId+"/"+resource, "", scope)
+	c.SetPath("/api/salesforce/customers/:userId/" + resource)
+	c.SetParamNames("userId")
+	c.SetParamValues(userId)
+	return c, rec
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceFindCustomer() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+
+	for _, query := range []string{"email=TestUser@gmail.com", "mobileNo=4159871234", "ledgerCustomerNumber=" + userRecord.LedgerCustomerNumber} {
+		c, rec := newSalesforceContext(http.MethodGet, "/api/salesforce/customers?"+query, "", "read:customers")
+		suite.Require().NoError(salesforce.SalesforceFindCustomer(c), "Handler should not return an error for %s", query)
+		suite.Require().Equal(http.StatusOK, rec.Code)
+
+		var responseBody salesforce.CustomerResponse
+		suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+		suite.Require().Equal(userRecord.Id, responseBody.UserId)
+		suite.Require().Equal(constant.ACTIVE, responseBody.OnboardingStatus)
+		suite.Require().Equal("te*******@gmail.com", responseBody.MaskedEmail)
+		suite.Require().Equal("********1234", responseBody.MaskedMobileNo)
+		suite.Require().Equal(2000, responseBody.BirthYear)
+		suite.Require().NotContains(rec.Body.String(), userRecord.StreetAddress, "The street address should not be returned")
+	}
+
+	c, _ := newSalesforceContext(http.MethodGet, "/api/salesforce/customers?email=testuser@gmail.com&mobileNo=4159871234", "", "read:customers")
+	_, ok := salesforce.SalesforceFindCustomer(c).(response.BadRequestErrors)
+	suite.Require().True(ok, "Looking up by more than one field should be refused")
+
+	c, _ = newSalesforceContext(http.MethodGet, "/api/salesforce/customers?email=nobody@example.com", "", "read:customers")
+	errResp, ok := salesforce.SalesforceFindCustomer(c).(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusNotFound, errResp.StatusCode)
+
+	c, _ = newSalesforceContext(http.MethodGet, "/api/salesforce/customers?email=testuser@gmail.com", "", "read:balance")
+	errResp, ok = salesforce.SalesforceFindCustomer(c).(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusForbidden, errResp.StatusCode)
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceGetCustomerDisputes() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	open := suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_UNDER_REVIEW, clock.Now())
+	suite.createTransactionDisputeRecord(userRecord.Id, constant.DISPUTE_RESOLVED_CUSTOMER, clock.Now())
+
+	c, rec := newSalesforceCustomerContext(userRecord.Id, "disputes", "read:disputes")
+	suite.Require().NoError(salesforce.SalesforceGetCustomerDisputes(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var disputes []salesforce.Dispute
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &disputes), "Failed to unmarshal response")
+	suite.Require().Len(disputes, 1, "Only open disputes should be listed")
+	suite.Require().Equal(open.Id, disputes[0].Id)
+	suite.Require().NotNil(disputes[0].ResolutionDueAt)
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceGetCustomerDemographicUpdates() {
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.Require().NoError(suite.TestDB.Create(&dao.DemographicUpdatesDao{
+		Id:           "8c4f2a8e-0b7d-4d4c-9a36-3c2e1b0a9f8e",
+		Type:         constant.DEMOGRAPHIC_UPDATE_ADDRESS,
+		Status:       constant.DEMOGRAPHIC_UPDATE_PENDING,
+		UpdatedValue: json.RawMessage(`{"streetAddress": "742 Evergreen Terrace"}`),
+		UserId:       userRecord.Id,
+	}).Error)
+
+	c, rec := newSalesforceCustomerContext(userRecord.Id, "demographic-updates", "read:demographic_updates")
+	suite.Require().NoError(salesforce.SalesforceGetCustomerDemographicUpdates(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Require().NotContains(rec.Body.String(), "Evergreen", "The requested values should not be returned")
+
+	var updates []salesforce.DemographicUpdate
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &updates), "Failed to unmarshal response")
+	suite.Require().Len(updates, 1)
+	suite.Require().Equal(constant.DEMOGRAPHIC_UPDATE_PENDING, updates[0].Status)
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceGetCustomerCard() {
+	defer SetupMockForLedger(suite).Close()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+
+	c, rec := newSalesforceCustomerContext(userRecord.Id, "card", "read:card")
+	suite.Require().NoError(salesforce.SalesforceGetCustomerCard(c))
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var card salesforce.CardResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &card), "Failed to unmarshal response")
+	suite.Require().NotEmpty(card.CardStatus)
+	suite.Require().Equal("ACTIVE", card.AccountStatus)
+
+	c, _ = newSalesforceCustomerContext("5d1f0c3e-7b2a-4e6f-8a9c-0d1e2f3a4b5c", "card", "read:card")
+	errResp, ok := salesforce.SalesforceGetCustomerCard(c).(response.ErrorResponse)
+	suite.Require().True(ok, "Expected error of type response.ErrorResponse")
+	suite.Require().Equal(http.StatusNotFound, errResp.StatusCode)
+}
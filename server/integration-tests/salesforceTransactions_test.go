@@ -0,0 +1,68 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/model/response"
+	"process-api/pkg/salesforce"
+)
+
+// getSalesforceTransactions returns the listed transactions and the cursor of the next page
+func (suite *IntegrationTestSuite) getSalesforceTransactions(ledgerAccountID string, query string) ([]salesforce.Transaction, string, error) {
+	c, rec := newSalesforceContext(http.MethodGet, "/api/salesforce/accounts/"+ledgerAccountID+"/transactions?"+query, "", "read:transactions")
+	c.SetPath("/api/salesforce/accounts/:ledgerAccountID/transactions")
+	c.SetParamNames("ledgerAccountID")
+	c.SetParamValues(ledgerAccountID)
+	if err := salesforce.SalesforceGetTransactions(c); err != nil {
+		return nil, "", err
+	}
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var transactions []salesforce.Transaction
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &transactions), "Failed to unmarshal response")
+	return transactions, rec.Header().Get(salesforce.HeaderNextCursor), nil
+}
+
+func (suite *IntegrationTestSuite) TestSalesforceGetTransactions() {
+	defer SetupMockForLedger(suite).Close()
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	suite.createUserAccountCard(userRecord.Id)
+	suite.Require().NoError(suite.TestDB.Model(&dao.UserAccountCardDao{}).Where("user_id = ?", userRecord.Id).Update("account_id", "500400026990").Error)
+
+	all, nextCursor, err := suite.getSalesforceTransactions("500400026990", "")
+	suite.Require().NoError(err)
+	suite.Require().Len(all, 2, "All transactions should be listed unless paging")
+	suite.Require().Empty(nextCursor)
+
+	firstPage, nextCursor, err := suite.getSalesforceTransactions("500400026990", "limit=1")
+	suite.Require().NoError(err)
+	suite.Require().Len(firstPage, 1)
+	suite.Require().Equal("VOID", firstPage[0].Type, "Transactions should be listed newest first")
+	suite.Require().Nil(firstPage[0].DisputeStatus, "A void cannot be disputed")
+	suite.Require().Equal(firstPage[0].Id, nextCursor)
+
+	secondPage, nextCursor, err := suite.getSalesforceTransactions("500400026990", "limit=1&cursor="+nextCursor)
+	suite.Require().NoError(err)
+	suite.Require().Len(secondPage, 1)
+	suite.Require().Equal("ledger.ach.transfer_ach_pull_1755001708162912900", secondPage[0].Id)
+	suite.Require().Equal("500400026990", secondPage[0].AccountId)
+	suite.Require().Equal("none", *secondPage[0].DisputeStatus)
+	suite.Require().Empty(nextCursor, "The last page should not have a cursor")
+
+	byType, _, err := suite.getSalesforceTransactions("500400026990", "type=ACH_PULL")
+	suite.Require().NoError(err)
+	suite.Require().Len(byType, 1)
+	suite.Require().Equal("ACH_PULL", byType[0].Type)
+
+	byDate, _, err := suite.getSalesforceTransactions("500400026990", "from=2025-08-01&to=2025-08-12")
+	suite.Require().NoError(err)
+	suite.Require().Len(byDate, 1, "The to date should be inclusive")
+	suite.Require().Equal("ledger.ach.transfer_ach_pull_1755001708162912900", byDate[0].Id)
+
+	for _, query := range []string{"from=08/01/2025", "from=2025-09-01&to=2025-08-01", "limit=0", "cursor=unknown"} {
+		_, _, err := suite.getSalesforceTransactions("500400026990", query)
+		_, ok := err.(response.BadRequestErrors)
+		suite.Require().True(ok, "Expected a bad request for %s", query)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"process-api/pkg/sardine"
+	"process-api/pkg/utils"
+)
+
+func (suite *IntegrationTestSuite) TestShareWithSardine() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	suite.Require().NoError(ps.ShareWithSardine(context.Background(), item.PlaidItemID, nil, clock.Now()))
+	tokens, err := dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Require().Len(tokens, 2, "Each account should get a processor token")
+	for _, token := range tokens {
+		suite.Equal(userId, token.UserId)
+		suite.NotNil(token.RegisteredAt, "Sardine should have accepted the token")
+		suite.Nil(token.SyncedAt, "A newly registered token doesn't need syncing")
+		processorToken, err := utils.DecryptKmsBinary(token.KmsEncryptedProcessorToken)
+		suite.Require().NoError(err)
+		suite.Equal("processor-sandbox-0asd1-a92nc", processorToken)
+	}
+
+	suite.Require().NoError(ps.ShareWithSardine(context.Background(), item.PlaidItemID, []sardine.PlaidDataSyncJSONBodyScope{sardine.Balance}, clock.Now()))
+	tokens, err = dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Require().Len(tokens, 2, "Registered tokens shouldn't be replaced")
+	for _, token := range tokens {
+		suite.NotNil(token.SyncedAt, "Sardine should have been asked to pull the balance again")
+	}
+
+	suite.Require().NoError(ps.UnlinkItem(userId, item.PlaidItemID, "access-sandbox-1b7e6039-337b-34d7-a3cd-7e13e379c0b2"))
+	tokens, err = dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Require().Len(tokens, 2, "Revoked tokens should be kept")
+	for _, token := range tokens {
+		suite.NotNil(token.RevokedAt)
+		suite.Empty(token.KmsEncryptedProcessorToken, "Revoked tokens should be forgotten")
+	}
+}
+
+func (suite *IntegrationTestSuite) TestShareWithSardine_SkipsItemsNeedingRelink() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+	suite.Require().NoError(dao.PlaidItemDao{}.SetItemError(item.PlaidItemID, "ITEM_LOGIN_REQUIRED"))
+
+	suite.Require().NoError(ps.ShareWithSardine(context.Background(), item.PlaidItemID, plaid.SardineRelinkScopes, clock.Now()))
+	tokens, err := dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Empty(tokens)
+}
+
+func (suite *IntegrationTestSuite) TestQueueSardineShare() {
+	h, userId := suite.beforePlaid()
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userId)
+
+	suite.Require().NoError(plaid.QueueSardineShare(context.Background(), suite.riverClient, item.PlaidItemID))
+	tokens, err := dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Empty(tokens, "Nothing should be shared unless it's enabled")
+
+	config.Config.Sardine.SharePlaidData = true
+	defer func() { config.Config.Sardine.SharePlaidData = false }()
+	suite.Require().NoError(plaid.QueueSardineShare(context.Background(), suite.riverClient, item.PlaidItemID))
+	suite.WaitForJobsDone(1)
+
+	tokens, err = dao.SardineProcessorTokenDao{}.FindForItem(item.PlaidItemID)
+	suite.Require().NoError(err)
+	suite.Len(tokens, 2)
+}
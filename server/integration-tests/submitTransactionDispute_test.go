@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"process-api/pkg/constant"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/handler"
 	"process-api/pkg/model/response"
@@ -15,6 +16,7 @@ import (
 
 func (suite *IntegrationTestSuite) TestSubmitTransactionDispute() {
 	defer SetupMockForLedger(suite).Close()
+	suite.configEmail()
 
 	user := suite.createTestUser(PartialMasterUserRecordDao{LedgerCustomerNumber: utils.Pointer("100000000034052")})
 
@@ -38,20 +40,35 @@ func (suite *IntegrationTestSuite) TestSubmitTransactionDispute() {
 
 	customContext := security.GenerateLoggedInRegisteredUserContext(user.Id, "publicKey", c)
 
-	err = handler.SubmitTransactionDispute(customContext)
+	err = suite.newHandler().SubmitTransactionDispute(customContext)
 	suite.NoError(err, "Handler should not return an error")
 	suite.Equal(http.StatusCreated, rec.Code, "Expected status code 201 Created")
 
+	var responseBody handler.SubmitTransactionDisputeResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &responseBody), "Failed to unmarshal response")
+	suite.Equal(constant.DISPUTE_LEGACY_PENDING, responseBody.Status, "Released app builds only parse the legacy statuses")
+
 	var transactionDisputeRecord dao.TransactionDisputeDao
 	err = suite.TestDB.Model(&dao.TransactionDisputeDao{}).Where("transaction_identifier = ?", referenceId).First(&transactionDisputeRecord).Error
 	suite.Require().NoError(err, "Failed to fetch transaction dispute record from database")
-	suite.Require().Equal("pending", transactionDisputeRecord.Status, "Invalid status")
+	suite.Require().Equal(constant.DISPUTE_SUBMITTED, transactionDisputeRecord.Status, "Invalid status")
 	suite.Require().NotEmpty(transactionDisputeRecord.TransactionIdentifier, "Process id should not be empty")
 	suite.Require().Equal("ledger.ach.transfer_ach_pull_1755001708162912900", transactionDisputeRecord.TransactionIdentifier, "Process id must be valid")
 	suite.Require().NotEmpty(transactionDisputeRecord.UserId, "User id should not be empty")
 	suite.Require().Equal(user.Id, transactionDisputeRecord.UserId, "User id must be valid")
 	suite.Require().Equal("Duplicate transaction", transactionDisputeRecord.Reason, "Invalid reason")
 	suite.Equal("I am not sure what this charge is for.", transactionDisputeRecord.Details, "Invalid details")
+	suite.Require().NotNil(transactionDisputeRecord.AmountCents, "Disputed amount should be stored")
+	suite.Equal(int64(7000), *transactionDisputeRecord.AmountCents, "Disputed amount should come from the ledger")
+	suite.Require().NotNil(transactionDisputeRecord.ProvisionalCreditDueAt, "Provisional credit deadline should be set")
+	suite.Require().NotNil(transactionDisputeRecord.ResolutionDueAt, "Resolution deadline should be set")
+	suite.True(transactionDisputeRecord.ResolutionDueAt.After(*transactionDisputeRecord.ProvisionalCreditDueAt), "Resolution deadline should follow the provisional credit deadline")
+
+	events, err := dao.TransactionDisputeEventDao{}.FindByTransactionDisputeId(transactionDisputeRecord.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 2, "Expected the submission and the queued notification")
+	suite.Equal(dao.DISPUTE_EVENT_SUBMITTED, events[0].Action)
+	suite.Equal(dao.DISPUTE_EVENT_NOTIFICATION_QUEUED, events[1].Action)
 }
 
 func (suite *IntegrationTestSuite) TestSubmitTransactionDisputeForEmptyTransaction() {
@@ -79,7 +96,7 @@ func (suite *IntegrationTestSuite) TestSubmitTransactionDisputeForEmptyTransacti
 
 	customContext := security.GenerateLoggedInRegisteredUserContext(user.Id, "publicKey", c)
 
-	err = handler.SubmitTransactionDispute(customContext)
+	err = suite.newHandler().SubmitTransactionDispute(customContext)
 	suite.NotNil(err, "Handler should return an error")
 
 	errResp := err.(response.ErrorResponse)
@@ -112,7 +129,7 @@ func (suite *IntegrationTestSuite) TestSubmitTransactionDisputeForInvalidTransac
 
 	customContext := security.GenerateLoggedInRegisteredUserContext(user.Id, "publicKey", c)
 
-	err = handler.SubmitTransactionDispute(customContext)
+	err = suite.newHandler().SubmitTransactionDispute(customContext)
 	suite.NotNil(err, "Handler should return an error")
 
 	errResp := err.(response.ErrorResponse)
@@ -145,7 +162,7 @@ func (suite *IntegrationTestSuite) TestSubmitTransactionDisputeForTransactioncti
 
 	customContext := security.GenerateLoggedInRegisteredUserContext(user.Id, "publicKey", c)
 
-	err = handler.SubmitTransactionDispute(customContext)
+	err = suite.newHandler().SubmitTransactionDispute(customContext)
 	suite.Require().Error(err, "Handler should return an error")
 
 	var responseErr response.ErrorResponse
@@ -8,16 +8,21 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"process-api/pkg/admin"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
+	"process-api/pkg/crypto"
 	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
 	"process-api/pkg/handler"
 	"process-api/pkg/logging"
 	"process-api/pkg/plaid"
 	"process-api/pkg/resource/agreements"
+	"process-api/pkg/statement"
 	"process-api/pkg/utils"
 	"process-api/pkg/validators"
+	"process-api/pkg/webhook"
 	"testing"
 	"time"
 
@@ -310,7 +315,15 @@ func (suite *IntegrationTestSuite) newRiver(cfg *config.Configs, testName string
 	handler.RegisterTransactionMonitoringWorker(workers)
 
 	handler.RegisterRefreshBalancesWorker(workers, plaid.NewPlaid(cfg))
-	handler.RegisterStatementNotificationWorker(workers)
+	plaid.RegisterSyncTransactionsWorker(workers, plaid.NewPlaid(cfg))
+	plaid.RegisterShareWithSardineWorker(workers, plaid.NewPlaid(cfg))
+	plaid.RegisterRelinkEmailWorker(workers)
+	statement.RegisterNotificationWorker(workers)
+	handler.RegisterNewDeviceAlertWorker(workers)
+	admin.RegisterDemographicUpdateOutcomeWorker(workers)
+	dispute.RegisterDisputeStatusEmailWorker(workers)
+	dispute.RegisterDeadlineEscalationWorker(workers)
+	webhook.RegisterDeliveryWorker(workers)
 	statementNotificationBatchWorker := handler.RegisterStatementNotificationEmailEnqueueBatchWorker(workers, nil)
 
 	riverClient, err := river.NewClient(riverdatabasesql.New(suite.initialDB.DB()), &river.Config{
@@ -322,6 +335,7 @@ func (suite *IntegrationTestSuite) newRiver(cfg *config.Configs, testName string
 			"debtwise":         {MaxWorkers: 1},
 			"plaid":            {MaxWorkers: 1},
 			"sendgrid":         {MaxWorkers: 1},
+			"webhooks":         {MaxWorkers: 1},
 		},
 		Workers: workers,
 	})
@@ -382,6 +396,31 @@ func (suite *IntegrationTestSuite) createUserPublicKeyRecord(userId string) dao.
 	return userPublicKey
 }
 
+// createSigningUserPublicKeyRecord registers a real ECDSA key for the user and
+// returns the private key so tests can sign payloads like the mobile app does.
+func (suite *IntegrationTestSuite) createSigningUserPublicKeyRecord(userId string) (dao.UserPublicKey, string) {
+	encryptedApiKey, _ := utils.EncryptKmsBinary("c077ad8b3d6f40c9896f5fb475f738d6")
+
+	publicKey, privateKey, err := crypto.CreateKeys()
+	suite.Require().NoError(err, "Failed to create test key pair")
+
+	userPublicKey := dao.UserPublicKey{
+		UserId:             userId,
+		KeyId:              "12345",
+		KmsEncryptedApiKey: encryptedApiKey,
+		PublicKey:          publicKey,
+	}
+	err = suite.TestDB.Create(&userPublicKey).Error
+	suite.Require().NoError(err, "Failed to insert test userPublicKey record")
+	return userPublicKey, privateKey
+}
+
+func (suite *IntegrationTestSuite) signPayload(payload string, privateKey string) string {
+	signature, err := crypto.SignECDSA([]byte(payload), privateKey)
+	suite.Require().NoError(err, "Failed to sign test payload")
+	return signature
+}
+
 func (suite *IntegrationTestSuite) createUserAccountCard(userId string) dao.UserAccountCardDao {
 	userAccountCard := dao.UserAccountCardDao{
 		CardHolderId:  "CH0000060090",
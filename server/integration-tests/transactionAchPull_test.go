@@ -7,9 +7,12 @@ import (
 	"net/http/httptest"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
+	"process-api/pkg/crypto"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/handler"
 	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
 	"process-api/pkg/security"
 	"process-api/pkg/utils"
 
@@ -39,24 +42,37 @@ func (suite *IntegrationTestSuite) TestTransactionAchPull() {
 	err = suite.TestDB.Select("id", "first_name", "last_name", "email", "kms_encrypted_ledger_password", "ledger_customer_number", "password", "user_status").Create(&userRecord).Error
 	suite.Require().NoError(err, "Failed to insert test user")
 
+	// Linked like an account verified with micro-deposits, which has no balance to check
+	ps := plaid.PlaidService{Logger: logging.Logger, Plaid: h.Plaid, DB: suite.TestDB}
+	item := suite.linkBalanceItem(ps, userRecord.Id)
+	err = suite.TestDB.Model(&dao.PlaidAccountDao{}).Where("plaid_item_id = ?", item.PlaidItemID).Updates(map[string]any{
+		"balance_refreshed_at":    nil,
+		"available_balance_cents": nil,
+		"identity_match_status":   dao.IDENTITY_MATCH_APPROVED,
+	}).Error
+	suite.Require().NoError(err, "Failed to update linked account")
+
 	reason := "Settlements"
 	payloadData := ledger.BuildOutboundAchDebitRequest(
 		&userRecord,
 		"50040002699049",
 		"50000",
 		"DEBTOR",
-		"234567890123456",
+		"1111119602",
 		"012345678",
 		"CHECKING",
 		&reason,
 		nil,
 	)
 
-	payload, err := dao.CreateSignablePayloadForUser(userRecord.Id, payloadData)
+	payload, err := ledger.AchDebitPayload.CreateForUser(userRecord.Id, payloadData)
 	suite.Require().Nil(err, "Failed to create signable payload for user")
 
+	publicKey, privateKey, err := crypto.CreateKeys()
+	suite.Require().NoError(err, "Failed to create example key pair")
+
 	transactionRequest := handler.TransactionAchPullRequest{
-		Signature: "example_signature",
+		Signature: suite.signPayload(payload.Payload, privateKey),
 		PayloadId: payload.PayloadId,
 	}
 	requestBody, err := json.Marshal(transactionRequest)
@@ -70,7 +86,7 @@ func (suite *IntegrationTestSuite) TestTransactionAchPull() {
 		UserId:             sessionId,
 		KmsEncryptedApiKey: []byte(encryptedApiKey),
 		KeyId:              "exampleKeyId",
-		PublicKey:          "examplePublicKey",
+		PublicKey:          publicKey,
 	}
 
 	err = suite.TestDB.Create(&userPublicKey).Error
@@ -84,7 +100,7 @@ func (suite *IntegrationTestSuite) TestTransactionAchPull() {
 	c := e.NewContext(req, rec)
 	c.SetPath("/ach/pull")
 
-	customContext := security.GenerateLoggedInRegisteredUserContext(sessionId, "examplePublicKey", c)
+	customContext := security.GenerateLoggedInRegisteredUserContext(sessionId, publicKey, c)
 
 	err = h.TransactionAchPull(customContext)
 
@@ -97,4 +113,10 @@ func (suite *IntegrationTestSuite) TestTransactionAchPull() {
 	suite.Require().NoError(err, "Failed to unmarshal response")
 
 	suite.Require().EqualValues(500_00, responseBody.Amount, "Expected Amount to match")
+
+	balanceCheck, err := dao.AchPullBalanceCheckDao{}.FindByPayloadId(payload.PayloadId)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(balanceCheck, "The pull's balance check should be recorded")
+	suite.Equal(dao.ACH_PULL_BALANCE_UNCHECKED, balanceCheck.Decision, "Plaid has no balance for the debited account")
+	suite.Equal(responseBody.TransactionNumber, *balanceCheck.TransactionNumber)
 }
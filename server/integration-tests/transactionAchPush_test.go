@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
+	"process-api/pkg/crypto"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/handler"
 	"process-api/pkg/ledger"
@@ -53,11 +54,14 @@ func (suite *IntegrationTestSuite) TestTransactionAchPush() {
 		nil,
 	)
 
-	payload, err := dao.CreateSignablePayloadForUser(userRecord.Id, payloadData)
+	payload, err := ledger.AchCreditPayload.CreateForUser(userRecord.Id, payloadData)
 	suite.Require().Nil(err, "Failed to create signable payload for user")
 
+	publicKey, privateKey, err := crypto.CreateKeys()
+	suite.Require().NoError(err, "Failed to create example key pair")
+
 	transactionRequest := handler.TransactionAchPushRequest{
-		Signature: "example_signature",
+		Signature: suite.signPayload(payload.Payload, privateKey),
 		PayloadId: payload.PayloadId,
 	}
 	requestBody, err := json.Marshal(transactionRequest)
@@ -71,7 +75,7 @@ func (suite *IntegrationTestSuite) TestTransactionAchPush() {
 		UserId:             sessionId,
 		KmsEncryptedApiKey: []byte(encryptedApiKey),
 		KeyId:              "exampleKeyId",
-		PublicKey:          "examplePublicKey",
+		PublicKey:          publicKey,
 	}
 
 	err = suite.TestDB.Create(&userPublicKey).Error
@@ -85,7 +89,7 @@ func (suite *IntegrationTestSuite) TestTransactionAchPush() {
 	c := e.NewContext(req, rec)
 	c.SetPath("/ach/push")
 
-	customContext := security.GenerateLoggedInRegisteredUserContext(sessionId, "examplePublicKey", c)
+	customContext := security.GenerateLoggedInRegisteredUserContext(sessionId, publicKey, c)
 
 	err = h.TransactionAchPush(customContext)
 
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/handler"
+	"process-api/pkg/webhook"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// webhookRequest is a delivery as the subscriber received it
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookSubscriber starts a subscriber that responds with status and records what it
+// receives
+func newWebhookSubscriber(status int) (*httptest.Server, *[]webhookRequest) {
+	var received []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, webhookRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	return server, &received
+}
+
+func (suite *IntegrationTestSuite) webhookDeliveries(subscriptionId string) []dao.WebhookDeliveryListItem {
+	deliveries, _, err := dao.WebhookDeliveryDao{}.Search(dao.WebhookDeliveryFilter{SubscriptionId: subscriptionId}, 100, 0)
+	suite.Require().NoError(err)
+	return deliveries
+}
+
+func (suite *IntegrationTestSuite) TestWebhookStatusChangeIsDeliveredSigned() {
+	server, received := newWebhookSubscriber(http.StatusOK)
+	defer server.Close()
+
+	now := clock.Now()
+	subscription, secret, err := webhook.CreateSubscription(webhook.SubscriptionRequest{
+		Name:       "Salesforce",
+		Url:        server.URL,
+		EventTypes: []string{dao.WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED},
+		CreatedBy:  "admin@dreamfi.com",
+	}, now)
+	suite.Require().NoError(err)
+	other, _, err := webhook.CreateSubscription(webhook.SubscriptionRequest{
+		Name:       "Disputes only",
+		Url:        server.URL,
+		EventTypes: []string{dao.WEBHOOK_EVENT_DISPUTE_UPDATED},
+		CreatedBy:  "admin@dreamfi.com",
+	}, now)
+	suite.Require().NoError(err)
+
+	phoneVerified := constant.PHONE_NUMBER_VERIFIED
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{UserStatus: &phoneVerified})
+	changedAt := now.Add(time.Second)
+
+	_, err = dao.MasterUserRecordDao{}.UpdateTrackingStatus(userRecord.Id, map[string]any{"city": "Austin"}, changedAt)
+	suite.Require().NoError(err)
+	_, err = dao.MasterUserRecordDao{}.UpdateTrackingStatus(userRecord.Id, map[string]any{"user_status": constant.ADDRESS_CONFIRMED}, changedAt)
+	suite.Require().NoError(err)
+
+	created, err := webhook.Dispatch(context.Background(), suite.riverClient, changedAt)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, created, "Only the status change should be delivered, and only to its subscriber")
+	suite.Require().Empty(suite.webhookDeliveries(other.Id))
+
+	created, err = webhook.Dispatch(context.Background(), suite.riverClient, changedAt)
+	suite.Require().NoError(err)
+	suite.Require().Zero(created, "Events should only be dispatched once")
+
+	deliveries := suite.webhookDeliveries(subscription.Id)
+	suite.Require().Len(deliveries, 1)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_PENDING, deliveries[0].Status)
+	suite.Require().Equal(userRecord.Id, *deliveries[0].UserId)
+
+	suite.Require().NoError(webhook.Deliver(context.Background(), deliveries[0].Id, false, changedAt))
+	suite.Require().Len(*received, 1)
+	request := (*received)[0]
+
+	timestamp, err := strconv.ParseInt(request.header.Get(webhook.HeaderTimestamp), 10, 64)
+	suite.Require().NoError(err)
+	suite.Require().True(webhook.Verify(secret, timestamp, request.body, request.header.Get(webhook.HeaderSignature)), "The delivery should be signed with the subscription's secret")
+	suite.Require().Equal(dao.WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED, request.header.Get(webhook.HeaderEvent))
+	suite.Require().Equal(deliveries[0].Id, request.header.Get(webhook.HeaderDelivery))
+
+	var envelope struct {
+		Type string                             `json:"type"`
+		Data dao.CustomerStatusChangedEventData `json:"data"`
+	}
+	suite.Require().NoError(json.Unmarshal(request.body, &envelope))
+	suite.Require().Equal(dao.WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED, envelope.Type)
+	suite.Require().Equal(dao.CustomerStatusChangedEventData{
+		UserId:         userRecord.Id,
+		PreviousStatus: constant.PHONE_NUMBER_VERIFIED,
+		Status:         constant.ADDRESS_CONFIRMED,
+	}, envelope.Data)
+
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(deliveries[0].Id)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_DELIVERED, delivery.Status)
+	suite.Require().Equal(1, delivery.Attempts)
+	suite.Require().NotNil(delivery.DeliveredAt)
+
+	attempts, err := dao.WebhookDeliveryDao{}.FindAttempts(delivery.Id)
+	suite.Require().NoError(err)
+	suite.Require().Len(attempts, 1)
+	suite.Require().Equal(http.StatusOK, *attempts[0].ResponseStatus)
+	suite.Require().Equal("ok", *attempts[0].ResponseBody)
+
+	suite.Require().NoError(webhook.Deliver(context.Background(), delivery.Id, false, changedAt))
+	suite.Require().Len(*received, 1, "A delivered delivery should not be sent again")
+}
+
+func (suite *IntegrationTestSuite) TestWebhookDeliveryRetriesUntilDead() {
+	server, received := newWebhookSubscriber(http.StatusInternalServerError)
+	defer server.Close()
+
+	now := clock.Now()
+	subscription, _, err := webhook.CreateSubscription(webhook.SubscriptionRequest{
+		Name:       "Salesforce",
+		Url:        server.URL,
+		EventTypes: []string{dao.WEBHOOK_EVENT_CARD_LOCKED},
+		CreatedBy:  "admin@dreamfi.com",
+	}, now)
+	suite.Require().NoError(err)
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	err = dao.WebhookEventDao{}.Create(db.DB, dao.WEBHOOK_EVENT_CARD_LOCKED, userRecord.Id, dao.CardLockedEventData{UserId: userRecord.Id, CardId: "card", Via: dao.CARD_LOCKED_VIA_APP}, now)
+	suite.Require().NoError(err)
+	_, err = webhook.Dispatch(context.Background(), suite.riverClient, now)
+	suite.Require().NoError(err)
+	deliveryId := suite.webhookDeliveries(subscription.Id)[0].Id
+
+	err = webhook.Deliver(context.Background(), deliveryId, false, now)
+	suite.Require().Error(err, "A rejected delivery should be retried")
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_PENDING, delivery.Status)
+
+	err = webhook.Deliver(context.Background(), deliveryId, true, now)
+	suite.Require().Error(err)
+	delivery, err = dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_DEAD, delivery.Status, "The delivery should be dead after its last attempt")
+	suite.Require().Equal(2, delivery.Attempts)
+	suite.Require().Len(*received, 2)
+
+	e := handler.NewEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/webhooks/deliveries/"+deliveryId+"/redeliver", strings.NewReader(url.Values{}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(deliveryId)
+	adminHandler := admin.Handler{RiverClient: suite.riverClient}
+	suite.Require().NoError(adminHandler.RedeliverWebhook(newAdminContextAs(c, "auth0|admin", "admin@dreamfi.com", "sandbox-operations", "operations-admin")))
+	suite.Require().Equal(http.StatusSeeOther, rec.Code)
+
+	delivery, err = dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_PENDING, delivery.Status, "A redelivered delivery should be pending again")
+	entries := suite.auditLogEntries(dao.ADMIN_AUDIT_ENTITY_WEBHOOK_DELIVERY, deliveryId)
+	suite.Require().Len(entries, 1)
+	suite.Require().Equal(admin.AUDIT_ACTION_WEBHOOK_DELIVERY_REDELIVERED, entries[0].Action)
+
+	_, _, err = webhook.DisableSubscription(subscription.Id, "admin@dreamfi.com", now)
+	suite.Require().NoError(err)
+	suite.Require().NoError(webhook.Deliver(context.Background(), deliveryId, false, now))
+	delivery, err = dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_DEAD, delivery.Status, "Deliveries to a disabled subscription should be dead")
+	suite.Require().Len(*received, 2, "Nothing should be sent to a disabled subscription")
+}
+
+func (suite *IntegrationTestSuite) TestWebhookDeliveryDeadWhenLastAttemptFailsBeforeSending() {
+	server, received := newWebhookSubscriber(http.StatusOK)
+	defer server.Close()
+
+	now := clock.Now()
+	subscription, _, err := webhook.CreateSubscription(webhook.SubscriptionRequest{
+		Name:       "Salesforce",
+		Url:        server.URL,
+		EventTypes: []string{dao.WEBHOOK_EVENT_CARD_LOCKED},
+		CreatedBy:  "admin@dreamfi.com",
+	}, now)
+	suite.Require().NoError(err)
+
+	userRecord := suite.createTestUser(PartialMasterUserRecordDao{})
+	err = dao.WebhookEventDao{}.Create(db.DB, dao.WEBHOOK_EVENT_CARD_LOCKED, userRecord.Id, dao.CardLockedEventData{UserId: userRecord.Id, CardId: "card", Via: dao.CARD_LOCKED_VIA_APP}, now)
+	suite.Require().NoError(err)
+	_, err = webhook.Dispatch(context.Background(), suite.riverClient, now)
+	suite.Require().NoError(err)
+	deliveryId := suite.webhookDeliveries(subscription.Id)[0].Id
+
+	// A secret that can't be decrypted fails the delivery before anything is sent
+	err = suite.TestDB.Model(&dao.WebhookSubscriptionDao{}).Where("id = ?", subscription.Id).Update("secret", []byte("not-encrypted")).Error
+	suite.Require().NoError(err)
+
+	suite.Require().Error(webhook.Deliver(context.Background(), deliveryId, false, now))
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_PENDING, delivery.Status, "The delivery should be retried")
+
+	suite.Require().Error(webhook.Deliver(context.Background(), deliveryId, true, now))
+	delivery, err = dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(dao.WEBHOOK_DELIVERY_DEAD, delivery.Status, "The delivery should be dead after its last attempt, whatever failed")
+	suite.Require().Empty(*received)
+}
@@ -13,18 +13,24 @@ import (
 	"strings"
 	"syscall"
 
+	"process-api/pkg/admin"
+	"process-api/pkg/audit"
 	"process-api/pkg/clock"
+	"process-api/pkg/compliance"
 	"process-api/pkg/config"
 	"process-api/pkg/db"
+	"process-api/pkg/dispute"
 	"process-api/pkg/handler"
 	"process-api/pkg/logging"
 	"process-api/pkg/maintenance"
 	"process-api/pkg/plaid"
 	"process-api/pkg/resource/agreements"
 	"process-api/pkg/security"
+	"process-api/pkg/statement"
 	"process-api/pkg/utils"
 	"process-api/pkg/validators"
 	"process-api/pkg/version"
+	"process-api/pkg/webhook"
 
 	_ "process-api/pkg/docs"
 
@@ -113,8 +119,33 @@ func main() {
 	plaidClient := plaid.NewPlaid(config.Config)
 	workers := river.NewWorkers()
 
-	handler.RegisterStatementNotificationWorker(workers)
+	statement.RegisterNotificationWorker(workers)
 	handler.RegisterRefreshBalancesWorker(workers, plaidClient)
+	plaid.RegisterSyncTransactionsWorker(workers, plaidClient)
+	plaid.RegisterShareWithSardineWorker(workers, plaidClient)
+	plaid.RegisterRetryWebhookEventsWorker(workers, plaidClient)
+	plaid.RegisterRelinkEmailWorker(workers)
+	plaid.RegisterItemHealthWorker(workers)
+	handler.RegisterNewDeviceAlertWorker(workers)
+	admin.RegisterDemographicUpdateOutcomeWorker(workers)
+	dispute.RegisterDisputeStatusEmailWorker(workers)
+	dispute.RegisterDeadlineEscalationWorker(workers)
+	compliance.RegisterExpiryWorker(workers)
+	webhook.RegisterDispatchWorker(workers)
+	webhook.RegisterDeliveryWorker(workers)
+
+	disputeEscalationSchedule, err := cron.ParseStandard(config.Config.Schedulers.EscalateDisputesCronExp)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid dispute escalation schedule: %s", err))
+	}
+	complianceHoldExpirySchedule, err := cron.ParseStandard(config.Config.Schedulers.ExpireComplianceHoldsCronExp)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid compliance hold expiry schedule: %s", err))
+	}
+	plaidItemHealthSchedule, err := cron.ParseStandard(config.Config.Schedulers.PlaidItemHealthCronExp)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid plaid item health schedule: %s", err))
+	}
 
 	riverClient, err := river.NewClient(riverdatabasesql.New(db.DB.DB()), &river.Config{
 		Queues: map[string]river.QueueConfig{
@@ -122,8 +153,16 @@ func main() {
 			"debtwise":         {MaxWorkers: 100},
 			"plaid":            {MaxWorkers: 100},
 			"sendgrid":         {MaxWorkers: 100},
+			"webhooks":         {MaxWorkers: 50},
 		},
 		Workers: workers,
+		PeriodicJobs: []*river.PeriodicJob{
+			dispute.NewDeadlineEscalationPeriodicJob(disputeEscalationSchedule),
+			compliance.NewExpiryPeriodicJob(complianceHoldExpirySchedule),
+			webhook.NewDispatchPeriodicJob(),
+			plaid.NewRetryWebhookEventsPeriodicJob(),
+			plaid.NewItemHealthPeriodicJob(plaidItemHealthSchedule),
+		},
 	})
 	if err != nil {
 		panic(err)
@@ -146,11 +185,19 @@ func main() {
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 	clientUrl := config.Config.Ledger.ClientUrl
 
+	// The gateway audit client connects lazily, so the admin console still starts
+	// without the gateway
+	auditClient, err := audit.New(ctx)
+	if err != nil {
+		logging.Logger.Error("Error in initializing gateway audit client", "error", err)
+	}
+
 	h := handler.Handler{
 		Config:      config.Config,
 		Plaid:       plaid.NewPlaid(config.Config),
 		Env:         env,
 		RiverClient: riverClient,
+		AuditClient: auditClient,
 	}
 
 	h.BuildRoutes(e, clientUrl, env)
@@ -160,11 +207,18 @@ func main() {
 	legacyClientUrl := "/process-api/evolvingsb/"
 	h.BuildRoutes(e, legacyClientUrl, env)
 
+	adminSessionStore, err := security.InitSessionStore("/admin", db.DB.DB())
+	if err != nil {
+		log.Fatal("Error in initializing admin session store: " + err.Error())
+	}
+	h.BuildAdminRoutes(e, adminSessionStore)
+
 	c := cron.New()
 
 	// Schedule DeleteExpiredOTPs() to run at 2:35 AM daily
 	_, err = c.AddFunc(config.Config.Schedulers.DeleteExpiredOTPsCronExp, func() {
 		maintenance.DeleteExpiredOTPs()
+		maintenance.DeleteOldOtpSendEvents()
 		logging.Logger.Info("Ended DeleteExpiredOTPs() scheduler at", "time", clock.Now())
 	})
 	if err != nil {
@@ -180,6 +234,33 @@ func main() {
 		logging.Logger.Error("Failed to add function", "error", err)
 	}
 
+	// Schedule DeleteExpiredSignablePayloads() to run every 15 minutes
+	_, err = c.AddFunc(config.Config.Schedulers.DeleteExpiredPayloadsCronExp, func() {
+		maintenance.DeleteExpiredSignablePayloads()
+		logging.Logger.Info("Ended DeleteExpiredSignablePayloads() scheduler at", "time", clock.Now())
+	})
+	if err != nil {
+		logging.Logger.Error("Failed to add function", "error", err)
+	}
+
+	// Schedule DeleteExpiredRateLimitCounters() to run hourly
+	_, err = c.AddFunc(config.Config.Schedulers.DeleteRateLimitCountersCronExp, func() {
+		maintenance.DeleteExpiredRateLimitCounters()
+		logging.Logger.Info("Ended DeleteExpiredRateLimitCounters() scheduler at", "time", clock.Now())
+	})
+	if err != nil {
+		logging.Logger.Error("Failed to add function", "error", err)
+	}
+
+	// Schedule DeleteExpiredIdempotencyKeys() to run hourly
+	_, err = c.AddFunc(config.Config.Schedulers.DeleteIdempotencyKeysCronExp, func() {
+		maintenance.DeleteExpiredIdempotencyKeys()
+		logging.Logger.Info("Ended DeleteExpiredIdempotencyKeys() scheduler at", "time", clock.Now())
+	})
+	if err != nil {
+		logging.Logger.Error("Failed to add function", "error", err)
+	}
+
 	c.Start()
 
 	sigintOrTerm := make(chan os.Signal, 1)
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"net/http"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/templates"
+	"strconv"
+
+	"braces.dev/errtrace"
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+const pageSize = 25
+
+func render(c echo.Context, statusCode int, component templ.Component) error {
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(statusCode)
+	return errtrace.Wrap(component.Render(c.Request().Context(), c.Response()))
+}
+
+func operatorFromContext(c echo.Context) (*security.AdminUserContext, templates.Operator, bool) {
+	cc, ok := c.(*security.AdminUserContext)
+	if !ok {
+		return nil, templates.Operator{}, false
+	}
+	return cc, templates.Operator{Id: cc.UserID, Name: cc.Name, Email: cc.Email, Permissions: cc.Permissions}, true
+}
+
+// renderError logs err and renders an error page, since admin pages are HTML
+// rather than the JSON error responses of the API
+func renderError(c echo.Context, operator templates.Operator, statusCode int, message string, err error) error {
+	if err != nil {
+		logging.GetEchoContextLogger(c).Error(message, "error", err.Error())
+	}
+	return render(c, statusCode, templates.ErrorPage(operator, http.StatusText(statusCode), message))
+}
+
+func currentPage(c echo.Context) int {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+func csrfToken(c echo.Context) string {
+	token, _ := c.Get("csrf").(string)
+	return token
+}
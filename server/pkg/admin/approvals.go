@@ -0,0 +1,254 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"process-api/templates"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Audited approval actions
+const (
+	AUDIT_ACTION_APPROVAL_REQUESTED = "approval.requested"
+	AUDIT_ACTION_APPROVAL_APPROVED  = "approval.approved"
+	AUDIT_ACTION_APPROVAL_REJECTED  = "approval.rejected"
+	AUDIT_ACTION_APPROVAL_FAILED    = "approval.failed"
+)
+
+// errApprovalStale is returned by an approved action that no longer applies, such as a
+// credit for a dispute that has since been resolved
+var errApprovalStale = errors.New("the approved action no longer applies")
+
+// approvalAction is a high-risk action that one operator requests and a second, holding
+// the same permission, approves before it is taken
+type approvalAction struct {
+	Permission security.AdminPermission
+	execute    func(h *Handler, c echo.Context, adminCtx *security.AdminUserContext, request dao.AdminApprovalRequestDao) error
+}
+
+// approvalActions are keyed by the audit action the approved action is recorded under
+var approvalActions = map[string]approvalAction{
+	AUDIT_ACTION_DISPUTE_PREFIX + string(dispute.ActionProvisionalCredit): {
+		Permission: security.ADMIN_PERMISSION_MONEY_CREDIT,
+		execute:    (*Handler).executeDisputeApproval,
+	},
+	AUDIT_ACTION_DISPUTE_PREFIX + string(dispute.ActionResolveCustomer): {
+		Permission: security.ADMIN_PERMISSION_MONEY_CREDIT,
+		execute:    (*Handler).executeDisputeApproval,
+	},
+}
+
+func needsApproval(action string) bool {
+	_, ok := approvalActions[action]
+	return ok
+}
+
+var approvalStatuses = []string{
+	constant.ADMIN_APPROVAL_PENDING,
+	constant.ADMIN_APPROVAL_APPROVED,
+	constant.ADMIN_APPROVAL_REJECTED,
+	constant.ADMIN_APPROVAL_FAILED,
+}
+
+func approvalAuditSnapshot(request dao.AdminApprovalRequestDao) map[string]any {
+	return map[string]any{
+		"action":        request.Action,
+		"entity_type":   request.EntityType,
+		"entity_id":     request.EntityId,
+		"reason":        request.Reason,
+		"status":        request.Status,
+		"requested_by":  request.RequestedByEmail,
+		"reviewed_by":   request.ReviewedByEmail,
+		"review_reason": request.ReviewReason,
+		"error":         request.Error,
+	}
+}
+
+// requestApproval holds the action for a second operator's approval instead of taking it
+func requestApproval(c echo.Context, adminCtx *security.AdminUserContext, request dao.AdminApprovalRequestDao) error {
+	request.Id = uuid.New().String()
+	request.Permission = string(approvalActions[request.Action].Permission)
+	request.Status = constant.ADMIN_APPROVAL_PENDING
+	request.RequestedById = adminCtx.UserID
+	request.RequestedByEmail = adminCtx.Email
+	request.RequestedAt = clock.Now()
+	if err := (dao.AdminApprovalRequestDao{}).Create(&request); err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_APPROVAL_REQUESTED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST,
+		EntityId:   request.Id,
+		UserId:     valueOrEmpty(request.UserId),
+		After:      approvalAuditSnapshot(request),
+	})
+	return nil
+}
+
+// ListApprovals lists high-risk actions awaiting a second operator, or those already
+// reviewed when another status is chosen
+func ListApprovals(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = constant.ADMIN_APPROVAL_PENDING
+	}
+	page := currentPage(c)
+
+	requests, totalCount, err := dao.AdminApprovalRequestDao{}.FindByStatus(status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load approval requests", err)
+	}
+
+	filters := url.Values{}
+	filters.Set("status", status)
+
+	return render(c, http.StatusOK, templates.Approvals(operator, templates.ApprovalsView{
+		Status:    status,
+		Statuses:  approvalStatuses,
+		Requests:  requests,
+		CsrfToken: csrfToken(c),
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+// loadReviewableApproval loads the pending request named in the path, rendering an error
+// and returning nil when the operator cannot review it
+func loadReviewableApproval(c echo.Context, adminCtx *security.AdminUserContext, operator templates.Operator) (*dao.AdminApprovalRequestDao, error) {
+	request, err := dao.AdminApprovalRequestDao{}.FindById(c.Param("id"))
+	if err != nil {
+		return nil, renderError(c, operator, http.StatusInternalServerError, "Failed to load approval request", err)
+	}
+	if request == nil {
+		return nil, renderError(c, operator, http.StatusNotFound, "Approval request not found", nil)
+	}
+	if request.Status != constant.ADMIN_APPROVAL_PENDING {
+		return nil, renderError(c, operator, http.StatusConflict, "This request was already reviewed", nil)
+	}
+	if !adminCtx.Can(security.AdminPermission(request.Permission)) {
+		return nil, renderError(c, operator, http.StatusForbidden, fmt.Sprintf("Reviewing this request requires the %s permission", request.Permission), nil)
+	}
+	return request, nil
+}
+
+// ApproveRequest approves a pending request and takes its action. The operator who made
+// the request cannot approve it.
+func (h *Handler) ApproveRequest(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	request, err := loadReviewableApproval(c, adminCtx, operator)
+	if request == nil {
+		return err
+	}
+	if request.RequestedById == adminCtx.UserID {
+		return renderError(c, operator, http.StatusForbidden, "A request must be approved by a different operator than the one who made it", nil)
+	}
+	action, ok := approvalActions[request.Action]
+	if !ok {
+		return renderError(c, operator, http.StatusInternalServerError, fmt.Sprintf("Unknown approval action %s", request.Action), nil)
+	}
+
+	before := *request
+	reviewed, err := dao.AdminApprovalRequestDao{}.Review(request.Id, constant.ADMIN_APPROVAL_APPROVED, adminCtx.UserID, adminCtx.Email, nil, clock.Now())
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to approve request", err)
+	}
+	if !reviewed {
+		return renderError(c, operator, http.StatusConflict, "This request was already reviewed", nil)
+	}
+	request.Status, request.ReviewedById, request.ReviewedByEmail = constant.ADMIN_APPROVAL_APPROVED, &adminCtx.UserID, &adminCtx.Email
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_APPROVAL_APPROVED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST,
+		EntityId:   request.Id,
+		UserId:     valueOrEmpty(request.UserId),
+		Before:     approvalAuditSnapshot(before),
+		After:      approvalAuditSnapshot(*request),
+	})
+
+	if err := action.execute(h, c, adminCtx, *request); err != nil {
+		if markErr := (dao.AdminApprovalRequestDao{}).MarkFailed(request.Id, err.Error()); markErr != nil {
+			return renderError(c, operator, http.StatusInternalServerError, "Failed to record the approved action's failure", markErr)
+		}
+		failed := *request
+		failed.Status, failed.Error = constant.ADMIN_APPROVAL_FAILED, utils.Pointer(err.Error())
+		recordAudit(c, adminCtx, auditEntry{
+			Action:     AUDIT_ACTION_APPROVAL_FAILED,
+			EntityType: dao.ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST,
+			EntityId:   request.Id,
+			UserId:     valueOrEmpty(request.UserId),
+			Before:     approvalAuditSnapshot(*request),
+			After:      approvalAuditSnapshot(failed),
+		})
+		if errors.Is(err, errApprovalStale) {
+			return renderError(c, operator, http.StatusConflict, "The request was approved, but its action no longer applies and was not taken", nil)
+		}
+		return renderError(c, operator, http.StatusInternalServerError, "The request was approved, but its action could not be completed", err)
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/admin/approvals")
+}
+
+// RejectRequest rejects a pending request without taking its action. The operator who
+// made the request may reject it to withdraw it.
+func RejectRequest(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	reason := strings.TrimSpace(c.FormValue("reason"))
+	if reason == "" {
+		return renderError(c, operator, http.StatusBadRequest, "A reason is required to reject a request", nil)
+	}
+
+	request, err := loadReviewableApproval(c, adminCtx, operator)
+	if request == nil {
+		return err
+	}
+
+	before := *request
+	reviewed, err := dao.AdminApprovalRequestDao{}.Review(request.Id, constant.ADMIN_APPROVAL_REJECTED, adminCtx.UserID, adminCtx.Email, &reason, clock.Now())
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to reject request", err)
+	}
+	if !reviewed {
+		return renderError(c, operator, http.StatusConflict, "This request was already reviewed", nil)
+	}
+	request.Status, request.ReviewedById, request.ReviewedByEmail, request.ReviewReason = constant.ADMIN_APPROVAL_REJECTED, &adminCtx.UserID, &adminCtx.Email, &reason
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_APPROVAL_REJECTED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST,
+		EntityId:   request.Id,
+		UserId:     valueOrEmpty(request.UserId),
+		Before:     approvalAuditSnapshot(before),
+		After:      approvalAuditSnapshot(*request),
+	})
+
+	return c.Redirect(http.StatusSeeOther, "/admin/approvals")
+}
@@ -0,0 +1,210 @@
+package admin
+
+import (
+	"encoding/json"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"slices"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+const ADMIN_AUDIT_WRITE_FAILED_EVENT = "admin_audit_write_failed"
+
+// Audited actions
+const (
+	AUDIT_ACTION_DEVICE_REVOKED                = "device.revoked"
+	AUDIT_ACTION_DEMOGRAPHIC_UPDATE_APPROVED   = "demographic_update.approved"
+	AUDIT_ACTION_DEMOGRAPHIC_UPDATE_REJECTED   = "demographic_update.rejected"
+	AUDIT_ACTION_COMPLIANCE_HOLD_PLACED        = "compliance_hold.placed"
+	AUDIT_ACTION_COMPLIANCE_HOLD_RELEASED      = "compliance_hold.released"
+	AUDIT_ACTION_CARD_LOCKED                   = "card.locked"
+	AUDIT_ACTION_CARD_UNLOCKED                 = "card.unlocked"
+	AUDIT_ACTION_STATEMENT_EMAIL_RESENT        = "statement_email.resent"
+	AUDIT_ACTION_DISPUTE_SUBMITTED             = "dispute.submitted"
+	AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_CREATED  = "webhook_subscription.created"
+	AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_DISABLED = "webhook_subscription.disabled"
+	AUDIT_ACTION_WEBHOOK_DELIVERY_REDELIVERED  = "webhook_delivery.redelivered"
+	AUDIT_ACTION_PLAID_WEBHOOK_EVENT_RETRIED   = "plaid_webhook_event.retried"
+	AUDIT_ACTION_PLAID_ACCOUNT_OWNER_APPROVED  = "plaid_account_owner.approved"
+	AUDIT_ACTION_PLAID_ACCOUNT_OWNER_REJECTED  = "plaid_account_owner.rejected"
+	// Dispute actions are audited as "dispute." followed by the dispute.Action
+	AUDIT_ACTION_DISPUTE_PREFIX = "dispute."
+)
+
+// auditRedactedFields hold customer PII. A change to one is recorded without its values.
+var auditRedactedFields = []string{
+	"first_name", "last_name", "suffix", "email", "mobile_no", "dob", "ssn",
+	"street_address", "apartment_no", "zip_code",
+}
+
+const auditRedacted = "[redacted]"
+
+// auditChange is the before and after value of a changed field
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditChanges returns the fields whose values differ between the before and after
+// snapshots of an entity, as JSON, with PII values redacted
+func auditChanges(before, after map[string]any) (string, error) {
+	changes := map[string]auditChange{}
+	for field := range keysOf(before, after) {
+		beforeJSON, err := json.Marshal(before[field])
+		if err != nil {
+			return "", errtrace.Wrap(err)
+		}
+		afterJSON, err := json.Marshal(after[field])
+		if err != nil {
+			return "", errtrace.Wrap(err)
+		}
+		if string(beforeJSON) == string(afterJSON) {
+			continue
+		}
+		if slices.Contains(auditRedactedFields, field) {
+			changes[field] = auditChange{Before: auditRedacted, After: auditRedacted}
+			continue
+		}
+		changes[field] = auditChange{Before: json.RawMessage(beforeJSON), After: json.RawMessage(afterJSON)}
+	}
+
+	// encoding/json sorts map keys, so the same changes always serialize the same way
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return "", errtrace.Wrap(err)
+	}
+	return string(changesJSON), nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func keysOf(snapshots ...map[string]any) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for _, snapshot := range snapshots {
+		for key := range snapshot {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// auditEntry describes an operator's change to an entity for the audit log
+type auditEntry struct {
+	Action     string
+	EntityType string
+	EntityId   string
+	// The customer the entity belongs to
+	UserId string
+	Before map[string]any
+	After  map[string]any
+}
+
+// auditActor is who made an audited change: an operator, or a system acting for one
+type auditActor struct {
+	Id    string
+	Email string
+	Roles []string
+}
+
+// recordAudit appends the operator's change to the audit log. It is written once the
+// change has been made, so a failure to write it cannot undo the change; it is logged
+// and alerted on instead.
+func recordAudit(c echo.Context, adminCtx *security.AdminUserContext, entry auditEntry) {
+	appendAudit(c, auditActor{Id: adminCtx.UserID, Email: adminCtx.Email, Roles: adminCtx.Roles}, entry)
+}
+
+// RecordExternalAudit audits a change made outside the console, such as by a Salesforce
+// agent, in the same log as the console's own changes
+func RecordExternalAudit(c echo.Context, actorId string, actorEmail string, actorRoles []string, action, entityType, entityId, userId string, before, after map[string]any) {
+	appendAudit(c, auditActor{Id: actorId, Email: actorEmail, Roles: actorRoles}, auditEntry{
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		UserId:     userId,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func appendAudit(c echo.Context, actor auditActor, entry auditEntry) {
+	logger := logging.GetEchoContextLogger(c)
+
+	changes, err := auditChanges(entry.Before, entry.After)
+	if err != nil {
+		logger.Error("Failed to diff audited change", "action", entry.Action, "entityId", entry.EntityId, "error", err.Error())
+		changes = "{}"
+	}
+
+	auditLog := dao.AdminAuditLogDao{
+		ActorId:    actor.Id,
+		ActorEmail: actor.Email,
+		ActorRoles: strings.Join(actor.Roles, ","),
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		UserId:     optionalString(entry.UserId),
+		Changes:    changes,
+		Ip:         optionalString(c.RealIP()),
+		RequestId:  optionalString(c.Response().Header().Get(echo.HeaderXRequestID)),
+		CreatedAt:  clock.Now(),
+	}
+	if err := (dao.AdminAuditLogDao{}).Append(&auditLog); err != nil {
+		logger.Error("Failed to write admin audit log", "action", entry.Action, "entityType", entry.EntityType, "entityId", entry.EntityId, "operator", actor.Email, "error", err.Error())
+		utils.PosthogClient.CaptureAlert(actor.Email, ADMIN_AUDIT_WRITE_FAILED_EVENT, map[string]any{
+			"action":     entry.Action,
+			"entityType": entry.EntityType,
+			"entityId":   entry.EntityId,
+		})
+	}
+}
+
+func deviceAuditSnapshot(device dao.UserPublicKey) map[string]any {
+	return map[string]any{
+		"revoked_at": device.RevokedAt,
+		"revoked_by": device.RevokedBy,
+	}
+}
+
+// demographicUpdateAuditSnapshot captures the update's review along with the customer
+// fields an approved update changes
+func demographicUpdateAuditSnapshot(update dao.DemographicUpdatesDao, user dao.MasterUserRecordDao) map[string]any {
+	return map[string]any{
+		"status":         update.Status,
+		"reviewed_by":    update.ReviewedBy,
+		"reviewed_at":    update.ReviewedAt,
+		"review_reason":  update.ReviewReason,
+		"first_name":     user.FirstName,
+		"last_name":      user.LastName,
+		"suffix":         user.Suffix,
+		"street_address": user.StreetAddress,
+		"apartment_no":   user.ApartmentNo,
+		"city":           user.City,
+		"state":          user.State,
+		"zip_code":       user.ZipCode,
+	}
+}
+
+func disputeAuditSnapshot(transactionDispute dao.TransactionDisputeDao) map[string]any {
+	return map[string]any{
+		"status":                         transactionDispute.Status,
+		"amount_cents":                   transactionDispute.AmountCents,
+		"provisional_credit_transaction": transactionDispute.ProvisionalCreditTransaction,
+		"credited_at":                    transactionDispute.CreditedAt,
+		"void_credit_transaction":        transactionDispute.VoidCreditTransaction,
+		"voided_at":                      transactionDispute.VoidedAt,
+		"resolved_by":                    transactionDispute.ResolvedBy,
+		"resolved_at":                    transactionDispute.ResolvedAt,
+		"resolution_reason":              transactionDispute.ResolutionReason,
+	}
+}
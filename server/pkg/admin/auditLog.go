@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/templates"
+	"strconv"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+var auditEntityTypes = []string{
+	dao.ADMIN_AUDIT_ENTITY_DEVICE,
+	dao.ADMIN_AUDIT_ENTITY_DEMOGRAPHIC_UPDATE,
+	dao.ADMIN_AUDIT_ENTITY_DISPUTE,
+	dao.ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST,
+	dao.ADMIN_AUDIT_ENTITY_CUSTOMER,
+	dao.ADMIN_AUDIT_ENTITY_WEBHOOK_SUBSCRIPTION,
+	dao.ADMIN_AUDIT_ENTITY_WEBHOOK_DELIVERY,
+	dao.ADMIN_AUDIT_ENTITY_PLAID_WEBHOOK_EVENT,
+	dao.ADMIN_AUDIT_ENTITY_PLAID_ACCOUNT,
+}
+
+// auditLogDateLayout is the layout of the from and to filters, which are whole UTC days
+const auditLogDateLayout = "2006-01-02"
+
+// auditLogFilterFromQuery reads the audit log filters, returning them along with the
+// query string that reproduces them
+func auditLogFilterFromQuery(c echo.Context) (dao.AdminAuditLogFilter, url.Values, error) {
+	filter := dao.AdminAuditLogFilter{
+		Query:      strings.TrimSpace(c.QueryParam("q")),
+		Action:     strings.TrimSpace(c.QueryParam("action")),
+		EntityType: c.QueryParam("entity"),
+	}
+	filters := url.Values{}
+	for _, name := range []string{"q", "action", "entity", "from", "to"} {
+		if value := strings.TrimSpace(c.QueryParam(name)); value != "" {
+			filters.Set(name, value)
+		}
+	}
+
+	if from := filters.Get("from"); from != "" {
+		fromDate, err := time.Parse(auditLogDateLayout, from)
+		if err != nil {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("invalid from date %q", from))
+		}
+		filter.From = fromDate
+	}
+	if to := filters.Get("to"); to != "" {
+		toDate, err := time.Parse(auditLogDateLayout, to)
+		if err != nil {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("invalid to date %q", to))
+		}
+		// The to date is inclusive
+		filter.To = toDate.AddDate(0, 0, 1)
+	}
+	return filter, filters, nil
+}
+
+// AuditLog lists the changes operators have made, newest first
+func AuditLog(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	filter, filters, err := auditLogFilterFromQuery(c)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, err.Error(), nil)
+	}
+	page := currentPage(c)
+
+	entries, totalCount, err := dao.AdminAuditLogDao{}.Search(filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search audit log", err)
+	}
+
+	return render(c, http.StatusOK, templates.AuditLog(operator, templates.AuditLogView{
+		Query:       filters.Get("q"),
+		Action:      filters.Get("action"),
+		EntityType:  filters.Get("entity"),
+		EntityTypes: auditEntityTypes,
+		From:        filters.Get("from"),
+		To:          filters.Get("to"),
+		Entries:     entries,
+		FilterQuery: filters.Encode(),
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+var auditLogCSVHeader = []string{
+	"id", "created_at", "actor_id", "actor_email", "actor_roles", "action", "entity_type", "entity_id",
+	"user_id", "changes", "ip", "request_id", "prev_hash", "hash",
+}
+
+// ExportAuditLog downloads the entries matching the filters as CSV, oldest first, with
+// their hashes so the export can be checked against the chain
+func ExportAuditLog(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	filter, _, err := auditLogFilterFromQuery(c)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, err.Error(), nil)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="admin-audit-log-%s.csv"`, clock.Now().UTC().Format("20060102-150405")))
+	c.Response().WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write(auditLogCSVHeader); err != nil {
+		return errtrace.Wrap(err)
+	}
+	err = dao.AdminAuditLogDao{}.FindInBatches(filter, 500, func(entries []dao.AdminAuditLogDao) error {
+		for _, entry := range entries {
+			record := []string{
+				strconv.FormatUint(entry.Id, 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+				entry.ActorId,
+				entry.ActorEmail,
+				entry.ActorRoles,
+				entry.Action,
+				entry.EntityType,
+				entry.EntityId,
+				valueOrEmpty(entry.UserId),
+				entry.Changes,
+				valueOrEmpty(entry.Ip),
+				valueOrEmpty(entry.RequestId),
+				entry.PrevHash,
+				entry.Hash,
+			}
+			if err := writer.Write(record); err != nil {
+				return errtrace.Wrap(err)
+			}
+		}
+		writer.Flush()
+		return errtrace.Wrap(writer.Error())
+	})
+	if err != nil {
+		// The response has started, so the export can only be cut short
+		logging.GetEchoContextLogger(c).Error("Failed to export audit log", "error", err.Error())
+		return nil
+	}
+	writer.Flush()
+	return errtrace.Wrap(writer.Error())
+}
+
+// VerifyAuditLog checks the audit log's hash chain from the first entry
+func VerifyAuditLog(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	verification, err := dao.AdminAuditLogDao{}.VerifyChain()
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to verify audit log", err)
+	}
+	if verification.BrokenAtId != nil {
+		logging.GetEchoContextLogger(c).Error("Admin audit log hash chain is broken", "entryId", *verification.BrokenAtId, "entriesChecked", verification.EntriesChecked)
+	}
+
+	return render(c, http.StatusOK, templates.AuditLogVerification(operator, verification))
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
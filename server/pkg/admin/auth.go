@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/templates"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// The OAuth state is kept in its own Lax cookie because the admin session cookie is
+// SameSite=Strict and is not sent on the redirect back from Auth0
+const oauthStateCookieName = "admin_oauth_state"
+
+func Login(c echo.Context) error {
+	stateBytes := make([]byte, 32)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return errtrace.Wrap(err)
+	}
+	state := base64.RawURLEncoding.EncodeToString(stateBytes)
+
+	c.SetCookie(&http.Cookie{
+		Name:     oauthStateCookieName,
+		Value:    state,
+		Path:     "/admin",
+		Expires:  clock.Now().Add(10 * time.Minute),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.Config.Server.BaseUrl, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return c.Redirect(http.StatusFound, security.GetAuthURL(state))
+}
+
+func Callback(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c)
+
+	stateCookie, err := c.Cookie(oauthStateCookieName)
+	if err != nil || stateCookie.Value == "" || stateCookie.Value != c.QueryParam("state") {
+		logger.Info("Admin login callback with missing or mismatched state")
+		return render(c, http.StatusBadRequest, templates.AuthError("The sign-in request expired or was not started from this browser."))
+	}
+	c.SetCookie(&http.Cookie{Name: oauthStateCookieName, Value: "", Path: "/admin", MaxAge: -1})
+
+	if authError := c.QueryParam("error"); authError != "" {
+		logger.Info("Auth0 returned an error to the admin login callback", "error", authError, "description", c.QueryParam("error_description"))
+		return render(c, http.StatusUnauthorized, templates.AuthError(c.QueryParam("error_description")))
+	}
+
+	token, rawIDToken, claims, err := security.ExchangeCodeForToken(c.Request().Context(), c.QueryParam("code"))
+	if err != nil {
+		logger.Error("Failed to exchange Auth0 code for token", "error", err.Error())
+		return render(c, http.StatusUnauthorized, templates.AuthError("Auth0 did not accept the sign-in."))
+	}
+
+	if err := security.CreateAdminSession(c, token, rawIDToken); err != nil {
+		logger.Error("Failed to create admin session", "error", err.Error())
+		return render(c, http.StatusInternalServerError, templates.AuthError("The admin session could not be created."))
+	}
+
+	logger.Info("Operator signed in to the admin console", "email", claims.Email)
+	return c.Redirect(http.StatusFound, "/admin/customers")
+}
+
+func Logout(c echo.Context) error {
+	security.ClearAdminSession(c)
+
+	query := url.Values{}
+	query.Set("client_id", config.Config.Auth0.ClientId)
+	query.Set("returnTo", config.Config.Auth0.LogoutReturnToUrl)
+	return c.Redirect(http.StatusFound, fmt.Sprintf("https://%s/v2/logout?%s", config.Config.Auth0.Domain, query.Encode()))
+}
@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/compliance"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// complianceHoldExpiryLayout is the format, in UTC, of the hold's expiry from its
+// datetime-local input
+const complianceHoldExpiryLayout = "2006-01-02T15:04"
+
+// PlaceComplianceHold stops all money movement for the customer until the hold is
+// released or expires, optionally suspending their ledger account too
+func PlaceComplianceHold(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(userId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load customer", err)
+	}
+	if customer == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No customer with id %s", userId), nil)
+	}
+
+	req := compliance.PlaceRequest{
+		Reason:         c.FormValue("reason"),
+		MirrorToLedger: c.FormValue("mirror_to_ledger") != "",
+		Via:            constant.COMPLIANCE_HOLD_VIA_ADMIN,
+		PlacedBy:       adminCtx.Email,
+	}
+	if value := strings.TrimSpace(c.FormValue("expires_at")); value != "" {
+		expiresAt, err := time.Parse(complianceHoldExpiryLayout, value)
+		if err != nil {
+			return renderError(c, operator, http.StatusBadRequest, "Invalid expiry", nil)
+		}
+		req.ExpiresAt = &expiresAt
+	}
+
+	hold, err := compliance.Place(logging.GetEchoContextLogger(c), userId, req, clock.Now())
+	switch {
+	case errors.Is(err, compliance.ErrReasonRequired):
+		return renderError(c, operator, http.StatusBadRequest, "A reason is required to place a compliance hold", nil)
+	case errors.Is(err, compliance.ErrExpiryNotInFuture):
+		return renderError(c, operator, http.StatusBadRequest, "The hold must expire in the future", nil)
+	case errors.Is(err, compliance.ErrHoldAlreadyPlaced):
+		return renderError(c, operator, http.StatusConflict, "The customer is already on compliance hold", nil)
+	case err != nil:
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to place compliance hold", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator placed compliance hold", "operator", adminCtx.Email, "userId", userId, "holdId", hold.Id)
+
+	recordAudit(c, adminCtx, complianceHoldAuditEntry(AUDIT_ACTION_COMPLIANCE_HOLD_PLACED, userId, nil, hold))
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/customers/%s", userId))
+}
+
+// ReleaseComplianceHold lifts the customer's hold, reactivating their ledger account if
+// the hold suspended it
+func ReleaseComplianceHold(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+	before, after, err := compliance.Release(logging.GetEchoContextLogger(c), userId, adminCtx.Email, c.FormValue("reason"), clock.Now())
+	switch {
+	case errors.Is(err, compliance.ErrReasonRequired):
+		return renderError(c, operator, http.StatusBadRequest, "A reason is required to release a compliance hold", nil)
+	case errors.Is(err, compliance.ErrNoHold):
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("Customer %s has no compliance hold", userId), nil)
+	case err != nil:
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to release compliance hold", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator released compliance hold", "operator", adminCtx.Email, "userId", userId, "holdId", after.Id)
+
+	recordAudit(c, adminCtx, complianceHoldAuditEntry(AUDIT_ACTION_COMPLIANCE_HOLD_RELEASED, userId, before, after))
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/customers/%s", userId))
+}
+
+func complianceHoldAuditEntry(action string, userId string, before, after *dao.ComplianceHoldDao) auditEntry {
+	return auditEntry{
+		Action:     action,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD,
+		EntityId:   after.Id,
+		UserId:     userId,
+		Before:     compliance.AuditSnapshot(before),
+		After:      compliance.AuditSnapshot(after),
+	}
+}
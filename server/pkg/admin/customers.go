@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+var customerStatuses = []string{
+	constant.USER_CREATED,
+	constant.PHONE_VERIFICATION_OTP_SENT,
+	constant.PHONE_NUMBER_VERIFIED,
+	constant.AGE_VERIFICATION_PASSED,
+	constant.AGE_VERIFICATION_FAILED,
+	constant.ADDRESS_CONFIRMED,
+	constant.PASSWORD_SET,
+	constant.AGREEMENTS_REVIEWED,
+	constant.CARD_AGREEMENTS_REVIEWED,
+	constant.MEMBERSHIP_ACCEPTED,
+	constant.ONBOARDING,
+	constant.KYC_PASS,
+	constant.KYC_FAIL,
+	constant.ACTIVE,
+}
+
+func SearchCustomers(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	query := c.QueryParam("q")
+	status := c.QueryParam("status")
+	page := currentPage(c)
+
+	customers, totalCount, err := dao.MasterUserRecordDao{}.SearchUsers(query, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search customers", err)
+	}
+
+	filters := url.Values{}
+	if query != "" {
+		filters.Set("q", query)
+	}
+	if status != "" {
+		filters.Set("status", status)
+	}
+
+	return render(c, http.StatusOK, templates.CustomerSearch(operator, templates.CustomerSearchView{
+		Query:     query,
+		Status:    status,
+		Statuses:  customerStatuses,
+		Customers: customers,
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+// AUDIT_ACTION_CUSTOMER_PII_REVEALED records an operator viewing a customer's unmasked
+// personal details
+const AUDIT_ACTION_CUSTOMER_PII_REVEALED = "customer.pii_revealed"
+
+// CustomerDetail shows everything about a customer. Their contact details, date of birth
+// and address are masked unless the operator asks to reveal them, which is audited.
+func CustomerDetail(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(userId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load customer", err)
+	}
+	if customer == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No customer with id %s", userId), nil)
+	}
+
+	view := templates.CustomerDetailView{Customer: *customer, CsrfToken: csrfToken(c)}
+
+	if c.QueryParam("reveal") == "pii" {
+		if !adminCtx.Can(security.ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL) {
+			return renderError(c, operator, http.StatusForbidden, "You do not have permission to reveal personal details", nil)
+		}
+		view.RevealPII = true
+		recordAudit(c, adminCtx, auditEntry{
+			Action:     AUDIT_ACTION_CUSTOMER_PII_REVEALED,
+			EntityType: dao.ADMIN_AUDIT_ENTITY_CUSTOMER,
+			EntityId:   userId,
+			UserId:     userId,
+		})
+	}
+
+	if view.Card, err = (dao.UserAccountCardDao{}).FindOneByUserId(db.DB, userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load card", err)
+	}
+	if view.Card != nil && view.Card.AccountId != "" {
+		view.LedgerBalance = fetchLedgerBalance(c, view.Card.AccountId)
+	}
+	if view.PlaidAccounts, err = (dao.PlaidAccountDao{}).FindAccountsForUser(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load Plaid accounts", err)
+	}
+	if view.PlaidItems, err = (dao.PlaidItemDao{}).GetItemsByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load Plaid items", err)
+	}
+	if view.Membership, err = (dao.UserMembershipDao{}).FindOneByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load membership", err)
+	}
+	if view.Disputes, err = (dao.TransactionDisputeDao{}).FindByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load disputes", err)
+	}
+	if view.DemographicUpdates, err = (dao.DemographicUpdatesDao{}).FindAllByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load demographic updates", err)
+	}
+	if view.Devices, err = (dao.UserPublicKey{}).FindByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load devices", err)
+	}
+	if view.ComplianceHolds, err = (dao.ComplianceHoldDao{}).FindByUserId(userId); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load compliance holds", err)
+	}
+	view.Now = clock.Now()
+
+	return render(c, http.StatusOK, templates.CustomerDetail(operator, view))
+}
+
+// fetchLedgerBalance reads the account balances from the ledger. A ledger failure is
+// shown on the page instead of failing it, since the rest of the detail comes from our DB.
+func fetchLedgerBalance(c echo.Context, accountId string) *templates.LedgerBalance {
+	logger := logging.GetEchoContextLogger(c)
+
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	ledgerAccountResp, err := ledgerClient.GetAccount(accountId)
+	if err != nil {
+		logger.Error("error while calling ledger's GetAccount", "error", err.Error())
+		return &templates.LedgerBalance{Error: "The ledger could not be reached."}
+	}
+	if ledgerAccountResp.Error != nil {
+		logger.Error("error from ledger's GetAccount", "error", ledgerAccountResp.Error)
+		return &templates.LedgerBalance{Error: fmt.Sprintf("The ledger returned an error: %s", ledgerAccountResp.Error.Message)}
+	}
+	if ledgerAccountResp.Result == nil {
+		logger.Error("no error was reported from ledger GetAccount, but Result is missing from response")
+		return &templates.LedgerBalance{Error: "The ledger returned no account."}
+	}
+
+	ledgerAccount := ledgerAccountResp.Result.Account
+	return &templates.LedgerBalance{
+		Status:             ledgerAccount.Status,
+		BalanceCents:       ledgerAccount.Balance,
+		HoldBalanceCents:   ledgerAccount.HoldBalance,
+		LedgerBalanceCents: ledgerAccount.LedgerBalance,
+	}
+}
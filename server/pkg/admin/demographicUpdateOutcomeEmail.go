@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"context"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+type DemographicUpdateOutcomeEmailJobArgs struct {
+	FirstName  string `json:"firstName"`
+	Email      string `json:"email"`
+	UpdateType string `json:"updateType"`
+	Approved   bool   `json:"approved"`
+	Reason     string `json:"reason"`
+}
+
+func (DemographicUpdateOutcomeEmailJobArgs) Kind() string { return "demographic_update_outcome_email" }
+
+func (DemographicUpdateOutcomeEmailJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "sendgrid",
+	}
+}
+
+type DemographicUpdateOutcomeWorker struct {
+	river.WorkerDefaults[DemographicUpdateOutcomeEmailJobArgs]
+}
+
+func RegisterDemographicUpdateOutcomeWorker(workers *river.Workers) {
+	river.AddWorker(workers, &DemographicUpdateOutcomeWorker{})
+}
+
+func (w *DemographicUpdateOutcomeWorker) Work(ctx context.Context, job *river.Job[DemographicUpdateOutcomeEmailJobArgs]) error {
+	err := sendDemographicUpdateOutcomeEmail(job.Args)
+	if err != nil {
+		logging.Logger.Error("Error sending demographic update outcome email", "err", err)
+		return err
+	}
+	return nil
+}
+
+func sendDemographicUpdateOutcomeEmail(args DemographicUpdateOutcomeEmailJobArgs) error {
+	updateDescription := "address"
+	if args.UpdateType == constant.DEMOGRAPHIC_UPDATE_FULL_NAME {
+		updateDescription = "name"
+	}
+
+	emailData := response.DemographicUpdateOutcomeEmailTemplateData{
+		FirstName:         args.FirstName,
+		UpdateDescription: updateDescription,
+		Approved:          args.Approved,
+		Reason:            args.Reason,
+	}
+
+	templateName := config.Config.Email.TemplateDirectory + constant.DEMOGRAPHIC_UPDATE_OUTCOME_TEMPLATE_NAME
+	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	emailSubject := "Your DreamFi " + updateDescription + " change was approved"
+	if !args.Approved {
+		emailSubject = "Your DreamFi " + updateDescription + " change could not be approved"
+	}
+	err = utils.SendEmail(args.FirstName, args.Email, emailSubject, htmlBody)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return nil
+}
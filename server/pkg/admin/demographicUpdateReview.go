@@ -0,0 +1,317 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"process-api/pkg/audit"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/debtwise"
+	"process-api/pkg/ledger"
+	"process-api/pkg/sardine"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	plaidSDK "github.com/plaid/plaid-go/v34/plaid"
+	"github.com/riverqueue/river"
+)
+
+// Handler holds the dependencies of admin actions that enqueue background jobs or call
+// other services
+type Handler struct {
+	RiverClient *river.Client[*sql.Tx]
+	// Reads the NetXD gateway's HTTP audits. Nil when the gateway is not available.
+	AuditClient *audit.AuditServiceClient
+	// Calls Plaid when retrying its webhooks
+	Plaid *plaidSDK.APIClient
+}
+
+var (
+	errDemographicUpdateNotFound   = errors.New("demographic update not found")
+	errDemographicUpdateNotPending = errors.New("demographic update was already reviewed")
+)
+
+// applyDemographicUpdate returns the user with the requested change applied, along with
+// the master_user_records columns that change.
+func applyDemographicUpdate(user dao.MasterUserRecordDao, update dao.DemographicUpdatesDao) (dao.MasterUserRecordDao, map[string]any, error) {
+	switch update.Type {
+	case constant.DEMOGRAPHIC_UPDATE_FULL_NAME:
+		var fullName dao.UpdateFullNameRequest
+		if err := json.Unmarshal(update.UpdatedValue, &fullName); err != nil {
+			return user, nil, errtrace.Wrap(fmt.Errorf("failed to decode full name update: %w", err))
+		}
+		user.FirstName, user.LastName, user.Suffix = fullName.FirstName, fullName.LastName, fullName.Suffix
+		return user, map[string]any{"first_name": user.FirstName, "last_name": user.LastName, "suffix": user.Suffix}, nil
+	case constant.DEMOGRAPHIC_UPDATE_ADDRESS:
+		var address dao.UpdateCustomerAddressRequest
+		if err := json.Unmarshal(update.UpdatedValue, &address); err != nil {
+			return user, nil, errtrace.Wrap(fmt.Errorf("failed to decode address update: %w", err))
+		}
+		user.StreetAddress, user.ApartmentNo, user.City, user.State, user.ZipCode = address.StreetAddress, address.ApartmentNo, address.City, address.State, address.ZipCode
+		return user, map[string]any{"street_address": user.StreetAddress, "apartment_no": user.ApartmentNo, "city": user.City, "state": user.State, "zip_code": user.ZipCode}, nil
+	default:
+		return user, nil, errtrace.Wrap(fmt.Errorf("demographic update type %s cannot be applied", update.Type))
+	}
+}
+
+// lockPendingDemographicUpdate locks the update row for the rest of tx so two operators
+// cannot review the same update at once. NO KEY UPDATE still lets events referencing the
+// row be written outside tx.
+func lockPendingDemographicUpdate(tx *gorm.DB, id string) (*dao.DemographicUpdatesDao, error) {
+	var update dao.DemographicUpdatesDao
+	err := tx.Set("gorm:query_option", "FOR NO KEY UPDATE").Where("id = ?", id).Take(&update).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errtrace.Wrap(errDemographicUpdateNotFound)
+	}
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if update.Status != constant.DEMOGRAPHIC_UPDATE_PENDING {
+		return nil, errtrace.Wrap(errDemographicUpdateNotPending)
+	}
+	return &update, nil
+}
+
+func recordDemographicUpdateEvent(logger *slog.Logger, demographicUpdateId, action, actor string, detail *string) {
+	if err := (dao.DemographicUpdateEventDao{}).Create(db.DB, demographicUpdateId, action, actor, detail, clock.Now()); err != nil {
+		logger.Error("Failed to record demographic update event", "demographicUpdateId", demographicUpdateId, "action", action, "error", err.Error())
+	}
+}
+
+// approveDemographicUpdate pushes the change to the ledger and then applies it to the
+// user record. The ledger is updated first, while the update row is locked, so a
+// ledger failure leaves the update pending for another attempt. Debtwise, Sardine and
+// the customer notification follow on a best-effort basis and are recorded as events.
+func (h *Handler) approveDemographicUpdate(ctx context.Context, logger *slog.Logger, id, actor, reason string) error {
+	var approved dao.DemographicUpdatesDao
+	var updatedUser dao.MasterUserRecordDao
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		update, err := lockPendingDemographicUpdate(tx, id)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		user, err := dao.MasterUserRecordDao{}.FindOneByUserId(update.UserId)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if user == nil {
+			return errtrace.Wrap(fmt.Errorf("user %s of demographic update %s not found", update.UserId, id))
+		}
+
+		var columns map[string]any
+		updatedUser, columns, err = applyDemographicUpdate(*user, *update)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		if err := updateLedgerCustomer(updatedUser); err != nil {
+			detail := err.Error()
+			recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_LEDGER_UPDATE_FAILED, actor, &detail)
+			return errtrace.Wrap(err)
+		}
+		if err := (dao.DemographicUpdateEventDao{}).Create(tx, id, dao.DEMOGRAPHIC_UPDATE_EVENT_LEDGER_UPDATED, actor, nil, clock.Now()); err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		if err := tx.Model(dao.MasterUserRecordDao{}).Where("id = ?", user.Id).Updates(columns).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		if _, err := (dao.DemographicUpdatesDao{}).MarkReviewed(tx, id, constant.DEMOGRAPHIC_UPDATE_ACCEPTED, actor, reason, clock.Now()); err != nil {
+			return errtrace.Wrap(err)
+		}
+		if err := (dao.DemographicUpdateEventDao{}).Create(tx, id, dao.DEMOGRAPHIC_UPDATE_EVENT_APPROVED, actor, optionalReason(reason), clock.Now()); err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		approved = *update
+		return nil
+	})
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	logger.Info("Demographic update approved", "demographicUpdateId", id, "type", approved.Type, "userId", approved.UserId, "operator", actor)
+
+	if approved.Type != constant.DEMOGRAPHIC_UPDATE_FULL_NAME || updatedUser.DebtwiseCustomerNumber == nil {
+		// Debtwise only holds the customer's name, and only once they have onboarded there
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_SKIPPED, actor, nil)
+	} else if err := updateDebtwiseUser(ctx, logger, updatedUser); err != nil {
+		logger.Error("Failed to sync approved demographic update to Debtwise", "demographicUpdateId", id, "error", err.Error())
+		detail := err.Error()
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_UPDATE_FAILED, actor, &detail)
+	} else {
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_UPDATED, actor, nil)
+	}
+
+	if err := updateSardineCustomer(ctx, updatedUser, approved.Type); err != nil {
+		logger.Error("Failed to sync approved demographic update to Sardine", "demographicUpdateId", id, "error", err.Error())
+		detail := err.Error()
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_SARDINE_UPDATE_FAILED, actor, &detail)
+	} else {
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_SARDINE_UPDATED, actor, nil)
+	}
+
+	h.notifyDemographicUpdateOutcome(ctx, logger, updatedUser, approved, true, reason, actor)
+	return nil
+}
+
+func (h *Handler) rejectDemographicUpdate(ctx context.Context, logger *slog.Logger, id, actor, reason string) error {
+	var rejected dao.DemographicUpdatesDao
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		update, err := lockPendingDemographicUpdate(tx, id)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if _, err := (dao.DemographicUpdatesDao{}).MarkReviewed(tx, id, constant.DEMOGRAPHIC_UPDATE_REJECTED, actor, reason, clock.Now()); err != nil {
+			return errtrace.Wrap(err)
+		}
+		if err := (dao.DemographicUpdateEventDao{}).Create(tx, id, dao.DEMOGRAPHIC_UPDATE_EVENT_REJECTED, actor, &reason, clock.Now()); err != nil {
+			return errtrace.Wrap(err)
+		}
+		rejected = *update
+		return nil
+	})
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	logger.Info("Demographic update rejected", "demographicUpdateId", id, "type", rejected.Type, "userId", rejected.UserId, "operator", actor)
+
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(rejected.UserId)
+	if err != nil || user == nil {
+		logger.Error("Failed to load user to notify of rejected demographic update", "demographicUpdateId", id, "error", err)
+		recordDemographicUpdateEvent(logger, id, dao.DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_FAILED, actor, nil)
+		return nil
+	}
+	h.notifyDemographicUpdateOutcome(ctx, logger, *user, rejected, false, reason, actor)
+	return nil
+}
+
+func optionalReason(reason string) *string {
+	if reason == "" {
+		return nil
+	}
+	return &reason
+}
+
+func updateLedgerCustomer(user dao.MasterUserRecordDao) error {
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	ledgerResponse, err := ledgerClient.UpdateCustomer(ledger.BuildUpdateCustomerRequest(user))
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error while calling ledger's UpdateCustomer: %w", err))
+	}
+	if ledgerResponse.Error != nil {
+		return errtrace.Wrap(fmt.Errorf("error from ledger's UpdateCustomer: %s", ledgerResponse.Error.Message))
+	}
+	return nil
+}
+
+func updateDebtwiseUser(ctx context.Context, logger *slog.Logger, user dao.MasterUserRecordDao) error {
+	debtwiseClient, err := debtwise.NewDebtwiseClient(config.Config.Debtwise, logger)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error occurred while creating debtwise client: %w", err))
+	}
+
+	debtwiseResponse, err := debtwiseClient.UpdateUserWithResponse(
+		ctx,
+		debtwise.UserIdParam(*user.DebtwiseCustomerNumber),
+		&debtwise.UpdateUserParams{},
+		debtwise.UpdateUserJSONRequestBody{FirstName: &user.FirstName, LastName: &user.LastName},
+	)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error occurred while invoking debtwise update user: %w", err))
+	}
+	if debtwiseResponse.JSON200 == nil {
+		return errtrace.Wrap(fmt.Errorf("unexpected debtwise response for update user: status %d", debtwiseResponse.StatusCode()))
+	}
+	return nil
+}
+
+func updateSardineCustomer(ctx context.Context, user dao.MasterUserRecordDao, updateType string) error {
+	client, err := utils.NewSardineClient(config.Config.Sardine)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("failed to create sardine client: %w", err))
+	}
+
+	flowName := "demographic_update"
+	flowType := sardine.FlowTypeAccountUpdate
+	if updateType == constant.DEMOGRAPHIC_UPDATE_ADDRESS {
+		flowType = sardine.FlowTypeAddressChange
+	}
+	checkpoints := []sardine.PostCustomerInformationJSONBodyCheckpoints{
+		sardine.PostCustomerInformationJSONBodyCheckpointsCustomer,
+	}
+	countryCode := "US"
+	street2 := &user.ApartmentNo
+	if user.ApartmentNo == "" {
+		street2 = nil
+	}
+
+	requestBody := sardine.PostCustomerInformationJSONRequestBody{
+		Flow: sardine.Flow{
+			Name: &flowName,
+			Type: &flowType,
+		},
+		// There is no device session behind an ops approval
+		SessionKey: uuid.New().String(),
+		Customer: sardine.Customer{
+			Id:        user.Id,
+			FirstName: &user.FirstName,
+			LastName:  &user.LastName,
+			Address: &sardine.Address{
+				Street1:     &user.StreetAddress,
+				Street2:     street2,
+				City:        &user.City,
+				RegionCode:  &user.State,
+				PostalCode:  &user.ZipCode,
+				CountryCode: &countryCode,
+			},
+		},
+		Checkpoints: &checkpoints,
+	}
+
+	sardineResponse, err := client.PostCustomerInformationWithResponse(ctx, requestBody)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error occurred while calling sardine API: %w", err))
+	}
+
+	switch {
+	case sardineResponse.JSON200 != nil:
+		return nil
+	case sardineResponse.JSON400 != nil:
+		return errtrace.Wrap(fmt.Errorf("received 400 response from sardine: %s", *sardineResponse.JSON400.Message))
+	case sardineResponse.JSON401 != nil:
+		return errtrace.Wrap(fmt.Errorf("received 401 response from sardine: %s", *sardineResponse.JSON401.Reason))
+	case sardineResponse.JSON422 != nil:
+		return errtrace.Wrap(fmt.Errorf("received 422 response from sardine: %s", *sardineResponse.JSON422.Message))
+	default:
+		return errtrace.Wrap(fmt.Errorf("received unexpected error response from sardine API"))
+	}
+}
+
+func (h *Handler) notifyDemographicUpdateOutcome(ctx context.Context, logger *slog.Logger, user dao.MasterUserRecordDao, update dao.DemographicUpdatesDao, approved bool, reason, actor string) {
+	_, err := h.RiverClient.Insert(ctx, DemographicUpdateOutcomeEmailJobArgs{
+		FirstName:  user.FirstName,
+		Email:      user.Email,
+		UpdateType: update.Type,
+		Approved:   approved,
+		Reason:     reason,
+	}, nil)
+	if err != nil {
+		logger.Error("Failed to enqueue demographic update outcome email", "demographicUpdateId", update.Id, "error", err.Error())
+		detail := err.Error()
+		recordDemographicUpdateEvent(logger, update.Id, dao.DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_FAILED, actor, &detail)
+		return
+	}
+	recordDemographicUpdateEvent(logger, update.Id, dao.DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_QUEUED, actor, nil)
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/templates"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+var demographicUpdateStatuses = []string{
+	constant.DEMOGRAPHIC_UPDATE_PENDING,
+	constant.DEMOGRAPHIC_UPDATE_ACCEPTED,
+	constant.DEMOGRAPHIC_UPDATE_REJECTED,
+}
+
+var demographicUpdateTypes = []string{
+	constant.DEMOGRAPHIC_UPDATE_FULL_NAME,
+	constant.DEMOGRAPHIC_UPDATE_ADDRESS,
+}
+
+// ListDemographicUpdates is the review queue. It shows pending updates unless another
+// status filter is chosen.
+func ListDemographicUpdates(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	query := c.QueryParam("q")
+	updateType := c.QueryParam("type")
+	status := constant.DEMOGRAPHIC_UPDATE_PENDING
+	if _, ok := c.QueryParams()["status"]; ok {
+		status = c.QueryParam("status")
+	}
+	page := currentPage(c)
+
+	updates, totalCount, err := dao.DemographicUpdatesDao{}.SearchDemographicUpdates(query, status, updateType, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search demographic updates", err)
+	}
+
+	filters := url.Values{}
+	if query != "" {
+		filters.Set("q", query)
+	}
+	filters.Set("status", status)
+	if updateType != "" {
+		filters.Set("type", updateType)
+	}
+
+	return render(c, http.StatusOK, templates.DemographicUpdateQueue(operator, templates.DemographicUpdateQueueView{
+		Query:    query,
+		Status:   status,
+		Type:     updateType,
+		Statuses: demographicUpdateStatuses,
+		Types:    demographicUpdateTypes,
+		Updates:  updates,
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+func DemographicUpdateDetail(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+
+	update, err := dao.DemographicUpdatesDao{}.FindById(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load demographic update", err)
+	}
+	if update == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No demographic update with id %s", id), nil)
+	}
+
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(update.UserId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load customer", err)
+	}
+	if customer == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No customer with id %s", update.UserId), nil)
+	}
+
+	events, err := dao.DemographicUpdateEventDao{}.FindByDemographicUpdateId(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load demographic update history", err)
+	}
+
+	requestedValue := string(update.UpdatedValue)
+	if requested, _, err := applyDemographicUpdate(*customer, *update); err == nil {
+		requestedValue = describeDemographicValue(requested, update.Type)
+	}
+
+	return render(c, http.StatusOK, templates.DemographicUpdateReview(operator, templates.DemographicUpdateReviewView{
+		Update:         *update,
+		Customer:       *customer,
+		CurrentValue:   describeDemographicValue(*customer, update.Type),
+		RequestedValue: requestedValue,
+		Events:         events,
+		CsrfToken:      csrfToken(c),
+	}))
+}
+
+func (h *Handler) ApproveDemographicUpdate(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	reason := strings.TrimSpace(c.FormValue("reason"))
+
+	before, userId, err := demographicUpdateAuditState(id)
+	if err != nil {
+		return renderReviewError(c, operator, err, "Failed to load demographic update")
+	}
+
+	err = h.approveDemographicUpdate(c.Request().Context(), logging.GetEchoContextLogger(c), id, adminCtx.Email, reason)
+	if err != nil {
+		return renderReviewError(c, operator, err, "The change could not be applied and the update is still pending. See the update's history for details.")
+	}
+
+	recordDemographicUpdateAudit(c, adminCtx, AUDIT_ACTION_DEMOGRAPHIC_UPDATE_APPROVED, id, userId, before)
+
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/demographic-updates/%s", id))
+}
+
+func (h *Handler) RejectDemographicUpdate(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	reason := strings.TrimSpace(c.FormValue("reason"))
+	if reason == "" {
+		return renderError(c, operator, http.StatusBadRequest, "A reason is required to reject a demographic update", nil)
+	}
+
+	before, userId, err := demographicUpdateAuditState(id)
+	if err != nil {
+		return renderReviewError(c, operator, err, "Failed to load demographic update")
+	}
+
+	err = h.rejectDemographicUpdate(c.Request().Context(), logging.GetEchoContextLogger(c), id, adminCtx.Email, reason)
+	if err != nil {
+		return renderReviewError(c, operator, err, "Failed to reject demographic update")
+	}
+
+	recordDemographicUpdateAudit(c, adminCtx, AUDIT_ACTION_DEMOGRAPHIC_UPDATE_REJECTED, id, userId, before)
+
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/demographic-updates/%s", id))
+}
+
+// demographicUpdateAuditState snapshots the update and the customer it changes for the
+// audit log, returning the customer's id
+func demographicUpdateAuditState(id string) (map[string]any, string, error) {
+	update, err := dao.DemographicUpdatesDao{}.FindById(id)
+	if err != nil {
+		return nil, "", errtrace.Wrap(err)
+	}
+	if update == nil {
+		return nil, "", errtrace.Wrap(errDemographicUpdateNotFound)
+	}
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(update.UserId)
+	if err != nil {
+		return nil, "", errtrace.Wrap(err)
+	}
+	if user == nil {
+		return nil, "", errtrace.Wrap(fmt.Errorf("user %s of demographic update %s not found", update.UserId, id))
+	}
+	return demographicUpdateAuditSnapshot(*update, *user), user.Id, nil
+}
+
+func recordDemographicUpdateAudit(c echo.Context, adminCtx *security.AdminUserContext, action, id, userId string, before map[string]any) {
+	after, _, err := demographicUpdateAuditState(id)
+	if err != nil {
+		// The review is still attributed to the operator, without its changes
+		logging.GetEchoContextLogger(c).Error("Failed to reload reviewed demographic update for audit", "demographicUpdateId", id, "error", err.Error())
+		after = before
+	}
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     action,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_DEMOGRAPHIC_UPDATE,
+		EntityId:   id,
+		UserId:     userId,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func renderReviewError(c echo.Context, operator templates.Operator, err error, message string) error {
+	switch {
+	case errors.Is(err, errDemographicUpdateNotFound):
+		return renderError(c, operator, http.StatusNotFound, "Demographic update not found", nil)
+	case errors.Is(err, errDemographicUpdateNotPending):
+		return renderError(c, operator, http.StatusConflict, "This demographic update has already been reviewed", nil)
+	default:
+		return renderError(c, operator, http.StatusInternalServerError, message, err)
+	}
+}
+
+func describeDemographicValue(user dao.MasterUserRecordDao, updateType string) string {
+	switch updateType {
+	case constant.DEMOGRAPHIC_UPDATE_FULL_NAME:
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", user.FirstName, user.LastName, user.Suffix))
+	case constant.DEMOGRAPHIC_UPDATE_ADDRESS:
+		street := user.StreetAddress
+		if user.ApartmentNo != "" {
+			street = fmt.Sprintf("%s %s", street, user.ApartmentNo)
+		}
+		return fmt.Sprintf("%s, %s, %s %s", street, user.City, user.State, user.ZipCode)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RevokeCustomerDevice lets ops revoke one of a customer's registered devices, e.g.
+// when the customer reports a lost phone. The operator's email is recorded as revoked_by.
+func RevokeCustomerDevice(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+	deviceId, err := strconv.ParseUint(c.Param("deviceId"), 10, 64)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, "Invalid device id", nil)
+	}
+
+	device, err := dao.UserPublicKey{}.FindByIdForUser(deviceId, userId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load device", err)
+	}
+	if device == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No device %d for customer %s", deviceId, userId), nil)
+	}
+
+	if !device.IsRevoked() {
+		if err := (dao.UserPublicKey{}).Revoke(device.ID, adminCtx.Email, clock.Now()); err != nil {
+			return renderError(c, operator, http.StatusInternalServerError, "Failed to revoke device", err)
+		}
+		logging.GetEchoContextLogger(c).Info("Operator revoked customer device", "operator", adminCtx.Email, "userId", userId, "deviceId", device.ID)
+
+		revoked, err := dao.UserPublicKey{}.FindByIdForUser(device.ID, userId)
+		if err != nil || revoked == nil {
+			logging.GetEchoContextLogger(c).Error("Failed to reload revoked device for audit", "deviceId", device.ID, "error", err)
+			revoked = device
+		}
+		recordAudit(c, adminCtx, auditEntry{
+			Action:     AUDIT_ACTION_DEVICE_REVOKED,
+			EntityType: dao.ADMIN_AUDIT_ENTITY_DEVICE,
+			EntityId:   strconv.FormatUint(device.ID, 10),
+			UserId:     userId,
+			Before:     deviceAuditSnapshot(*device),
+			After:      deviceAuditSnapshot(*revoked),
+		})
+	}
+
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/customers/%s", userId))
+}
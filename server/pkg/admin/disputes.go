@@ -0,0 +1,284 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+	"process-api/templates"
+	"slices"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+var disputeStatuses = []string{
+	constant.DISPUTE_SUBMITTED,
+	constant.DISPUTE_UNDER_REVIEW,
+	constant.DISPUTE_PROVISIONALLY_CREDITED,
+	constant.DISPUTE_RESOLVED_CUSTOMER,
+	constant.DISPUTE_RESOLVED_MERCHANT,
+	constant.DISPUTE_WITHDRAWN,
+}
+
+// openDisputeFilter is the queue's default status filter, covering every status a
+// dispute can still be acted on in
+const openDisputeFilter = "open"
+
+// ListDisputes is the dispute queue. It shows open disputes, nearest resolution deadline
+// first, unless another status filter is chosen.
+func ListDisputes(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	query := c.QueryParam("q")
+	status := openDisputeFilter
+	if _, ok := c.QueryParams()["status"]; ok {
+		status = c.QueryParam("status")
+	}
+	page := currentPage(c)
+
+	var statuses []string
+	switch status {
+	case "":
+	case openDisputeFilter:
+		for _, s := range disputeStatuses {
+			if dispute.IsOpen(s) {
+				statuses = append(statuses, s)
+			}
+		}
+	default:
+		statuses = []string{status}
+	}
+
+	disputes, totalCount, err := dao.TransactionDisputeDao{}.SearchDisputes(query, statuses, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search disputes", err)
+	}
+
+	filters := url.Values{}
+	if query != "" {
+		filters.Set("q", query)
+	}
+	filters.Set("status", status)
+
+	return render(c, http.StatusOK, templates.DisputeQueue(operator, templates.DisputeQueueView{
+		Query:    query,
+		Status:   status,
+		Statuses: disputeStatuses,
+		Disputes: disputes,
+		Now:      clock.Now(),
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+func DisputeCase(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+
+	transactionDispute, err := dao.TransactionDisputeDao{}.FindById(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load dispute", err)
+	}
+	if transactionDispute == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No dispute with id %s", id), nil)
+	}
+
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(transactionDispute.UserId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load customer", err)
+	}
+	if customer == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No customer with id %s", transactionDispute.UserId), nil)
+	}
+
+	events, err := dao.TransactionDisputeEventDao{}.FindByTransactionDisputeId(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load dispute history", err)
+	}
+
+	messages, err := dao.TransactionDisputeMessageDao{}.FindByTransactionDisputeId(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load dispute messages", err)
+	}
+
+	evidence, err := dao.TransactionDisputeEvidenceDao{}.FindByTransactionDisputeId(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load dispute evidence", err)
+	}
+	evidenceFiles := make([]templates.DisputeEvidenceFile, 0, len(evidence))
+	for _, file := range evidence {
+		url, err := utils.GeneratePreSignedUrl(file.S3Key)
+		if err != nil {
+			return renderError(c, operator, http.StatusInternalServerError, "Failed to link dispute evidence", err)
+		}
+		evidenceFiles = append(evidenceFiles, templates.DisputeEvidenceFile{Evidence: file, Url: url})
+	}
+
+	pendingApprovals, err := dao.AdminApprovalRequestDao{}.FindPendingForEntity(dao.ADMIN_AUDIT_ENTITY_DISPUTE, id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load approval requests", err)
+	}
+
+	// Offer only the actions the operator can take that are not already awaiting approval
+	var actions, approvalRequired []dispute.Action
+	for _, action := range dispute.Actions(transactionDispute.Status) {
+		auditAction := AUDIT_ACTION_DISPUTE_PREFIX + string(action)
+		if !canTakeDisputeAction(adminCtx, action) || slices.ContainsFunc(pendingApprovals, func(request dao.AdminApprovalRequestDao) bool {
+			return request.Action == auditAction
+		}) {
+			continue
+		}
+		actions = append(actions, action)
+		if needsApproval(auditAction) {
+			approvalRequired = append(approvalRequired, action)
+		}
+	}
+
+	return render(c, http.StatusOK, templates.DisputeCase(operator, templates.DisputeCaseView{
+		Dispute:          *transactionDispute,
+		Customer:         *customer,
+		Events:           events,
+		Messages:         messages,
+		Evidence:         evidenceFiles,
+		Actions:          actions,
+		ApprovalRequired: approvalRequired,
+		PendingApprovals: pendingApprovals,
+		Now:              clock.Now(),
+		CsrfToken:        csrfToken(c),
+	}))
+}
+
+// disputeActionPermissions are the permissions dispute actions need beyond
+// disputes:resolve, which every dispute action needs
+var disputeActionPermissions = map[dispute.Action]security.AdminPermission{
+	dispute.ActionProvisionalCredit: security.ADMIN_PERMISSION_MONEY_CREDIT,
+	dispute.ActionResolveCustomer:   security.ADMIN_PERMISSION_MONEY_CREDIT,
+}
+
+// canTakeDisputeAction reports whether the operator's permissions allow the action
+func canTakeDisputeAction(adminCtx *security.AdminUserContext, action dispute.Action) bool {
+	if !adminCtx.Can(security.ADMIN_PERMISSION_DISPUTES_RESOLVE) {
+		return false
+	}
+	permission, ok := disputeActionPermissions[action]
+	return !ok || adminCtx.Can(permission)
+}
+
+// TransitionDispute takes the dispute action named in the path, making any ledger entry
+// it needs and notifying the customer. Actions that credit the customer are instead held
+// for a second operator's approval.
+func (h *Handler) TransitionDispute(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	action := dispute.Action(c.Param("action"))
+	reason := strings.TrimSpace(c.FormValue("reason"))
+	if dispute.ReasonRequired(action) && reason == "" {
+		return renderError(c, operator, http.StatusBadRequest, "A reason is required for this dispute action", nil)
+	}
+	if !canTakeDisputeAction(adminCtx, action) {
+		return renderError(c, operator, http.StatusForbidden, "You do not have permission to take this dispute action", nil)
+	}
+
+	auditAction := AUDIT_ACTION_DISPUTE_PREFIX + string(action)
+	if needsApproval(auditAction) {
+		transactionDispute, err := dao.TransactionDisputeDao{}.FindById(id)
+		if err != nil {
+			return renderError(c, operator, http.StatusInternalServerError, "Failed to load dispute", err)
+		}
+		if transactionDispute == nil {
+			return renderError(c, operator, http.StatusNotFound, "Dispute not found", nil)
+		}
+		if !slices.Contains(dispute.Actions(transactionDispute.Status), action) {
+			return renderError(c, operator, http.StatusConflict, "This action is not available in the dispute's current status", nil)
+		}
+
+		err = requestApproval(c, adminCtx, dao.AdminApprovalRequestDao{
+			Action:     auditAction,
+			EntityType: dao.ADMIN_AUDIT_ENTITY_DISPUTE,
+			EntityId:   id,
+			UserId:     &transactionDispute.UserId,
+			Reason:     optionalString(reason),
+		})
+		if errors.Is(err, dao.ErrAdminApprovalAlreadyPending) {
+			return renderError(c, operator, http.StatusConflict, "This action is already awaiting approval", nil)
+		}
+		if err != nil {
+			return renderError(c, operator, http.StatusInternalServerError, "Failed to request approval", err)
+		}
+		return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/disputes/%s", id))
+	}
+
+	if err := h.transitionDispute(c, adminCtx, id, action, reason); err != nil {
+		switch {
+		case errors.Is(err, dispute.ErrDisputeNotFound), errors.Is(err, dispute.ErrUnknownAction):
+			return renderError(c, operator, http.StatusNotFound, "Dispute not found", nil)
+		case errors.Is(err, dispute.ErrInvalidTransition):
+			return renderError(c, operator, http.StatusConflict, "This action is not available in the dispute's current status", nil)
+		default:
+			return renderError(c, operator, http.StatusInternalServerError, "The action could not be completed and the dispute is unchanged. See the dispute's history for details.", err)
+		}
+	}
+
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/disputes/%s", id))
+}
+
+// transitionDispute takes the action on the dispute and audits the change
+func (h *Handler) transitionDispute(c echo.Context, adminCtx *security.AdminUserContext, id string, action dispute.Action, reason string) error {
+	before, err := dao.TransactionDisputeDao{}.FindById(id)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if before == nil {
+		return errtrace.Wrap(dispute.ErrDisputeNotFound)
+	}
+
+	disputeService := dispute.Service{RiverClient: h.RiverClient}
+	after, err := disputeService.Transition(c.Request().Context(), logging.GetEchoContextLogger(c), id, action, adminCtx.Email, reason)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_DISPUTE_PREFIX + string(action),
+		EntityType: dao.ADMIN_AUDIT_ENTITY_DISPUTE,
+		EntityId:   id,
+		UserId:     after.UserId,
+		Before:     disputeAuditSnapshot(*before),
+		After:      disputeAuditSnapshot(*after),
+	})
+	return nil
+}
+
+// executeDisputeApproval takes an approved dispute action, as the approving operator
+func (h *Handler) executeDisputeApproval(c echo.Context, adminCtx *security.AdminUserContext, request dao.AdminApprovalRequestDao) error {
+	action := dispute.Action(strings.TrimPrefix(request.Action, AUDIT_ACTION_DISPUTE_PREFIX))
+	err := h.transitionDispute(c, adminCtx, request.EntityId, action, valueOrEmpty(request.Reason))
+	if errors.Is(err, dispute.ErrDisputeNotFound) || errors.Is(err, dispute.ErrInvalidTransition) {
+		return errtrace.Wrap(fmt.Errorf("%w: %w", errApprovalStale, err))
+	}
+	return errtrace.Wrap(err)
+}
@@ -0,0 +1,230 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/audit"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/templates"
+	"strconv"
+	"strings"
+	"time"
+
+	api "process-api/pkg/audit/api/v1"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// gatewayAuditTimeLayout is the layout of the from and to filters, which are UTC times
+// from datetime-local inputs
+const gatewayAuditTimeLayout = "2006-01-02T15:04"
+
+const (
+	gatewayAuditTimeout         = 15 * time.Second
+	gatewayAuditExportPageSize  = 500
+	gatewayAuditExportMaxAudits = 50000
+)
+
+var errGatewayAuditFilterMissing = errors.New("gateway audit filter missing")
+
+// gatewayAuditRequestFromQuery reads the gateway audit filters, returning the gateway
+// request along with the query string that reproduces them. A customer is looked up for
+// their ledger customer number, which their audits are recorded under.
+func gatewayAuditRequestFromQuery(c echo.Context) (audit.AuditRequest, url.Values, error) {
+	var req audit.AuditRequest
+	filters := url.Values{}
+	for _, name := range []string{"customer", "requestId", "from", "to"} {
+		if value := strings.TrimSpace(c.QueryParam(name)); value != "" {
+			filters.Set(name, value)
+		}
+	}
+
+	if from := filters.Get("from"); from != "" {
+		fromTime, err := time.Parse(gatewayAuditTimeLayout, from)
+		if err != nil {
+			return req, filters, errtrace.Wrap(fmt.Errorf("invalid from time %q", from))
+		}
+		req.From = fromTime
+	}
+	if to := filters.Get("to"); to != "" {
+		toTime, err := time.Parse(gatewayAuditTimeLayout, to)
+		if err != nil {
+			return req, filters, errtrace.Wrap(fmt.Errorf("invalid to time %q", to))
+		}
+		req.To = toTime
+	}
+
+	if requestId := filters.Get("requestId"); requestId != "" {
+		req.AuditRequestId = &requestId
+		return req, filters, nil
+	}
+	userId := filters.Get("customer")
+	if userId == "" {
+		return req, filters, errGatewayAuditFilterMissing
+	}
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(userId)
+	if err != nil {
+		return req, filters, errtrace.Wrap(err)
+	}
+	if customer == nil {
+		return req, filters, errtrace.Wrap(fmt.Errorf("no customer with id %s", userId))
+	}
+	if customer.LedgerCustomerNumber == "" {
+		return req, filters, errtrace.Wrap(fmt.Errorf("customer %s has no ledger customer number", userId))
+	}
+	req.CustomerId = &customer.LedgerCustomerNumber
+	return req, filters, nil
+}
+
+// GatewayAudits lists the HTTP calls the NetXD gateway recorded for a customer or
+// request id
+func (h *Handler) GatewayAudits(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+	if h.AuditClient == nil {
+		return renderError(c, operator, http.StatusServiceUnavailable, "Gateway audits are not available", nil)
+	}
+
+	req, filters, err := gatewayAuditRequestFromQuery(c)
+	view := templates.GatewayAuditsView{
+		UserId:    filters.Get("customer"),
+		RequestId: filters.Get("requestId"),
+		From:      filters.Get("from"),
+		To:        filters.Get("to"),
+	}
+	if errors.Is(err, errGatewayAuditFilterMissing) {
+		return render(c, http.StatusOK, templates.GatewayAudits(operator, view))
+	}
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, err.Error(), nil)
+	}
+
+	page := currentPage(c)
+	req.PageNumber, req.PageSize = int64(page), pageSize
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), gatewayAuditTimeout)
+	defer cancel()
+	resp, err := h.AuditClient.ListAudits(ctx, req)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadGateway, "Failed to load gateway audits", err)
+	}
+
+	view.Searched = true
+	view.Audits = resp.Records
+	view.FilterQuery = filters.Encode()
+	view.Pagination = templates.Pagination{
+		Page:        page,
+		PageSize:    pageSize,
+		TotalCount:  resp.TotalCount,
+		FilterQuery: filters.Encode(),
+	}
+	return render(c, http.StatusOK, templates.GatewayAudits(operator, view))
+}
+
+var gatewayAuditCSVHeader = []string{
+	"request_id", "request_timestamp", "response_timestamp", "elapsed_ms", "method", "url",
+	"response_status", "response_status_text", "application_name", "rpc_service", "rpc_method",
+	"originator_ip", "request_body", "response_body",
+}
+
+// ExportGatewayAudits downloads the gateway audits matching the filters as CSV
+func (h *Handler) ExportGatewayAudits(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+	if h.AuditClient == nil {
+		return renderError(c, operator, http.StatusServiceUnavailable, "Gateway audits are not available", nil)
+	}
+
+	req, _, err := gatewayAuditRequestFromQuery(c)
+	if errors.Is(err, errGatewayAuditFilterMissing) {
+		return renderError(c, operator, http.StatusBadRequest, "Enter a customer or request id to export", nil)
+	}
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, err.Error(), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 4*gatewayAuditTimeout)
+	defer cancel()
+
+	// Read the first page before writing anything, so a gateway failure can still be
+	// shown as an error page
+	req.PageNumber, req.PageSize = 1, gatewayAuditExportPageSize
+	resp, err := h.AuditClient.ListAudits(ctx, req)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadGateway, "Failed to load gateway audits", err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="gateway-audits-%s.csv"`, clock.Now().UTC().Format("20060102-150405")))
+	c.Response().WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write(gatewayAuditCSVHeader); err != nil {
+		return errtrace.Wrap(err)
+	}
+	written := 0
+	for {
+		for _, record := range resp.Records {
+			if err := writer.Write(gatewayAuditCSVRecord(record)); err != nil {
+				return errtrace.Wrap(err)
+			}
+		}
+		written += len(resp.Records)
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		if len(resp.Records) == 0 || int64(written) >= resp.TotalCount {
+			return nil
+		}
+		if written >= gatewayAuditExportMaxAudits {
+			logging.GetEchoContextLogger(c).Warn("Gateway audit export truncated", "written", written, "totalCount", resp.TotalCount)
+			return nil
+		}
+
+		req.PageNumber++
+		if resp, err = h.AuditClient.ListAudits(ctx, req); err != nil {
+			// The response has started, so the export can only be cut short
+			logging.GetEchoContextLogger(c).Error("Failed to export gateway audits", "page", req.PageNumber, "error", err.Error())
+			return nil
+		}
+	}
+}
+
+func gatewayAuditCSVRecord(record *api.HttpAudit) []string {
+	return []string{
+		record.GetRequestId(),
+		formatAuditTimestamp(record.GetRequestTimestamp().AsTime(), record.GetRequestTimestamp() != nil),
+		formatAuditTimestamp(record.GetResponseTimestamp().AsTime(), record.GetResponseTimestamp() != nil),
+		strconv.FormatInt(record.GetElapsedDuration().AsDuration().Milliseconds(), 10),
+		record.GetMethod(),
+		record.GetUrl(),
+		strconv.FormatInt(record.GetResponseStatus(), 10),
+		record.GetResponseStatusText(),
+		record.GetApplicationName(),
+		record.GetRpcService(),
+		record.GetRpcMethod(),
+		record.GetOriginatorIp(),
+		string(record.GetRequestBody()),
+		string(record.GetResponseBody()),
+	}
+}
+
+func formatAuditTimestamp(t time.Time, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
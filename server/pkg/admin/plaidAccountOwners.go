@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ApprovePlaidAccountOwner lets money be pulled from a linked account whose owner didn't
+// clearly match the customer, such as a joint account under a maiden name. A rejected
+// account can also be approved, once the customer has shown it's theirs.
+func ApprovePlaidAccountOwner(c echo.Context) error {
+	return reviewPlaidAccountOwner(c, dao.IDENTITY_MATCH_APPROVED, AUDIT_ACTION_PLAID_ACCOUNT_OWNER_APPROVED)
+}
+
+// RejectPlaidAccountOwner blocks pulls from a linked account sent for review or never matched
+func RejectPlaidAccountOwner(c echo.Context) error {
+	return reviewPlaidAccountOwner(c, dao.IDENTITY_MATCH_REJECTED, AUDIT_ACTION_PLAID_ACCOUNT_OWNER_REJECTED)
+}
+
+func reviewPlaidAccountOwner(c echo.Context, status, action string) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+	account, err := dao.PlaidAccountDao{}.GetAccountForUserByID(userId, c.Param("id"))
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load Plaid account", err)
+	}
+	if account == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("Customer %s has no Plaid account %s", userId, c.Param("id")), nil)
+	}
+	// Accounts whose owner couldn't be matched, such as when Plaid Identity failed, are
+	// blocked until reviewed
+	before := "unmatched"
+	if account.IdentityMatchStatus != nil {
+		before = *account.IdentityMatchStatus
+	}
+	switch before {
+	case dao.IDENTITY_MATCH_APPROVED, dao.IDENTITY_MATCH_LEGACY:
+		return renderError(c, operator, http.StatusConflict, "The account's owner doesn't need review", nil)
+	case status:
+		return renderError(c, operator, http.StatusConflict, fmt.Sprintf("The account's owner is already %s", status), nil)
+	}
+
+	if err := (dao.PlaidAccountDao{}).ReviewIdentityMatch(account.ID, status, adminCtx.Email, clock.Now()); err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to review Plaid account owner", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator reviewed Plaid account owner", "operator", adminCtx.Email, "userId", userId, "plaidAccountId", account.ID, "status", status)
+
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     action,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_PLAID_ACCOUNT,
+		EntityId:   account.ID,
+		UserId:     userId,
+		Before:     map[string]any{"identity_match_status": before},
+		After:      map[string]any{"identity_match_status": status},
+	})
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/customers/%s", userId))
+}
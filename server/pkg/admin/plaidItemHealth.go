@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"net/http"
+	"process-api/pkg/db/dao"
+	"process-api/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// plaidItemHealthDays is how many days of item health snapshots are shown
+const plaidItemHealthDays = 30
+
+// PlaidItemHealth shows how many Plaid items had each connection problem on each recent
+// day, as counted by the item health monitor
+func PlaidItemHealth(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	snapshots, err := dao.PlaidItemHealthSnapshotDao{}.FindRecent(plaidItemHealthDays)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load Plaid item health", err)
+	}
+
+	return render(c, http.StatusOK, templates.PlaidItemHealth(operator, snapshots))
+}
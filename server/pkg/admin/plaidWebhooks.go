@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/plaid"
+	"process-api/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListPlaidWebhookEvents shows the webhooks received from Plaid newest first, filtered by
+// status, webhook type and item
+func ListPlaidWebhookEvents(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	filter := dao.PlaidWebhookEventFilter{
+		Status:      c.QueryParam("status"),
+		WebhookType: c.QueryParam("type"),
+		PlaidItemId: c.QueryParam("itemId"),
+	}
+	page := currentPage(c)
+
+	events, totalCount, err := dao.PlaidWebhookEventDao{}.Search(filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search Plaid webhooks", err)
+	}
+
+	filters := url.Values{}
+	for name, value := range map[string]string{"status": filter.Status, "type": filter.WebhookType, "itemId": filter.PlaidItemId} {
+		if value != "" {
+			filters.Set(name, value)
+		}
+	}
+
+	return render(c, http.StatusOK, templates.PlaidWebhookEvents(operator, templates.PlaidWebhookEventsView{
+		Filter:       filter,
+		Statuses:     dao.PlaidWebhookEventStatuses,
+		WebhookTypes: plaid.PlaidWebhookTypes,
+		Events:       events,
+		CsrfToken:    csrfToken(c),
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+// RetryPlaidWebhookEvent processes a failed or unknown item webhook again, such as once
+// whatever made it fail is fixed
+func (h *Handler) RetryPlaidWebhookEvent(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	event, err := dao.PlaidWebhookEventDao{}.FindById(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load Plaid webhook", err)
+	}
+	if event == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No Plaid webhook with id %s", id), nil)
+	}
+	before := map[string]any{"status": event.Status, "attempts": event.Attempts}
+
+	logger := logging.GetEchoContextLogger(c)
+	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB, TransactionsEnabled: config.Config.Plaid.TransactionsEnabled}
+	err = ps.RetryWebhookEvent(c.Request().Context(), h.RiverClient, event, clock.Now())
+	if errors.Is(err, plaid.ErrWebhookEventNotRetryable) {
+		return renderError(c, operator, http.StatusConflict, "Only failed and unknown item webhooks can be retried", nil)
+	}
+	// Any other failure is recorded on the event, which the operator is shown below
+	logger.Info("Operator retried Plaid webhook", "operator", adminCtx.Email, "eventId", id, "status", event.Status)
+
+	var userId string
+	if event.PlaidItemId != nil {
+		item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(*event.PlaidItemId)
+		if err == nil && item != nil {
+			userId = item.UserId
+		}
+	}
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_PLAID_WEBHOOK_EVENT_RETRIED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_PLAID_WEBHOOK_EVENT,
+		EntityId:   id,
+		UserId:     userId,
+		Before:     before,
+		After:      map[string]any{"status": event.Status, "attempts": event.Attempts},
+	})
+	return c.Redirect(http.StatusSeeOther, "/admin/plaid-webhooks")
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/timeline"
+	"process-api/templates"
+	"slices"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// timelineTimeLayout is the layout of the from and to filters, which are UTC times from
+// datetime-local inputs
+const timelineTimeLayout = "2006-01-02T15:04"
+
+// timelineFilterFromQuery reads the timeline filters, returning them along with the query
+// string that reproduces them. The before cursor, set when paging, takes precedence over to.
+func timelineFilterFromQuery(c echo.Context) (timeline.Filter, url.Values, error) {
+	filter := timeline.Filter{Limit: pageSize}
+	filters := url.Values{}
+
+	categories := timeline.Categories()
+	for _, value := range c.QueryParams()["category"] {
+		category := timeline.Category(value)
+		if !slices.Contains(categories, category) {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("unknown category %q", value))
+		}
+		filter.Categories = append(filter.Categories, category)
+		filters.Add("category", value)
+	}
+
+	if from := strings.TrimSpace(c.QueryParam("from")); from != "" {
+		fromTime, err := time.Parse(timelineTimeLayout, from)
+		if err != nil {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("invalid from time %q", from))
+		}
+		filter.From = fromTime
+		filters.Set("from", from)
+	}
+	if to := strings.TrimSpace(c.QueryParam("to")); to != "" {
+		toTime, err := time.Parse(timelineTimeLayout, to)
+		if err != nil {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("invalid to time %q", to))
+		}
+		filter.Before = toTime
+		filters.Set("to", to)
+	}
+	if before := c.QueryParam("before"); before != "" {
+		beforeTime, err := time.Parse(time.RFC3339Nano, before)
+		if err != nil {
+			return filter, filters, errtrace.Wrap(fmt.Errorf("invalid before time %q", before))
+		}
+		filter.Before = beforeTime
+	}
+	return filter, filters, nil
+}
+
+// CustomerTimeline merges everything that happened on a customer's account, from logins
+// and one-time codes to disputes and transactions, into one feed, newest first
+func CustomerTimeline(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	userId := c.Param("userId")
+	customer, err := dao.MasterUserRecordDao{}.FindOneByUserId(userId)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load customer", err)
+	}
+	if customer == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No customer with id %s", userId), nil)
+	}
+
+	filter, filters, err := timelineFilterFromQuery(c)
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, err.Error(), nil)
+	}
+
+	page, err := timeline.ForUser(*customer, filter)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load the customer's activity", err)
+	}
+
+	view := templates.CustomerTimelineView{
+		Customer:    *customer,
+		Categories:  timeline.Categories(),
+		Selected:    filter.Categories,
+		From:        filters.Get("from"),
+		To:          filters.Get("to"),
+		Events:      page.Events,
+		FilterQuery: filters.Encode(),
+	}
+	if page.HasMore {
+		view.NextBefore = page.Events[len(page.Events)-1].At.UTC().Format(time.RFC3339Nano)
+	}
+	return render(c, http.StatusOK, templates.CustomerTimeline(operator, view))
+}
@@ -0,0 +1,234 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/webhook"
+	"process-api/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListWebhookSubscriptions shows the subscribers events are sent to, with a form to add one
+func ListWebhookSubscriptions(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+	return renderWebhookSubscriptions(c, operator, http.StatusOK, nil, "")
+}
+
+func renderWebhookSubscriptions(c echo.Context, operator templates.Operator, statusCode int, created *dao.WebhookSubscriptionDao, secret string) error {
+	subscriptions, err := dao.WebhookSubscriptionDao{}.FindAll()
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook subscriptions", err)
+	}
+	return render(c, statusCode, templates.WebhookSubscriptions(operator, templates.WebhookSubscriptionsView{
+		Subscriptions: subscriptions,
+		EventTypes:    dao.WebhookEventTypes,
+		Created:       created,
+		Secret:        secret,
+		CsrfToken:     csrfToken(c),
+	}))
+}
+
+// CreateWebhookSubscription registers a subscriber and shows its signing secret, which
+// is only shown this once
+func CreateWebhookSubscription(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	form, err := c.FormParams()
+	if err != nil {
+		return renderError(c, operator, http.StatusBadRequest, "Invalid form", nil)
+	}
+	subscription, secret, err := webhook.CreateSubscription(webhook.SubscriptionRequest{
+		Name:       form.Get("name"),
+		Url:        form.Get("url"),
+		EventTypes: form["event_type"],
+		CreatedBy:  adminCtx.Email,
+	}, clock.Now())
+	switch {
+	case errors.Is(err, webhook.ErrNameRequired):
+		return renderError(c, operator, http.StatusBadRequest, "A name is required", nil)
+	case errors.Is(err, webhook.ErrInvalidUrl):
+		return renderError(c, operator, http.StatusBadRequest, "The URL must be an https URL", nil)
+	case errors.Is(err, webhook.ErrEventTypesRequired), errors.Is(err, webhook.ErrUnknownEventType):
+		return renderError(c, operator, http.StatusBadRequest, "Choose at least one event type", nil)
+	case err != nil:
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to create webhook subscription", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator created webhook subscription", "operator", adminCtx.Email, "subscriptionId", subscription.Id)
+
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_CREATED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_WEBHOOK_SUBSCRIPTION,
+		EntityId:   subscription.Id,
+		Before:     webhook.AuditSnapshot(nil),
+		After:      webhook.AuditSnapshot(subscription),
+	})
+	return renderWebhookSubscriptions(c, operator, http.StatusCreated, subscription, secret)
+}
+
+// DisableWebhookSubscription stops sending events to the subscriber. Deliveries still
+// pending for it are dead instead of attempted.
+func DisableWebhookSubscription(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	before, after, err := webhook.DisableSubscription(id, adminCtx.Email, clock.Now())
+	switch {
+	case errors.Is(err, webhook.ErrSubscriptionNotFound):
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No webhook subscription with id %s", id), nil)
+	case err != nil:
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to disable webhook subscription", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator disabled webhook subscription", "operator", adminCtx.Email, "subscriptionId", id)
+
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_WEBHOOK_SUBSCRIPTION_DISABLED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_WEBHOOK_SUBSCRIPTION,
+		EntityId:   id,
+		Before:     webhook.AuditSnapshot(before),
+		After:      webhook.AuditSnapshot(after),
+	})
+	return c.Redirect(http.StatusSeeOther, "/admin/webhooks")
+}
+
+// ListWebhookDeliveries shows deliveries newest first, filtered by subscription, status,
+// event type and customer
+func ListWebhookDeliveries(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	filter := dao.WebhookDeliveryFilter{
+		SubscriptionId: c.QueryParam("subscription"),
+		Status:         c.QueryParam("status"),
+		EventType:      c.QueryParam("type"),
+		UserId:         c.QueryParam("userId"),
+	}
+	page := currentPage(c)
+
+	deliveries, totalCount, err := dao.WebhookDeliveryDao{}.Search(filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to search webhook deliveries", err)
+	}
+	subscriptions, err := dao.WebhookSubscriptionDao{}.FindAll()
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook subscriptions", err)
+	}
+
+	filters := url.Values{}
+	for name, value := range map[string]string{"subscription": filter.SubscriptionId, "status": filter.Status, "type": filter.EventType, "userId": filter.UserId} {
+		if value != "" {
+			filters.Set(name, value)
+		}
+	}
+
+	return render(c, http.StatusOK, templates.WebhookDeliveries(operator, templates.WebhookDeliveriesView{
+		Filter:        filter,
+		Subscriptions: subscriptions,
+		Statuses:      dao.WebhookDeliveryStatuses,
+		EventTypes:    dao.WebhookEventTypes,
+		Deliveries:    deliveries,
+		Pagination: templates.Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalCount:  totalCount,
+			FilterQuery: filters.Encode(),
+		},
+	}))
+}
+
+// WebhookDeliveryDetail shows the event a delivery sends and each attempt to send it
+func WebhookDeliveryDetail(c echo.Context) error {
+	_, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook delivery", err)
+	}
+	if delivery == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No webhook delivery with id %s", id), nil)
+	}
+	event, err := dao.WebhookEventDao{}.FindById(delivery.EventId)
+	if err != nil || event == nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook event", err)
+	}
+	subscription, err := dao.WebhookSubscriptionDao{}.FindById(delivery.SubscriptionId)
+	if err != nil || subscription == nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook subscription", err)
+	}
+	attempts, err := dao.WebhookDeliveryDao{}.FindAttempts(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook delivery attempts", err)
+	}
+
+	return render(c, http.StatusOK, templates.WebhookDeliveryDetail(operator, templates.WebhookDeliveryDetailView{
+		Delivery:     *delivery,
+		Event:        *event,
+		Subscription: *subscription,
+		Attempts:     attempts,
+		CsrfToken:    csrfToken(c),
+	}))
+}
+
+// RedeliverWebhook sends a dead delivery again, such as once its subscriber is fixed
+func (h *Handler) RedeliverWebhook(c echo.Context) error {
+	adminCtx, operator, ok := operatorFromContext(c)
+	if !ok {
+		return c.Redirect(http.StatusFound, "/admin/login")
+	}
+
+	id := c.Param("id")
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(id)
+	if err != nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook delivery", err)
+	}
+	if delivery == nil {
+		return renderError(c, operator, http.StatusNotFound, fmt.Sprintf("No webhook delivery with id %s", id), nil)
+	}
+	event, err := dao.WebhookEventDao{}.FindById(delivery.EventId)
+	if err != nil || event == nil {
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to load webhook event", err)
+	}
+
+	err = webhook.Redeliver(c.Request().Context(), h.RiverClient, id, clock.Now())
+	switch {
+	case errors.Is(err, webhook.ErrDeliveryNotRedeliverable):
+		return renderError(c, operator, http.StatusConflict, "Only dead deliveries can be redelivered", nil)
+	case err != nil:
+		return renderError(c, operator, http.StatusInternalServerError, "Failed to redeliver webhook", err)
+	}
+	logging.GetEchoContextLogger(c).Info("Operator redelivered webhook", "operator", adminCtx.Email, "deliveryId", id)
+
+	var userId string
+	if event.UserId != nil {
+		userId = *event.UserId
+	}
+	recordAudit(c, adminCtx, auditEntry{
+		Action:     AUDIT_ACTION_WEBHOOK_DELIVERY_REDELIVERED,
+		EntityType: dao.ADMIN_AUDIT_ENTITY_WEBHOOK_DELIVERY,
+		EntityId:   id,
+		UserId:     userId,
+		Before:     map[string]any{"status": delivery.Status},
+		After:      map[string]any{"status": dao.WEBHOOK_DELIVERY_PENDING},
+	})
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/webhooks/deliveries/%s", id))
+}
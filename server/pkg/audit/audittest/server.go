@@ -0,0 +1,153 @@
+// Package audittest provides an in-process fake of the gateway's audit service, for
+// testing code that reads gateway audits without a running gateway.
+package audittest
+
+import (
+	"context"
+	"net"
+	"process-api/pkg/audit"
+	"sync"
+
+	api "process-api/pkg/audit/api/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Audit is an HTTP audit held by the fake, along with the id the gateway recorded it
+// under, such as a ledger customer number
+type Audit struct {
+	Id    string
+	Audit *api.HttpAudit
+}
+
+// FakeGwAuditServiceServer answers GetAudits from the audits it holds. It filters by
+// time range, id and request id, and pages the matches in the order they were added.
+type FakeGwAuditServiceServer struct {
+	api.UnimplementedGwAuditServiceServer
+
+	mu       sync.Mutex
+	audits   []Audit
+	requests []*api.GetAuditsRequest
+	err      error
+}
+
+// Add adds audits for GetAudits to return
+func (s *FakeGwAuditServiceServer) Add(audits ...Audit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audits = append(s.audits, audits...)
+}
+
+// FailWith makes GetAudits return err, or succeed again when err is nil
+func (s *FakeGwAuditServiceServer) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Requests returns the requests GetAudits has received
+func (s *FakeGwAuditServiceServer) Requests() []*api.GetAuditsRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*api.GetAuditsRequest(nil), s.requests...)
+}
+
+func (s *FakeGwAuditServiceServer) GetAudits(_ context.Context, req *api.GetAuditsRequest) (*api.GetAuditsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var matches []*api.HttpAudit
+	for _, audit := range s.audits {
+		if matchesRequest(audit, req) {
+			matches = append(matches, audit.Audit)
+		}
+	}
+
+	resp := &api.GetAuditsResponse{TotalCount: int64(len(matches))}
+	if req.PageSize <= 0 {
+		resp.Records = matches
+		return resp, nil
+	}
+	pageNumber := max(req.PageNumber, 1)
+	start := min((pageNumber-1)*req.PageSize, int64(len(matches)))
+	end := min(start+req.PageSize, int64(len(matches)))
+	resp.Records = matches[start:end]
+	return resp, nil
+}
+
+func matchesRequest(audit Audit, req *api.GetAuditsRequest) bool {
+	requestedAt := audit.Audit.GetRequestTimestamp().AsTime()
+	if req.From != nil && requestedAt.Before(req.From.AsTime()) {
+		return false
+	}
+	if req.To != nil && !requestedAt.Before(req.To.AsTime()) {
+		return false
+	}
+	switch filter := req.Filter.(type) {
+	case *api.GetAuditsRequest_Id:
+		return audit.Id == filter.Id
+	case *api.GetAuditsRequest_RequestId:
+		return audit.Audit.GetRequestId() == filter.RequestId
+	case *api.GetAuditsRequest_Query:
+		return (filter.Query.ApplicationName == "" || audit.Audit.GetApplicationName() == filter.Query.ApplicationName) &&
+			(filter.Query.RpcService == "" || audit.Audit.GetRpcService() == filter.Query.RpcService) &&
+			(filter.Query.RpcMethod == "" || audit.Audit.GetRpcMethod() == filter.Query.RpcMethod)
+	default:
+		return true
+	}
+}
+
+// Server serves a FakeGwAuditServiceServer over an in-memory connection
+type Server struct {
+	*FakeGwAuditServiceServer
+
+	grpcServer *grpc.Server
+	conn       *grpc.ClientConn
+	client     *audit.AuditServiceClient
+}
+
+// NewServer starts a fake gateway audit service holding audits. Close it when done.
+func NewServer(audits ...Audit) (*Server, error) {
+	listener := bufconn.Listen(1 << 20)
+	fake := &FakeGwAuditServiceServer{audits: audits}
+
+	grpcServer := grpc.NewServer()
+	api.RegisterGwAuditServiceServer(grpcServer, fake)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, err
+	}
+
+	return &Server{
+		FakeGwAuditServiceServer: fake,
+		grpcServer:               grpcServer,
+		conn:                     conn,
+		client:                   audit.NewFromConn(conn),
+	}, nil
+}
+
+// Client returns a client connected to the fake
+func (s *Server) Client() *audit.AuditServiceClient {
+	return s.client
+}
+
+func (s *Server) Close() {
+	_ = s.conn.Close()
+	s.grpcServer.Stop()
+}
@@ -25,10 +25,12 @@ type AuditServiceClient struct {
 type AuditRequest struct {
 	Id             string
 	AuditRequestId *string
-	PageNumber     int64 `json:"pageNumber"`
-	PageSize       int64 `json:"pageSize"`
-	From           time.Time
-	To             time.Time
+	// Audits of calls made for a customer are recorded under their ledger customer number
+	CustomerId *string
+	PageNumber int64 `json:"pageNumber"`
+	PageSize   int64 `json:"pageSize"`
+	From       time.Time
+	To         time.Time
 }
 
 func New(ctx context.Context) (*AuditServiceClient, error) {
@@ -48,10 +50,16 @@ func New(ctx context.Context) (*AuditServiceClient, error) {
 		logging.Logger.With("error", err).Error("grpc connect failed")
 		return nil, err
 	}
-	return &AuditServiceClient{client: api.NewGwAuditServiceClient(conn)}, nil
+	return NewFromConn(conn), nil
 }
 
-func (c *AuditServiceClient) GetAudits(ctx context.Context, req AuditRequest) ([]byte, error) {
+// NewFromConn returns a client of the gateway reached over conn
+func NewFromConn(conn grpc.ClientConnInterface) *AuditServiceClient {
+	return &AuditServiceClient{client: api.NewGwAuditServiceClient(conn)}
+}
+
+// ListAudits returns a page of the gateway's HTTP audits matching the request
+func (c *AuditServiceClient) ListAudits(ctx context.Context, req AuditRequest) (*api.GetAuditsResponse, error) {
 	var gwr api.GetAuditsRequest
 	if !req.From.IsZero() {
 		gwr.From = timestamppb.New(req.From)
@@ -61,6 +69,8 @@ func (c *AuditServiceClient) GetAudits(ctx context.Context, req AuditRequest) ([
 	}
 	if req.AuditRequestId != nil {
 		gwr.Filter = &api.GetAuditsRequest_RequestId{RequestId: *req.AuditRequestId}
+	} else if req.CustomerId != nil {
+		gwr.Filter = &api.GetAuditsRequest_Id{Id: *req.CustomerId}
 	}
 	gwr.PageNumber, gwr.PageSize = req.PageNumber, req.PageSize
 
@@ -68,6 +78,14 @@ func (c *AuditServiceClient) GetAudits(ctx context.Context, req AuditRequest) ([
 	if err != nil {
 		return nil, errtrace.Wrap(err)
 	}
+	return resp, nil
+}
+
+func (c *AuditServiceClient) GetAudits(ctx context.Context, req AuditRequest) ([]byte, error) {
+	resp, err := c.ListAudits(ctx, req)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
 
 	bs, err := protojson.Marshal(resp)
 	if err == nil && req.Id != "" {
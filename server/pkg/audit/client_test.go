@@ -0,0 +1,110 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"process-api/pkg/audit"
+	"process-api/pkg/audit/audittest"
+	"testing"
+	"time"
+
+	api "process-api/pkg/audit/api/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func httpAudit(requestId string, requestedAt time.Time) *api.HttpAudit {
+	return &api.HttpAudit{
+		RequestId:        requestId,
+		Method:           "POST",
+		Url:              "https://ledger.example.com/jsonrpc",
+		ResponseStatus:   200,
+		RequestTimestamp: timestamppb.New(requestedAt),
+	}
+}
+
+func TestListAudits_FiltersByCustomerAndTimeRange(t *testing.T) {
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	server, err := audittest.NewServer(
+		audittest.Audit{Id: "100000000034052", Audit: httpAudit("req-1", start)},
+		audittest.Audit{Id: "100000000034052", Audit: httpAudit("req-2", start.Add(time.Hour))},
+		audittest.Audit{Id: "100000000034052", Audit: httpAudit("req-3", start.Add(2*time.Hour))},
+		audittest.Audit{Id: "100000000099999", Audit: httpAudit("req-4", start.Add(time.Hour))},
+	)
+	require.NoError(t, err)
+	defer server.Close()
+
+	customerId := "100000000034052"
+	resp, err := server.Client().ListAudits(context.Background(), audit.AuditRequest{
+		CustomerId: &customerId,
+		From:       start,
+		To:         start.Add(2 * time.Hour),
+		PageNumber: 1,
+		PageSize:   10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.TotalCount)
+	require.Len(t, resp.Records, 2)
+	assert.Equal(t, "req-1", resp.Records[0].RequestId)
+	assert.Equal(t, "req-2", resp.Records[1].RequestId)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, customerId, requests[0].GetId())
+}
+
+func TestListAudits_RequestIdTakesPrecedenceAndPages(t *testing.T) {
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	server, err := audittest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	for i := range 5 {
+		server.Add(audittest.Audit{Id: "100000000034052", Audit: httpAudit("req-1", start.Add(time.Duration(i)*time.Minute))})
+	}
+
+	customerId, requestId := "100000000099999", "req-1"
+	resp, err := server.Client().ListAudits(context.Background(), audit.AuditRequest{
+		CustomerId:     &customerId,
+		AuditRequestId: &requestId,
+		PageNumber:     3,
+		PageSize:       2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.TotalCount)
+	require.Len(t, resp.Records, 1, "The last page should hold the remaining audit")
+	assert.Equal(t, start.Add(4*time.Minute), resp.Records[0].RequestTimestamp.AsTime())
+}
+
+func TestGetAudits_SetsIdOnJSON(t *testing.T) {
+	server, err := audittest.NewServer(audittest.Audit{Id: "100000000034052", Audit: httpAudit("req-1", time.Now())})
+	require.NoError(t, err)
+	defer server.Close()
+
+	bs, err := server.Client().GetAudits(context.Background(), audit.AuditRequest{Id: "call-1"})
+	require.NoError(t, err)
+	var body struct {
+		Id      string `json:"id"`
+		Records []struct {
+			RequestId string `json:"requestId"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(bs, &body))
+	assert.Equal(t, "call-1", body.Id)
+	require.Len(t, body.Records, 1)
+	assert.Equal(t, "req-1", body.Records[0].RequestId)
+}
+
+func TestListAudits_ReturnsGatewayError(t *testing.T) {
+	server, err := audittest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	server.FailWith(status.Error(codes.Unavailable, "gateway down"))
+
+	_, err = server.Client().ListAudits(context.Background(), audit.AuditRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
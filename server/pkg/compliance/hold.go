@@ -0,0 +1,221 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+var (
+	ErrNoHold            = errors.New("customer has no compliance hold")
+	ErrHoldAlreadyPlaced = dao.ErrComplianceHoldAlreadyPlaced
+	ErrReasonRequired    = errors.New("a reason is required")
+	ErrExpiryNotInFuture = errors.New("the hold must expire in the future")
+	errNoLedgerAccount   = errors.New("customer has no ledger account")
+)
+
+// releaseReasonExpired is the release reason of a hold released because it expired
+const releaseReasonExpired = "expired"
+
+// PlaceRequest is a hold to place on a customer
+type PlaceRequest struct {
+	Reason    string
+	ExpiresAt *time.Time
+	// MirrorToLedger also suspends the customer's ledger account while the hold is active
+	MirrorToLedger bool
+	// Where the hold is placed from, one of the constant.COMPLIANCE_HOLD_VIA values
+	Via      string
+	PlacedBy string
+}
+
+// AuditSnapshot captures the hold for the admin audit log
+func AuditSnapshot(hold *dao.ComplianceHoldDao) map[string]any {
+	if hold == nil {
+		return map[string]any{}
+	}
+	return map[string]any{
+		"reason":             hold.Reason,
+		"placed_via":         hold.PlacedVia,
+		"placed_by":          hold.PlacedBy,
+		"expires_at":         hold.ExpiresAt,
+		"ledger_mirrored_at": hold.LedgerMirroredAt,
+		"ledger_error":       hold.LedgerError,
+		"released_at":        hold.ReleasedAt,
+		"released_by":        hold.ReleasedBy,
+		"release_reason":     hold.ReleaseReason,
+	}
+}
+
+// Place puts the customer on hold. A hold that expired without being released is
+// released first. Failing to mirror the hold to the ledger does not undo it, since the
+// hold alone stops money movement through us; the failure is recorded on the hold.
+func Place(logger *slog.Logger, userId string, req PlaceRequest, now time.Time) (*dao.ComplianceHoldDao, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, errtrace.Wrap(ErrReasonRequired)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, errtrace.Wrap(ErrExpiryNotInFuture)
+	}
+
+	unreleased, err := dao.ComplianceHoldDao{}.FindUnreleasedForUser(userId)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if unreleased != nil {
+		if unreleased.IsActive(now) {
+			return nil, errtrace.Wrap(ErrHoldAlreadyPlaced)
+		}
+		if _, err := release(logger, *unreleased, constant.COMPLIANCE_HOLD_RELEASED_BY_SYSTEM, releaseReasonExpired, now); err != nil {
+			return nil, errtrace.Wrap(err)
+		}
+	}
+
+	hold := dao.ComplianceHoldDao{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		Reason:    req.Reason,
+		PlacedVia: req.Via,
+		PlacedBy:  req.PlacedBy,
+		PlacedAt:  now,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := (dao.ComplianceHoldDao{}).Create(&hold); err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	if req.MirrorToLedger {
+		if err := updateLedgerAccountStatus(userId, ledger.SUSPENDED); err != nil {
+			logger.Error("Failed to suspend ledger account for compliance hold", "holdId", hold.Id, "error", err.Error())
+			ledgerError := err.Error()
+			hold.LedgerError = &ledgerError
+		} else {
+			hold.LedgerMirroredAt = &now
+		}
+		if err := (dao.ComplianceHoldDao{}).SetLedgerResult(hold.Id, hold.LedgerMirroredAt, hold.LedgerError); err != nil {
+			return nil, errtrace.Wrap(err)
+		}
+	}
+	return &hold, nil
+}
+
+// Release lifts the customer's hold, returning it as it was before and after. A hold
+// mirrored to the ledger reactivates the ledger account.
+func Release(logger *slog.Logger, userId string, releasedBy string, reason string, now time.Time) (*dao.ComplianceHoldDao, *dao.ComplianceHoldDao, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, errtrace.Wrap(ErrReasonRequired)
+	}
+	hold, err := dao.ComplianceHoldDao{}.FindUnreleasedForUser(userId)
+	if err != nil {
+		return nil, nil, errtrace.Wrap(err)
+	}
+	if hold == nil {
+		return nil, nil, errtrace.Wrap(ErrNoHold)
+	}
+	released, err := release(logger, *hold, releasedBy, reason, now)
+	if err != nil {
+		return nil, nil, errtrace.Wrap(err)
+	}
+	return hold, released, nil
+}
+
+func release(logger *slog.Logger, hold dao.ComplianceHoldDao, releasedBy string, reason string, now time.Time) (*dao.ComplianceHoldDao, error) {
+	releasedNow, err := dao.ComplianceHoldDao{}.Release(hold.Id, releasedBy, reason, now)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if !releasedNow {
+		return nil, errtrace.Wrap(ErrNoHold)
+	}
+	hold.ReleasedAt, hold.ReleasedBy, hold.ReleaseReason = &now, &releasedBy, &reason
+
+	if hold.LedgerMirroredAt != nil {
+		if err := updateLedgerAccountStatus(hold.UserId, ledger.ACTIVE); err != nil {
+			logger.Error("Failed to reactivate ledger account on releasing compliance hold", "holdId", hold.Id, "error", err.Error())
+			ledgerError := err.Error()
+			hold.LedgerError = &ledgerError
+			if err := (dao.ComplianceHoldDao{}).SetLedgerResult(hold.Id, hold.LedgerMirroredAt, hold.LedgerError); err != nil {
+				return nil, errtrace.Wrap(err)
+			}
+		}
+	}
+	return &hold, nil
+}
+
+func updateLedgerAccountStatus(userId string, status string) error {
+	account, err := dao.UserAccountCardDao{}.FindOneByUserId(db.DB, userId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if account == nil || account.AccountNumber == "" {
+		return errtrace.Wrap(errNoLedgerAccount)
+	}
+
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	resp, err := ledgerClient.UpdateAccountStatus(ledger.BuildUpdateAccountStatusRequest(account.AccountNumber, status))
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if resp.Error != nil {
+		return errtrace.Wrap(fmt.Errorf("the ledger responded with an error: %s", resp.Error.Message))
+	}
+	return nil
+}
+
+// ExpiryArgs is a periodic job that releases holds once they expire, so that holds
+// mirrored to the ledger reactivate the ledger account
+type ExpiryArgs struct{}
+
+func (ExpiryArgs) Kind() string { return "compliance_hold_expiry" }
+
+type ExpiryWorker struct {
+	river.WorkerDefaults[ExpiryArgs]
+}
+
+func RegisterExpiryWorker(workers *river.Workers) {
+	river.AddWorker(workers, &ExpiryWorker{})
+}
+
+// NewExpiryPeriodicJob schedules the expiry job. Periodic jobs run on the elected River
+// leader only, so each run happens once across instances.
+func NewExpiryPeriodicJob(schedule river.PeriodicSchedule) *river.PeriodicJob {
+	return river.NewPeriodicJob(schedule, func() (river.JobArgs, *river.InsertOpts) {
+		return ExpiryArgs{}, nil
+	}, &river.PeriodicJobOpts{RunOnStart: true})
+}
+
+func (w *ExpiryWorker) Work(ctx context.Context, job *river.Job[ExpiryArgs]) error {
+	return errtrace.Wrap(ReleaseExpired(clock.Now()))
+}
+
+// ReleaseExpired releases the holds that expired by now. A hold that fails to release is
+// logged and retried on the next run.
+func ReleaseExpired(now time.Time) error {
+	holds, err := dao.ComplianceHoldDao{}.FindExpiredUnreleased(now)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	logger := logging.Logger.With("job", ExpiryArgs{}.Kind())
+	for _, hold := range holds {
+		if _, err := release(logger, hold, constant.COMPLIANCE_HOLD_RELEASED_BY_SYSTEM, releaseReasonExpired, now); err != nil && !errors.Is(err, ErrNoHold) {
+			logger.Error("Failed to release expired compliance hold", "holdId", hold.Id, "error", err.Error())
+		}
+	}
+	return nil
+}
@@ -40,6 +40,13 @@ type Configs struct {
 	Posthog          PosthogConfigs
 	Salesforce       SalesforceConfigs
 	VisaSimulator    VisaSimulatorConfigs
+	Devices          DeviceConfigs
+	SignablePayloads SignablePayloadConfigs
+	// Rules for security.RateLimit, keyed by the name passed to it in BuildRoutes.
+	// Viper lowercases map keys, so names must be lowercase.
+	RateLimits  map[string]RateLimitRule
+	Idempotency IdempotencyConfigs
+	Disputes    DisputeConfigs
 }
 
 // ServerConfigurations exported
@@ -159,6 +166,9 @@ type SardineConfigs struct {
 	Credential       string `json:"sardine-credential"`
 	ApiBase          string `json:"sardine-apiBase"`
 	SendTransactions bool   `json:"sardine-sendTransactions"`
+	// Registers Plaid processor tokens for linked accounts with Sardine, so ACH transactions
+	// are scored with the accounts' bank data
+	SharePlaidData bool `json:"sardine-sharePlaidData"`
 }
 
 // DebtwiseConfigs exported
@@ -173,6 +183,28 @@ type PlaidConfigs struct {
 	Environment     string `json:"plaid-environment"`
 	LinkRedirectURI string `json:"plaid-linkRedirectURI"`
 	WebhookURL      string `json:"plaid-webhookURL"`
+	// Adds Transactions to newly linked items and syncs their transactions into plaid_transactions
+	TransactionsEnabled bool `json:"plaid-transactionsEnabled"`
+	// Deep link into the app's reconnect flow, which sends relink reminders' accountId to PlaidUpdateLinkToken
+	RelinkURL string `json:"plaid-relinkURL"`
+	// Items whose consent expires within this many days are reminded to relink
+	ConsentExpiryWarningDays int `json:"plaid-consentExpiryWarningDays"`
+	// Items whose balances weren't refreshed for this many days are counted as stale
+	StaleBalanceDays int `json:"plaid-staleBalanceDays"`
+	// Days between relink reminders for an item, and how many are sent before giving up
+	RelinkReminderIntervalDays int `json:"plaid-relinkReminderIntervalDays"`
+	MaxRelinkReminders         int `json:"plaid-maxRelinkReminders"`
+	// Linked accounts whose owner matches the customer from 0 to 1 at least this well are
+	// approved for pulls, and below IdentityMatchReviewScore they are rejected. Those in
+	// between are reviewed by an operator.
+	IdentityMatchApproveScore float64 `json:"plaid-identityMatchApproveScore"`
+	IdentityMatchReviewScore  float64 `json:"plaid-identityMatchReviewScore"`
+	// ACH pulls need the debited account's balance to have been refreshed within this many
+	// minutes, and refresh it for up to PullBalanceRefreshTimeoutSeconds when it's older.
+	// Pulls of more than the available balance less PullBalanceBufferCents are rejected.
+	PullBalanceMaxAgeMinutes         int   `json:"plaid-pullBalanceMaxAgeMinutes"`
+	PullBalanceRefreshTimeoutSeconds int   `json:"plaid-pullBalanceRefreshTimeoutSeconds"`
+	PullBalanceBufferCents           int64 `json:"plaid-pullBalanceBufferCents"`
 }
 
 // OtpConfigurations exported
@@ -182,6 +214,23 @@ type OtpConfigs struct {
 	MaxOtpRetryCount  int    `json:"maxOtpRetryCount"`
 	OtpExpiryDuration int    `json:"otpExpiryDuration"`
 	OtpDigits         int    `json:"otpDigits"`
+	SendRateLimits    OtpSendRateLimitConfigs
+}
+
+// OtpSendRateLimitConfigs bounds how many OTPs can be sent within a sliding
+// window, independently per user, phone number, client IP and destination country prefix.
+type OtpSendRateLimitConfigs struct {
+	PerUser          RateLimitRule `json:"perUser"`
+	PerMobileNo      RateLimitRule `json:"perMobileNo"`
+	PerIp            RateLimitRule `json:"perIp"`
+	PerCountryPrefix RateLimitRule `json:"perCountryPrefix"`
+}
+
+// RateLimitRule allows Limit requests per Window milliseconds. A Limit or Window of 0
+// disables the rule, so overrides must set both.
+type RateLimitRule struct {
+	Limit  int `json:"limit"`
+	Window int `json:"window"`
 }
 
 // KycConfigs exported
@@ -211,11 +260,17 @@ type OnboardingDataConfig struct {
 
 // SchedulersConfig exported
 type SchedulersConfig struct {
-	DeleteLogCronExp              string `json:"deleteLogCronExp"`
-	DeleteExpiredOTPsCronExp      string `json:"deleteExpiredOTPsCronExp"`
-	DeleteOldNotificationsCronExp string `json:"deleteOldNotificationsCronExp"`
-	DeleteOldLedgerTokensCronExp  string `json:"deleteOldLedgerTokensCronExp"`
-	CloseSuspendedAccountsCronExp string `json:"closeSuspendedAccountCronExp"`
+	DeleteLogCronExp               string `json:"deleteLogCronExp"`
+	DeleteExpiredOTPsCronExp       string `json:"deleteExpiredOTPsCronExp"`
+	DeleteOldNotificationsCronExp  string `json:"deleteOldNotificationsCronExp"`
+	DeleteOldLedgerTokensCronExp   string `json:"deleteOldLedgerTokensCronExp"`
+	CloseSuspendedAccountsCronExp  string `json:"closeSuspendedAccountCronExp"`
+	DeleteExpiredPayloadsCronExp   string `json:"deleteExpiredPayloadsCronExp"`
+	DeleteRateLimitCountersCronExp string `json:"deleteRateLimitCountersCronExp"`
+	DeleteIdempotencyKeysCronExp   string `json:"deleteIdempotencyKeysCronExp"`
+	EscalateDisputesCronExp        string `json:"escalateDisputesCronExp"`
+	ExpireComplianceHoldsCronExp   string `json:"expireComplianceHoldsCronExp"`
+	PlaidItemHealthCronExp         string `json:"plaidItemHealthCronExp"`
 }
 
 // EnvironmentConfig exported
@@ -251,6 +306,31 @@ type VisaSimulatorConfigs struct {
 	BaseUrl string `json:"baseUrl"`
 }
 
+type IdempotencyConfigs struct {
+	// Milliseconds a stored response is replayed for
+	KeyTtl int `json:"keyTtl"`
+}
+
+// SignablePayloadConfigs exported
+type SignablePayloadConfigs struct {
+	// Milliseconds a payload can be consumed after creation, keyed by payload type
+	Ttls map[string]int `json:"ttls"`
+	// Milliseconds, used for payload types without an entry in Ttls and for untyped legacy payloads
+	DefaultTtl int `json:"defaultTtl"`
+}
+
+// DeviceConfigs exported
+type DeviceConfigs struct {
+	// Applies to users whose master_user_records.device_limit is NULL. 0 means no limit.
+	DefaultDeviceLimit int `json:"defaultDeviceLimit"`
+}
+
+// DisputeConfigs exported
+type DisputeConfigs struct {
+	// Disputes are escalated when a Reg E deadline is this many business days away
+	EscalationBusinessDays int `json:"escalationBusinessDays"`
+}
+
 func ReadConfig(configJson *io.Reader) error {
 	return initViper(Config, configJson)
 }
@@ -329,6 +409,7 @@ func SetDefaults() {
 	viper.SetDefault("sardine.credential", nil)
 	viper.SetDefault("sardine.apibase", "http://localhost:5004")
 	viper.SetDefault("sardine.sendtransactions", true)
+	viper.SetDefault("sardine.shareplaiddata", false)
 	viper.SetDefault("debtwise.apibase", "http://localhost:5006")
 	viper.SetDefault("debtwise.credential", "")
 	viper.SetDefault("plaid.secret", nil)
@@ -339,6 +420,17 @@ func SetDefaults() {
 	// linkredirecturi comes from https://github.com/plaid/tiny-quickstart/tree/main/react_native and is used for Plaid sandbox
 	viper.SetDefault("plaid.linkredirecturi", "https://cdn-testing.plaid.com/link/v2/stable/sandbox-oauth-a2a-react-native-redirect.html")
 	viper.SetDefault("plaid.webhookurl", "")
+	viper.SetDefault("plaid.transactionsenabled", false)
+	viper.SetDefault("plaid.relinkurl", "dreamfi://plaid/relink")
+	viper.SetDefault("plaid.consentexpirywarningdays", 7)
+	viper.SetDefault("plaid.stalebalancedays", 7)
+	viper.SetDefault("plaid.relinkreminderintervaldays", 3)
+	viper.SetDefault("plaid.maxrelinkreminders", 5)
+	viper.SetDefault("plaid.identitymatchapprovescore", 0.85)
+	viper.SetDefault("plaid.identitymatchreviewscore", 0.5)
+	viper.SetDefault("plaid.pullbalancemaxageminutes", 60)
+	viper.SetDefault("plaid.pullbalancerefreshtimeoutseconds", 5)
+	viper.SetDefault("plaid.pullbalancebuffercents", 0)
 	viper.SetDefault("encrypt.encryptionkey", nil)
 	viper.SetDefault("environment.envname", "dreamfiSandbox")
 	viper.SetDefault("jwt.buffertimerefreshtoken", 300000)
@@ -399,11 +491,26 @@ MAocOQ27Ae2/cV4007V59D1y+FHRrkT++K3freRxIUDmGrzmjfUmMDqB75oD+fYV
 	viper.SetDefault("otp.maxotpretrycount", 3)
 	viper.SetDefault("otp.otpdigits", 6)
 	viper.SetDefault("otp.otpexpiryduration", 300000)
+	viper.SetDefault("otp.sendratelimits.peruser.limit", 5)
+	viper.SetDefault("otp.sendratelimits.peruser.window", 3600000)
+	viper.SetDefault("otp.sendratelimits.permobileno.limit", 5)
+	viper.SetDefault("otp.sendratelimits.permobileno.window", 3600000)
+	viper.SetDefault("otp.sendratelimits.perip.limit", 20)
+	viper.SetDefault("otp.sendratelimits.perip.window", 3600000)
+	viper.SetDefault("otp.sendratelimits.percountryprefix.limit", 2000)
+	viper.SetDefault("otp.sendratelimits.percountryprefix.window", 3600000)
 	viper.SetDefault("schedulers.deleteexpiredotpscronexp", "35 02 * * *")
 	viper.SetDefault("schedulers.deletelogcronexp", "30 02 * * *")
 	viper.SetDefault("schedulers.deleteoldnotificationscronexp", "40 02 * * *")
 	viper.SetDefault("schedulers.deleteoldledgertokenscronexp", "*/30 * * * *")
 	viper.SetDefault("schedulers.closesuspendedaccountscronexp", "0 8 * * *")
+	viper.SetDefault("schedulers.deleteexpiredpayloadscronexp", "*/15 * * * *")
+	viper.SetDefault("schedulers.deleteratelimitcounterscronexp", "0 * * * *")
+	viper.SetDefault("schedulers.deleteidempotencykeyscronexp", "10 * * * *")
+	viper.SetDefault("schedulers.escalatedisputescronexp", "20 * * * *")
+	viper.SetDefault("schedulers.expirecomplianceholdscronexp", "*/5 * * * *")
+	viper.SetDefault("schedulers.plaiditemhealthcronexp", "0 15 * * *")
+	viper.SetDefault("idempotency.keyttl", 86400000)
 	viper.SetDefault("server.port", 5000)
 	viper.SetDefault("cors.alloworigins", []string{"http://localhost:5000", "http://localhost:5002", "http://localhost:5173", "middleware.sandbox.dreamfi.com"})
 	viper.SetDefault("server.baseurl", "https://middleware.sandbox.dreamfi.com/api/v1/")
@@ -419,6 +526,25 @@ MAocOQ27Ae2/cV4007V59D1y+FHRrkT++K3freRxIUDmGrzmjfUmMDqB75oD+fYV
 	viper.SetDefault("salesforce.inboundauth0audience", "https://middleware.dreamfi.com/salesforce")
 
 	viper.SetDefault("visasimulator.baseurl", "https://dreamfisb.netxd.com/visafwd")
+
+	viper.SetDefault("devices.defaultdevicelimit", 0)
+	viper.SetDefault("disputes.escalationbusinessdays", 2)
+
+	viper.SetDefault("ratelimits", map[string]any{
+		"login":                map[string]int{"limit": 10, "window": 60000},
+		"email_duplicate":      map[string]int{"limit": 10, "window": 60000},
+		"address_autocomplete": map[string]int{"limit": 60, "window": 60000},
+		"balance_refresh":      map[string]int{"limit": 5, "window": 3600000},
+	})
+	viper.SetDefault("signablepayloads.defaultttl", 180000)
+	viper.SetDefault("signablepayloads.ttls", map[string]int{
+		"ach_debit":       180000,
+		"ach_credit":      180000,
+		"validate_cvv":    60000,
+		"get_card_limit":  180000,
+		"get_statement":   180000,
+		"list_statements": 180000,
+	})
 }
 
 func AssertConfig() error {
@@ -0,0 +1,10 @@
+package constant
+
+// Statuses of a high-risk admin action awaiting a second operator's approval
+const (
+	ADMIN_APPROVAL_PENDING  = "pending"
+	ADMIN_APPROVAL_APPROVED = "approved"
+	ADMIN_APPROVAL_REJECTED = "rejected"
+	// Approved, but the action failed when it was taken
+	ADMIN_APPROVAL_FAILED = "failed"
+)
@@ -0,0 +1,9 @@
+package constant
+
+// Where a compliance hold was placed or released from
+const (
+	COMPLIANCE_HOLD_VIA_ADMIN      = "admin"
+	COMPLIANCE_HOLD_VIA_SALESFORCE = "salesforce"
+	// Expired holds are released by the system
+	COMPLIANCE_HOLD_RELEASED_BY_SYSTEM = "system"
+)
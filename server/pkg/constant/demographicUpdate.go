@@ -0,0 +1,12 @@
+package constant
+
+const (
+	DEMOGRAPHIC_UPDATE_PENDING  = "pending"
+	DEMOGRAPHIC_UPDATE_ACCEPTED = "accepted"
+	DEMOGRAPHIC_UPDATE_REJECTED = "rejected"
+)
+
+const (
+	DEMOGRAPHIC_UPDATE_FULL_NAME = "full_name"
+	DEMOGRAPHIC_UPDATE_ADDRESS   = "address"
+)
@@ -46,8 +46,23 @@ const (
 	DISPUTE_REQUEST_INVALID_TRANSACTION_TYPE  = "DISPUTE_REQUEST_INVALID_TRANSACTION_TYPE"
 	INSUFFICIENT_FUNDS                        = "INSUFFICIENT_FUNDS"
 	DISPUTE_DOES_NOT_EXISTS                   = "DISPUTE_DOES_NOT_EXISTS"
+	DISPUTE_CLOSED                            = "DISPUTE_CLOSED"
+	DISPUTE_EVIDENCE_INVALID_FILE             = "DISPUTE_EVIDENCE_INVALID_FILE"
+	DISPUTE_EVIDENCE_LIMIT_REACHED            = "DISPUTE_EVIDENCE_LIMIT_REACHED"
 	TRANSACTION_DOES_NOT_EXIST                = "TRANSACTION_DOES_NOT_EXIST"
 	FORBIDDEN                                 = "FORBIDDEN"
+	DEVICE_REVOKED                            = "DEVICE_REVOKED"
+	DEVICE_LIMIT_REACHED                      = "DEVICE_LIMIT_REACHED"
+	INVALID_SIGNATURE                         = "INVALID_SIGNATURE"
+	OTP_SEND_RATE_LIMITED                     = "OTP_SEND_RATE_LIMITED"
+	RATE_LIMIT_EXCEEDED                       = "RATE_LIMIT_EXCEEDED"
+	IDEMPOTENCY_KEY_REUSED                    = "IDEMPOTENCY_KEY_REUSED"
+	IDEMPOTENCY_REQUEST_IN_PROGRESS           = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+	COMPLIANCE_HOLD                           = "COMPLIANCE_HOLD"
+	COMPLIANCE_HOLD_ALREADY_PLACED            = "COMPLIANCE_HOLD_ALREADY_PLACED"
+	ACCOUNT_OWNER_NOT_VERIFIED                = "ACCOUNT_OWNER_NOT_VERIFIED"
+	EXTERNAL_ACCOUNT_BALANCE_UNAVAILABLE      = "EXTERNAL_ACCOUNT_BALANCE_UNAVAILABLE"
+	EXTERNAL_ACCOUNT_NOT_LINKED               = "EXTERNAL_ACCOUNT_NOT_LINKED"
 )
 
 const (
@@ -92,8 +107,24 @@ const (
 	DEMOGRAPHIC_UPDATE_REQUEST_ALREADY_EXISTS_MSG = "A demographic update request with the same type is already pending for this user."
 	DISPUTE_REQUEST_ALREADY_EXISTS_MSG            = "Dispute request already exists for this transaction."
 	DISPUTE_DOES_NOT_EXISTS_MSG                   = "Dispute not exists for this transaction."
+	DISPUTE_CLOSED_MSG                            = "This dispute has been resolved and can no longer be changed."
+	DISPUTE_EVIDENCE_INVALID_FILE_MSG             = "Evidence must be a JPEG, PNG, WebP or PDF file of at most 10 MB."
+	DISPUTE_EVIDENCE_LIMIT_REACHED_MSG            = "The maximum number of files has been uploaded for this dispute."
 	DISPUTE_REQUEST_INVALID_TRANSACTION_TYPE_MSG  = "Dispute cannot be requested for this transaction type."
 	TRANSACTION_DOES_NOT_EXIST_MSG                = "Transaction not found for the given referenceID."
 	SARDINE_RETRY_ERROR_MSG                       = "Something went wrong. Please try again."
 	FORBIDDEN_MSG                                 = "Forbidden"
+	DEVICE_REVOKED_MSG                            = "This device has been removed from your account. Please log in again to register it."
+	DEVICE_LIMIT_REACHED_MSG                      = "The maximum number of registered devices has been reached. Please remove a device and try again."
+	INVALID_SIGNATURE_MSG                         = "The request signature could not be verified."
+	OTP_SEND_RATE_LIMITED_MSG                     = "Too many verification codes have been requested. Please try again later."
+	RATE_LIMIT_EXCEEDED_MSG                       = "Too many requests. Please try again later."
+	IDEMPOTENCY_KEY_REUSED_MSG                    = "This Idempotency-Key was already used for a different request."
+	IDEMPOTENCY_REQUEST_IN_PROGRESS_MSG           = "A request with this Idempotency-Key is still being processed."
+	COMPLIANCE_HOLD_MSG                           = "Money movement on this account is on hold. Please contact support."
+	COMPLIANCE_HOLD_ALREADY_PLACED_MSG            = "The customer is already on compliance hold."
+	ACCOUNT_OWNER_NOT_VERIFIED_MSG                = "We couldn't confirm this bank account is in your name. Please contact support."
+	INSUFFICIENT_FUNDS_MSG                        = "Your bank account doesn't have enough available money for this transfer."
+	EXTERNAL_ACCOUNT_BALANCE_UNAVAILABLE_MSG      = "We couldn't check your bank account's balance. Please try again, or reconnect the account if this keeps happening."
+	EXTERNAL_ACCOUNT_NOT_LINKED_MSG               = "This bank account isn't linked. Please link it before transferring from it."
 )
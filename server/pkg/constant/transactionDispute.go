@@ -0,0 +1,32 @@
+package constant
+
+const (
+	DISPUTE_SUBMITTED              = "submitted"
+	DISPUTE_UNDER_REVIEW           = "under_review"
+	DISPUTE_PROVISIONALLY_CREDITED = "provisionally_credited"
+	DISPUTE_RESOLVED_CUSTOMER      = "resolved_customer"
+	DISPUTE_RESOLVED_MERCHANT      = "resolved_merchant"
+	DISPUTE_WITHDRAWN              = "withdrawn"
+)
+
+// Dispute statuses from before case management, which released app builds still expect
+// in the disputeStatus of transactions
+const (
+	DISPUTE_LEGACY_PENDING  = "pending"
+	DISPUTE_LEGACY_CREDITED = "credited"
+	DISPUTE_LEGACY_VOIDED   = "voided"
+	DISPUTE_LEGACY_REJECTED = "rejected"
+)
+
+// Regulation E allows 10 business days to investigate a dispute before the customer must
+// be provisionally credited, and 45 to resolve it
+const (
+	DISPUTE_PROVISIONAL_CREDIT_BUSINESS_DAYS = 10
+	DISPUTE_RESOLUTION_BUSINESS_DAYS         = 45
+)
+
+// Limits on the evidence a customer can upload to a dispute
+const (
+	DISPUTE_EVIDENCE_MAX_BYTES = 10 << 20
+	DISPUTE_EVIDENCE_MAX_FILES = 10
+)
@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+// Whether an ACH pull was sent to the ledger after checking the debited account's balance
+const (
+	ACH_PULL_BALANCE_ALLOWED   = "allowed"
+	ACH_PULL_BALANCE_REJECTED  = "rejected"
+	ACH_PULL_BALANCE_UNCHECKED = "unchecked"
+)
+
+// Why an ACH pull was rejected or its balance wasn't checked
+const (
+	ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS  = "insufficient_funds"
+	ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE = "balance_unavailable"
+	ACH_PULL_BALANCE_REASON_NOT_LINKED          = "not_linked"
+	ACH_PULL_BALANCE_REASON_NO_BALANCE          = "no_balance"
+)
+
+// AchPullBalanceCheckDao records whether the external account an ACH pull debits had
+// enough available money for it, and what was decided
+type AchPullBalanceCheckDao struct {
+	Id                    string     `gorm:"column:id;primaryKey"`
+	PayloadId             string     `gorm:"column:payload_id"`
+	UserId                string     `gorm:"column:user_id"`
+	PlaidAccountId        *string    `gorm:"column:plaid_account_id"`
+	AmountCents           int64      `gorm:"column:amount_cents"`
+	BufferCents           int64      `gorm:"column:buffer_cents"`
+	AvailableBalanceCents *int64     `gorm:"column:available_balance_cents"`
+	BalanceRefreshedAt    *time.Time `gorm:"column:balance_refreshed_at"`
+	Refreshed             bool       `gorm:"column:refreshed"`
+	Decision              string     `gorm:"column:decision"`
+	Reason                *string    `gorm:"column:reason"`
+	TransactionNumber     *string    `gorm:"column:transaction_number"`
+	CreatedAt             time.Time  `gorm:"column:created_at"`
+}
+
+func (AchPullBalanceCheckDao) TableName() string {
+	return "ach_pull_balance_checks"
+}
+
+func (AchPullBalanceCheckDao) Create(check *AchPullBalanceCheckDao) error {
+	return errtrace.Wrap(db.DB.Create(check).Error)
+}
+
+// SetTransactionNumber records the ledger transaction of an allowed pull
+func (AchPullBalanceCheckDao) SetTransactionNumber(id, transactionNumber string) error {
+	return errtrace.Wrap(db.DB.Model(&AchPullBalanceCheckDao{}).Where("id = ?", id).Update("transaction_number", transactionNumber).Error)
+}
+
+// FindByPayloadId returns the check of the pull with the signed payload, or nil
+func (AchPullBalanceCheckDao) FindByPayloadId(payloadId string) (*AchPullBalanceCheckDao, error) {
+	var checks []AchPullBalanceCheckDao
+	if err := db.DB.Where("payload_id = ?", payloadId).Limit(1).Find(&checks).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+	return &checks[0], nil
+}
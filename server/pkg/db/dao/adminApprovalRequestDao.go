@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// AdminApprovalRequestDao is a high-risk admin action one operator has requested and a
+// second must approve before it is taken
+type AdminApprovalRequestDao struct {
+	Id               string     `gorm:"column:id;primaryKey"`
+	Action           string     `gorm:"column:action"`
+	EntityType       string     `gorm:"column:entity_type"`
+	EntityId         string     `gorm:"column:entity_id"`
+	UserId           *string    `gorm:"column:user_id"`
+	Reason           *string    `gorm:"column:reason"`
+	Permission       string     `gorm:"column:permission"`
+	Status           string     `gorm:"column:status"`
+	RequestedById    string     `gorm:"column:requested_by_id"`
+	RequestedByEmail string     `gorm:"column:requested_by_email"`
+	RequestedAt      time.Time  `gorm:"column:requested_at"`
+	ReviewedById     *string    `gorm:"column:reviewed_by_id"`
+	ReviewedByEmail  *string    `gorm:"column:reviewed_by_email"`
+	ReviewedAt       *time.Time `gorm:"column:reviewed_at"`
+	ReviewReason     *string    `gorm:"column:review_reason"`
+	Error            *string    `gorm:"column:error"`
+}
+
+func (AdminApprovalRequestDao) TableName() string {
+	return "admin_approval_requests"
+}
+
+// ErrAdminApprovalAlreadyPending is returned when the same action on the entity is already
+// awaiting approval
+var ErrAdminApprovalAlreadyPending = errors.New("admin action is already awaiting approval")
+
+func (AdminApprovalRequestDao) Create(request *AdminApprovalRequestDao) error {
+	err := db.DB.Create(request).Error
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errtrace.Wrap(ErrAdminApprovalAlreadyPending)
+	}
+	return errtrace.Wrap(err)
+}
+
+func (AdminApprovalRequestDao) FindById(id string) (*AdminApprovalRequestDao, error) {
+	var request AdminApprovalRequestDao
+	result := db.DB.Where("id = ?", id).Take(&request)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &request, nil
+}
+
+// FindByStatus returns requests in the status, oldest first so the longest waiting are
+// reviewed first
+func (AdminApprovalRequestDao) FindByStatus(status string, limit int, offset int) ([]AdminApprovalRequestDao, int64, error) {
+	var requests []AdminApprovalRequestDao
+	var totalCount int64
+
+	query := db.DB.Model(&AdminApprovalRequestDao{}).Where("status = ?", status)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	if err := query.Order("requested_at, id").Limit(limit).Offset(offset).Find(&requests).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	return requests, totalCount, nil
+}
+
+// FindPendingForEntity returns the entity's requests awaiting approval
+func (AdminApprovalRequestDao) FindPendingForEntity(entityType string, entityId string) ([]AdminApprovalRequestDao, error) {
+	var requests []AdminApprovalRequestDao
+	err := db.DB.Where("entity_type = ? AND entity_id = ? AND status = ?", entityType, entityId, constant.ADMIN_APPROVAL_PENDING).
+		Order("requested_at").Find(&requests).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return requests, nil
+}
+
+// Review moves a pending request to status, recording the reviewer. It returns false
+// when the request was no longer pending, so only one operator's review takes effect.
+func (AdminApprovalRequestDao) Review(id string, status string, reviewerId string, reviewerEmail string, reason *string, now time.Time) (bool, error) {
+	result := db.DB.Model(&AdminApprovalRequestDao{}).
+		Where("id = ? AND status = ?", id, constant.ADMIN_APPROVAL_PENDING).
+		Updates(map[string]any{
+			"status":            status,
+			"reviewed_by_id":    reviewerId,
+			"reviewed_by_email": reviewerEmail,
+			"reviewed_at":       now,
+			"review_reason":     reason,
+		})
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkFailed records that an approved request's action could not be taken
+func (AdminApprovalRequestDao) MarkFailed(id string, cause string) error {
+	return errtrace.Wrap(db.DB.Model(&AdminApprovalRequestDao{}).
+		Where("id = ? AND status = ?", id, constant.ADMIN_APPROVAL_APPROVED).
+		Updates(map[string]any{"status": constant.ADMIN_APPROVAL_FAILED, "error": cause}).Error)
+}
@@ -0,0 +1,225 @@
+package dao
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"process-api/pkg/db"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// AdminAuditLogDao is an entry of the append-only record of changes operators make
+// through the admin console. Entries are hash chained for tamper evidence.
+type AdminAuditLogDao struct {
+	Id         uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	ActorId    string `gorm:"column:actor_id"`
+	ActorEmail string `gorm:"column:actor_email"`
+	// Comma separated Auth0 roles the operator held
+	ActorRoles string  `gorm:"column:actor_roles"`
+	Action     string  `gorm:"column:action"`
+	EntityType string  `gorm:"column:entity_type"`
+	EntityId   string  `gorm:"column:entity_id"`
+	UserId     *string `gorm:"column:user_id"`
+	// JSON object of the fields that changed, {"field": {"before": ..., "after": ...}}
+	Changes   string    `gorm:"column:changes"`
+	Ip        *string   `gorm:"column:ip"`
+	RequestId *string   `gorm:"column:request_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	PrevHash  string    `gorm:"column:prev_hash"`
+	Hash      string    `gorm:"column:hash"`
+}
+
+func (AdminAuditLogDao) TableName() string {
+	return "admin_audit_log"
+}
+
+// Entity types of audited changes
+const (
+	ADMIN_AUDIT_ENTITY_DEVICE               = "user_public_key"
+	ADMIN_AUDIT_ENTITY_DEMOGRAPHIC_UPDATE   = "demographic_update"
+	ADMIN_AUDIT_ENTITY_DISPUTE              = "transaction_dispute"
+	ADMIN_AUDIT_ENTITY_APPROVAL_REQUEST     = "admin_approval_request"
+	ADMIN_AUDIT_ENTITY_CUSTOMER             = "master_user_record"
+	ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD      = "compliance_hold"
+	ADMIN_AUDIT_ENTITY_CARD                 = "user_account_card"
+	ADMIN_AUDIT_ENTITY_STATEMENT            = "statement"
+	ADMIN_AUDIT_ENTITY_WEBHOOK_SUBSCRIPTION = "webhook_subscription"
+	ADMIN_AUDIT_ENTITY_WEBHOOK_DELIVERY     = "webhook_delivery"
+	ADMIN_AUDIT_ENTITY_PLAID_WEBHOOK_EVENT  = "plaid_webhook_event"
+	ADMIN_AUDIT_ENTITY_PLAID_ACCOUNT        = "plaid_account"
+)
+
+// ADMIN_AUDIT_LOG_GENESIS_HASH is the previous hash of the first entry
+var ADMIN_AUDIT_LOG_GENESIS_HASH = strings.Repeat("0", 64)
+
+// adminAuditLogLockKey is the advisory lock serializing appends, so each entry chains
+// onto the one written before it
+const adminAuditLogLockKey = 7263518801
+
+// computeHash hashes every field of the entry but its id and own hash. created_at is
+// hashed at the microsecond precision Postgres stores.
+func (entry AdminAuditLogDao) computeHash() (string, error) {
+	hashed, err := json.Marshal(struct {
+		PrevHash   string    `json:"prevHash"`
+		ActorId    string    `json:"actorId"`
+		ActorEmail string    `json:"actorEmail"`
+		ActorRoles string    `json:"actorRoles"`
+		Action     string    `json:"action"`
+		EntityType string    `json:"entityType"`
+		EntityId   string    `json:"entityId"`
+		UserId     *string   `json:"userId"`
+		Changes    string    `json:"changes"`
+		Ip         *string   `json:"ip"`
+		RequestId  *string   `json:"requestId"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}{
+		PrevHash:   entry.PrevHash,
+		ActorId:    entry.ActorId,
+		ActorEmail: entry.ActorEmail,
+		ActorRoles: entry.ActorRoles,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityId:   entry.EntityId,
+		UserId:     entry.UserId,
+		Changes:    entry.Changes,
+		Ip:         entry.Ip,
+		RequestId:  entry.RequestId,
+		CreatedAt:  entry.CreatedAt.UTC().Truncate(time.Microsecond),
+	})
+	if err != nil {
+		return "", errtrace.Wrap(err)
+	}
+	sum := sha256.Sum256(hashed)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Append chains the entry onto the latest one and writes it
+func (AdminAuditLogDao) Append(entry *AdminAuditLogDao) error {
+	return errtrace.Wrap(db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", adminAuditLogLockKey).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		var latest AdminAuditLogDao
+		err := tx.Select("hash").Order("id DESC").Take(&latest).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			entry.PrevHash = ADMIN_AUDIT_LOG_GENESIS_HASH
+		case err != nil:
+			return errtrace.Wrap(err)
+		default:
+			entry.PrevHash = latest.Hash
+		}
+
+		entry.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+		entry.Hash, err = entry.computeHash()
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		return errtrace.Wrap(tx.Create(entry).Error)
+	}))
+}
+
+// AdminAuditLogFilter narrows a search of the audit log. Zero values do not filter.
+type AdminAuditLogFilter struct {
+	// Matches the operator's email, the entity id or the customer id
+	Query      string
+	Action     string
+	EntityType string
+	From       time.Time
+	To         time.Time
+}
+
+func (filter AdminAuditLogFilter) apply(query *gorm.DB) *gorm.DB {
+	if filter.Query != "" {
+		pattern := "%" + filter.Query + "%"
+		query = query.Where("actor_email ILIKE ? OR entity_id ILIKE ? OR user_id ILIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
+
+// Search returns matching entries, newest first
+func (AdminAuditLogDao) Search(filter AdminAuditLogFilter, limit int, offset int) ([]AdminAuditLogDao, int64, error) {
+	var entries []AdminAuditLogDao
+	var totalCount int64
+
+	query := filter.apply(db.DB.Model(&AdminAuditLogDao{}))
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	return entries, totalCount, nil
+}
+
+// FindInBatches calls fn with matching entries, oldest first, batchSize at a time
+func (AdminAuditLogDao) FindInBatches(filter AdminAuditLogFilter, batchSize int, fn func([]AdminAuditLogDao) error) error {
+	var afterId uint64
+	for {
+		var entries []AdminAuditLogDao
+		err := filter.apply(db.DB.Model(&AdminAuditLogDao{})).Where("id > ?", afterId).Order("id").Limit(batchSize).Find(&entries).Error
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := fn(entries); err != nil {
+			return errtrace.Wrap(err)
+		}
+		afterId = entries[len(entries)-1].Id
+	}
+}
+
+// AdminAuditLogVerification is the result of checking the hash chain
+type AdminAuditLogVerification struct {
+	EntriesChecked int
+	// The first entry whose hash or link to the previous entry does not match, if any
+	BrokenAtId *uint64
+}
+
+// VerifyChain recomputes the hash of every entry in order and checks that each links to
+// the entry before it
+func (AdminAuditLogDao) VerifyChain() (AdminAuditLogVerification, error) {
+	var verification AdminAuditLogVerification
+	prevHash := ADMIN_AUDIT_LOG_GENESIS_HASH
+	errChainBroken := errors.New("admin audit log chain broken")
+
+	err := AdminAuditLogDao{}.FindInBatches(AdminAuditLogFilter{}, 500, func(entries []AdminAuditLogDao) error {
+		for _, entry := range entries {
+			hash, err := entry.computeHash()
+			if err != nil {
+				return errtrace.Wrap(err)
+			}
+			if entry.PrevHash != prevHash || entry.Hash != hash {
+				verification.BrokenAtId = &entry.Id
+				return errChainBroken
+			}
+			verification.EntriesChecked++
+			prevHash = entry.Hash
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return verification, errtrace.Wrap(err)
+	}
+	return verification, nil
+}
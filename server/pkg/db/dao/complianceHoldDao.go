@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// ComplianceHoldDao stops all money movement for a customer while it is active, that is
+// until it is released or expires
+type ComplianceHoldDao struct {
+	Id               string     `gorm:"column:id;primaryKey"`
+	UserId           string     `gorm:"column:user_id"`
+	Reason           string     `gorm:"column:reason"`
+	PlacedVia        string     `gorm:"column:placed_via"`
+	PlacedBy         string     `gorm:"column:placed_by"`
+	PlacedAt         time.Time  `gorm:"column:placed_at"`
+	ExpiresAt        *time.Time `gorm:"column:expires_at"`
+	LedgerMirroredAt *time.Time `gorm:"column:ledger_mirrored_at"`
+	LedgerError      *string    `gorm:"column:ledger_error"`
+	ReleasedAt       *time.Time `gorm:"column:released_at"`
+	ReleasedBy       *string    `gorm:"column:released_by"`
+	ReleaseReason    *string    `gorm:"column:release_reason"`
+}
+
+func (ComplianceHoldDao) TableName() string {
+	return "compliance_holds"
+}
+
+// IsActive reports whether the hold blocks money movement at now
+func (hold ComplianceHoldDao) IsActive(now time.Time) bool {
+	return hold.ReleasedAt == nil && (hold.ExpiresAt == nil || hold.ExpiresAt.After(now))
+}
+
+// ErrComplianceHoldAlreadyPlaced is returned when the customer already has an unreleased
+// hold
+var ErrComplianceHoldAlreadyPlaced = errors.New("customer already has a compliance hold")
+
+func (ComplianceHoldDao) Create(hold *ComplianceHoldDao) error {
+	err := db.DB.Create(hold).Error
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return errtrace.Wrap(ErrComplianceHoldAlreadyPlaced)
+	}
+	return errtrace.Wrap(err)
+}
+
+// FindUnreleasedForUser returns the customer's hold that has not been released, which may
+// have expired, or nil
+func (ComplianceHoldDao) FindUnreleasedForUser(userId string) (*ComplianceHoldDao, error) {
+	var hold ComplianceHoldDao
+	result := db.DB.Where("user_id = ? AND released_at IS NULL", userId).Take(&hold)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &hold, nil
+}
+
+// FindActiveForUser returns the customer's hold if it is active at now, or nil
+func (ComplianceHoldDao) FindActiveForUser(userId string, now time.Time) (*ComplianceHoldDao, error) {
+	hold, err := ComplianceHoldDao{}.FindUnreleasedForUser(userId)
+	if err != nil || hold == nil || !hold.IsActive(now) {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// FindByUserId returns all of the customer's holds, newest first
+func (ComplianceHoldDao) FindByUserId(userId string) ([]ComplianceHoldDao, error) {
+	var holds []ComplianceHoldDao
+	err := db.DB.Where("user_id = ?", userId).Order("placed_at DESC").Find(&holds).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return holds, nil
+}
+
+// FindExpiredUnreleased returns the holds that expired by now but are not yet released
+func (ComplianceHoldDao) FindExpiredUnreleased(now time.Time) ([]ComplianceHoldDao, error) {
+	var holds []ComplianceHoldDao
+	err := db.DB.Where("released_at IS NULL AND expires_at <= ?", now).Order("expires_at").Find(&holds).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return holds, nil
+}
+
+// Release releases the hold, returning false if it was already released
+func (ComplianceHoldDao) Release(id string, releasedBy string, reason string, now time.Time) (bool, error) {
+	result := db.DB.Model(&ComplianceHoldDao{}).
+		Where("id = ? AND released_at IS NULL", id).
+		Updates(map[string]any{
+			"released_at":    now,
+			"released_by":    releasedBy,
+			"release_reason": reason,
+		})
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SetLedgerResult records the outcome of mirroring the hold to the ledger account status
+func (ComplianceHoldDao) SetLedgerResult(id string, mirroredAt *time.Time, ledgerError *string) error {
+	return errtrace.Wrap(db.DB.Model(&ComplianceHoldDao{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"ledger_mirrored_at": mirroredAt, "ledger_error": ledgerError}).Error)
+}
+
+// RequireNoComplianceHold refuses money movement for a customer with an active hold
+func RequireNoComplianceHold(userId string) *response.ErrorResponse {
+	hold, err := ComplianceHoldDao{}.FindActiveForUser(userId, clock.Now())
+	if err != nil {
+		return &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("DB Error: %s", err), MaybeInnerError: errtrace.Wrap(err)}
+	}
+	if hold != nil {
+		return &response.ErrorResponse{
+			ErrorCode:       constant.COMPLIANCE_HOLD,
+			Message:         constant.COMPLIANCE_HOLD_MSG,
+			StatusCode:      http.StatusForbidden,
+			LogMessage:      fmt.Sprintf("Customer is on compliance hold %s", hold.Id),
+			MaybeInnerError: errtrace.New(""),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// DemographicUpdateEventDao is the audit trail of a demographic update's review, one
+// row per step, so it is clear which downstream systems a change reached.
+type DemographicUpdateEventDao struct {
+	Id                  uint64    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	DemographicUpdateId string    `json:"demographicUpdateId" gorm:"column:demographic_update_id"`
+	Action              string    `json:"action" gorm:"column:action"`
+	Actor               string    `json:"actor" gorm:"column:actor"`
+	Detail              *string   `json:"detail" gorm:"column:detail"`
+	CreatedAt           time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (DemographicUpdateEventDao) TableName() string {
+	return "demographic_update_events"
+}
+
+const (
+	DEMOGRAPHIC_UPDATE_EVENT_APPROVED               = "approved"
+	DEMOGRAPHIC_UPDATE_EVENT_REJECTED               = "rejected"
+	DEMOGRAPHIC_UPDATE_EVENT_LEDGER_UPDATED         = "ledger_updated"
+	DEMOGRAPHIC_UPDATE_EVENT_LEDGER_UPDATE_FAILED   = "ledger_update_failed"
+	DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_UPDATED       = "debtwise_updated"
+	DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_UPDATE_FAILED = "debtwise_update_failed"
+	DEMOGRAPHIC_UPDATE_EVENT_DEBTWISE_SKIPPED       = "debtwise_skipped"
+	DEMOGRAPHIC_UPDATE_EVENT_SARDINE_UPDATED        = "sardine_updated"
+	DEMOGRAPHIC_UPDATE_EVENT_SARDINE_UPDATE_FAILED  = "sardine_update_failed"
+	DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_QUEUED    = "customer_notification_queued"
+	DEMOGRAPHIC_UPDATE_EVENT_NOTIFICATION_FAILED    = "customer_notification_failed"
+)
+
+func (DemographicUpdateEventDao) Create(tx *gorm.DB, demographicUpdateId, action, actor string, detail *string, now time.Time) error {
+	event := DemographicUpdateEventDao{
+		DemographicUpdateId: demographicUpdateId,
+		Action:              action,
+		Actor:               actor,
+		Detail:              detail,
+		CreatedAt:           now,
+	}
+	return errtrace.Wrap(tx.Create(&event).Error)
+}
+
+// FindByDemographicUpdateId returns the update's events in the order they happened
+func (DemographicUpdateEventDao) FindByDemographicUpdateId(demographicUpdateId string) ([]DemographicUpdateEventDao, error) {
+	var events []DemographicUpdateEventDao
+	err := db.DB.Where("demographic_update_id = ?", demographicUpdateId).Order("created_at, id").Find(&events).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
@@ -3,6 +3,7 @@ package dao
 import (
 	"encoding/json"
 	"errors"
+	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"strings"
 	"time"
@@ -18,6 +19,9 @@ type DemographicUpdatesDao struct {
 	UpdatedValue json.RawMessage `json:"value" gorm:"column:updated_value;type:jsonb"`
 	ExtraInfo    string          `json:"extraInfo" gorm:"column:extra_info"`
 	UserId       string          `gorm:"column:user_id;foreignKey:UserId;references:Id"`
+	ReviewedBy   *string         `json:"reviewedBy" gorm:"column:reviewed_by"`
+	ReviewedAt   *time.Time      `json:"reviewedAt" gorm:"column:reviewed_at"`
+	ReviewReason *string         `json:"reviewReason" gorm:"column:review_reason"`
 	CreatedAt    time.Time       `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt    time.Time       `gorm:"column:updated_at;autoUpdateTime"`
 }
@@ -41,7 +45,7 @@ type UpdateCustomerAddressRequest struct {
 
 func (DemographicUpdatesDao) FindByTypeAndUserId(userId, updateType string) (*DemographicUpdatesDao, error) {
 	var demographicUpdateRecord DemographicUpdatesDao
-	result := db.DB.Where("user_id = ? AND type = ? AND status = ?", userId, updateType, "pending").First(&demographicUpdateRecord)
+	result := db.DB.Where("user_id = ? AND type = ? AND status = ?", userId, updateType, constant.DEMOGRAPHIC_UPDATE_PENDING).First(&demographicUpdateRecord)
 	// record not found
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -72,6 +76,16 @@ func (DemographicUpdatesDao) FindDemographicUpdatesByUserId(userId string) ([]De
 	return demographicUpdates, nil
 }
 
+// FindAllByUserId returns every update the user submitted, newest first
+func (DemographicUpdatesDao) FindAllByUserId(userId string) ([]DemographicUpdatesDao, error) {
+	var demographicUpdates []DemographicUpdatesDao
+	err := db.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&demographicUpdates).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return demographicUpdates, nil
+}
+
 func (DemographicUpdatesDao) FindById(demographicId string) (*DemographicUpdatesDao, error) {
 	var demographicUpdateRecord DemographicUpdatesDao
 	result := db.DB.Where("id = ?", demographicId).Take(&demographicUpdateRecord)
@@ -84,6 +98,18 @@ func (DemographicUpdatesDao) FindById(demographicId string) (*DemographicUpdates
 	return &demographicUpdateRecord, nil
 }
 
+// MarkReviewed moves a pending update to accepted or rejected. It returns false when the
+// update was no longer pending, e.g. because another operator reviewed it first.
+func (DemographicUpdatesDao) MarkReviewed(tx *gorm.DB, id, status, reviewedBy, reason string, now time.Time) (bool, error) {
+	result := tx.Model(DemographicUpdatesDao{}).
+		Where("id = ? AND status = ?", id, constant.DEMOGRAPHIC_UPDATE_PENDING).
+		Updates(map[string]any{"status": status, "reviewed_by": reviewedBy, "reviewed_at": now, "review_reason": reason, "updated_at": now})
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
 type DemographicUpdateWithUser struct {
 	DemographicUpdate DemographicUpdatesDao `gorm:"embedded"`
 	User              MasterUserRecordDao   `gorm:"embedded"`
@@ -0,0 +1,79 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+// IdempotencyKeyDao stores the outcome of a request made with an Idempotency-Key
+// header so that a retry with the same key replays it instead of running again.
+// A record without CompletedAt belongs to a request that is still being processed.
+type IdempotencyKeyDao struct {
+	UserId              string     `gorm:"column:user_id;primaryKey"`
+	Key                 string     `gorm:"column:key;primaryKey"`
+	RequestFingerprint  string     `gorm:"column:request_fingerprint"`
+	ResponseStatus      *int       `gorm:"column:response_status"`
+	ResponseContentType *string    `gorm:"column:response_content_type"`
+	ResponseBody        []byte     `gorm:"column:response_body"`
+	CreatedAt           time.Time  `gorm:"column:created_at"`
+	CompletedAt         *time.Time `gorm:"column:completed_at"`
+	ExpiresAt           time.Time  `gorm:"column:expires_at"`
+}
+
+func (IdempotencyKeyDao) TableName() string {
+	return "idempotency_keys"
+}
+
+func (k IdempotencyKeyDao) IsCompleted() bool {
+	return k.CompletedAt != nil
+}
+
+// Claim inserts record as an in-progress request. If the user already used the key
+// and it has not expired, nothing is inserted and the existing record is returned
+// with claimed set to false.
+func (IdempotencyKeyDao) Claim(record *IdempotencyKeyDao) (existing *IdempotencyKeyDao, claimed bool, err error) {
+	err = db.DB.Where("user_id = ? AND key = ? AND expires_at < ?", record.UserId, record.Key, record.CreatedAt).Delete(IdempotencyKeyDao{}).Error
+	if err != nil {
+		return nil, false, errtrace.Wrap(err)
+	}
+
+	result := db.DB.Set("gorm:insert_option", "ON CONFLICT (user_id, key) DO NOTHING").Create(record)
+	if result.Error != nil {
+		return nil, false, errtrace.Wrap(result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, true, nil
+	}
+
+	// The conflicting record may have been released since the insert, in which case
+	// this fails with gorm.ErrRecordNotFound and the client has to retry
+	var found IdempotencyKeyDao
+	err = db.DB.Where("user_id = ? AND key = ?", record.UserId, record.Key).Take(&found).Error
+	if err != nil {
+		return nil, false, errtrace.Wrap(err)
+	}
+	return &found, false, nil
+}
+
+func (IdempotencyKeyDao) Complete(userId, key string, status int, contentType string, body []byte, now time.Time) error {
+	err := db.DB.Model(IdempotencyKeyDao{}).Where("user_id = ? AND key = ?", userId, key).Updates(map[string]any{
+		"response_status":       status,
+		"response_content_type": contentType,
+		"response_body":         body,
+		"completed_at":          now,
+	}).Error
+	return errtrace.Wrap(err)
+}
+
+// Release forgets the key so that a request which did not complete can be retried with it
+func (IdempotencyKeyDao) Release(userId, key string) error {
+	err := db.DB.Where("user_id = ? AND key = ?", userId, key).Delete(IdempotencyKeyDao{}).Error
+	return errtrace.Wrap(err)
+}
+
+func (IdempotencyKeyDao) DeleteExpired(now time.Time) (int64, error) {
+	result := db.DB.Where("expires_at < ?", now).Delete(IdempotencyKeyDao{})
+	return result.RowsAffected, errtrace.Wrap(result.Error)
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"errors"
+	"process-api/pkg/db"
 	"time"
 
 	"braces.dev/errtrace"
@@ -48,3 +49,14 @@ func (LedgerTransactionEventDao) FindOneByEventId(db *gorm.DB, eventId string) (
 func (LedgerTransactionEventDao) TableName() string {
 	return "ledger_transaction_events"
 }
+
+// FindForTimeline returns the ledger's transaction events for the user inside the window,
+// newest first
+func (LedgerTransactionEventDao) FindForTimeline(userId string, window TimelineWindow) ([]LedgerTransactionEventDao, error) {
+	var events []LedgerTransactionEventDao
+	err := window.apply(db.DB.Where("user_id = ?", userId), "created_at").Find(&events).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
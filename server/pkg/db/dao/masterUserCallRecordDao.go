@@ -1,6 +1,11 @@
 package dao
 
-import "time"
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+)
 
 type MasterUserCallRecordDao struct {
 	CallSid    string    `gorm:"column:call_sid;primaryKey"`
@@ -13,3 +18,15 @@ type MasterUserCallRecordDao struct {
 func (MasterUserCallRecordDao) TableName() string {
 	return "master_user_call_record"
 }
+
+// FindForTimeline returns the calls placed to mobileNo inside the window, newest first.
+// Calls are recorded by the number dialled rather than by user, so a number that has
+// changed hands also lists its previous owner's calls.
+func (MasterUserCallRecordDao) FindForTimeline(mobileNo string, window TimelineWindow) ([]MasterUserCallRecordDao, error) {
+	var calls []MasterUserCallRecordDao
+	err := window.apply(db.DB.Where(`"to" = ?`, mobileNo), "created_at").Find(&calls).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return calls, nil
+}
@@ -1,7 +1,10 @@
 package dao
 
 import (
+	"process-api/pkg/db"
 	"time"
+
+	"braces.dev/errtrace"
 )
 
 type MasterUserLoginDao struct {
@@ -14,3 +17,13 @@ type MasterUserLoginDao struct {
 func (MasterUserLoginDao) TableName() string {
 	return "master_user_logins"
 }
+
+// FindForTimeline returns the user's logins inside the window, newest first
+func (MasterUserLoginDao) FindForTimeline(userId string, window TimelineWindow) ([]MasterUserLoginDao, error) {
+	var logins []MasterUserLoginDao
+	err := window.apply(db.DB.Where("user_id = ?", userId), "created_at").Find(&logins).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return logins, nil
+}
@@ -1,6 +1,11 @@
 package dao
 
-import "time"
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+)
 
 type MasterUserOtpDao struct {
 	OtpId     string `gorm:"column:otp_id;primaryKey"`
@@ -25,3 +30,13 @@ type MasterUserOtpDao struct {
 func (MasterUserOtpDao) TableName() string {
 	return "master_user_otp"
 }
+
+// FindForTimeline returns the OTPs issued to the user inside the window, newest first
+func (MasterUserOtpDao) FindForTimeline(userId string, window TimelineWindow) ([]MasterUserOtpDao, error) {
+	var otps []MasterUserOtpDao
+	err := window.apply(db.DB.Where("user_id = ?", userId), "created_at").Find(&otps).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return otps, nil
+}
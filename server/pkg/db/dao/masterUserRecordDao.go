@@ -37,6 +37,7 @@ type MasterUserRecordDao struct {
 	ResetToken                 string    `json:"resetToken" gorm:"column:reset_token" mask:"true"`
 	DebtwiseOnboardingStatus   string    `json:"debtwiseOnboardingStatus" gorm:"column:debtwise_onboarding_status;default:'uninitialized'"`
 	DebtwiseCustomerNumber     *int      `json:"debtwiseCustomerNumber" gorm:"column:debtwise_customer_number"`
+	DeviceLimit                *int      `json:"deviceLimit" gorm:"column:device_limit"`
 	CreatedAt                  time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt                  time.Time `gorm:"column:updated_at;autoUpdateTime"`
 	// Agreement hash values
@@ -101,12 +102,61 @@ func (MasterUserRecordDao) FindUserByMobileNumber(mobileNo string) (*MasterUserR
 	return &user, nil
 }
 
+func (MasterUserRecordDao) FindUserByLedgerCustomerNumber(ledgerCustomerNumber string) (*MasterUserRecordDao, error) {
+	var user MasterUserRecordDao
+	result := db.DB.Where("ledger_customer_number = ?", ledgerCustomerNumber).Take(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &user, nil
+}
+
 func (MasterUserRecordDao) FindAll() ([]MasterUserRecordDao, error) {
 	var users []MasterUserRecordDao
 	err := db.DB.Find(&users).Error
 	return users, errtrace.Wrap(err)
 }
 
+// UpdateTrackingStatus applies updates to the user, returning the number of rows updated.
+// When the updates change the user's status a customer.status_changed webhook event is
+// written in the same transaction.
+func (MasterUserRecordDao) UpdateTrackingStatus(userId string, updates any, now time.Time) (int64, error) {
+	var rowsAffected int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var before MasterUserRecordDao
+		result := tx.Set("gorm:query_option", "FOR UPDATE").Select("user_status").Where("id = ?", userId).Take(&before)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if result.Error != nil {
+			return errtrace.Wrap(result.Error)
+		}
+
+		result = tx.Model(&MasterUserRecordDao{}).Where("id = ?", userId).Updates(updates)
+		if result.Error != nil {
+			return errtrace.Wrap(result.Error)
+		}
+		rowsAffected = result.RowsAffected
+
+		var after MasterUserRecordDao
+		if err := tx.Select("user_status").Where("id = ?", userId).Take(&after).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		if after.UserStatus == before.UserStatus {
+			return nil
+		}
+		return errtrace.Wrap(WebhookEventDao{}.Create(tx, WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED, userId, CustomerStatusChangedEventData{
+			UserId:         userId,
+			PreviousStatus: before.UserStatus,
+			Status:         after.UserStatus,
+		}, now))
+	})
+	return rowsAffected, errtrace.Wrap(err)
+}
+
 func RequireUserWithState(userId string, userStates ...string) (*MasterUserRecordDao, *response.ErrorResponse) {
 	user, err := MasterUserRecordDao{}.FindOneByUserId(userId)
 	if err != nil {
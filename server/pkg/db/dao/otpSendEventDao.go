@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// OtpSendEventDao records every OTP handed to Twilio or the email provider. The
+// send rate limits count these rows over a sliding window.
+type OtpSendEventDao struct {
+	Id            string    `json:"id" gorm:"column:id;primaryKey"`
+	UserId        string    `json:"userId" gorm:"column:user_id"`
+	MobileNo      *string   `json:"mobileNo" gorm:"column:mobile_no" mask:"true"`
+	CountryPrefix *string   `json:"countryPrefix" gorm:"column:country_prefix"`
+	IP            string    `json:"ip" gorm:"column:ip"`
+	ApiPath       string    `json:"apiPath" gorm:"column:api_path"`
+	OtpType       string    `json:"otpType" gorm:"column:otp_type"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (OtpSendEventDao) TableName() string {
+	return "otp_send_events"
+}
+
+// Columns an OTP send can be rate limited on
+const (
+	OTP_SEND_EVENT_USER_ID        = "user_id"
+	OTP_SEND_EVENT_MOBILE_NO      = "mobile_no"
+	OTP_SEND_EVENT_IP             = "ip"
+	OTP_SEND_EVENT_COUNTRY_PREFIX = "country_prefix"
+)
+
+func (OtpSendEventDao) Create(tx *gorm.DB, record *OtpSendEventDao) error {
+	if record.Id == "" {
+		record.Id = uuid.New().String()
+	}
+	return errtrace.Wrap(tx.Create(record).Error)
+}
+
+// Lock holds a transaction-scoped lock on column = value, so that concurrent sends
+// sharing it are counted and recorded one at a time
+func (OtpSendEventDao) Lock(tx *gorm.DB, column, value string) error {
+	return errtrace.Wrap(tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "otp_send_events:"+column+":"+value).Error)
+}
+
+// CountSince returns how many sends matched column = value since the given time,
+// along with the time of the oldest of them so callers can work out when the
+// window frees up. column must be one of the OTP_SEND_EVENT_* constants.
+func (OtpSendEventDao) CountSince(tx *gorm.DB, column, value string, since time.Time) (int, *time.Time, error) {
+	var count int
+	query := tx.Model(OtpSendEventDao{}).Where(column+" = ? AND created_at > ?", value, since)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, nil, errtrace.Wrap(err)
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	var oldest OtpSendEventDao
+	err := query.Order("created_at ASC").Take(&oldest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return count, nil, nil
+	}
+	if err != nil {
+		return 0, nil, errtrace.Wrap(err)
+	}
+	return count, &oldest.CreatedAt, nil
+}
+
+func (OtpSendEventDao) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := db.DB.Where("created_at < ?", cutoff).Delete(OtpSendEventDao{})
+	return result.RowsAffected, errtrace.Wrap(result.Error)
+}
@@ -3,8 +3,11 @@ package dao
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"process-api/pkg/clock"
+	"process-api/pkg/constant"
 	"process-api/pkg/db"
+	"process-api/pkg/model/response"
 	"slices"
 	"time"
 
@@ -13,6 +16,15 @@ import (
 	"github.com/plaid/plaid-go/v34/plaid"
 )
 
+// Whether money can be pulled from an account, given how well its owner matched the customer
+const (
+	IDENTITY_MATCH_APPROVED = "approved"
+	IDENTITY_MATCH_REVIEW   = "review"
+	IDENTITY_MATCH_REJECTED = "rejected"
+	// Linked before identity matching was added, and allowed without it
+	IDENTITY_MATCH_LEGACY = "legacy"
+)
+
 const (
 	CheckingSubtype plaid.AccountSubtype = plaid.ACCOUNTSUBTYPE_CHECKING
 	SavingsSubtype  plaid.AccountSubtype = plaid.ACCOUNTSUBTYPE_SAVINGS
@@ -35,6 +47,7 @@ type PlaidAccountDao struct {
 	InstitutionID         *string
 	AvailableBalanceCents *int64
 	PrimaryOwnerName      *string
+	PrimaryOwnerAddress   *string
 	//  The way that a user links an external account (the auth method) changes  what kind of information we can expect from Plaid about the account,
 	// e.g., for the micro-deposits auth flow, Identity (name) and Balance information _can't_ be retrieved:
 	// https://support.plaid.com/hc/en-us/articles/14977532310167-Can-I-obtain-Balance-and-Identity-data-for-Items-created-using-Same-Day-Micro-deposits
@@ -46,8 +59,16 @@ type PlaidAccountDao struct {
 	// > Plaid is not able to provide Identity or Balance details for the account.
 	AuthMethod         *plaid.ItemAuthMethod `gorm:"type:plaid_auth_method"`
 	VerificationStatus *string               `gorm:"type:plaid_verification_status"`
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	// How well the primary owner matched the customer who linked the account. Accounts linked
+	// before identity matching are legacy, and those not matched yet have no status.
+	IdentityNameScore    *float64
+	IdentityAddressScore *float64
+	IdentityMatchStatus  *string
+	IdentityMatchedAt    *time.Time
+	IdentityReviewedBy   *string
+	IdentityReviewedAt   *time.Time
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
 
 func (PlaidAccountDao) TableName() string { return "plaid_accounts" }
@@ -105,6 +126,143 @@ func (PlaidAccountDao) FindNonErrorAccountsWithStaleBalanceForUser(userId string
 	return accounts, nil
 }
 
+// FindLatestBalanceRefreshByItem returns when the balances of each item's accounts were
+// last refreshed, by Plaid item id. Items whose balances were never refreshed, such as
+// those linked with micro-deposits, are left out.
+func (PlaidAccountDao) FindLatestBalanceRefreshByItem() (map[string]time.Time, error) {
+	var rows []struct {
+		PlaidItemID        string
+		BalanceRefreshedAt time.Time
+	}
+	err := db.DB.Model(&PlaidAccountDao{}).
+		Select("plaid_item_id, MAX(balance_refreshed_at) AS balance_refreshed_at").
+		Where("balance_refreshed_at IS NOT NULL").
+		Group("plaid_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not find latest balance refreshes: %w", err))
+	}
+
+	refreshedAt := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		refreshedAt[row.PlaidItemID] = row.BalanceRefreshedAt
+	}
+	return refreshedAt, nil
+}
+
+// RecordIdentityMatch stores how well the account's owner matched its customer and what
+// was decided, clearing an earlier review
+func (PlaidAccountDao) RecordIdentityMatch(id string, nameScore, addressScore *float64, status string, now time.Time) error {
+	err := db.DB.Model(&PlaidAccountDao{}).Where("id = ?", id).Updates(map[string]any{
+		"identity_name_score":    nameScore,
+		"identity_address_score": addressScore,
+		"identity_match_status":  status,
+		"identity_matched_at":    now,
+		"identity_reviewed_by":   nil,
+		"identity_reviewed_at":   nil,
+	}).Error
+	return errtrace.Wrap(err)
+}
+
+// ReviewIdentityMatch records an operator's decision on an account whose owner didn't
+// clearly match its customer
+func (PlaidAccountDao) ReviewIdentityMatch(id, status, reviewedBy string, now time.Time) error {
+	err := db.DB.Model(&PlaidAccountDao{}).Where("id = ?", id).Updates(map[string]any{
+		"identity_match_status": status,
+		"identity_reviewed_by":  reviewedBy,
+		"identity_reviewed_at":  now,
+	}).Error
+	return errtrace.Wrap(err)
+}
+
+// FindAccountsForItem returns the user's accounts at the item
+func (PlaidAccountDao) FindAccountsForItem(userId, plaidItemId string) ([]PlaidAccountDao, error) {
+	var accounts []PlaidAccountDao
+	err := db.DB.Where("user_id = ? AND plaid_item_id = ?", userId, plaidItemId).Order("created_at").Find(&accounts).Error
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not find accounts for item %s: %w", plaidItemId, err))
+	}
+	return accounts, nil
+}
+
+// accountNumberMask is the last four digits of an account number, which Plaid shows as
+// the account's mask
+func accountNumberMask(accountNumber string) (string, bool) {
+	if len(accountNumber) < 4 {
+		return "", false
+	}
+	return accountNumber[len(accountNumber)-4:], true
+}
+
+// ErrAmbiguousAccountNumber is returned when more than one of the user's linked accounts
+// has the account number's mask, so there's no telling which of them it is
+var ErrAmbiguousAccountNumber = errors.New("more than one linked account has the account number's mask")
+
+// FindAccountForUserByAccountNumber returns the user's linked account with the account
+// number's mask, or nil. Plaid doesn't give us full account numbers, so if accounts at
+// two banks share a mask, ErrAmbiguousAccountNumber is returned rather than either one.
+func (PlaidAccountDao) FindAccountForUserByAccountNumber(userId, accountNumber string) (*PlaidAccountDao, error) {
+	mask, ok := accountNumberMask(accountNumber)
+	if !ok {
+		return nil, nil
+	}
+	var accounts []PlaidAccountDao
+	err := db.DB.Where("user_id = ? AND mask = ?", userId, mask).Order("created_at DESC").Limit(2).Find(&accounts).Error
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not find account for user %s: %w", userId, err))
+	}
+	switch len(accounts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &accounts[0], nil
+	default:
+		return nil, errtrace.Wrap(ErrAmbiguousAccountNumber)
+	}
+}
+
+// RequireAccountOwnerVerified refuses to pull money from an external account unless it is
+// linked and its owner was matched to the customer. The pull only carries the account
+// number, so the customer's linked accounts are matched on its last four digits, and any
+// one with that mask that isn't approved or legacy blocks it. Accounts that haven't been
+// matched yet are blocked too.
+func RequireAccountOwnerVerified(userId, accountNumber string) *response.ErrorResponse {
+	var accounts []PlaidAccountDao
+	if mask, ok := accountNumberMask(accountNumber); ok {
+		err := db.DB.Where("user_id = ? AND mask = ?", userId, mask).Order("created_at").Find(&accounts).Error
+		if err != nil {
+			return &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("DB Error: %s", err), MaybeInnerError: errtrace.Wrap(err)}
+		}
+	}
+	if len(accounts) == 0 {
+		return &response.ErrorResponse{
+			ErrorCode:       constant.EXTERNAL_ACCOUNT_NOT_LINKED,
+			Message:         constant.EXTERNAL_ACCOUNT_NOT_LINKED_MSG,
+			StatusCode:      http.StatusForbidden,
+			LogMessage:      "The debited account isn't linked with Plaid",
+			MaybeInnerError: errtrace.New(""),
+		}
+	}
+
+	for _, account := range accounts {
+		if account.IdentityMatchStatus != nil && (*account.IdentityMatchStatus == IDENTITY_MATCH_APPROVED || *account.IdentityMatchStatus == IDENTITY_MATCH_LEGACY) {
+			continue
+		}
+		status := "not matched"
+		if account.IdentityMatchStatus != nil {
+			status = *account.IdentityMatchStatus
+		}
+		return &response.ErrorResponse{
+			ErrorCode:       constant.ACCOUNT_OWNER_NOT_VERIFIED,
+			Message:         constant.ACCOUNT_OWNER_NOT_VERIFIED_MSG,
+			StatusCode:      http.StatusForbidden,
+			LogMessage:      fmt.Sprintf("Plaid account %s identity match is %s", account.ID, status),
+			MaybeInnerError: errtrace.New(""),
+		}
+	}
+	return nil
+}
+
 // These come from plaid.LinkDeliveryVerificationStatus
 var verifiedStatuses = []string{
 	"automatically_verified",
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+// PlaidItemHealthSnapshotDao counts how many Plaid items were in each state on a day the
+// item health monitor ran. An item with several problems is counted under each of them.
+type PlaidItemHealthSnapshotDao struct {
+	Id                     string    `gorm:"column:id;primaryKey"`
+	SnapshotDate           time.Time `gorm:"column:snapshot_date"`
+	TotalItems             int       `gorm:"column:total_items"`
+	HealthyItems           int       `gorm:"column:healthy_items"`
+	LoginRequiredItems     int       `gorm:"column:login_required_items"`
+	PendingDisconnectItems int       `gorm:"column:pending_disconnect_items"`
+	ConsentExpiringItems   int       `gorm:"column:consent_expiring_items"`
+	StaleBalanceItems      int       `gorm:"column:stale_balance_items"`
+	RemindersSent          int       `gorm:"column:reminders_sent"`
+	CreatedAt              time.Time `gorm:"column:created_at"`
+}
+
+func (PlaidItemHealthSnapshotDao) TableName() string {
+	return "plaid_item_health_snapshots"
+}
+
+const upsertPlaidItemHealthSnapshotOption = `ON CONFLICT (snapshot_date) DO UPDATE SET
+	total_items = EXCLUDED.total_items,
+	healthy_items = EXCLUDED.healthy_items,
+	login_required_items = EXCLUDED.login_required_items,
+	pending_disconnect_items = EXCLUDED.pending_disconnect_items,
+	consent_expiring_items = EXCLUDED.consent_expiring_items,
+	stale_balance_items = EXCLUDED.stale_balance_items,
+	reminders_sent = plaid_item_health_snapshots.reminders_sent + EXCLUDED.reminders_sent,
+	created_at = EXCLUDED.created_at`
+
+// Save stores the snapshot, replacing the counts of one already taken that day. Reminders
+// sent by each run are added up, since a later run only sends the ones not yet sent.
+func (PlaidItemHealthSnapshotDao) Save(snapshot *PlaidItemHealthSnapshotDao) error {
+	return errtrace.Wrap(db.DB.Set("gorm:insert_option", upsertPlaidItemHealthSnapshotOption).Create(snapshot).Error)
+}
+
+// FindRecent returns the latest snapshots, newest first
+func (PlaidItemHealthSnapshotDao) FindRecent(limit int) ([]PlaidItemHealthSnapshotDao, error) {
+	var snapshots []PlaidItemHealthSnapshotDao
+	err := db.DB.Order("snapshot_date DESC").Limit(limit).Find(&snapshots).Error
+	return snapshots, errtrace.Wrap(err)
+}
@@ -10,15 +10,22 @@ import (
 )
 
 type PlaidItemDao struct {
-	Id                      uint64    `gorm:"primaryKey"`
-	UserId                  string    `gorm:"column:user_id;foreignKey:UserId;references:Id"`
-	PlaidItemID             string    `gorm:"column:plaid_item_id;unique"`
-	EncryptedAccessToken    []byte    `gorm:"column:encrypted_access_token" mask:"true"`
-	KmsEncryptedAccessToken []byte    `gorm:"column:kms_encrypted_access_token" mask:"true"`
-	ItemError               *string   `gorm:"column:item_error"`
-	IsPendingDisconnect     bool      `gorm:"column:is_pending_disconnect;default:false"`
-	CreatedAt               time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt               time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	Id                      uint64     `gorm:"primaryKey"`
+	UserId                  string     `gorm:"column:user_id;foreignKey:UserId;references:Id"`
+	PlaidItemID             string     `gorm:"column:plaid_item_id;unique"`
+	EncryptedAccessToken    []byte     `gorm:"column:encrypted_access_token" mask:"true"`
+	KmsEncryptedAccessToken []byte     `gorm:"column:kms_encrypted_access_token" mask:"true"`
+	ItemError               *string    `gorm:"column:item_error"`
+	IsPendingDisconnect     bool       `gorm:"column:is_pending_disconnect;default:false"`
+	NewAccountsAvailable    bool       `gorm:"column:new_accounts_available;default:false"`
+	TransactionsCursor      *string    `gorm:"column:transactions_cursor"` // where /transactions/sync left off
+	TransactionsSyncedAt    *time.Time `gorm:"column:transactions_synced_at"`
+	ConsentExpirationTime   *time.Time `gorm:"column:consent_expiration_time"`
+	RelinkRemindedAt        *time.Time `gorm:"column:relink_reminded_at"`
+	RelinkRemindersSent     int        `gorm:"column:relink_reminders_sent;default:0"`
+	RelinkedAt              *time.Time `gorm:"column:relinked_at"`
+	CreatedAt               time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt               time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (PlaidItemDao) TableName() string { return "plaid_items" }
@@ -129,6 +136,33 @@ func (PlaidItemDao) SetIsPendingDisconnect(itemID string, isPending bool) error
 	return errtrace.Wrap(db.DB.Model(&item).Update("is_pending_disconnect", isPending).Error)
 }
 
+func (PlaidItemDao) SetNewAccountsAvailable(itemID string, available bool) error {
+	return errtrace.Wrap(db.DB.Model(&PlaidItemDao{}).Where("plaid_item_id = ?", itemID).Update("new_accounts_available", available).Error)
+}
+
+func (PlaidItemDao) SetConsentExpirationTime(itemID string, consentExpirationTime *time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&PlaidItemDao{}).Where("plaid_item_id = ?", itemID).Update("consent_expiration_time", consentExpirationTime).Error)
+}
+
+// MarkRelinkReminded records that the item's user was just asked to relink it
+func (PlaidItemDao) MarkRelinkReminded(itemID string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&PlaidItemDao{}).Where("plaid_item_id = ?", itemID).Updates(map[string]any{
+		"relink_reminded_at":    now,
+		"relink_reminders_sent": gorm.Expr("relink_reminders_sent + 1"),
+	}).Error)
+}
+
+// MarkRelinked records that the user reconnected the item, which starts its reminders over
+// and drops the consent expiration the reconnection renewed
+func (PlaidItemDao) MarkRelinked(itemID string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&PlaidItemDao{}).Where("plaid_item_id = ?", itemID).Updates(map[string]any{
+		"relinked_at":             now,
+		"relink_reminded_at":      nil,
+		"relink_reminders_sent":   0,
+		"consent_expiration_time": nil,
+	}).Error)
+}
+
 func (PlaidItemDao) FindAll() ([]PlaidItemDao, error) {
 	var records []PlaidItemDao
 	err := db.DB.Find(&records).Error
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// PlaidTransactionDao is a transaction on one of the user's linked external accounts, as
+// last reported by Plaid's /transactions/sync
+type PlaidTransactionDao struct {
+	Id                 string `gorm:"column:id;primaryKey"`
+	UserId             string `gorm:"column:user_id"`
+	PlaidItemId        string `gorm:"column:plaid_item_id"`
+	PlaidAccountId     string `gorm:"column:plaid_account_id"`
+	PlaidTransactionId string `gorm:"column:plaid_transaction_id"`
+	// Money into the account is positive and money out is negative
+	AmountCents          int64      `gorm:"column:amount_cents"`
+	IsoCurrencyCode      *string    `gorm:"column:iso_currency_code"`
+	Date                 time.Time  `gorm:"column:date;type:date"`
+	AuthorizedDate       *time.Time `gorm:"column:authorized_date;type:date"`
+	Name                 string     `gorm:"column:name"`
+	MerchantName         *string    `gorm:"column:merchant_name"`
+	CategoryPrimary      *string    `gorm:"column:category_primary"`
+	CategoryDetailed     *string    `gorm:"column:category_detailed"`
+	PaymentChannel       *string    `gorm:"column:payment_channel"`
+	Pending              bool       `gorm:"column:pending"`
+	PendingTransactionId *string    `gorm:"column:pending_transaction_id"`
+	CreatedAt            time.Time  `gorm:"column:created_at"`
+	UpdatedAt            time.Time  `gorm:"column:updated_at"`
+}
+
+func (PlaidTransactionDao) TableName() string { return "plaid_transactions" }
+
+const upsertPlaidTransactionOption = `ON CONFLICT (plaid_item_id, plaid_transaction_id) DO UPDATE SET
+	plaid_account_id = EXCLUDED.plaid_account_id,
+	amount_cents = EXCLUDED.amount_cents,
+	iso_currency_code = EXCLUDED.iso_currency_code,
+	date = EXCLUDED.date,
+	authorized_date = EXCLUDED.authorized_date,
+	name = EXCLUDED.name,
+	merchant_name = EXCLUDED.merchant_name,
+	category_primary = EXCLUDED.category_primary,
+	category_detailed = EXCLUDED.category_detailed,
+	payment_channel = EXCLUDED.payment_channel,
+	pending = EXCLUDED.pending,
+	pending_transaction_id = EXCLUDED.pending_transaction_id,
+	updated_at = EXCLUDED.updated_at`
+
+// ApplySync stores one /transactions/sync update for the item: the transactions added or
+// modified since its cursor, and those removed. The item's cursor only moves on once they
+// are all stored, so a failed sync is retried from where the last one left off.
+func (PlaidTransactionDao) ApplySync(plaidItemId string, upserted []PlaidTransactionDao, removedTransactionIds []string, cursor string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, transaction := range upserted {
+			transaction.Id = uuid.NewString()
+			transaction.PlaidItemId = plaidItemId
+			transaction.CreatedAt = now
+			transaction.UpdatedAt = now
+			if err := tx.Set("gorm:insert_option", upsertPlaidTransactionOption).Create(&transaction).Error; err != nil {
+				return errtrace.Wrap(err)
+			}
+		}
+		if len(removedTransactionIds) > 0 {
+			err := tx.Where("plaid_item_id = ? AND plaid_transaction_id IN (?)", plaidItemId, removedTransactionIds).Delete(&PlaidTransactionDao{}).Error
+			if err != nil {
+				return errtrace.Wrap(err)
+			}
+		}
+		return errtrace.Wrap(tx.Model(&PlaidItemDao{}).Where("plaid_item_id = ?", plaidItemId).Updates(map[string]any{
+			"transactions_cursor":    cursor,
+			"transactions_synced_at": now,
+		}).Error)
+	}))
+}
+
+// FindForUser returns the transactions on all of the user's linked accounts dated on or
+// after since, newest first
+func (PlaidTransactionDao) FindForUser(userId string, since time.Time) ([]PlaidTransactionDao, error) {
+	var records []PlaidTransactionDao
+	err := db.DB.Where("user_id = ? AND date >= ?", userId, since.Format(time.DateOnly)).
+		Order("date DESC, plaid_transaction_id").
+		Find(&records).Error
+	return records, errtrace.Wrap(err)
+}
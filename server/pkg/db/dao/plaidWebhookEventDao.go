@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// PlaidWebhookEventDao is a verified webhook from Plaid, stored before it is processed so
+// that what came of every webhook can be looked up and those that couldn't be processed
+// can be retried
+type PlaidWebhookEventDao struct {
+	Id             string  `gorm:"column:id;primaryKey"`
+	WebhookType    string  `gorm:"column:webhook_type"`
+	WebhookCode    string  `gorm:"column:webhook_code"`
+	PlaidItemId    *string `gorm:"column:plaid_item_id"`
+	PlaidAccountId *string `gorm:"column:plaid_account_id"`
+	// The webhook's body as Plaid sent it
+	Payload       string     `gorm:"column:payload"`
+	Status        string     `gorm:"column:status"`
+	Error         *string    `gorm:"column:error"`
+	Attempts      int        `gorm:"column:attempts"`
+	ReceivedAt    time.Time  `gorm:"column:received_at"`
+	ProcessedAt   *time.Time `gorm:"column:processed_at"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at"`
+}
+
+func (PlaidWebhookEventDao) TableName() string {
+	return "plaid_webhook_events"
+}
+
+const (
+	PLAID_WEBHOOK_EVENT_RECEIVED  = "received"
+	PLAID_WEBHOOK_EVENT_PROCESSED = "processed"
+	// A webhook type or code we don't act on
+	PLAID_WEBHOOK_EVENT_IGNORED = "ignored"
+	// The webhook's item isn't linked, and it is retried in case the item is being linked
+	PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM = "unknown_item"
+	PLAID_WEBHOOK_EVENT_FAILED       = "failed"
+)
+
+var PlaidWebhookEventStatuses = []string{
+	PLAID_WEBHOOK_EVENT_RECEIVED,
+	PLAID_WEBHOOK_EVENT_PROCESSED,
+	PLAID_WEBHOOK_EVENT_IGNORED,
+	PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM,
+	PLAID_WEBHOOK_EVENT_FAILED,
+}
+
+// PlaidWebhookEventOutcome is what came of one attempt to process an event
+type PlaidWebhookEventOutcome struct {
+	Status        string
+	Error         *string
+	NextAttemptAt *time.Time
+}
+
+func (PlaidWebhookEventDao) Create(event *PlaidWebhookEventDao) error {
+	return errtrace.Wrap(db.DB.Create(event).Error)
+}
+
+func (PlaidWebhookEventDao) FindById(id string) (*PlaidWebhookEventDao, error) {
+	var event PlaidWebhookEventDao
+	result := db.DB.Where("id = ?", id).Take(&event)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &event, nil
+}
+
+// RecordOutcome saves the outcome of an attempt to process the event
+func (PlaidWebhookEventDao) RecordOutcome(id string, outcome PlaidWebhookEventOutcome, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&PlaidWebhookEventDao{}).Where("id = ?", id).Updates(map[string]any{
+		"status":          outcome.Status,
+		"error":           outcome.Error,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"processed_at":    now,
+		"next_attempt_at": outcome.NextAttemptAt,
+	}).Error)
+}
+
+// ClaimDueForRetry returns the oldest unknown_item events whose retry is due and pushes
+// their next attempt back to claimUntil, so runs overlapping this one skip them. Rows
+// another run is claiming are skipped rather than waited on.
+func (PlaidWebhookEventDao) ClaimDueForRetry(now time.Time, limit int, claimUntil time.Time) ([]PlaidWebhookEventDao, error) {
+	var events []PlaidWebhookEventDao
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").Where("status = ? AND next_attempt_at <= ?", PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM, now).
+			Order("next_attempt_at, id").Limit(limit).Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return errtrace.Wrap(err)
+		}
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+		return errtrace.Wrap(tx.Model(&PlaidWebhookEventDao{}).Where("id IN (?)", ids).Update("next_attempt_at", claimUntil).Error)
+	})
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
+
+// PlaidWebhookEventFilter narrows a search of events. Zero values do not filter.
+type PlaidWebhookEventFilter struct {
+	Status      string
+	WebhookType string
+	PlaidItemId string
+}
+
+// Search returns a page of the events matching the filter, newest first, along with the
+// number of events matching it
+func (PlaidWebhookEventDao) Search(filter PlaidWebhookEventFilter, limit int, offset int) ([]PlaidWebhookEventDao, int64, error) {
+	var events []PlaidWebhookEventDao
+	var totalCount int64
+
+	query := db.DB.Model(&PlaidWebhookEventDao{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.WebhookType != "" {
+		query = query.Where("webhook_type = ?", filter.WebhookType)
+	}
+	if filter.PlaidItemId != "" {
+		query = query.Where("plaid_item_id = ?", filter.PlaidItemId)
+	}
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	if err := query.Order("received_at DESC, id").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	return events, totalCount, nil
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// RateLimitCounterDao holds the number of requests made for a rate limit key in
+// one fixed window. Keeping the counters in Postgres makes limits hold across replicas.
+type RateLimitCounterDao struct {
+	Key         string    `gorm:"column:key;primaryKey"`
+	WindowStart time.Time `gorm:"column:window_start;primaryKey"`
+	Count       int       `gorm:"column:count"`
+	ExpiresAt   time.Time `gorm:"column:expires_at"`
+}
+
+func (RateLimitCounterDao) TableName() string {
+	return "rate_limit_counters"
+}
+
+// Increment atomically adds one to the counter for key in the window starting at
+// windowStart, and returns the new count along with the count of the window before it.
+func (RateLimitCounterDao) Increment(key string, windowStart time.Time, window time.Duration) (int, int, error) {
+	var current RateLimitCounterDao
+	err := db.DB.Raw(`
+		INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING *
+	`, key, windowStart, windowStart.Add(2*window)).Scan(&current).Error
+	if err != nil {
+		return 0, 0, errtrace.Wrap(err)
+	}
+
+	var previous RateLimitCounterDao
+	err = db.DB.Where("key = ? AND window_start = ?", key, windowStart.Add(-window)).Take(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, errtrace.Wrap(err)
+	}
+
+	return current.Count, previous.Count, nil
+}
+
+// DeleteExpired removes counters that no longer contribute to any window
+func (RateLimitCounterDao) DeleteExpired(now time.Time) (int64, error) {
+	result := db.DB.Where("expires_at < ?", now).Delete(RateLimitCounterDao{})
+	return result.RowsAffected, errtrace.Wrap(result.Error)
+}
@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// SardineProcessorTokenDao is a Plaid processor token that gives Sardine access to a
+// linked account's bank data
+type SardineProcessorTokenDao struct {
+	Id                         string     `gorm:"column:id;primaryKey"`
+	UserId                     string     `gorm:"column:user_id"`
+	PlaidItemId                string     `gorm:"column:plaid_item_id"`
+	PlaidAccountId             string     `gorm:"column:plaid_account_id"`
+	KmsEncryptedProcessorToken []byte     `gorm:"column:kms_encrypted_processor_token" mask:"true"`
+	RegisteredAt               *time.Time `gorm:"column:registered_at"`
+	SyncedAt                   *time.Time `gorm:"column:synced_at"`
+	RevokedAt                  *time.Time `gorm:"column:revoked_at"`
+	LastError                  *string    `gorm:"column:last_error"`
+	CreatedAt                  time.Time  `gorm:"column:created_at"`
+	UpdatedAt                  time.Time  `gorm:"column:updated_at"`
+}
+
+func (SardineProcessorTokenDao) TableName() string {
+	return "sardine_processor_tokens"
+}
+
+func (SardineProcessorTokenDao) Create(token *SardineProcessorTokenDao) error {
+	return errtrace.Wrap(db.DB.Create(token).Error)
+}
+
+// FindActiveForItem returns the item's tokens that haven't been revoked, keyed by Plaid
+// account id
+func (SardineProcessorTokenDao) FindActiveForItem(plaidItemId string) (map[string]SardineProcessorTokenDao, error) {
+	var tokens []SardineProcessorTokenDao
+	if err := db.DB.Where("plaid_item_id = ? AND revoked_at IS NULL", plaidItemId).Find(&tokens).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	byAccount := make(map[string]SardineProcessorTokenDao, len(tokens))
+	for _, token := range tokens {
+		byAccount[token.PlaidAccountId] = token
+	}
+	return byAccount, nil
+}
+
+// FindForItem returns all of the item's tokens, revoked or not
+func (SardineProcessorTokenDao) FindForItem(plaidItemId string) ([]SardineProcessorTokenDao, error) {
+	var tokens []SardineProcessorTokenDao
+	if err := db.DB.Where("plaid_item_id = ?", plaidItemId).Order("created_at").Find(&tokens).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return tokens, nil
+}
+
+func (SardineProcessorTokenDao) MarkRegistered(ids []string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&SardineProcessorTokenDao{}).Where("id IN (?)", ids).Updates(map[string]any{
+		"registered_at": now,
+		"last_error":    nil,
+		"updated_at":    now,
+	}).Error)
+}
+
+func (SardineProcessorTokenDao) MarkSynced(id string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&SardineProcessorTokenDao{}).Where("id = ?", id).Updates(map[string]any{
+		"synced_at":  now,
+		"last_error": nil,
+		"updated_at": now,
+	}).Error)
+}
+
+func (SardineProcessorTokenDao) SetLastError(ids []string, lastError string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&SardineProcessorTokenDao{}).Where("id IN (?)", ids).Updates(map[string]any{
+		"last_error": lastError,
+		"updated_at": now,
+	}).Error)
+}
+
+// RevokeForItem records that the item's tokens no longer work and forgets them. It takes
+// the caller's transaction so the revocation is recorded along with the unlink.
+func (SardineProcessorTokenDao) RevokeForItem(tx *gorm.DB, plaidItemId string, now time.Time) error {
+	return errtrace.Wrap(tx.Model(&SardineProcessorTokenDao{}).Where("plaid_item_id = ? AND revoked_at IS NULL", plaidItemId).Updates(map[string]any{
+		"kms_encrypted_processor_token": nil,
+		"revoked_at":                    now,
+		"updated_at":                    now,
+	}).Error)
+}
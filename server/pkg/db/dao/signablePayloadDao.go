@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"process-api/pkg/clock"
+	"process-api/pkg/config"
 	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"process-api/pkg/model/response"
@@ -17,18 +18,33 @@ import (
 )
 
 type SignablePayloadDao struct {
-	Id         string     `json:"id" gorm:"column:id;primaryKey"`
-	Payload    string     `json:"payload" gorm:"column:payload;type:text" mask:"true"`
-	UserId     *string    `json:"userId" gorm:"column:user_id"`
-	ConsumedAt *time.Time `json:"consumedAt" gorm:"column:consumed_at"`
-	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	Id          string     `json:"id" gorm:"column:id;primaryKey"`
+	Payload     string     `json:"payload" gorm:"column:payload;type:text" mask:"true"`
+	UserId      *string    `json:"userId" gorm:"column:user_id"`
+	PayloadType *string    `json:"payloadType" gorm:"column:payload_type"`
+	ExpiresAt   *time.Time `json:"expiresAt" gorm:"column:expires_at"`
+	ConsumedAt  *time.Time `json:"consumedAt" gorm:"column:consumed_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (SignablePayloadDao) TableName() string {
 	return "signable_payloads"
 }
 
+func defaultSignablePayloadTtl() time.Duration {
+	return time.Duration(config.Config.SignablePayloads.DefaultTtl) * time.Millisecond
+}
+
+// ExpirationTime falls back to the default TTL for payloads created before
+// expires_at was recorded.
+func (p SignablePayloadDao) ExpirationTime() time.Time {
+	if p.ExpiresAt != nil {
+		return *p.ExpiresAt
+	}
+	return p.CreatedAt.Add(defaultSignablePayloadTtl())
+}
+
 func (SignablePayloadDao) FindById(payloadId string) (*SignablePayloadDao, error) {
 	var payloadRecord SignablePayloadDao
 	result := db.DB.Where("id = ?", payloadId).Take(&payloadRecord)
@@ -53,7 +69,9 @@ func RequireSignablePayload(payloadId string) (*SignablePayloadDao, *response.Er
 	return payloadRecord, nil
 }
 
-func ConsumePayload(userId string, payloadId string) (*SignablePayloadDao, *response.ErrorResponse) {
+// RequireConsumablePayload returns the user's payload if it is of the expected type
+// and has neither been consumed nor expired. It does not mark the payload as consumed.
+func RequireConsumablePayload(userId string, payloadId string, payloadType string) (*SignablePayloadDao, *response.ErrorResponse) {
 	var payloadRecord SignablePayloadDao
 	// Ensure the payload's associated with this user. If we want to later, we can return
 	// a specific error if the payload record exists but the payload isn't associated with the user.
@@ -68,33 +86,50 @@ func ConsumePayload(userId string, payloadId string) (*SignablePayloadDao, *resp
 		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_ALREADY_CONSUMED", StatusCode: http.StatusConflict, LogMessage: "payload already consumed", MaybeInnerError: errtrace.New("")}
 	}
 
-	// TODO: for now, hardcoding this to 3 minutes:
-	expirationTime := payloadRecord.CreatedAt.Add(3 * time.Minute)
-	if clock.Now().After(expirationTime) {
-		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_EXPIRED", StatusCode: http.StatusGone, LogMessage: "payload expired", MaybeInnerError: errtrace.New("")}
+	// Prevents a payload built for one endpoint from being replayed against another
+	if payloadRecord.PayloadType == nil || *payloadRecord.PayloadType != payloadType {
+		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_TYPE_MISMATCH", StatusCode: http.StatusUnprocessableEntity, LogMessage: fmt.Sprintf("payload type %v does not match expected type %s", payloadRecord.PayloadType, payloadType), MaybeInnerError: errtrace.New("")}
 	}
 
-	now := clock.Now()
-	payloadRecord.ConsumedAt = &now
-	err = db.DB.Save(&payloadRecord).Error
-	if err != nil {
-		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_INTERNAL_ERROR", StatusCode: http.StatusInternalServerError, LogMessage: "error saving payload to db", MaybeInnerError: errtrace.Wrap(err)}
+	if clock.Now().After(payloadRecord.ExpirationTime()) {
+		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_EXPIRED", StatusCode: http.StatusGone, LogMessage: "payload expired", MaybeInnerError: errtrace.New("")}
 	}
 
 	return &payloadRecord, nil
 }
 
-func CreateSignablePayloadForUser(userId string, payload any) (*response.BuildPayloadResponse, *response.ErrorResponse) {
+// MarkPayloadConsumed sets consumed_at only if no concurrent request has already
+// consumed the payload.
+func MarkPayloadConsumed(payloadRecord *SignablePayloadDao) *response.ErrorResponse {
+	now := clock.Now()
+	result := db.DB.Model(SignablePayloadDao{}).Where("id = ? AND consumed_at IS NULL", payloadRecord.Id).Update("consumed_at", now)
+	if result.Error != nil {
+		return &response.ErrorResponse{ErrorCode: "PAYLOAD_INTERNAL_ERROR", StatusCode: http.StatusInternalServerError, LogMessage: "error saving payload to db", MaybeInnerError: errtrace.Wrap(result.Error)}
+	}
+	if result.RowsAffected == 0 {
+		return &response.ErrorResponse{ErrorCode: "PAYLOAD_ALREADY_CONSUMED", StatusCode: http.StatusConflict, LogMessage: "payload already consumed", MaybeInnerError: errtrace.New("")}
+	}
+	payloadRecord.ConsumedAt = &now
+	return nil
+}
+
+// CreateSignablePayloadForUser should not be called directly by handlers. Use the
+// payload type registry in the ledger package, which checks the payload's schema
+// and supplies the type's TTL.
+func CreateSignablePayloadForUser(userId string, payloadType string, ttl time.Duration, payload any) (*response.BuildPayloadResponse, *response.ErrorResponse) {
 	jsonPayloadBytes, marshallErr := json.Marshal(payload)
 	if marshallErr != nil {
 		return nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_MARSHAL_FAILED", StatusCode: http.StatusInternalServerError, LogMessage: "error marshaling payload", MaybeInnerError: errtrace.Wrap(marshallErr)}
 	}
 
 	id := uuid.New().String()
+	expiresAt := clock.Now().Add(ttl)
 	payloadRecord := SignablePayloadDao{
-		Id:      id,
-		UserId:  &userId,
-		Payload: string(jsonPayloadBytes),
+		Id:          id,
+		UserId:      &userId,
+		Payload:     string(jsonPayloadBytes),
+		PayloadType: &payloadType,
+		ExpiresAt:   &expiresAt,
 	}
 
 	result := db.DB.Create(&payloadRecord)
@@ -108,3 +143,13 @@ func CreateSignablePayloadForUser(userId string, payload any) (*response.BuildPa
 	}
 	return response, nil
 }
+
+// DeleteExpiredUnconsumedPayloads removes payloads that can no longer be consumed.
+// Consumed payloads are kept since they back ledger requests that were sent.
+func DeleteExpiredUnconsumedPayloads(now time.Time) (int64, error) {
+	result := db.DB.Where(
+		"consumed_at IS NULL AND (expires_at < ? OR (expires_at IS NULL AND created_at < ?))",
+		now, now.Add(-defaultSignablePayloadTtl()),
+	).Delete(&SignablePayloadDao{})
+	return result.RowsAffected, errtrace.Wrap(result.Error)
+}
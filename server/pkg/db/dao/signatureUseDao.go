@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+)
+
+// SignatureUseDao records every attempt to use a device signature, valid or not,
+// so that activity for a given key can be reconstructed.
+type SignatureUseDao struct {
+	Id              string    `json:"id" gorm:"column:id;primaryKey"`
+	UserPublicKeyId uint64    `json:"userPublicKeyId" gorm:"column:user_public_key_id"`
+	UserId          string    `json:"userId" gorm:"column:user_id"`
+	PayloadId       string    `json:"payloadId" gorm:"column:payload_id"`
+	Endpoint        string    `json:"endpoint" gorm:"column:endpoint"`
+	IP              string    `json:"ip" gorm:"column:ip"`
+	IsValid         bool      `json:"isValid" gorm:"column:is_valid"`
+	CreatedAt       time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (SignatureUseDao) TableName() string {
+	return "signature_uses"
+}
+
+func (SignatureUseDao) Create(record *SignatureUseDao) error {
+	if record.Id == "" {
+		record.Id = uuid.New().String()
+	}
+	return errtrace.Wrap(db.DB.Create(record).Error)
+}
+
+func (SignatureUseDao) FindByUserPublicKeyId(userPublicKeyId uint64) ([]SignatureUseDao, error) {
+	var records []SignatureUseDao
+	err := db.DB.Where("user_public_key_id = ?", userPublicKeyId).Order("created_at DESC").Find(&records).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return records, nil
+}
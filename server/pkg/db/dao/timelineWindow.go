@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TimelineWindow bounds a query for a customer's activity timeline to the newest Limit
+// rows before Before. Zero values do not filter.
+type TimelineWindow struct {
+	From   time.Time
+	Before time.Time
+	Limit  int
+}
+
+// Contains reports whether at falls inside the window's time range
+func (window TimelineWindow) Contains(at time.Time) bool {
+	return (window.From.IsZero() || !at.Before(window.From)) && (window.Before.IsZero() || at.Before(window.Before))
+}
+
+func (window TimelineWindow) apply(query *gorm.DB, column string) *gorm.DB {
+	if !window.From.IsZero() {
+		query = query.Where(column+" >= ?", window.From)
+	}
+	if !window.Before.IsZero() {
+		query = query.Where(column+" < ?", window.Before)
+	}
+	query = query.Order(column + " DESC")
+	if window.Limit > 0 {
+		query = query.Limit(window.Limit)
+	}
+	return query
+}
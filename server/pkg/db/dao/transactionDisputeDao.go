@@ -2,6 +2,7 @@ package dao
 
 import (
 	"errors"
+	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"strings"
 	"time"
@@ -24,6 +25,15 @@ type TransactionDisputeDao struct {
 	VoidCreditTransaction        *string    `gorm:"column:void_credit_transaction"`
 	CreditedAt                   *time.Time `gorm:"column:credited_at"`
 	VoidedAt                     *time.Time `gorm:"column:voided_at"`
+	// Amount of the disputed transaction, which is what a provisional credit pays back
+	AmountCents                  *int64     `gorm:"column:amount_cents"`
+	ProvisionalCreditDueAt       *time.Time `gorm:"column:provisional_credit_due_at"`
+	ResolutionDueAt              *time.Time `gorm:"column:resolution_due_at"`
+	ProvisionalCreditEscalatedAt *time.Time `gorm:"column:provisional_credit_escalated_at"`
+	ResolutionEscalatedAt        *time.Time `gorm:"column:resolution_escalated_at"`
+	ResolvedBy                   *string    `gorm:"column:resolved_by"`
+	ResolvedAt                   *time.Time `gorm:"column:resolved_at"`
+	ResolutionReason             *string    `gorm:"column:resolution_reason"`
 }
 
 func (TransactionDisputeDao) TableName() string {
@@ -69,12 +79,64 @@ func (TransactionDisputeDao) FindById(disputeId string) (*TransactionDisputeDao,
 	return &transactionDisputeRecord, nil
 }
 
+// FindByIdForUser returns the dispute only if it was submitted by the user
+func (TransactionDisputeDao) FindByIdForUser(disputeId string, userId string) (*TransactionDisputeDao, error) {
+	var transactionDisputeRecord TransactionDisputeDao
+	result := db.DB.Where("id = ? AND user_id = ?", disputeId, userId).Take(&transactionDisputeRecord)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &transactionDisputeRecord, nil
+}
+
+func (TransactionDisputeDao) FindByUserId(userId string) ([]TransactionDisputeDao, error) {
+	var records []TransactionDisputeDao
+	err := db.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&records).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return records, nil
+}
+
+// FindNearingDeadline returns disputes with a Reg E deadline at or before dueBy that
+// has not been escalated yet. The provisional credit deadline only applies until the
+// customer is credited.
+func (TransactionDisputeDao) FindNearingDeadline(dueBy time.Time) ([]TransactionDisputeDao, error) {
+	var records []TransactionDisputeDao
+	err := db.DB.Where(
+		"(status IN (?) AND provisional_credit_due_at <= ? AND provisional_credit_escalated_at IS NULL) OR (status IN (?) AND resolution_due_at <= ? AND resolution_escalated_at IS NULL)",
+		[]string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW}, dueBy,
+		[]string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW, constant.DISPUTE_PROVISIONALLY_CREDITED}, dueBy,
+	).Order("created_at").Find(&records).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return records, nil
+}
+
+// MarkEscalated records that a deadline was escalated, reporting false when another
+// run already did so. column is provisional_credit_escalated_at or resolution_escalated_at.
+func (TransactionDisputeDao) MarkEscalated(id string, column string, now time.Time) (bool, error) {
+	result := db.DB.Model(&TransactionDisputeDao{}).
+		Where("id = ? AND "+column+" IS NULL", id).
+		UpdateColumn(column, now)
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
 type DisputeWithUser struct {
 	Dispute TransactionDisputeDao `gorm:"embedded"`
 	User    MasterUserRecordDao   `gorm:"embedded"`
 }
 
-func (TransactionDisputeDao) SearchDisputes(searchTerm string, disputeStatus string, limit int, offset int) ([]DisputeWithUser, int64, error) {
+// SearchDisputes returns disputes in any of disputeStatuses, or in any status when it is
+// empty, with the nearest resolution deadline first
+func (TransactionDisputeDao) SearchDisputes(searchTerm string, disputeStatuses []string, limit int, offset int) ([]DisputeWithUser, int64, error) {
 	var disputeUsers []DisputeWithUser
 	var totalCount int64
 
@@ -82,8 +144,8 @@ func (TransactionDisputeDao) SearchDisputes(searchTerm string, disputeStatus str
 		Select("dispute_record.*, user_record.*").
 		Joins("INNER JOIN master_user_records AS user_record ON dispute_record.user_id = user_record.id")
 
-	if disputeStatus != "" {
-		query = query.Where("dispute_record.status = ?", disputeStatus)
+	if len(disputeStatuses) > 0 {
+		query = query.Where("dispute_record.status IN (?)", disputeStatuses)
 	}
 
 	if searchTerm != "" {
@@ -159,7 +221,7 @@ func (TransactionDisputeDao) SearchDisputes(searchTerm string, disputeStatus str
 		return nil, 0, errtrace.Wrap(err)
 	}
 
-	if err := query.Order("dispute_record.created_at DESC").Limit(limit).Offset(offset).Scan(&disputeUsers).Error; err != nil {
+	if err := query.Order("dispute_record.resolution_due_at, dispute_record.created_at DESC").Limit(limit).Offset(offset).Scan(&disputeUsers).Error; err != nil {
 		return nil, 0, errtrace.Wrap(err)
 	}
 
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// TransactionDisputeEventDao is the history of a dispute: its status changes, the ledger
+// entries made for it, escalations and customer notifications.
+type TransactionDisputeEventDao struct {
+	Id                   uint64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	TransactionDisputeId string `json:"transactionDisputeId" gorm:"column:transaction_dispute_id"`
+	Action               string `json:"action" gorm:"column:action"`
+	// The dispute's status after the event, nil for events that did not change it
+	Status    *string   `json:"status" gorm:"column:status"`
+	Actor     string    `json:"actor" gorm:"column:actor"`
+	Detail    *string   `json:"detail" gorm:"column:detail"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (TransactionDisputeEventDao) TableName() string {
+	return "transaction_dispute_events"
+}
+
+const (
+	DISPUTE_EVENT_SUBMITTED                    = "submitted"
+	DISPUTE_EVENT_REVIEW_STARTED               = "review_started"
+	DISPUTE_EVENT_PROVISIONALLY_CREDITED       = "provisionally_credited"
+	DISPUTE_EVENT_RESOLVED_CUSTOMER            = "resolved_customer"
+	DISPUTE_EVENT_RESOLVED_MERCHANT            = "resolved_merchant"
+	DISPUTE_EVENT_WITHDRAWN                    = "withdrawn"
+	DISPUTE_EVENT_CREDIT_ISSUED                = "ledger_credit_issued"
+	DISPUTE_EVENT_CREDIT_FAILED                = "ledger_credit_failed"
+	DISPUTE_EVENT_CREDIT_REVERSED              = "ledger_credit_reversed"
+	DISPUTE_EVENT_CREDIT_REVERSAL_FAILED       = "ledger_credit_reversal_failed"
+	DISPUTE_EVENT_PROVISIONAL_CREDIT_ESCALATED = "provisional_credit_deadline_escalated"
+	DISPUTE_EVENT_RESOLUTION_ESCALATED         = "resolution_deadline_escalated"
+	DISPUTE_EVENT_NOTIFICATION_QUEUED          = "customer_notification_queued"
+	DISPUTE_EVENT_NOTIFICATION_FAILED          = "customer_notification_failed"
+	DISPUTE_EVENT_MESSAGE_ADDED                = "message_added"
+	DISPUTE_EVENT_EVIDENCE_UPLOADED            = "evidence_uploaded"
+)
+
+// Actors for events not performed by an operator
+const (
+	DISPUTE_ACTOR_CUSTOMER = "customer"
+	DISPUTE_ACTOR_SYSTEM   = "system"
+)
+
+func (TransactionDisputeEventDao) Create(tx *gorm.DB, transactionDisputeId, action string, status *string, actor string, detail *string, now time.Time) error {
+	event := TransactionDisputeEventDao{
+		TransactionDisputeId: transactionDisputeId,
+		Action:               action,
+		Status:               status,
+		Actor:                actor,
+		Detail:               detail,
+		CreatedAt:            now,
+	}
+	return errtrace.Wrap(tx.Create(&event).Error)
+}
+
+// FindByTransactionDisputeId returns the dispute's events in the order they happened
+func (TransactionDisputeEventDao) FindByTransactionDisputeId(transactionDisputeId string) ([]TransactionDisputeEventDao, error) {
+	var events []TransactionDisputeEventDao
+	err := db.DB.Where("transaction_dispute_id = ?", transactionDisputeId).Order("created_at, id").Find(&events).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
+
+// TransactionDisputeTimelineEvent is a dispute event along with the transaction disputed
+type TransactionDisputeTimelineEvent struct {
+	TransactionDisputeEventDao
+	TransactionIdentifier string `gorm:"column:transaction_identifier"`
+}
+
+// FindForTimeline returns the events of all the user's disputes inside the window, newest
+// first
+func (TransactionDisputeEventDao) FindForTimeline(userId string, window TimelineWindow) ([]TransactionDisputeTimelineEvent, error) {
+	var events []TransactionDisputeTimelineEvent
+	query := db.DB.Table("transaction_dispute_events").
+		Select("transaction_dispute_events.*, transaction_disputes.transaction_identifier").
+		Joins("JOIN transaction_disputes ON transaction_disputes.id = transaction_dispute_events.transaction_dispute_id").
+		Where("transaction_disputes.user_id = ?", userId)
+	err := window.apply(query, "transaction_dispute_events.created_at").Scan(&events).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// TransactionDisputeEvidenceDao is a file, such as a receipt or screenshot, uploaded to
+// S3 in support of a dispute
+type TransactionDisputeEvidenceDao struct {
+	Id                   string    `gorm:"column:id;primaryKey"`
+	TransactionDisputeId string    `gorm:"column:transaction_dispute_id"`
+	FileName             string    `gorm:"column:file_name"`
+	ContentType          string    `gorm:"column:content_type"`
+	SizeBytes            int64     `gorm:"column:size_bytes"`
+	S3Key                string    `gorm:"column:s3_key"`
+	UploadedBy           string    `gorm:"column:uploaded_by"`
+	CreatedAt            time.Time `gorm:"column:created_at"`
+}
+
+func (TransactionDisputeEvidenceDao) TableName() string {
+	return "transaction_dispute_evidence"
+}
+
+func (TransactionDisputeEvidenceDao) Create(tx *gorm.DB, evidence *TransactionDisputeEvidenceDao) error {
+	return errtrace.Wrap(tx.Create(evidence).Error)
+}
+
+// FindByTransactionDisputeId returns the dispute's evidence in the order it was uploaded
+func (TransactionDisputeEvidenceDao) FindByTransactionDisputeId(transactionDisputeId string) ([]TransactionDisputeEvidenceDao, error) {
+	var evidence []TransactionDisputeEvidenceDao
+	err := db.DB.Where("transaction_dispute_id = ?", transactionDisputeId).Order("created_at, id").Find(&evidence).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return evidence, nil
+}
+
+func (TransactionDisputeEvidenceDao) CountByTransactionDisputeId(transactionDisputeId string) (int, error) {
+	var count int
+	err := db.DB.Model(&TransactionDisputeEvidenceDao{}).Where("transaction_dispute_id = ?", transactionDisputeId).Count(&count).Error
+	if err != nil {
+		return 0, errtrace.Wrap(err)
+	}
+	return count, nil
+}
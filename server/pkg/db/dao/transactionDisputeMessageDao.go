@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// TransactionDisputeMessageDao is a message added to a dispute after it was submitted
+type TransactionDisputeMessageDao struct {
+	Id                   uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	TransactionDisputeId string `gorm:"column:transaction_dispute_id"`
+	// DISPUTE_ACTOR_CUSTOMER, or the email of the operator who wrote the message
+	Author    string    `gorm:"column:author"`
+	Body      string    `gorm:"column:body"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (TransactionDisputeMessageDao) TableName() string {
+	return "transaction_dispute_messages"
+}
+
+func (TransactionDisputeMessageDao) Create(tx *gorm.DB, transactionDisputeId, author, body string, now time.Time) (*TransactionDisputeMessageDao, error) {
+	message := TransactionDisputeMessageDao{
+		TransactionDisputeId: transactionDisputeId,
+		Author:               author,
+		Body:                 body,
+		CreatedAt:            now,
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return &message, nil
+}
+
+// FindByTransactionDisputeId returns the dispute's messages, oldest first
+func (TransactionDisputeMessageDao) FindByTransactionDisputeId(transactionDisputeId string) ([]TransactionDisputeMessageDao, error) {
+	var messages []TransactionDisputeMessageDao
+	err := db.DB.Where("transaction_dispute_id = ?", transactionDisputeId).Order("created_at, id").Find(&messages).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return messages, nil
+}
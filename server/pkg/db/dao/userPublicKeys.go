@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"process-api/pkg/config"
 	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"process-api/pkg/model/response"
@@ -14,21 +15,45 @@ import (
 )
 
 type UserPublicKey struct {
-	ID                 uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserId             string    `json:"userId" gorm:"column:user_id;size:36;not null;index"`
-	KeyId              string    `json:"keyId" gorm:"size:50;not null;column:key_id" mask:"true"`
-	ApiKey             []byte    `json:"apiKey" gorm:"column:encrypted_api_key" mask:"true"`
-	KmsEncryptedApiKey []byte    `json:"kmsEncryptedApiKey" gorm:"column:kms_encrypted_api_key" mask:"true"`
-	PublicKey          string    `json:"publicKey" gorm:"column:public_key;size:255;not null;uniqueIndex" mask:"true"`
-	CreatedAt          time.Time `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt          time.Time `json:"updatedAt" gorm:"column:updated_at;autoUpdateTime"`
+	ID                 uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserId             string     `json:"userId" gorm:"column:user_id;size:36;not null;index"`
+	KeyId              string     `json:"keyId" gorm:"size:50;not null;column:key_id" mask:"true"`
+	ApiKey             []byte     `json:"apiKey" gorm:"column:encrypted_api_key" mask:"true"`
+	KmsEncryptedApiKey []byte     `json:"kmsEncryptedApiKey" gorm:"column:kms_encrypted_api_key" mask:"true"`
+	PublicKey          string     `json:"publicKey" gorm:"column:public_key;size:255;not null;uniqueIndex" mask:"true"`
+	DeviceName         *string    `json:"deviceName" gorm:"column:device_name"`
+	DevicePlatform     *string    `json:"devicePlatform" gorm:"column:device_platform"`
+	RegisteredIP       *string    `json:"registeredIp" gorm:"column:registered_ip"`
+	LastUsedAt         *time.Time `json:"lastUsedAt" gorm:"column:last_used_at"`
+	LastUsedIP         *string    `json:"lastUsedIp" gorm:"column:last_used_ip"`
+	RevokedAt          *time.Time `json:"revokedAt" gorm:"column:revoked_at"`
+	RevokedBy          *string    `json:"revokedBy" gorm:"column:revoked_by"`
+	CreatedAt          time.Time  `json:"createdAt" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time  `json:"updatedAt" gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (UserPublicKey) TableName() string {
 	return "user_public_keys"
 }
 
+func (k UserPublicKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// FindUserPublicRecord only returns keys that have not been revoked, so a revoked
+// device is treated as unregistered on login.
 func (UserPublicKey) FindUserPublicRecord(userId, publicKey string) (*UserPublicKey, error) {
+	userPublicKey, err := UserPublicKey{}.FindUserPublicRecordIncludingRevoked(userId, publicKey)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if userPublicKey == nil || userPublicKey.IsRevoked() {
+		return nil, nil
+	}
+	return userPublicKey, nil
+}
+
+func (UserPublicKey) FindUserPublicRecordIncludingRevoked(userId, publicKey string) (*UserPublicKey, error) {
 	var userPublicKey UserPublicKey
 	result := db.DB.Model(UserPublicKey{}).Where("user_id = ? AND public_key = ?", userId, publicKey).Take(&userPublicKey)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -40,14 +65,19 @@ func (UserPublicKey) FindUserPublicRecord(userId, publicKey string) (*UserPublic
 	return &userPublicKey, nil
 }
 
+// RequireUserPublicKey refuses revoked keys, which prevents payloads signed by a
+// revoked device from being forwarded to the ledger.
 func RequireUserPublicKey(userId, publicKey string) (*UserPublicKey, *response.ErrorResponse) {
-	userPublicKey, err := UserPublicKey{}.FindUserPublicRecord(userId, publicKey)
+	userPublicKey, err := UserPublicKey{}.FindUserPublicRecordIncludingRevoked(userId, publicKey)
 	if err != nil {
 		return nil, &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("DB Error: %s", err), MaybeInnerError: errtrace.Wrap(err)}
 	}
 	if userPublicKey == nil {
 		return nil, &response.ErrorResponse{ErrorCode: constant.NO_DATA_FOUND, StatusCode: http.StatusNotFound, LogMessage: "userPublicKey record not found in DB", MaybeInnerError: errtrace.New("")}
 	}
+	if userPublicKey.IsRevoked() {
+		return nil, &response.ErrorResponse{ErrorCode: constant.DEVICE_REVOKED, Message: constant.DEVICE_REVOKED_MSG, StatusCode: http.StatusUnauthorized, LogMessage: fmt.Sprintf("userPublicKey %d was revoked at %s", userPublicKey.ID, userPublicKey.RevokedAt), MaybeInnerError: errtrace.New("")}
+	}
 	return userPublicKey, nil
 }
 
@@ -56,3 +86,115 @@ func (UserPublicKey) FindAll() ([]UserPublicKey, error) {
 	err := db.DB.Find(&records).Error
 	return records, errtrace.Wrap(err)
 }
+
+// FindActiveByUserId returns the user's non-revoked devices, most recently used first
+func (UserPublicKey) FindActiveByUserId(userId string) ([]UserPublicKey, error) {
+	var records []UserPublicKey
+	err := db.DB.Where("user_id = ? AND revoked_at IS NULL", userId).Order("COALESCE(last_used_at, created_at) DESC").Find(&records).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return records, nil
+}
+
+// FindByUserId returns all of the user's devices, including revoked ones, newest first
+func (UserPublicKey) FindByUserId(userId string) ([]UserPublicKey, error) {
+	var records []UserPublicKey
+	err := db.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&records).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return records, nil
+}
+
+func (UserPublicKey) FindByIdForUser(id uint64, userId string) (*UserPublicKey, error) {
+	var userPublicKey UserPublicKey
+	result := db.DB.Where("id = ? AND user_id = ?", id, userId).Take(&userPublicKey)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &userPublicKey, nil
+}
+
+func (UserPublicKey) CountActiveByUserId(tx *gorm.DB, userId string) (int, error) {
+	var count int
+	err := tx.Model(UserPublicKey{}).Where("user_id = ? AND revoked_at IS NULL", userId).Count(&count).Error
+	return count, errtrace.Wrap(err)
+}
+
+func (UserPublicKey) TouchLastUsed(id uint64, ip string, now time.Time) error {
+	err := db.DB.Model(UserPublicKey{}).Where("id = ?", id).Updates(map[string]any{"last_used_at": now, "last_used_ip": ip}).Error
+	return errtrace.Wrap(err)
+}
+
+// Revoke marks the key as revoked. revokedBy is "customer" for self-service
+// revocation or the operator's email for revocations done by ops.
+func (UserPublicKey) Revoke(id uint64, revokedBy string, now time.Time) error {
+	err := db.DB.Model(UserPublicKey{}).Where("id = ? AND revoked_at IS NULL", id).Updates(map[string]any{"revoked_at": now, "revoked_by": revokedBy}).Error
+	return errtrace.Wrap(err)
+}
+
+// DeviceLimitForUser returns how many active devices the user may have, where 0 means
+// no limit
+func DeviceLimitForUser(user *MasterUserRecordDao) int {
+	if user.DeviceLimit != nil {
+		return *user.DeviceLimit
+	}
+	return config.Config.Devices.DefaultDeviceLimit
+}
+
+func requireBelowDeviceLimit(tx *gorm.DB, user *MasterUserRecordDao) error {
+	limit := DeviceLimitForUser(user)
+	if limit <= 0 {
+		return nil
+	}
+	count, err := UserPublicKey{}.CountActiveByUserId(tx, user.Id)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if count >= limit {
+		return errtrace.Wrap(&response.ErrorResponse{ErrorCode: constant.DEVICE_LIMIT_REACHED, Message: constant.DEVICE_LIMIT_REACHED_MSG, StatusCode: http.StatusConflict, LogMessage: fmt.Sprintf("user already has %d active devices", count), MaybeInnerError: errtrace.New("")})
+	}
+	return nil
+}
+
+// RequireBelowDeviceLimit refuses users who already have as many active devices as
+// their limit allows. Registration checks it up front, before any OTP is used up or key
+// is issued, and CreateUserPublicKey checks it again when the key is stored.
+func RequireBelowDeviceLimit(user *MasterUserRecordDao) *response.ErrorResponse {
+	err := requireBelowDeviceLimit(db.DB, user)
+	if err != nil {
+		var errResponse *response.ErrorResponse
+		if errors.As(err, &errResponse) {
+			return errResponse
+		}
+		return &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Failed to count active devices: %s", err), MaybeInnerError: errtrace.Wrap(err)}
+	}
+	return nil
+}
+
+// CreateUserPublicKey inserts a newly registered device key, refusing the insert
+// when the user already has as many active devices as their limit allows.
+func CreateUserPublicKey(user *MasterUserRecordDao, userPublicKey *UserPublicKey) *response.ErrorResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize concurrent registrations for the same user so the limit holds
+		if err := tx.Exec("SELECT id FROM master_user_records WHERE id = ? FOR UPDATE", user.Id).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		if err := requireBelowDeviceLimit(tx, user); err != nil {
+			return errtrace.Wrap(err)
+		}
+		return errtrace.Wrap(tx.Create(userPublicKey).Error)
+	})
+	if err != nil {
+		var errResponse *response.ErrorResponse
+		if errors.As(err, &errResponse) {
+			return errResponse
+		}
+		return &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Failed to create userPublicKey: %s", err), MaybeInnerError: errtrace.Wrap(err)}
+	}
+	return nil
+}
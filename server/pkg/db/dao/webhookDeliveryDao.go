@@ -0,0 +1,171 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+)
+
+// WebhookDeliveryDao is an event to be delivered to one subscription. A delivery is retried
+// until the subscriber accepts it or it runs out of attempts and is dead.
+type WebhookDeliveryDao struct {
+	Id             string     `gorm:"column:id;primaryKey"`
+	EventId        string     `gorm:"column:event_id"`
+	SubscriptionId string     `gorm:"column:subscription_id"`
+	Status         string     `gorm:"column:status"`
+	Attempts       int        `gorm:"column:attempts"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at"`
+}
+
+func (WebhookDeliveryDao) TableName() string {
+	return "webhook_deliveries"
+}
+
+const (
+	WEBHOOK_DELIVERY_PENDING   = "pending"
+	WEBHOOK_DELIVERY_DELIVERED = "delivered"
+	WEBHOOK_DELIVERY_DEAD      = "dead"
+)
+
+var WebhookDeliveryStatuses = []string{
+	WEBHOOK_DELIVERY_PENDING,
+	WEBHOOK_DELIVERY_DELIVERED,
+	WEBHOOK_DELIVERY_DEAD,
+}
+
+// WebhookDeliveryAttemptDao is one request of a delivery to the subscriber
+type WebhookDeliveryAttemptDao struct {
+	Id          uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	DeliveryId  string    `gorm:"column:delivery_id"`
+	AttemptedAt time.Time `gorm:"column:attempted_at"`
+	DurationMs  int64     `gorm:"column:duration_ms"`
+	// The subscriber's response status, nil when no response was received
+	ResponseStatus *int    `gorm:"column:response_status"`
+	ResponseBody   *string `gorm:"column:response_body"`
+	Error          *string `gorm:"column:error"`
+}
+
+func (WebhookDeliveryAttemptDao) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+func (WebhookDeliveryDao) Create(tx *gorm.DB, delivery *WebhookDeliveryDao) error {
+	return errtrace.Wrap(tx.Create(delivery).Error)
+}
+
+func (WebhookDeliveryDao) FindById(id string) (*WebhookDeliveryDao, error) {
+	var delivery WebhookDeliveryDao
+	result := db.DB.Where("id = ?", id).Take(&delivery)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &delivery, nil
+}
+
+// RecordAttempt saves an attempt of the delivery and moves the delivery to status
+func (WebhookDeliveryDao) RecordAttempt(attempt WebhookDeliveryAttemptDao, status string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		columns := map[string]any{
+			"status":     status,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": now,
+		}
+		if status == WEBHOOK_DELIVERY_DELIVERED {
+			columns["delivered_at"] = now
+		}
+		return errtrace.Wrap(tx.Model(&WebhookDeliveryDao{}).Where("id = ?", attempt.DeliveryId).Updates(columns).Error)
+	}))
+}
+
+// MarkDead gives up on the delivery without attempting it again, unless it was already
+// delivered or given up on
+func (WebhookDeliveryDao) MarkDead(id string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Model(&WebhookDeliveryDao{}).Where("id = ? AND status = ?", id, WEBHOOK_DELIVERY_PENDING).Updates(map[string]any{
+		"status":     WEBHOOK_DELIVERY_DEAD,
+		"updated_at": now,
+	}).Error)
+}
+
+// Requeue moves a dead delivery back to pending within tx, reporting whether it was dead
+func (WebhookDeliveryDao) Requeue(tx *gorm.DB, id string, now time.Time) (bool, error) {
+	result := tx.Model(&WebhookDeliveryDao{}).Where("id = ? AND status = ?", id, WEBHOOK_DELIVERY_DEAD).Updates(map[string]any{
+		"status":     WEBHOOK_DELIVERY_PENDING,
+		"updated_at": now,
+	})
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindAttempts returns the delivery's attempts in the order they were made
+func (WebhookDeliveryDao) FindAttempts(deliveryId string) ([]WebhookDeliveryAttemptDao, error) {
+	var attempts []WebhookDeliveryAttemptDao
+	if err := db.DB.Where("delivery_id = ?", deliveryId).Order("attempted_at, id").Find(&attempts).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return attempts, nil
+}
+
+// WebhookDeliveryFilter narrows a search of deliveries. Zero values do not filter.
+type WebhookDeliveryFilter struct {
+	SubscriptionId string
+	Status         string
+	EventType      string
+	UserId         string
+}
+
+// WebhookDeliveryListItem is a delivery along with its event and subscription
+type WebhookDeliveryListItem struct {
+	WebhookDeliveryDao
+	EventType        string  `gorm:"column:event_type"`
+	UserId           *string `gorm:"column:user_id"`
+	SubscriptionName string  `gorm:"column:subscription_name"`
+}
+
+func (filter WebhookDeliveryFilter) apply(query *gorm.DB) *gorm.DB {
+	query = query.Joins("JOIN webhook_events ON webhook_events.id = webhook_deliveries.event_id").
+		Joins("JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id")
+	if filter.SubscriptionId != "" {
+		query = query.Where("webhook_deliveries.subscription_id = ?", filter.SubscriptionId)
+	}
+	if filter.Status != "" {
+		query = query.Where("webhook_deliveries.status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("webhook_events.event_type = ?", filter.EventType)
+	}
+	if filter.UserId != "" {
+		query = query.Where("webhook_events.user_id = ?", filter.UserId)
+	}
+	return query
+}
+
+// Search returns a page of the deliveries matching the filter, newest first, along with
+// the number of deliveries matching it
+func (WebhookDeliveryDao) Search(filter WebhookDeliveryFilter, limit int, offset int) ([]WebhookDeliveryListItem, int64, error) {
+	var deliveries []WebhookDeliveryListItem
+	var totalCount int64
+
+	query := filter.apply(db.DB.Table("webhook_deliveries"))
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	err := query.Select("webhook_deliveries.*, webhook_events.event_type, webhook_events.user_id, webhook_subscriptions.name AS subscription_name").
+		Order("webhook_deliveries.created_at DESC, webhook_deliveries.id").Limit(limit).Offset(offset).Scan(&deliveries).Error
+	if err != nil {
+		return nil, 0, errtrace.Wrap(err)
+	}
+	return deliveries, totalCount, nil
+}
@@ -0,0 +1,137 @@
+package dao
+
+import (
+	"encoding/json"
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+// WebhookEventDao is an entry of the outbox of events for webhook subscribers. It is
+// written in the same transaction as the change it describes, so subscribers are told
+// about every committed change and nothing else.
+type WebhookEventDao struct {
+	Id        string  `gorm:"column:id;primaryKey"`
+	EventType string  `gorm:"column:event_type"`
+	UserId    *string `gorm:"column:user_id"`
+	// JSON object describing the change, sent to subscribers as the event's data
+	Data         string     `gorm:"column:data"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	DispatchedAt *time.Time `gorm:"column:dispatched_at"`
+}
+
+func (WebhookEventDao) TableName() string {
+	return "webhook_events"
+}
+
+// Event types webhook subscribers can subscribe to
+const (
+	WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED      = "customer.status_changed"
+	WEBHOOK_EVENT_DISPUTE_UPDATED              = "dispute.updated"
+	WEBHOOK_EVENT_TRANSFER_RETURNED            = "transfer.returned"
+	WEBHOOK_EVENT_CARD_LOCKED                  = "card.locked"
+	WEBHOOK_EVENT_DEMOGRAPHIC_UPDATE_SUBMITTED = "demographic_update.submitted"
+)
+
+var WebhookEventTypes = []string{
+	WEBHOOK_EVENT_CUSTOMER_STATUS_CHANGED,
+	WEBHOOK_EVENT_DISPUTE_UPDATED,
+	WEBHOOK_EVENT_TRANSFER_RETURNED,
+	WEBHOOK_EVENT_CARD_LOCKED,
+	WEBHOOK_EVENT_DEMOGRAPHIC_UPDATE_SUBMITTED,
+}
+
+// The data of each event type, as subscribers receive it
+
+type CustomerStatusChangedEventData struct {
+	UserId         string `json:"userId"`
+	PreviousStatus string `json:"previousStatus"`
+	Status         string `json:"status"`
+}
+
+type DisputeUpdatedEventData struct {
+	UserId    string `json:"userId"`
+	DisputeId string `json:"disputeId"`
+	// The ledger reference id of the disputed transaction
+	TransactionReferenceId string `json:"transactionReferenceId"`
+	PreviousStatus         string `json:"previousStatus,omitempty"`
+	Status                 string `json:"status"`
+}
+
+type TransferReturnedEventData struct {
+	UserId            string `json:"userId"`
+	EventId           string `json:"eventId"`
+	TransactionNumber string `json:"transactionNumber"`
+	AmountCents       int    `json:"amountCents"`
+	Currency          string `json:"currency"`
+	Channel           string `json:"channel"`
+}
+
+type CardLockedEventData struct {
+	UserId string `json:"userId"`
+	CardId string `json:"cardId"`
+	// Where the card was locked from, one of the CARD_LOCKED_VIA values
+	Via string `json:"via"`
+}
+
+const (
+	CARD_LOCKED_VIA_APP        = "app"
+	CARD_LOCKED_VIA_SALESFORCE = "salesforce"
+)
+
+type DemographicUpdateSubmittedEventData struct {
+	UserId              string `json:"userId"`
+	DemographicUpdateId string `json:"demographicUpdateId"`
+	Type                string `json:"type"`
+}
+
+// Create writes an event to the outbox within tx. data is sent to subscribers as JSON, so
+// it should not hold PII beyond the ids a subscriber needs to look the change up.
+func (WebhookEventDao) Create(tx *gorm.DB, eventType string, userId string, data any, now time.Time) error {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	event := WebhookEventDao{
+		Id:        uuid.New().String(),
+		EventType: eventType,
+		Data:      string(dataJSON),
+		CreatedAt: now,
+	}
+	if userId != "" {
+		event.UserId = &userId
+	}
+	return errtrace.Wrap(tx.Create(&event).Error)
+}
+
+// LockUndispatched returns up to limit of the oldest events not yet fanned out to
+// subscriptions, locking them for the rest of tx. Locked events are skipped, so dispatchers
+// running at once do not dispatch an event twice.
+func (WebhookEventDao) LockUndispatched(tx *gorm.DB, limit int) ([]WebhookEventDao, error) {
+	var events []WebhookEventDao
+	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").Where("dispatched_at IS NULL").Order("created_at, id").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return events, nil
+}
+
+func (WebhookEventDao) MarkDispatched(tx *gorm.DB, ids []string, now time.Time) error {
+	return errtrace.Wrap(tx.Model(&WebhookEventDao{}).Where("id IN (?)", ids).Update("dispatched_at", now).Error)
+}
+
+func (WebhookEventDao) FindById(id string) (*WebhookEventDao, error) {
+	var event WebhookEventDao
+	result := db.DB.Where("id = ?", id).Take(&event)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &event, nil
+}
@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"errors"
+	"process-api/pkg/db"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// WebhookSubscriptionDao is an internal consumer, such as Salesforce, that is sent the
+// events of the types it subscribed to
+type WebhookSubscriptionDao struct {
+	Id   string `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+	Url  string `gorm:"column:url"`
+	// KMS encrypted secret the deliveries are signed with
+	Secret     []byte         `gorm:"column:secret"`
+	EventTypes pq.StringArray `gorm:"column:event_types;type:text[]"`
+	CreatedBy  string         `gorm:"column:created_by"`
+	CreatedAt  time.Time      `gorm:"column:created_at"`
+	DisabledAt *time.Time     `gorm:"column:disabled_at"`
+	DisabledBy *string        `gorm:"column:disabled_by"`
+}
+
+func (WebhookSubscriptionDao) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (WebhookSubscriptionDao) Create(subscription *WebhookSubscriptionDao) error {
+	return errtrace.Wrap(db.DB.Create(subscription).Error)
+}
+
+func (WebhookSubscriptionDao) FindById(id string) (*WebhookSubscriptionDao, error) {
+	var subscription WebhookSubscriptionDao
+	result := db.DB.Where("id = ?", id).Take(&subscription)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, errtrace.Wrap(result.Error)
+	}
+	return &subscription, nil
+}
+
+// FindAll returns every subscription, disabled ones included, newest first
+func (WebhookSubscriptionDao) FindAll() ([]WebhookSubscriptionDao, error) {
+	var subscriptions []WebhookSubscriptionDao
+	if err := db.DB.Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return subscriptions, nil
+}
+
+// FindEnabled returns the subscriptions that are not disabled
+func (WebhookSubscriptionDao) FindEnabled(tx *gorm.DB) ([]WebhookSubscriptionDao, error) {
+	var subscriptions []WebhookSubscriptionDao
+	if err := tx.Where("disabled_at IS NULL").Find(&subscriptions).Error; err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return subscriptions, nil
+}
+
+// Disable stops sending events to the subscription, reporting whether it was enabled
+func (WebhookSubscriptionDao) Disable(id string, disabledBy string, now time.Time) (bool, error) {
+	result := db.DB.Model(&WebhookSubscriptionDao{}).Where("id = ? AND disabled_at IS NULL", id).Updates(map[string]any{
+		"disabled_at": now,
+		"disabled_by": disabledBy,
+	})
+	if result.Error != nil {
+		return false, errtrace.Wrap(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
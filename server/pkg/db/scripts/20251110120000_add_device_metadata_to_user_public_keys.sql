@@ -0,0 +1,28 @@
+-- +goose Up
+
+ALTER TABLE user_public_keys
+ADD COLUMN device_name varchar(100),
+ADD COLUMN device_platform varchar(20),
+ADD COLUMN registered_ip varchar(45),
+ADD COLUMN last_used_at timestamp with time zone,
+ADD COLUMN last_used_ip varchar(45),
+ADD COLUMN revoked_at timestamp with time zone,
+ADD COLUMN revoked_by varchar(255);
+
+-- NULL means the default limit from config applies
+ALTER TABLE master_user_records
+ADD COLUMN device_limit integer;
+
+-- +goose Down
+
+ALTER TABLE user_public_keys
+DROP COLUMN IF EXISTS device_name,
+DROP COLUMN IF EXISTS device_platform,
+DROP COLUMN IF EXISTS registered_ip,
+DROP COLUMN IF EXISTS last_used_at,
+DROP COLUMN IF EXISTS last_used_ip,
+DROP COLUMN IF EXISTS revoked_at,
+DROP COLUMN IF EXISTS revoked_by;
+
+ALTER TABLE master_user_records
+DROP COLUMN IF EXISTS device_limit;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+CREATE TABLE public.signature_uses (
+    id uuid NOT NULL PRIMARY KEY,
+    user_public_key_id bigint NOT NULL,
+    user_id uuid NOT NULL,
+    payload_id character varying(36) NOT NULL,
+    endpoint character varying(255) NOT NULL,
+    ip character varying(45),
+    is_valid boolean NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT signature_uses_user_public_key_id_fkey FOREIGN KEY (user_public_key_id) REFERENCES public.user_public_keys (id),
+    CONSTRAINT signature_uses_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.master_user_records (id)
+);
+
+CREATE INDEX signature_uses_user_public_key_id_idx ON public.signature_uses (user_public_key_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS public.signature_uses;
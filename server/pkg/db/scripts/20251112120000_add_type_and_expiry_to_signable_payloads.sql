@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- Payloads created before this migration have no type and cannot be consumed
+-- afterwards; they expire within minutes so no backfill is needed.
+ALTER TABLE signable_payloads
+ADD COLUMN payload_type varchar(50),
+ADD COLUMN expires_at timestamp with time zone;
+
+CREATE INDEX signable_payloads_unconsumed_expires_at_idx ON signable_payloads (expires_at) WHERE consumed_at IS NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS signable_payloads_unconsumed_expires_at_idx;
+
+ALTER TABLE signable_payloads
+DROP COLUMN IF EXISTS payload_type,
+DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+CREATE TABLE public.otp_send_events (
+    id uuid NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL,
+    mobile_no character varying(20),
+    country_prefix character varying(8),
+    ip character varying(45),
+    api_path character varying(255) NOT NULL,
+    otp_type character varying(10) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT otp_send_events_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.master_user_records (id)
+);
+
+CREATE INDEX otp_send_events_user_id_idx ON public.otp_send_events (user_id, created_at);
+CREATE INDEX otp_send_events_mobile_no_idx ON public.otp_send_events (mobile_no, created_at);
+CREATE INDEX otp_send_events_ip_idx ON public.otp_send_events (ip, created_at);
+CREATE INDEX otp_send_events_country_prefix_idx ON public.otp_send_events (country_prefix, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS public.otp_send_events;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE public.rate_limit_counters (
+    key character varying(255) NOT NULL,
+    window_start timestamp with time zone NOT NULL,
+    count integer NOT NULL DEFAULT 0,
+    expires_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX rate_limit_counters_expires_at_idx ON public.rate_limit_counters (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS public.rate_limit_counters;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE public.idempotency_keys (
+    user_id uuid NOT NULL,
+    key character varying(255) NOT NULL,
+    request_fingerprint character varying(64) NOT NULL,
+    response_status integer,
+    response_content_type character varying(255),
+    response_body bytea,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    completed_at timestamp with time zone,
+    expires_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (user_id, key),
+    CONSTRAINT idempotency_keys_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.master_user_records (id)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON public.idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS public.idempotency_keys;
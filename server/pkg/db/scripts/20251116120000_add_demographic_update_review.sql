@@ -0,0 +1,26 @@
+-- +goose Up
+
+ALTER TABLE public.demographic_updates
+    ADD COLUMN reviewed_by character varying(255),
+    ADD COLUMN reviewed_at timestamp with time zone,
+    ADD COLUMN review_reason text;
+
+CREATE TABLE public.demographic_update_events (
+    id bigserial PRIMARY KEY,
+    demographic_update_id uuid NOT NULL,
+    action character varying(64) NOT NULL,
+    actor character varying(255) NOT NULL,
+    detail text,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT demographic_update_events_demographic_update_id_fkey FOREIGN KEY (demographic_update_id) REFERENCES public.demographic_updates (id)
+);
+
+CREATE INDEX demographic_update_events_demographic_update_id_idx ON public.demographic_update_events (demographic_update_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS public.demographic_update_events;
+
+ALTER TABLE public.demographic_updates
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS review_reason;
@@ -0,0 +1,89 @@
+-- +goose Up
+ALTER TABLE transaction_disputes
+  ALTER COLUMN status TYPE text
+    USING (status::text);
+
+CREATE TYPE new_transaction_disputes_status AS ENUM (
+  'submitted',
+  'under_review',
+  'provisionally_credited',
+  'resolved_customer',
+  'resolved_merchant',
+  'withdrawn'
+);
+UPDATE transaction_disputes SET status = 'submitted' WHERE status = 'pending';
+UPDATE transaction_disputes SET status = 'provisionally_credited' WHERE status = 'credited';
+UPDATE transaction_disputes SET status = 'resolved_merchant' WHERE status IN ('rejected', 'voided');
+
+ALTER TABLE transaction_disputes
+  ADD COLUMN amount_cents bigint,
+  ADD COLUMN provisional_credit_due_at timestamptz,
+  ADD COLUMN resolution_due_at timestamptz,
+  ADD COLUMN provisional_credit_escalated_at timestamptz,
+  ADD COLUMN resolution_escalated_at timestamptz,
+  ADD COLUMN resolved_by character varying(255),
+  ADD COLUMN resolved_at timestamptz,
+  ADD COLUMN resolution_reason text,
+  ALTER COLUMN status TYPE new_transaction_disputes_status
+    USING (status::new_transaction_disputes_status);
+
+DROP TYPE transaction_disputes_status;
+ALTER TYPE new_transaction_disputes_status RENAME TO transaction_disputes_status;
+
+-- Reg E deadlines for disputes submitted before they were tracked. Only weekends are
+-- skipped here, so these land on or before the deadline the application computes.
+UPDATE transaction_disputes SET
+  provisional_credit_due_at = (
+    SELECT day FROM generate_series(created_at + interval '1 day', created_at + interval '30 days', interval '1 day') AS day
+    WHERE extract(isodow FROM day) < 6 ORDER BY day OFFSET 9 LIMIT 1
+  ),
+  resolution_due_at = (
+    SELECT day FROM generate_series(created_at + interval '1 day', created_at + interval '90 days', interval '1 day') AS day
+    WHERE extract(isodow FROM day) < 6 ORDER BY day OFFSET 44 LIMIT 1
+  );
+UPDATE transaction_disputes SET resolved_at = COALESCE(voided_at, updated_at) WHERE status = 'resolved_merchant';
+
+CREATE TABLE transaction_dispute_events (
+    id bigserial PRIMARY KEY,
+    transaction_dispute_id uuid NOT NULL,
+    action character varying(64) NOT NULL,
+    -- The dispute's status after the event, for events that changed it
+    status transaction_disputes_status,
+    actor character varying(255) NOT NULL,
+    detail text,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT transaction_dispute_events_transaction_dispute_id_fkey FOREIGN KEY (transaction_dispute_id) REFERENCES transaction_disputes (id)
+);
+
+CREATE INDEX transaction_dispute_events_transaction_dispute_id_idx ON transaction_dispute_events (transaction_dispute_id);
+
+INSERT INTO transaction_dispute_events (transaction_dispute_id, action, status, actor, created_at)
+  SELECT id, 'submitted', 'submitted', 'customer', created_at FROM transaction_disputes;
+
+-- +goose Down
+DROP TABLE IF EXISTS transaction_dispute_events;
+
+ALTER TABLE transaction_disputes
+  ALTER COLUMN status TYPE text
+    USING (status::text);
+
+CREATE TYPE old_transaction_disputes_status AS ENUM ('pending', 'credited', 'rejected', 'voided');
+UPDATE transaction_disputes SET status = 'pending' WHERE status IN ('submitted', 'under_review');
+UPDATE transaction_disputes SET status = 'credited' WHERE status IN ('provisionally_credited', 'resolved_customer');
+UPDATE transaction_disputes SET status = 'voided' WHERE status IN ('resolved_merchant', 'withdrawn') AND void_credit_transaction IS NOT NULL;
+UPDATE transaction_disputes SET status = 'rejected' WHERE status IN ('resolved_merchant', 'withdrawn');
+
+ALTER TABLE transaction_disputes
+  DROP COLUMN amount_cents,
+  DROP COLUMN provisional_credit_due_at,
+  DROP COLUMN resolution_due_at,
+  DROP COLUMN provisional_credit_escalated_at,
+  DROP COLUMN resolution_escalated_at,
+  DROP COLUMN resolved_by,
+  DROP COLUMN resolved_at,
+  DROP COLUMN resolution_reason,
+  ALTER COLUMN status TYPE old_transaction_disputes_status
+    USING (status::old_transaction_disputes_status);
+
+DROP TYPE transaction_disputes_status;
+ALTER TYPE old_transaction_disputes_status RENAME TO transaction_disputes_status;
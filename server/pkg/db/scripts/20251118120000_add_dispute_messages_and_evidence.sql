@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE transaction_dispute_messages (
+    id bigserial PRIMARY KEY,
+    transaction_dispute_id uuid NOT NULL,
+    -- "customer", or the email of the operator who wrote the message
+    author character varying(255) NOT NULL,
+    body text NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT transaction_dispute_messages_transaction_dispute_id_fkey FOREIGN KEY (transaction_dispute_id) REFERENCES transaction_disputes (id)
+);
+CREATE INDEX transaction_dispute_messages_transaction_dispute_id_idx ON transaction_dispute_messages (transaction_dispute_id);
+
+CREATE TABLE transaction_dispute_evidence (
+    id uuid PRIMARY KEY,
+    transaction_dispute_id uuid NOT NULL,
+    file_name character varying(255) NOT NULL,
+    content_type character varying(127) NOT NULL,
+    size_bytes bigint NOT NULL,
+    -- Key of the uploaded file in the S3 bucket
+    s3_key text NOT NULL,
+    uploaded_by character varying(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT transaction_dispute_evidence_transaction_dispute_id_fkey FOREIGN KEY (transaction_dispute_id) REFERENCES transaction_disputes (id)
+);
+CREATE INDEX transaction_dispute_evidence_transaction_dispute_id_idx ON transaction_dispute_evidence (transaction_dispute_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS transaction_dispute_evidence;
+DROP TABLE IF EXISTS transaction_dispute_messages;
@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE admin_audit_log (
+    id bigserial PRIMARY KEY,
+    -- Auth0 subject, email and roles of the operator
+    actor_id character varying(255) NOT NULL,
+    actor_email character varying(255) NOT NULL,
+    actor_roles text NOT NULL,
+    action character varying(64) NOT NULL,
+    entity_type character varying(64) NOT NULL,
+    entity_id character varying(255) NOT NULL,
+    -- The customer the change concerns, if any
+    user_id character varying(36),
+    -- Fields that changed, as {"field": {"before": ..., "after": ...}} with PII redacted
+    changes json NOT NULL,
+    ip character varying(64),
+    request_id character varying(64),
+    created_at timestamp with time zone NOT NULL,
+    -- Each entry's hash covers its fields and the previous entry's hash, so an edited,
+    -- removed or reordered entry breaks the chain
+    prev_hash character(64) NOT NULL,
+    hash character(64) NOT NULL UNIQUE
+);
+CREATE INDEX admin_audit_log_created_at_idx ON admin_audit_log (created_at);
+CREATE INDEX admin_audit_log_user_id_idx ON admin_audit_log (user_id);
+CREATE INDEX admin_audit_log_entity_idx ON admin_audit_log (entity_type, entity_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION admin_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER admin_audit_log_no_update_or_delete
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION admin_audit_log_append_only();
+
+CREATE TRIGGER admin_audit_log_no_truncate
+    BEFORE TRUNCATE ON admin_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION admin_audit_log_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_audit_log;
+DROP FUNCTION IF EXISTS admin_audit_log_append_only();
@@ -0,0 +1,30 @@
+-- +goose Up
+-- High-risk admin actions wait here until a second operator approves them
+CREATE TABLE admin_approval_requests (
+    id uuid PRIMARY KEY,
+    action character varying(64) NOT NULL,
+    entity_type character varying(64) NOT NULL,
+    entity_id character varying(255) NOT NULL,
+    -- The customer the action concerns, if any
+    user_id character varying(36),
+    -- The reason the requesting operator gave, passed to the action when it is taken
+    reason text,
+    -- The permission both operators must hold
+    permission character varying(64) NOT NULL,
+    status character varying(16) NOT NULL,
+    requested_by_id character varying(255) NOT NULL,
+    requested_by_email character varying(255) NOT NULL,
+    requested_at timestamp with time zone NOT NULL,
+    reviewed_by_id character varying(255),
+    reviewed_by_email character varying(255),
+    reviewed_at timestamp with time zone,
+    review_reason text,
+    -- Why the action failed after it was approved
+    error text
+);
+CREATE INDEX admin_approval_requests_status_idx ON admin_approval_requests (status, requested_at);
+-- An action can only be awaiting approval once per entity
+CREATE UNIQUE INDEX admin_approval_requests_pending_idx ON admin_approval_requests (action, entity_type, entity_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_approval_requests;
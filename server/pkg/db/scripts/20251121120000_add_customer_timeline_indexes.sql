@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- The customer activity timeline reads each user's newest events from these tables
+CREATE INDEX ledger_transaction_events_user_id_created_at_idx ON public.ledger_transaction_events (user_id, created_at);
+CREATE INDEX master_user_call_record_to_created_at_idx ON public.master_user_call_record ("to", created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS public.master_user_call_record_to_created_at_idx;
+DROP INDEX IF EXISTS public.ledger_transaction_events_user_id_created_at_idx;
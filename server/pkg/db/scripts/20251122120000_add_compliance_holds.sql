@@ -0,0 +1,27 @@
+-- +goose Up
+-- A compliance hold stops all money movement for a customer while their account stays
+-- viewable. Holds are never deleted; releasing one records who released it and why.
+CREATE TABLE compliance_holds (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES master_user_records (id),
+    reason text NOT NULL,
+    -- Where the hold was placed from: admin, salesforce
+    placed_via character varying(16) NOT NULL,
+    placed_by character varying(255) NOT NULL,
+    placed_at timestamp with time zone NOT NULL,
+    -- The hold lifts itself at this time, or stays until released when null
+    expires_at timestamp with time zone,
+    -- Set when the hold was mirrored to the ledger account status
+    ledger_mirrored_at timestamp with time zone,
+    -- Why mirroring the hold to, or releasing it from, the ledger failed
+    ledger_error text,
+    released_at timestamp with time zone,
+    released_by character varying(255),
+    release_reason text
+);
+-- A customer has at most one unreleased hold
+CREATE UNIQUE INDEX compliance_holds_unreleased_idx ON compliance_holds (user_id) WHERE released_at IS NULL;
+CREATE INDEX compliance_holds_user_id_idx ON compliance_holds (user_id, placed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS compliance_holds;
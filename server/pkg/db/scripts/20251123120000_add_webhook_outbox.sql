@@ -0,0 +1,63 @@
+-- +goose Up
+-- Internal consumers, such as Salesforce, subscribe to be told about changes to customers
+-- instead of polling for them
+CREATE TABLE webhook_subscriptions (
+    id uuid PRIMARY KEY,
+    name character varying(255) NOT NULL,
+    url text NOT NULL,
+    -- KMS encrypted secret the deliveries are signed with
+    secret bytea NOT NULL,
+    event_types text[] NOT NULL,
+    created_by character varying(255) NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    disabled_at timestamp with time zone,
+    disabled_by character varying(255)
+);
+
+-- The outbox: events are written in the same transaction as the change they describe and
+-- fanned out to the subscriptions for their type once committed
+CREATE TABLE webhook_events (
+    id uuid PRIMARY KEY,
+    event_type character varying(64) NOT NULL,
+    -- The customer the event concerns, if any
+    user_id character varying(36),
+    data json NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    dispatched_at timestamp with time zone
+);
+CREATE INDEX webhook_events_undispatched_idx ON webhook_events (created_at) WHERE dispatched_at IS NULL;
+
+-- An event to be delivered to one subscription
+CREATE TABLE webhook_deliveries (
+    id uuid PRIMARY KEY,
+    event_id uuid NOT NULL REFERENCES webhook_events (id),
+    subscription_id uuid NOT NULL REFERENCES webhook_subscriptions (id),
+    -- pending, delivered or, once it runs out of retries, dead
+    status character varying(16) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    delivered_at timestamp with time zone,
+    UNIQUE (event_id, subscription_id)
+);
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, created_at);
+CREATE INDEX webhook_deliveries_status_idx ON webhook_deliveries (status, created_at);
+
+CREATE TABLE webhook_delivery_attempts (
+    id bigserial PRIMARY KEY,
+    delivery_id uuid NOT NULL REFERENCES webhook_deliveries (id),
+    attempted_at timestamp with time zone NOT NULL,
+    duration_ms integer NOT NULL,
+    -- The subscriber's response status, null when no response was received
+    response_status integer,
+    -- The start of the subscriber's response body, or why no response was received
+    response_body text,
+    error text
+);
+CREATE INDEX webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id, attempted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Where /transactions/sync left off for the item; null until its first sync
+ALTER TABLE plaid_items ADD COLUMN transactions_cursor text;
+ALTER TABLE plaid_items ADD COLUMN transactions_synced_at timestamp with time zone;
+
+-- Transactions on the user's linked external accounts, kept in step with Plaid so the
+-- user's cash flow outside DreamFi can be seen alongside their DreamFi account
+CREATE TABLE plaid_transactions (
+    id                      uuid                     NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id                 character varying(36)    NOT NULL,
+    plaid_item_id           character varying(255)   NOT NULL,
+    plaid_account_id        text                     NOT NULL,
+    plaid_transaction_id    text                     NOT NULL,
+    -- Money into the account is positive and money out is negative, the opposite of Plaid
+    amount_cents            bigint                   NOT NULL,
+    iso_currency_code       character varying(3),
+    date                    date                     NOT NULL, -- posted, or for pending transactions, occurred
+    authorized_date         date,
+    name                    text                     NOT NULL,
+    merchant_name           text,
+    -- Plaid's personal finance category, e.g. INCOME and INCOME_WAGES
+    category_primary        text,
+    category_detailed       text,
+    payment_channel         text,
+    pending                 boolean                  NOT NULL,
+    -- For a posted transaction, the pending transaction it replaced
+    pending_transaction_id  text,
+    created_at              timestamp with time zone NOT NULL,
+    updated_at              timestamp with time zone NOT NULL,
+
+    CONSTRAINT plaid_transactions_item_transaction_unique UNIQUE (plaid_item_id, plaid_transaction_id),
+
+    CONSTRAINT fk_plaid_transactions_plaid_items FOREIGN KEY (plaid_item_id) REFERENCES plaid_items (plaid_item_id),
+
+    CONSTRAINT fk_plaid_transactions_users FOREIGN KEY (user_id) REFERENCES master_user_records (id)
+);
+CREATE INDEX plaid_transactions_user_date_idx ON plaid_transactions (user_id, date DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS plaid_transactions;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS transactions_synced_at;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS transactions_cursor;
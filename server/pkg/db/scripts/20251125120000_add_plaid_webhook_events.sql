@@ -0,0 +1,30 @@
+-- +goose Up
+-- Set when Plaid reports the user has accounts at the institution they didn't share with us
+ALTER TABLE plaid_items ADD COLUMN new_accounts_available boolean NOT NULL DEFAULT false;
+
+-- Every verified webhook from Plaid along with what came of processing it
+CREATE TABLE plaid_webhook_events (
+    id uuid PRIMARY KEY,
+    webhook_type character varying(64) NOT NULL,
+    webhook_code character varying(64) NOT NULL,
+    -- Not a foreign key: webhooks can arrive for items we haven't stored yet or already removed
+    plaid_item_id character varying(255),
+    plaid_account_id text,
+    payload json NOT NULL,
+    -- received, processed, ignored, unknown_item until the item is found, or failed
+    status character varying(16) NOT NULL,
+    -- Why the last attempt to process the event failed
+    error text,
+    attempts integer NOT NULL DEFAULT 0,
+    received_at timestamp with time zone NOT NULL,
+    processed_at timestamp with time zone,
+    -- When an unknown_item event is next retried
+    next_attempt_at timestamp with time zone
+);
+CREATE INDEX plaid_webhook_events_received_at_idx ON plaid_webhook_events (received_at);
+CREATE INDEX plaid_webhook_events_plaid_item_id_idx ON plaid_webhook_events (plaid_item_id, received_at);
+CREATE INDEX plaid_webhook_events_retry_idx ON plaid_webhook_events (next_attempt_at) WHERE status = 'unknown_item';
+
+-- +goose Down
+DROP TABLE IF EXISTS plaid_webhook_events;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS new_accounts_available;
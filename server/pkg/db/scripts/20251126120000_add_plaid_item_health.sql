@@ -0,0 +1,29 @@
+-- +goose Up
+-- When the user's consent for the item runs out, from Plaid's PENDING_EXPIRATION webhook
+ALTER TABLE plaid_items ADD COLUMN consent_expiration_time timestamp with time zone;
+-- When the user was last asked to relink the item, and how many times since it last needed it
+ALTER TABLE plaid_items ADD COLUMN relink_reminded_at timestamp with time zone;
+ALTER TABLE plaid_items ADD COLUMN relink_reminders_sent integer NOT NULL DEFAULT 0;
+-- When the user last reconnected the item through Link's update mode
+ALTER TABLE plaid_items ADD COLUMN relinked_at timestamp with time zone;
+
+-- How many items were in each state on each day the health monitor ran
+CREATE TABLE plaid_item_health_snapshots (
+    id uuid PRIMARY KEY,
+    snapshot_date date NOT NULL UNIQUE,
+    total_items integer NOT NULL,
+    healthy_items integer NOT NULL,
+    login_required_items integer NOT NULL,
+    pending_disconnect_items integer NOT NULL,
+    consent_expiring_items integer NOT NULL,
+    stale_balance_items integer NOT NULL,
+    reminders_sent integer NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS plaid_item_health_snapshots;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS relinked_at;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS relink_reminders_sent;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS relink_reminded_at;
+ALTER TABLE plaid_items DROP COLUMN IF EXISTS consent_expiration_time;
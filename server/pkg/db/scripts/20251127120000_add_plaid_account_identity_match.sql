@@ -0,0 +1,21 @@
+-- +goose Up
+-- The primary owner's address, as reported by Plaid Identity
+ALTER TABLE plaid_accounts ADD COLUMN primary_owner_address text;
+-- How well the account's owner matched the customer linking it, from 0 to 1, and what was
+-- decided: approved, review or rejected. Accounts linked before matching have no decision.
+ALTER TABLE plaid_accounts ADD COLUMN identity_name_score double precision;
+ALTER TABLE plaid_accounts ADD COLUMN identity_address_score double precision;
+ALTER TABLE plaid_accounts ADD COLUMN identity_match_status varchar(16);
+ALTER TABLE plaid_accounts ADD COLUMN identity_matched_at timestamp with time zone;
+-- The operator who approved or rejected an account sent for review
+ALTER TABLE plaid_accounts ADD COLUMN identity_reviewed_by text;
+ALTER TABLE plaid_accounts ADD COLUMN identity_reviewed_at timestamp with time zone;
+
+-- +goose Down
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_reviewed_at;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_reviewed_by;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_matched_at;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_match_status;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_address_score;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS identity_name_score;
+ALTER TABLE plaid_accounts DROP COLUMN IF EXISTS primary_owner_address;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Whether the external account an ACH pull debits had enough available money, checked
+-- against its Plaid balance before the pull is sent to the ledger. One per pull.
+CREATE TABLE ach_pull_balance_checks (
+    id uuid PRIMARY KEY,
+    -- The signed ach_debit payload of the pull
+    payload_id character varying(36) NOT NULL UNIQUE,
+    user_id uuid NOT NULL REFERENCES master_user_records (id),
+    -- The linked account being debited, if it could be found and is still linked
+    plaid_account_id uuid REFERENCES plaid_accounts (id) ON DELETE SET NULL,
+    amount_cents bigint NOT NULL,
+    buffer_cents bigint NOT NULL,
+    available_balance_cents bigint,
+    balance_refreshed_at timestamp with time zone,
+    -- Whether the balance was refreshed from Plaid for this pull
+    refreshed boolean NOT NULL DEFAULT false,
+    -- allowed, rejected or unchecked
+    decision character varying(16) NOT NULL,
+    -- Why the pull was rejected or not checked
+    reason character varying(32),
+    -- Set once the ledger accepts an allowed pull
+    transaction_number text,
+    created_at timestamp with time zone NOT NULL
+);
+CREATE INDEX ach_pull_balance_checks_user_id_idx ON ach_pull_balance_checks (user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS ach_pull_balance_checks;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Plaid processor tokens that give Sardine access to a linked account's bank data for
+-- risk scoring. One per account; kept after the item is unlinked to record the revocation.
+CREATE TABLE sardine_processor_tokens (
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES master_user_records (id),
+    plaid_item_id character varying(255) NOT NULL,
+    -- Plaid's id of the account, which Sardine knows the token by
+    plaid_account_id text NOT NULL,
+    -- Cleared once the token is revoked
+    kms_encrypted_processor_token bytea,
+    -- Set once Sardine accepts the token
+    registered_at timestamp with time zone,
+    -- When Sardine was last asked to pull fresh data with the token
+    synced_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    -- Why the last registration or sync failed
+    last_error text,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    UNIQUE (plaid_item_id, plaid_account_id)
+);
+CREATE INDEX sardine_processor_tokens_user_id_idx ON sardine_processor_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS sardine_processor_tokens;
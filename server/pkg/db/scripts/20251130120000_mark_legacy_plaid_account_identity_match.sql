@@ -0,0 +1,7 @@
+-- +goose Up
+-- Accounts linked before identity matching was added were never matched. Mark them so ACH
+-- pulls from them keep working, while new accounts that haven't been matched are blocked.
+UPDATE plaid_accounts SET identity_match_status = 'legacy' WHERE identity_match_status IS NULL;
+
+-- +goose Down
+UPDATE plaid_accounts SET identity_match_status = NULL WHERE identity_match_status = 'legacy';
@@ -0,0 +1,85 @@
+package dispute
+
+import (
+	"process-api/pkg/constant"
+	"time"
+)
+
+// AddBusinessDays returns the time the given number of business days after t. Business
+// days are the days the Federal Reserve is open: weekdays other than federal holidays.
+func AddBusinessDays(t time.Time, days int) time.Time {
+	for days > 0 {
+		t = t.AddDate(0, 0, 1)
+		if IsBusinessDay(t) {
+			days--
+		}
+	}
+	return t
+}
+
+func IsBusinessDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !isFederalHoliday(t)
+}
+
+// Deadlines returns the Reg E provisional credit and resolution deadlines of a dispute
+// submitted at submittedAt.
+func Deadlines(submittedAt time.Time) (provisionalCreditDueAt time.Time, resolutionDueAt time.Time) {
+	return AddBusinessDays(submittedAt, constant.DISPUTE_PROVISIONAL_CREDIT_BUSINESS_DAYS),
+		AddBusinessDays(submittedAt, constant.DISPUTE_RESOLUTION_BUSINESS_DAYS)
+}
+
+func isFederalHoliday(t time.Time) bool {
+	year, month, day := t.Date()
+	for _, holiday := range federalHolidays(year, t.Location()) {
+		holidayYear, holidayMonth, holidayDay := holiday.Date()
+		if holidayYear == year && holidayMonth == month && holidayDay == day {
+			return true
+		}
+	}
+	return false
+}
+
+// federalHolidays returns the days the Federal Reserve observes holidays in the given
+// year. Holidays falling on a Sunday are observed the following Monday, while those
+// falling on a Saturday are not observed.
+func federalHolidays(year int, loc *time.Location) []time.Time {
+	fixed := func(month time.Month, day int) time.Time {
+		date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if date.Weekday() == time.Sunday {
+			return date.AddDate(0, 0, 1)
+		}
+		return date
+	}
+	// nthWeekday returns the nth given weekday of the month, or the last one when n is -1
+	nthWeekday := func(month time.Month, weekday time.Weekday, n int) time.Time {
+		if n < 0 {
+			date := time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+			for date.Weekday() != weekday {
+				date = date.AddDate(0, 0, -1)
+			}
+			return date
+		}
+		date := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		for date.Weekday() != weekday {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date.AddDate(0, 0, 7*(n-1))
+	}
+
+	return []time.Time{
+		fixed(time.January, 1),
+		nthWeekday(time.January, time.Monday, 3),
+		nthWeekday(time.February, time.Monday, 3),
+		nthWeekday(time.May, time.Monday, -1),
+		fixed(time.June, 19),
+		fixed(time.July, 4),
+		nthWeekday(time.September, time.Monday, 1),
+		nthWeekday(time.October, time.Monday, 2),
+		fixed(time.November, 11),
+		nthWeekday(time.November, time.Thursday, 4),
+		fixed(time.December, 25),
+	}
+}
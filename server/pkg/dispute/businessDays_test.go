@@ -0,0 +1,31 @@
+package dispute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddBusinessDays(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 15, 4, 5, 0, time.UTC)
+	}
+
+	assert.Equal(t, date(2025, time.November, 17), AddBusinessDays(date(2025, time.November, 14), 1), "Weekends should be skipped")
+	assert.Equal(t, date(2025, time.November, 28), AddBusinessDays(date(2025, time.November, 26), 1), "Thanksgiving should be skipped")
+	assert.Equal(t, date(2026, time.January, 6), AddBusinessDays(date(2025, time.December, 19), 10), "Christmas and New Year's Day should be skipped")
+	assert.Equal(t, date(2022, time.June, 21), AddBusinessDays(date(2022, time.June, 17), 1), "Holidays on a Sunday should be observed the following Monday")
+	assert.Equal(t, date(2026, time.July, 3), AddBusinessDays(date(2026, time.July, 2), 1), "Holidays on a Saturday should not be observed")
+	assert.Equal(t, date(2025, time.November, 14), AddBusinessDays(date(2025, time.November, 14), 0), "Zero days should not move the time")
+}
+
+func TestDeadlines(t *testing.T) {
+	submittedAt := time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC)
+
+	provisionalCreditDueAt, resolutionDueAt := Deadlines(submittedAt)
+
+	// Veterans Day falls within both windows, Thanksgiving and Christmas within the second
+	assert.Equal(t, time.Date(2025, time.November, 18, 9, 0, 0, 0, time.UTC), provisionalCreditDueAt)
+	assert.Equal(t, time.Date(2026, time.January, 9, 9, 0, 0, 0, time.UTC), resolutionDueAt)
+}
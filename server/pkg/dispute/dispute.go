@@ -0,0 +1,271 @@
+package dispute
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"slices"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+	"github.com/riverqueue/river"
+)
+
+// Action is a step an operator or the customer takes on a dispute
+type Action string
+
+const (
+	ActionStartReview       Action = "review"
+	ActionProvisionalCredit Action = "provisional-credit"
+	ActionResolveCustomer   Action = "resolve-customer"
+	ActionResolveMerchant   Action = "resolve-merchant"
+	ActionWithdraw          Action = "withdraw"
+)
+
+var (
+	ErrDisputeNotFound   = errors.New("dispute not found")
+	ErrInvalidTransition = errors.New("dispute cannot take this action in its current status")
+	ErrUnknownAction     = errors.New("unknown dispute action")
+)
+
+// transitions lists, for each action, the status it moves a dispute to and the statuses
+// it can be taken from
+var transitions = map[Action]struct {
+	to   string
+	from []string
+}{
+	ActionStartReview: {
+		to:   constant.DISPUTE_UNDER_REVIEW,
+		from: []string{constant.DISPUTE_SUBMITTED},
+	},
+	ActionProvisionalCredit: {
+		to:   constant.DISPUTE_PROVISIONALLY_CREDITED,
+		from: []string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW},
+	},
+	ActionResolveCustomer: {
+		to:   constant.DISPUTE_RESOLVED_CUSTOMER,
+		from: []string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW, constant.DISPUTE_PROVISIONALLY_CREDITED},
+	},
+	ActionResolveMerchant: {
+		to:   constant.DISPUTE_RESOLVED_MERCHANT,
+		from: []string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW, constant.DISPUTE_PROVISIONALLY_CREDITED},
+	},
+	ActionWithdraw: {
+		to:   constant.DISPUTE_WITHDRAWN,
+		from: []string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW, constant.DISPUTE_PROVISIONALLY_CREDITED},
+	},
+}
+
+var statusEvents = map[string]string{
+	constant.DISPUTE_UNDER_REVIEW:           dao.DISPUTE_EVENT_REVIEW_STARTED,
+	constant.DISPUTE_PROVISIONALLY_CREDITED: dao.DISPUTE_EVENT_PROVISIONALLY_CREDITED,
+	constant.DISPUTE_RESOLVED_CUSTOMER:      dao.DISPUTE_EVENT_RESOLVED_CUSTOMER,
+	constant.DISPUTE_RESOLVED_MERCHANT:      dao.DISPUTE_EVENT_RESOLVED_MERCHANT,
+	constant.DISPUTE_WITHDRAWN:              dao.DISPUTE_EVENT_WITHDRAWN,
+}
+
+// Actions returns the actions that can be taken on a dispute in the given status, in the
+// order they are usually taken
+func Actions(status string) []Action {
+	var actions []Action
+	for _, action := range []Action{ActionStartReview, ActionProvisionalCredit, ActionResolveCustomer, ActionResolveMerchant, ActionWithdraw} {
+		if slices.Contains(transitions[action].from, status) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// ReasonRequired reports whether an action must be given a reason, which is recorded as
+// the dispute's resolution
+func ReasonRequired(action Action) bool {
+	return action == ActionResolveMerchant || action == ActionWithdraw
+}
+
+// IsOpen reports whether a dispute in the given status is still being worked on
+func IsOpen(status string) bool {
+	return slices.Contains(transitions[ActionWithdraw].from, status)
+}
+
+// IsCredited reports whether the customer holds a credit for the dispute that has not
+// been reversed
+func IsCredited(dispute dao.TransactionDisputeDao) bool {
+	return dispute.ProvisionalCreditTransaction != nil && dispute.VoidCreditTransaction == nil
+}
+
+// Service moves disputes through their workflow and notifies customers of each change
+type Service struct {
+	RiverClient *river.Client[*sql.Tx]
+}
+
+// Transition takes action on a dispute. Crediting a dispute that is resolved for the
+// customer without a provisional credit issues the credit then, and resolving for the
+// merchant or withdrawing a credited dispute reverses the credit. Money moves in a
+// transaction of its own that records the ledger reference before the status changes,
+// so a ledger failure leaves the dispute as it was and a retry after a failed status
+// change doesn't credit or reverse the money again.
+func (s *Service) Transition(ctx context.Context, logger *slog.Logger, id string, action Action, actor, reason string) (*dao.TransactionDisputeDao, error) {
+	transition, ok := transitions[action]
+	if !ok {
+		return nil, errtrace.Wrap(ErrUnknownAction)
+	}
+
+	user, err := settleCredit(logger, id, transition.to, transition.from, actor, reason)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	var updated dao.TransactionDisputeDao
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		dispute, err := lockDispute(tx, id)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if !slices.Contains(transition.from, dispute.Status) {
+			return errtrace.Wrap(ErrInvalidTransition)
+		}
+
+		now := clock.Now()
+		columns := map[string]any{"status": transition.to}
+		if !IsOpen(transition.to) {
+			columns["resolved_by"] = actor
+			columns["resolved_at"] = now
+			columns["resolution_reason"] = optionalReason(reason)
+		}
+
+		if err := tx.Model(&dao.TransactionDisputeDao{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		if err := (dao.TransactionDisputeEventDao{}).Create(tx, id, statusEvents[transition.to], &transition.to, actor, optionalReason(reason), now); err != nil {
+			return errtrace.Wrap(err)
+		}
+		err = dao.WebhookEventDao{}.Create(tx, dao.WEBHOOK_EVENT_DISPUTE_UPDATED, dispute.UserId, dao.DisputeUpdatedEventData{
+			UserId:                 dispute.UserId,
+			DisputeId:              id,
+			TransactionReferenceId: dispute.TransactionIdentifier,
+			PreviousStatus:         dispute.Status,
+			Status:                 transition.to,
+		}, now)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+
+		if err := tx.Where("id = ?", id).Take(&updated).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	logger.Info("Dispute status changed", "disputeId", id, "status", updated.Status, "userId", updated.UserId, "actor", actor)
+
+	s.Notify(ctx, logger, *user, updated, reason, actor)
+	return &updated, nil
+}
+
+// settleCredit issues or reverses the dispute's credit as moving it to status requires,
+// and commits the ledger reference along with an event before returning. It returns the
+// dispute's customer.
+func settleCredit(logger *slog.Logger, id, status string, from []string, actor, reason string) (*dao.MasterUserRecordDao, error) {
+	var user *dao.MasterUserRecordDao
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		dispute, err := lockDispute(tx, id)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if !slices.Contains(from, dispute.Status) {
+			return errtrace.Wrap(ErrInvalidTransition)
+		}
+
+		user, err = dao.MasterUserRecordDao{}.FindOneByUserId(dispute.UserId)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if user == nil {
+			return errtrace.Wrap(fmt.Errorf("user %s of dispute %s not found", dispute.UserId, id))
+		}
+
+		now := clock.Now()
+		var columns map[string]any
+		var event, reference string
+
+		credited := IsCredited(*dispute)
+		switch {
+		case (status == constant.DISPUTE_PROVISIONALLY_CREDITED || status == constant.DISPUTE_RESOLVED_CUSTOMER) && !credited:
+			amountCents, err := disputeAmountCents(*dispute)
+			if err != nil {
+				return errtrace.Wrap(err)
+			}
+			reference, err = issueCredit(*user, *dispute, amountCents)
+			if err != nil {
+				detail := err.Error()
+				recordEvent(logger, id, dao.DISPUTE_EVENT_CREDIT_FAILED, actor, &detail)
+				return errtrace.Wrap(err)
+			}
+			event = dao.DISPUTE_EVENT_CREDIT_ISSUED
+			columns = map[string]any{
+				"amount_cents":                   amountCents,
+				"provisional_credit_transaction": reference,
+				"credited_at":                    now,
+			}
+		case (status == constant.DISPUTE_RESOLVED_MERCHANT || status == constant.DISPUTE_WITHDRAWN) && credited:
+			reference, err = reverseCredit(*user, *dispute, reason)
+			if err != nil {
+				detail := err.Error()
+				recordEvent(logger, id, dao.DISPUTE_EVENT_CREDIT_REVERSAL_FAILED, actor, &detail)
+				return errtrace.Wrap(err)
+			}
+			event = dao.DISPUTE_EVENT_CREDIT_REVERSED
+			columns = map[string]any{
+				"void_credit_transaction": reference,
+				"voided_at":               now,
+			}
+		default:
+			return nil
+		}
+
+		if err := tx.Model(&dao.TransactionDisputeDao{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		return errtrace.Wrap(dao.TransactionDisputeEventDao{}.Create(tx, id, event, nil, actor, &reference, now))
+	})
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return user, nil
+}
+
+// lockDispute locks the dispute row for the rest of tx so two actions cannot be taken on
+// it at once. NO KEY UPDATE still lets events referencing the row be written outside tx.
+func lockDispute(tx *gorm.DB, id string) (*dao.TransactionDisputeDao, error) {
+	var dispute dao.TransactionDisputeDao
+	err := tx.Set("gorm:query_option", "FOR NO KEY UPDATE").Where("id = ?", id).Take(&dispute).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errtrace.Wrap(ErrDisputeNotFound)
+	}
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return &dispute, nil
+}
+
+func recordEvent(logger *slog.Logger, disputeId, action, actor string, detail *string) {
+	if err := (dao.TransactionDisputeEventDao{}).Create(db.DB, disputeId, action, nil, actor, detail, clock.Now()); err != nil {
+		logger.Error("Failed to record dispute event", "disputeId", disputeId, "action", action, "error", err.Error())
+	}
+}
+
+func optionalReason(reason string) *string {
+	if reason == "" {
+		return nil
+	}
+	return &reason
+}
@@ -0,0 +1,94 @@
+package dispute
+
+import (
+	"context"
+	"log/slog"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/utils"
+	"slices"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+const DISPUTE_DEADLINE_ESCALATED_EVENT = "dispute_deadline_escalated"
+
+// DeadlineEscalationArgs is a periodic job that raises an alert for each dispute nearing
+// a Reg E deadline
+type DeadlineEscalationArgs struct{}
+
+func (DeadlineEscalationArgs) Kind() string { return "dispute_deadline_escalation" }
+
+type DeadlineEscalationWorker struct {
+	river.WorkerDefaults[DeadlineEscalationArgs]
+}
+
+func RegisterDeadlineEscalationWorker(workers *river.Workers) {
+	river.AddWorker(workers, &DeadlineEscalationWorker{})
+}
+
+// NewDeadlineEscalationPeriodicJob schedules the escalation job. Periodic jobs run on the
+// elected River leader only, so each run happens once across instances.
+func NewDeadlineEscalationPeriodicJob(schedule river.PeriodicSchedule) *river.PeriodicJob {
+	return river.NewPeriodicJob(schedule, func() (river.JobArgs, *river.InsertOpts) {
+		return DeadlineEscalationArgs{}, nil
+	}, &river.PeriodicJobOpts{RunOnStart: true})
+}
+
+func (w *DeadlineEscalationWorker) Work(ctx context.Context, job *river.Job[DeadlineEscalationArgs]) error {
+	return errtrace.Wrap(EscalateDisputesNearingDeadline(clock.Now()))
+}
+
+// EscalateDisputesNearingDeadline alerts on disputes with a deadline within the configured
+// number of business days of now, or already past. Each deadline of a dispute is
+// escalated once.
+func EscalateDisputesNearingDeadline(now time.Time) error {
+	dueBy := AddBusinessDays(now, config.Config.Disputes.EscalationBusinessDays)
+	disputes, err := dao.TransactionDisputeDao{}.FindNearingDeadline(dueBy)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	logger := logging.Logger.With("job", DeadlineEscalationArgs{}.Kind())
+	for _, dispute := range disputes {
+		pendingCredit := slices.Contains([]string{constant.DISPUTE_SUBMITTED, constant.DISPUTE_UNDER_REVIEW}, dispute.Status)
+		if pendingCredit && dispute.ProvisionalCreditEscalatedAt == nil && dispute.ProvisionalCreditDueAt != nil && !dispute.ProvisionalCreditDueAt.After(dueBy) {
+			escalate(logger, dispute, "provisional_credit", "provisional_credit_escalated_at", dao.DISPUTE_EVENT_PROVISIONAL_CREDIT_ESCALATED, *dispute.ProvisionalCreditDueAt, now)
+		}
+		if dispute.ResolutionEscalatedAt == nil && dispute.ResolutionDueAt != nil && !dispute.ResolutionDueAt.After(dueBy) {
+			escalate(logger, dispute, "resolution", "resolution_escalated_at", dao.DISPUTE_EVENT_RESOLUTION_ESCALATED, *dispute.ResolutionDueAt, now)
+		}
+	}
+	return nil
+}
+
+func escalate(logger *slog.Logger, dispute dao.TransactionDisputeDao, deadline, column, action string, dueAt time.Time, now time.Time) {
+	marked, err := dao.TransactionDisputeDao{}.MarkEscalated(dispute.Id, column, now)
+	if err != nil {
+		logger.Error("Failed to mark dispute deadline escalated", "disputeId", dispute.Id, "deadline", deadline, "error", err.Error())
+		return
+	}
+	if !marked {
+		return
+	}
+
+	logger.Warn("Dispute is nearing its Reg E deadline", "disputeId", dispute.Id, "deadline", deadline, "dueAt", dueAt, "status", dispute.Status)
+	utils.PosthogClient.CaptureAlert(dispute.UserId, DISPUTE_DEADLINE_ESCALATED_EVENT, map[string]any{
+		"disputeId": dispute.Id,
+		"deadline":  deadline,
+		"dueAt":     dueAt.Format(time.RFC3339),
+		"overdue":   dueAt.Before(now),
+		"status":    dispute.Status,
+	})
+
+	detail := dueAt.Format(time.RFC3339)
+	if err := (dao.TransactionDisputeEventDao{}).Create(db.DB, dispute.Id, action, nil, dao.DISPUTE_ACTOR_SYSTEM, &detail, now); err != nil {
+		logger.Error("Failed to record dispute event", "disputeId", dispute.Id, "action", action, "error", err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package dispute
+
+import (
+	"fmt"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"strconv"
+
+	"braces.dev/errtrace"
+)
+
+// disputeAmountCents returns the disputed amount, looking the transaction up in the
+// ledger for disputes submitted before the amount was stored
+func disputeAmountCents(dispute dao.TransactionDisputeDao) (int64, error) {
+	if dispute.AmountCents != nil {
+		return *dispute.AmountCents, nil
+	}
+
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	ledgerResponse, err := ledgerClient.GetTransactionByReferenceNumber(ledger.BuildGetTransactionByReferenceNumberRequest(dispute.TransactionIdentifier))
+	if err != nil {
+		return 0, errtrace.Wrap(fmt.Errorf("error while calling ledger's GetTransactionByReferenceNumber: %w", err))
+	}
+	if ledgerResponse.Error != nil {
+		return 0, errtrace.Wrap(fmt.Errorf("error from ledger's GetTransactionByReferenceNumber: %s", ledgerResponse.Error.Message))
+	}
+	if ledgerResponse.Result == nil {
+		return 0, errtrace.Wrap(fmt.Errorf("ledger's GetTransactionByReferenceNumber returned no result"))
+	}
+	return ledgerResponse.Result.InstructedAmount.Amount, nil
+}
+
+// issueCredit credits the disputed amount to the customer's account and returns the
+// ledger reference of the credit
+func issueCredit(user dao.MasterUserRecordDao, dispute dao.TransactionDisputeDao, amountCents int64) (string, error) {
+	account, err := dao.UserAccountCardDao{}.FindOneByUserId(db.DB, user.Id)
+	if err != nil {
+		return "", errtrace.Wrap(err)
+	}
+	if account == nil || account.AccountNumber == "" {
+		return "", errtrace.Wrap(fmt.Errorf("user %s has no account to credit", user.Id))
+	}
+
+	reason := fmt.Sprintf("Provisional credit for transaction dispute %s", dispute.Id)
+	req, err := ledger.BuildProvisionalCreditRequest(&user, strconv.FormatInt(amountCents, 10), account.AccountNumber, "CHECKING", &reason)
+	if err != nil {
+		return "", errtrace.Wrap(err)
+	}
+	// The ledger recognises a retried credit by its reference
+	req.Reference = provisionalCreditReference(dispute.Id)
+
+	paymentClient := ledger.NewNetXDPaymentApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	ledgerResponse, err := paymentClient.ProvisionalCredit(*req)
+	if err != nil {
+		return "", errtrace.Wrap(fmt.Errorf("error while calling ledger's ProvisionalCredit: %w", err))
+	}
+	if ledgerResponse.Error != nil {
+		return "", errtrace.Wrap(fmt.Errorf("error from ledger's ProvisionalCredit: %s", ledgerResponse.Error.Message))
+	}
+	if ledgerResponse.Result == nil {
+		return "", errtrace.Wrap(fmt.Errorf("ledger's ProvisionalCredit returned no result"))
+	}
+	// VoidPayment identifies the credit by the reference it was made with
+	return ledgerResponse.Result.Api.Reference, nil
+}
+
+// provisionalCreditReference is the reference a dispute's provisional credit is made with
+func provisionalCreditReference(disputeId string) string {
+	return fmt.Sprintf("dispute.%s.provisional_credit", disputeId)
+}
+
+// reverseCredit voids the dispute's provisional credit and returns the ledger reference
+// of the void
+func reverseCredit(user dao.MasterUserRecordDao, dispute dao.TransactionDisputeDao, reason string) (string, error) {
+	notes := fmt.Sprintf("Reversal of provisional credit for transaction dispute %s", dispute.Id)
+	if reason != "" {
+		notes = fmt.Sprintf("%s: %s", notes, reason)
+	}
+
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	ledgerResponse, err := ledgerClient.VoidPayment(ledger.BuildVoidPaymentRequest(*dispute.ProvisionalCreditTransaction, user.LedgerCustomerNumber, notes))
+	if err != nil {
+		return "", errtrace.Wrap(fmt.Errorf("error while calling ledger's VoidPayment: %w", err))
+	}
+	if ledgerResponse.Error != nil {
+		return "", errtrace.Wrap(fmt.Errorf("error from ledger's VoidPayment: %s", ledgerResponse.Error.Message))
+	}
+	if ledgerResponse.Result == nil {
+		return "", errtrace.Wrap(fmt.Errorf("ledger's VoidPayment returned no result"))
+	}
+	return ledgerResponse.Result.ReferenceID, nil
+}
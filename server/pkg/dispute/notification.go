@@ -0,0 +1,136 @@
+package dispute
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+type DisputeStatusEmailJobArgs struct {
+	FirstName       string     `json:"firstName"`
+	Email           string     `json:"email"`
+	Status          string     `json:"status"`
+	AmountCents     *int64     `json:"amountCents"`
+	ResolutionDueAt *time.Time `json:"resolutionDueAt"`
+	CreditReversed  bool       `json:"creditReversed"`
+	Reason          string     `json:"reason"`
+}
+
+func (DisputeStatusEmailJobArgs) Kind() string { return "dispute_status_email" }
+
+func (DisputeStatusEmailJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "sendgrid",
+	}
+}
+
+type DisputeStatusEmailWorker struct {
+	river.WorkerDefaults[DisputeStatusEmailJobArgs]
+}
+
+func RegisterDisputeStatusEmailWorker(workers *river.Workers) {
+	river.AddWorker(workers, &DisputeStatusEmailWorker{})
+}
+
+func (w *DisputeStatusEmailWorker) Work(ctx context.Context, job *river.Job[DisputeStatusEmailJobArgs]) error {
+	err := sendDisputeStatusEmail(job.Args)
+	if err != nil {
+		logging.Logger.Error("Error sending dispute status email", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Notify enqueues an email telling the customer the dispute's current status
+func (s *Service) Notify(ctx context.Context, logger *slog.Logger, user dao.MasterUserRecordDao, dispute dao.TransactionDisputeDao, reason, actor string) {
+	// Reasons are operator notes, and only shared to explain a decision against the customer
+	if dispute.Status != constant.DISPUTE_RESOLVED_MERCHANT {
+		reason = ""
+	}
+
+	_, err := s.RiverClient.Insert(ctx, DisputeStatusEmailJobArgs{
+		FirstName:       user.FirstName,
+		Email:           user.Email,
+		Status:          dispute.Status,
+		AmountCents:     dispute.AmountCents,
+		ResolutionDueAt: dispute.ResolutionDueAt,
+		CreditReversed:  dispute.VoidCreditTransaction != nil,
+		Reason:          reason,
+	}, nil)
+	if err != nil {
+		logger.Error("Failed to enqueue dispute status email", "disputeId", dispute.Id, "error", err.Error())
+		detail := err.Error()
+		recordEvent(logger, dispute.Id, dao.DISPUTE_EVENT_NOTIFICATION_FAILED, actor, &detail)
+		return
+	}
+	recordEvent(logger, dispute.Id, dao.DISPUTE_EVENT_NOTIFICATION_QUEUED, actor, nil)
+}
+
+func sendDisputeStatusEmail(args DisputeStatusEmailJobArgs) error {
+	amount := "disputed"
+	if args.AmountCents != nil {
+		amount = fmt.Sprintf("$%.2f", utils.CentsToUSD(*args.AmountCents))
+	}
+
+	var subject, message string
+	switch args.Status {
+	case constant.DISPUTE_SUBMITTED:
+		subject = "We received your DreamFi dispute"
+		message = fmt.Sprintf("We received your dispute of a %s transaction and will investigate it.", amount)
+		if args.ResolutionDueAt != nil {
+			message += fmt.Sprintf(" We will let you know the outcome by %s.", args.ResolutionDueAt.Format("January 2, 2006"))
+		}
+	case constant.DISPUTE_UNDER_REVIEW:
+		subject = "We are reviewing your DreamFi dispute"
+		message = fmt.Sprintf("Our team has started reviewing your dispute of a %s transaction.", amount)
+	case constant.DISPUTE_PROVISIONALLY_CREDITED:
+		subject = "We credited your DreamFi account while we review your dispute"
+		message = fmt.Sprintf("We have provisionally credited %s to your account while we finish reviewing your dispute. If we find the transaction was correct, this credit will be reversed.", amount)
+	case constant.DISPUTE_RESOLVED_CUSTOMER:
+		subject = "Your DreamFi dispute was resolved in your favor"
+		message = fmt.Sprintf("We resolved your dispute of a %s transaction in your favor. The credit to your account is final.", amount)
+	case constant.DISPUTE_RESOLVED_MERCHANT:
+		subject = "Your DreamFi dispute has been resolved"
+		message = fmt.Sprintf("We finished reviewing your dispute of a %s transaction and found that the transaction was correct.", amount)
+		if args.CreditReversed {
+			message += " The provisional credit made to your account has been reversed."
+		}
+	case constant.DISPUTE_WITHDRAWN:
+		subject = "Your DreamFi dispute was withdrawn"
+		message = fmt.Sprintf("Your dispute of a %s transaction has been withdrawn.", amount)
+		if args.CreditReversed {
+			message += " The provisional credit made to your account has been reversed."
+		}
+	default:
+		return errtrace.Wrap(fmt.Errorf("no dispute email for status %s", args.Status))
+	}
+
+	emailData := response.DisputeStatusEmailTemplateData{
+		FirstName: args.FirstName,
+		Message:   message,
+		Reason:    args.Reason,
+	}
+
+	templateName := config.Config.Email.TemplateDirectory + constant.DISPUTE_STATUS_TEMPLATE_NAME
+	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	err = utils.SendEmail(args.FirstName, args.Email, subject, htmlBody)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package dispute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"slices"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// NonDisputableTransactionTypes are entries the ledger makes for disputes themselves
+var NonDisputableTransactionTypes = []string{
+	"PROVISIONAL_CREDIT",
+	"VOID",
+}
+
+// AdministrativeTransactionTypes are ledger bookkeeping entries that are not shown to
+// customers, so cannot be disputed either
+var AdministrativeTransactionTypes = []string{
+	"OVER_DRAFT_REQ",
+	"OVER_DRAFT_RELEASE",
+}
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrTransactionNotOwned = errors.New("transaction does not belong to the customer")
+	ErrNotDisputable       = errors.New("transaction type cannot be disputed")
+	ErrAlreadyDisputed     = errors.New("transaction is already disputed")
+)
+
+// Submit disputes one of the customer's ledger transactions and emails them that it was
+// received. The actor is DISPUTE_ACTOR_CUSTOMER, or the email of the agent filing the
+// dispute on the customer's behalf.
+func (s *Service) Submit(ctx context.Context, logger *slog.Logger, user dao.MasterUserRecordDao, referenceId, reason, details, actor string) (*dao.TransactionDisputeDao, error) {
+	ledgerClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	responseData, err := ledgerClient.GetTransactionByReferenceNumber(ledger.BuildGetTransactionByReferenceNumberRequest(referenceId))
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("error from getTransactionByReferenceNumber: %w", err))
+	}
+	if responseData.Error != nil {
+		if responseData.Error.Code == "NOT_FOUND_TRANSACTION" {
+			return nil, errtrace.Wrap(ErrTransactionNotFound)
+		}
+		logger.Error("The ledger responded with an error", "code", responseData.Error.Code, "msg", responseData.Error.Message)
+		return nil, errtrace.Wrap(fmt.Errorf("the ledger responded with an error: %s", responseData.Error.Message))
+	}
+	if responseData.Result == nil {
+		logger.Error("The ledger responded with an empty result object", "responseData", responseData)
+		return nil, errtrace.Wrap(errors.New("the ledger responded with an empty result object"))
+	}
+
+	if responseData.Result.CustomerID != user.LedgerCustomerNumber {
+		return nil, errtrace.Wrap(ErrTransactionNotOwned)
+	}
+	if slices.Contains(NonDisputableTransactionTypes, responseData.Result.Type) || slices.Contains(AdministrativeTransactionTypes, responseData.Result.Type) {
+		logger.Error("Cannot request a dispute for this transaction type", "type", responseData.Result.Type)
+		return nil, errtrace.Wrap(ErrNotDisputable)
+	}
+
+	id := uuid.New().String()
+	now := clock.Now()
+	provisionalCreditDueAt, resolutionDueAt := Deadlines(now)
+
+	transactionDispute := dao.TransactionDisputeDao{
+		Id:                     id,
+		Status:                 constant.DISPUTE_SUBMITTED,
+		TransactionIdentifier:  referenceId,
+		Reason:                 reason,
+		Details:                details,
+		UserId:                 user.Id,
+		AmountCents:            &responseData.Result.InstructedAmount.Amount,
+		ProvisionalCreditDueAt: &provisionalCreditDueAt,
+		ResolutionDueAt:        &resolutionDueAt,
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id", "status", "transaction_identifier", "reason", "details", "user_id", "amount_cents", "provisional_credit_due_at", "resolution_due_at").Create(&transactionDispute).Error
+		if err != nil {
+			return err
+		}
+		if err := (dao.TransactionDisputeEventDao{}).Create(tx, id, dao.DISPUTE_EVENT_SUBMITTED, &transactionDispute.Status, actor, nil, now); err != nil {
+			return err
+		}
+		return dao.WebhookEventDao{}.Create(tx, dao.WEBHOOK_EVENT_DISPUTE_UPDATED, user.Id, dao.DisputeUpdatedEventData{
+			UserId:                 user.Id,
+			DisputeId:              id,
+			TransactionReferenceId: referenceId,
+			Status:                 transactionDispute.Status,
+		}, now)
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			return nil, errtrace.Wrap(fmt.Errorf("%w: %w", ErrAlreadyDisputed, err))
+		}
+		return nil, errtrace.Wrap(fmt.Errorf("error while creating transaction dispute record: %w", err))
+	}
+
+	s.Notify(ctx, logger, user, transactionDispute, "", actor)
+	return &transactionDispute, nil
+}
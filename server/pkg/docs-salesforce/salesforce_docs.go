@@ -68,9 +68,250 @@ const docTemplatesalesforce = `{
                 }
             }
         },
+        "/api/salesforce/accounts/{ledgerAccountID}/compliance-hold": {
+            "get": {
+                "description": "Gets the compliance hold on money movement for the customer owning a given ledger account id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceGetComplianceHold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ledger account id",
+                        "name": "ledgerAccountID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.ComplianceHoldResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stops all money movement for the customer owning a given ledger account id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforcePlaceComplianceHold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ledger account id",
+                        "name": "ledgerAccountID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    },
+                    {
+                        "description": "The hold to place",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.PlaceComplianceHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.ComplianceHoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The customer is already on compliance hold, or a request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Lifts the compliance hold on the customer owning a given ledger account id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceReleaseComplianceHold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ledger account id",
+                        "name": "ledgerAccountID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Why the hold is released",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.ReleaseComplianceHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.ComplianceHoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find account, or the customer has no compliance hold",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/salesforce/accounts/{ledgerAccountID}/transactions": {
             "get": {
-                "description": "Gets a list of transactions given a ledger account id",
+                "description": "Gets a list of transactions given a ledger account id, newest first. Transactions are described as they are in the app, with their merchant category, card acceptor and dispute status. Passing a limit or cursor lists a page of them, and the X-Next-Cursor header holds the cursor of the next page.",
                 "produces": [
                     "application/json"
                 ],
@@ -85,6 +326,44 @@ const docTemplatesalesforce = `{
                         "name": "ledgerAccountID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "description": "Only list transactions on or after this date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "description": "Only list transactions on or before this date",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only list transactions of these ledger types or type details, such as PURCHASE",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The X-Next-Cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 200,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "The number of transactions per page, 50 when only a cursor is passed",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -95,22 +374,695 @@ const docTemplatesalesforce = `{
                             "items": {
                                 "$ref": "#/definitions/salesforce.Transaction"
                             }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "The cursor of the next page. Missing on the last page and when not paging."
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers": {
+            "get": {
+                "description": "Finds a customer by exactly one of email, mobile number or ledger customer number, and returns their masked profile with onboarding and membership status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceFindCustomer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "10 digit US mobile number",
+                        "name": "mobileNo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ledger customer number",
+                        "name": "ledgerCustomerNumber",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.CustomerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/card": {
+            "get": {
+                "description": "Gets the status of a customer's card, from the ledger while their account is active",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceGetCustomerCard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.CardResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer or card",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/card/lock": {
+            "post": {
+                "description": "Locks a customer's card on their behalf, as the customer can by freezing it in the app",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceLockCustomerCard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.CardStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer or card",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The customer's account is not active",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/card/unlock": {
+            "post": {
+                "description": "Unlocks a customer's card on their behalf. A card cannot be unlocked while the customer is on compliance hold.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceUnlockCustomerCard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.CardStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope), or the customer is on compliance hold",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer or card",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The customer's account is not active",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/demographic-updates": {
+            "get": {
+                "description": "Gets the changes of name or address a customer requested, newest first. The requested values are not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceGetCustomerDemographicUpdates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/salesforce.DemographicUpdate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/disputes": {
+            "get": {
+                "description": "Gets a customer's open transaction disputes, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceGetCustomerDisputes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/salesforce.Dispute"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Disputes one of a customer's transactions on their behalf. The customer is emailed that the dispute was received.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceSubmitCustomerDispute",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    },
+                    {
+                        "description": "The transaction to dispute and why",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.SubmitDisputeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.Dispute"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer or transaction",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The transaction is already disputed, or a request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The customer is not active",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The transaction cannot be disputed, or the Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/linked-accounts": {
+            "get": {
+                "description": "Gets the external bank accounts a customer linked through Plaid, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceGetCustomerLinkedAccounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/salesforce.LinkedAccount"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Could not find customer",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/salesforce/customers/{userId}/statements/resend": {
+            "post": {
+                "description": "Emails a customer again that their statement for a month is ready to view in the app",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "salesforce"
+                ],
+                "summary": "SalesforceResendCustomerStatementEmail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email of the agent, unless the token was issued for one",
+                        "name": "X-Salesforce-Agent",
+                        "in": "header"
+                    },
+                    {
+                        "description": "The statement's month",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/salesforce.ResendStatementEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestErrors"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid authentication",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions (missing required scope)",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Missing or invalid authentication",
+                    "404": {
+                        "description": "Could not find customer or account",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Insufficient permissions (missing required scope)",
+                    "409": {
+                        "description": "A request with the Idempotency-Key is in progress",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Could not find account",
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -126,6 +1078,34 @@ const docTemplatesalesforce = `{
         }
     },
     "definitions": {
+        "response.BadRequestError": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fieldName": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.BadRequestErrors": {
+            "type": "object",
+            "required": [
+                "errors"
+            ],
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.BadRequestError"
+                    }
+                }
+            }
+        },
         "response.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -150,14 +1130,419 @@ const docTemplatesalesforce = `{
                 }
             }
         },
+        "salesforce.CardResponse": {
+            "type": "object",
+            "required": [
+                "accountStatus",
+                "cardExpiryDate",
+                "cardMaskNumber",
+                "cardStatus"
+            ],
+            "properties": {
+                "accountStatus": {
+                    "description": "The status of the account the card draws on: ACTIVE, SUSPENDED, CLOSED",
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "cardExpiryDate": {
+                    "type": "string",
+                    "example": "2029-09"
+                },
+                "cardMaskNumber": {
+                    "description": "The last digits of the card number",
+                    "type": "string",
+                    "example": "XXXXXXXXXXXX1234"
+                },
+                "cardStatus": {
+                    "description": "The card status as reported by the ledger: ACTIVATED, TEMPRORY_BLOCKED_BY_CLIENT, LOST_STOLEN, etc",
+                    "type": "string",
+                    "example": "ACTIVATED"
+                },
+                "isReIssue": {
+                    "description": "Whether the card replaces an expired card",
+                    "type": "boolean"
+                },
+                "isReplace": {
+                    "description": "Whether the card replaces a lost, stolen or damaged card",
+                    "type": "boolean"
+                },
+                "orderStatus": {
+                    "description": "The status of the card's order with the card printer",
+                    "type": "string",
+                    "example": "SHIPPED"
+                }
+            }
+        },
+        "salesforce.CardStatusResponse": {
+            "type": "object",
+            "required": [
+                "cardStatus"
+            ],
+            "properties": {
+                "cardStatus": {
+                    "description": "The card status as reported by the ledger: ACTIVATED, TEMPRORY_BLOCKED_BY_CLIENT, etc",
+                    "type": "string",
+                    "example": "TEMPRORY_BLOCKED_BY_CLIENT"
+                }
+            }
+        },
+        "salesforce.ComplianceHoldResponse": {
+            "type": "object",
+            "required": [
+                "active"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Whether money movement is on hold for the customer",
+                    "type": "boolean",
+                    "example": true
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "ledgerError": {
+                    "type": "string"
+                },
+                "ledgerMirroredAt": {
+                    "type": "string"
+                },
+                "placedAt": {
+                    "type": "string"
+                },
+                "placedBy": {
+                    "type": "string",
+                    "example": "agent@dreamfi.com"
+                },
+                "placedVia": {
+                    "type": "string",
+                    "example": "salesforce"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Suspected account takeover"
+                }
+            }
+        },
+        "salesforce.CustomerResponse": {
+            "type": "object",
+            "required": [
+                "birthYear",
+                "city",
+                "firstName",
+                "lastName",
+                "maskedEmail",
+                "maskedMobileNo",
+                "onboardingStatus",
+                "state",
+                "userId",
+                "zipCode"
+            ],
+            "properties": {
+                "birthYear": {
+                    "description": "The year of the customer's date of birth",
+                    "type": "integer",
+                    "example": 1990
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Oakland"
+                },
+                "firstName": {
+                    "type": "string",
+                    "example": "Alberta"
+                },
+                "lastName": {
+                    "type": "string",
+                    "example": "Charleson"
+                },
+                "ledgerAccountId": {
+                    "description": "The ledger account id to fetch the customer's balance and transactions by",
+                    "type": "string",
+                    "example": "11522031"
+                },
+                "ledgerCustomerNumber": {
+                    "type": "string",
+                    "example": "100000000006001"
+                },
+                "maskedEmail": {
+                    "description": "The email with all but its first two characters and domain masked",
+                    "type": "string",
+                    "example": "al*****@example.com"
+                },
+                "maskedMobileNo": {
+                    "description": "The mobile number with all but its last four digits masked",
+                    "type": "string",
+                    "example": "********1234"
+                },
+                "membershipStatus": {
+                    "description": "The customer's membership status, empty until they have a membership",
+                    "type": "string",
+                    "example": "active"
+                },
+                "onboardingStatus": {
+                    "description": "The customer's step in onboarding, ACTIVE once onboarded",
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "CA"
+                },
+                "suffix": {
+                    "type": "string",
+                    "example": "Jr"
+                },
+                "userId": {
+                    "description": "The customer's id, used to fetch the rest of their details",
+                    "type": "string",
+                    "example": "7f1e2b9c-2d4a-4b8e-9a57-0c1d2e3f4a5b"
+                },
+                "zipCode": {
+                    "type": "string",
+                    "example": "94612"
+                }
+            }
+        },
+        "salesforce.DemographicUpdate": {
+            "type": "object",
+            "required": [
+                "id",
+                "status",
+                "submittedAt",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a"
+                },
+                "reviewReason": {
+                    "description": "Why the request was approved or rejected",
+                    "type": "string",
+                    "example": "Proof of address did not match"
+                },
+                "reviewedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "The status of the request: pending, approved or rejected",
+                    "type": "string",
+                    "example": "pending"
+                },
+                "submittedAt": {
+                    "type": "string",
+                    "example": "2025-09-28T12:21:53Z"
+                },
+                "type": {
+                    "description": "What the customer asked to change: full_name or address",
+                    "type": "string",
+                    "example": "address"
+                }
+            }
+        },
+        "salesforce.Dispute": {
+            "type": "object",
+            "required": [
+                "id",
+                "reason",
+                "status",
+                "submittedAt",
+                "transactionId"
+            ],
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0c8e7a-4f8e-4f4e-9a3b-2b1c0d9e8f7a"
+                },
+                "isCredited": {
+                    "description": "Whether the customer holds a provisional credit for the dispute",
+                    "type": "boolean"
+                },
+                "provisionalCreditDueAt": {
+                    "description": "When Reg E requires a provisional credit, unless the dispute is resolved first",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Unauthorized transaction"
+                },
+                "resolutionDueAt": {
+                    "description": "When Reg E requires the dispute to be resolved",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "The status of the dispute: submitted, under_review or provisionally_credited",
+                    "type": "string",
+                    "example": "submitted"
+                },
+                "submittedAt": {
+                    "type": "string",
+                    "example": "2025-09-28T12:21:53Z"
+                },
+                "transactionId": {
+                    "description": "The reference id of the disputed transaction",
+                    "type": "string",
+                    "example": "ledger.ach.transfer_ach_pull_1756829941484592000"
+                }
+            }
+        },
+        "salesforce.LinkedAccount": {
+            "type": "object",
+            "required": [
+                "linkedAt",
+                "name",
+                "subtype"
+            ],
+            "properties": {
+                "authMethod": {
+                    "description": "How the account was linked: INSTANT_AUTH, SAME_DAY_MICRODEPOSITS, etc",
+                    "type": "string",
+                    "example": "INSTANT_AUTH"
+                },
+                "institutionName": {
+                    "type": "string",
+                    "example": "Chase"
+                },
+                "linkError": {
+                    "description": "The error Plaid last reported for the link, e.g. ITEM_LOGIN_REQUIRED when the customer must reconnect",
+                    "type": "string",
+                    "example": "ITEM_LOGIN_REQUIRED"
+                },
+                "linkedAt": {
+                    "type": "string",
+                    "example": "2025-09-28T12:21:53Z"
+                },
+                "mask": {
+                    "description": "The last digits of the account number",
+                    "type": "string",
+                    "example": "0000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Plaid Checking"
+                },
+                "subtype": {
+                    "type": "string",
+                    "example": "checking"
+                },
+                "verificationStatus": {
+                    "description": "Plaid's verification of the account, empty for instantly linked accounts: pending_manual_verification, manually_verified, verification_failed, etc",
+                    "type": "string",
+                    "example": "pending_manual_verification"
+                }
+            }
+        },
+        "salesforce.PlaceComplianceHoldRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "mirrorToLedger": {
+                    "description": "Also suspend the customer's ledger account while the hold is active",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "salesforce.ReleaseComplianceHoldRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "salesforce.ResendStatementEmailRequest": {
+            "type": "object",
+            "required": [
+                "month",
+                "year"
+            ],
+            "properties": {
+                "month": {
+                    "description": "The month of the statement, in English",
+                    "type": "string",
+                    "enum": [
+                        "January",
+                        "February",
+                        "March",
+                        "April",
+                        "May",
+                        "June",
+                        "July",
+                        "August",
+                        "September",
+                        "October",
+                        "November",
+                        "December"
+                    ],
+                    "example": "September"
+                },
+                "year": {
+                    "type": "string",
+                    "example": "2025"
+                }
+            }
+        },
+        "salesforce.SubmitDisputeRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "transactionId"
+            ],
+            "properties": {
+                "details": {
+                    "type": "string",
+                    "example": "Customer was charged twice for the same order"
+                },
+                "reason": {
+                    "description": "One of the reasons customers can choose from in the app",
+                    "type": "string",
+                    "enum": [
+                        "Incorrect amount charged",
+                        "Duplicate transaction",
+                        "Goods not received",
+                        "Goods not as described",
+                        "Billing error",
+                        "Unauthorized transaction",
+                        "Identity theft",
+                        "Card skimming",
+                        "Online fraud"
+                    ],
+                    "example": "Duplicate transaction"
+                },
+                "transactionId": {
+                    "description": "The reference id of the transaction to dispute",
+                    "type": "string",
+                    "example": "ledger.ach.transfer_ach_pull_1756829941484592000"
+                }
+            }
+        },
         "salesforce.Transaction": {
             "type": "object",
             "required": [
                 "accountId",
                 "amountCents",
+                "credit",
                 "date",
                 "id",
                 "merchant",
+                "merchantCategory",
                 "status",
                 "type"
             ],
@@ -172,11 +1557,43 @@ const docTemplatesalesforce = `{
                     "type": "integer",
                     "example": 10000
                 },
+                "cardAcceptor": {
+                    "description": "The merchant details of a card transaction",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/transaction.CardAcceptor"
+                        }
+                    ]
+                },
+                "completionDate": {
+                    "description": "The date a card purchase was completed, when it was authorized first",
+                    "type": "string",
+                    "example": "2025-09-29T08:01:12Z"
+                },
+                "credit": {
+                    "description": "True when funds were credited to the account",
+                    "type": "boolean",
+                    "example": false
+                },
                 "date": {
                     "description": "The date of the transaction",
                     "type": "string",
                     "example": "2025-09-28T12:21:53Z"
                 },
+                "disputeStatus": {
+                    "description": "The status of the customer's dispute of the transaction. Missing for transactions that cannot be disputed.",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "submitted",
+                        "under_review",
+                        "provisionally_credited",
+                        "resolved_customer",
+                        "resolved_merchant",
+                        "withdrawn"
+                    ],
+                    "example": "none"
+                },
                 "id": {
                     "description": "The reference id for the transaction",
                     "type": "string",
@@ -187,6 +1604,11 @@ const docTemplatesalesforce = `{
                     "type": "string",
                     "example": "Alberta Bobbeth Charleson"
                 },
+                "merchantCategory": {
+                    "description": "The merchant category of the card transaction's MCC, empty when there is none",
+                    "type": "string",
+                    "example": "Grocery Stores And Supermarkets"
+                },
                 "status": {
                     "description": "The status of the transaction: ACTIVE, CLOSED, DORMANT, SUSPENDED",
                     "type": "string",
@@ -198,6 +1620,29 @@ const docTemplatesalesforce = `{
                     "example": "ACH_OUT"
                 }
             }
+        },
+        "transaction.CardAcceptor": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "merchant": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/request"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary AddDisputeMessage
+// @Description Adds a message from the customer to an open transaction dispute
+// @Tags Transaction Disputes
+// @Accept json
+// @Produce json
+// @Param id path string true "Dispute id"
+// @Param payload body request.AddDisputeMessageRequest true "Message payload"
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 201 {object} response.DisputeMessageResponse
+// @header 201 {string} Authorization "Bearer token for user authentication"
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/disputes/{id}/messages [post]
+func AddDisputeMessage(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	requestData := new(request.AddDisputeMessageRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	requestData.Message = strings.TrimSpace(requestData.Message)
+
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	transactionDispute, err := requireCustomerDispute(c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+	if !dispute.IsOpen(transactionDispute.Status) {
+		return disputeClosedError(transactionDispute.Id, transactionDispute.Status)
+	}
+
+	var message *dao.TransactionDisputeMessageDao
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		now := clock.Now()
+		message, err = dao.TransactionDisputeMessageDao{}.Create(tx, transactionDispute.Id, dao.DISPUTE_ACTOR_CUSTOMER, requestData.Message, now)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		return dao.TransactionDisputeEventDao{}.Create(tx, transactionDispute.Id, dao.DISPUTE_EVENT_MESSAGE_ADDED, nil, dao.DISPUTE_ACTOR_CUSTOMER, nil, now)
+	})
+	if err != nil {
+		logger.Error("Failed to add dispute message", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to add dispute message: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	logger.Info("Customer added a dispute message", "disputeId", transactionDispute.Id)
+
+	return c.JSON(http.StatusCreated, toDisputeMessageResponse(*message))
+}
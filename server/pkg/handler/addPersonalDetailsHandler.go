@@ -3,8 +3,8 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
-	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/request"
@@ -70,10 +70,10 @@ func AddPersonalDetails(c echo.Context) error {
 		updateData.UserStatus = constant.AGE_VERIFICATION_PASSED
 	}
 
-	result := db.DB.Model(&dao.MasterUserRecordDao{}).Where("id=?", userId).Updates(updateData)
-	if result.Error != nil {
-		logger.Error("Error while updating user", "error", result.Error)
-		return response.InternalServerError(fmt.Sprintf("Error while updating user: %s", result.Error), errtrace.Wrap(result.Error))
+	_, err = dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, updateData, clock.Now())
+	if err != nil {
+		logger.Error("Error while updating user", "error", err)
+		return response.InternalServerError(fmt.Sprintf("Error while updating user: %s", err), errtrace.Wrap(err))
 	}
 
 	return c.NoContent(http.StatusOK)
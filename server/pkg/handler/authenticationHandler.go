@@ -146,6 +146,11 @@ func Login(c echo.Context) error {
 		return c.JSON(http.StatusOK, unregisteredLoginResponse)
 	}
 
+	err = dao.UserPublicKey{}.TouchLastUsed(userPublicKey.ID, c.RealIP(), now)
+	if err != nil {
+		logging.Logger.Warn("Failed to record device last use", "error", err.Error())
+	}
+
 	// NOTE: only the userPublicKey record can be trusted for assigning the JWT claims
 	token, err := security.GenerateOnboardedJwt(user.Id, userPublicKey.PublicKey, &now)
 	if err != nil {
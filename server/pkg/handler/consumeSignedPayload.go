@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/crypto"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// consumeSignedPayload checks that the stored payload is of the type the endpoint
+// expects, verifies the device signature over it against the user's registered
+// public key, records the signature use, and only then marks the payload as
+// consumed. A payload that fails any check is left unconsumed and is never
+// forwarded to the ledger.
+func consumeSignedPayload[T any](c echo.Context, userPublicKey *dao.UserPublicKey, payloadType ledger.SignablePayloadType[T], payloadId string, signature string) (*dao.SignablePayloadDao, *T, *response.ErrorResponse) {
+	logger := logging.GetEchoContextLogger(c)
+
+	payloadRecord, errResponse := dao.RequireConsumablePayload(userPublicKey.UserId, payloadId, payloadType.Name)
+	if errResponse != nil {
+		return nil, nil, errResponse
+	}
+
+	request, err := payloadType.Decode(payloadRecord.Payload)
+	if err != nil {
+		logger.Error("Stored payload does not match its type", "payloadId", payloadId, "payloadType", payloadType.Name, "error", err.Error())
+		return nil, nil, &response.ErrorResponse{ErrorCode: "PAYLOAD_SCHEMA_INVALID", StatusCode: http.StatusUnprocessableEntity, LogMessage: err.Error(), MaybeInnerError: errtrace.Wrap(err)}
+	}
+
+	isValid, err := crypto.VerifyECDSA([]byte(payloadRecord.Payload), userPublicKey.PublicKey, signature)
+	if err != nil {
+		logger.Warn("Failed to verify payload signature", "userPublicKeyId", userPublicKey.ID, "payloadId", payloadId, "error", err.Error())
+		isValid = false
+	}
+
+	err = dao.SignatureUseDao{}.Create(&dao.SignatureUseDao{
+		UserPublicKeyId: userPublicKey.ID,
+		UserId:          userPublicKey.UserId,
+		PayloadId:       payloadId,
+		Endpoint:        c.Path(),
+		IP:              c.RealIP(),
+		IsValid:         isValid,
+	})
+	if err != nil {
+		logger.Error("Failed to record signature use", "userPublicKeyId", userPublicKey.ID, "payloadId", payloadId, "error", err.Error())
+		return nil, nil, &response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Failed to record signature use: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
+	}
+
+	if !isValid {
+		return nil, nil, &response.ErrorResponse{ErrorCode: constant.INVALID_SIGNATURE, Message: constant.INVALID_SIGNATURE_MSG, StatusCode: http.StatusUnauthorized, LogMessage: fmt.Sprintf("Invalid signature for payload %s from userPublicKey %d", payloadId, userPublicKey.ID), MaybeInnerError: errtrace.New("")}
+	}
+
+	errResponse = dao.MarkPayloadConsumed(payloadRecord)
+	if errResponse != nil {
+		return nil, nil, errResponse
+	}
+
+	return payloadRecord, request, nil
+}
@@ -29,6 +29,7 @@ import (
 // @failure 401 {object} response.ErrorResponse
 // @failure 404 {object} response.ErrorResponse
 // @failure 412 {object} response.ErrorResponse
+// @failure 429 {object} response.ErrorResponse
 // @failure 500 {object} response.ErrorResponse
 // @router /account/customer/demographic-update/mobile [post]
 func DemographicUpdateSendOtp(c echo.Context) error {
@@ -54,6 +55,11 @@ func DemographicUpdateSendOtp(c echo.Context) error {
 		return err
 	}
 
+	apiPath := "/account/customer/demographic-update/mobile"
+	if err := utils.EnforceOtpSendRateLimit(c, utils.NewOtpSend(c, userId, user.MobileNo, apiPath, sendOtpRequest.Type)); err != nil {
+		return err
+	}
+
 	otp, err := utils.GenerateOTP(nil)
 	if err != nil {
 		logger.Error(constant.ERROR_IN_GENERATING_OTP)
@@ -65,7 +71,6 @@ func DemographicUpdateSendOtp(c echo.Context) error {
 		return err
 	}
 
-	apiPath := "/account/customer/demographic-update/mobile"
 	otpId, err := updateOtpRecord(c.RealIP(), otp, user.MobileNo, user.Email, apiPath, userId, sendOtpRequest.Type)
 	if err != nil {
 		logger.Error("Error in updating/creating record in user_otp table", "error", err.Error())
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/request"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const deviceRegistrationOtpApiPath = "/register-device/otp"
+
+// @summary Get Device Registration OTP
+// @description Issues otp for unregistered device after login
+// @tags auth
+// @accept json
+// @produce json
+// @param getDeviceRegistrationOtpRequest body request.GetDeviceRegistrationOtpRequest true "Get Device Registration Otp payload"
+// @param Authorization header string true "Bearer token for user authentication"
+// @success 200 {object} response.OtpResponse "Successful otp issuance for logged in user"
+// @failure 400 {object} response.BadRequestErrors
+// @failure 401 {object} response.ErrorResponse
+// @failure 404 {object} response.ErrorResponse
+// @failure 409 {object} response.ErrorResponse
+// @failure 429 {object} response.ErrorResponse
+// @router /register-device/otp [post]
+func GetDeviceRegistrationOtp(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInUnregisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	var requestData request.GetDeviceRegistrationOtpRequest
+	err := c.Bind(&requestData)
+	if err != nil {
+		return response.BadRequestInvalidBody
+	}
+
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	user, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	if errResponse := dao.RequireBelowDeviceLimit(user); errResponse != nil {
+		return *errResponse
+	}
+
+	if err := utils.EnforceOtpSendRateLimit(c, utils.NewOtpSend(c, userId, user.MobileNo, deviceRegistrationOtpApiPath, requestData.Type)); err != nil {
+		return err
+	}
+
+	otp, err := utils.GenerateOTP(nil)
+	if err != nil {
+		logger.Error("Error generating OTP", "error", err.Error())
+		return response.ErrorResponse{ErrorCode: constant.ERROR_IN_GENERATING_OTP, Message: constant.OTP_GENERATING_ERROR_MSG, StatusCode: http.StatusInternalServerError, MaybeInnerError: errtrace.Wrap(err)}
+	}
+
+	err = utils.SendOTPWithType(requestData.Type, otp, *user, logger)
+	if err != nil {
+		return err
+	}
+
+	userOtpRecord := dao.MasterUserOtpDao{
+		OtpId:     uuid.New().String(),
+		UserId:    userId,
+		OtpType:   requestData.Type,
+		Otp:       otp,
+		OtpStatus: constant.OTP_SENT,
+		ApiPath:   deviceRegistrationOtpApiPath,
+		MobileNo:  user.MobileNo,
+		Email:     user.Email,
+		IP:        c.RealIP(),
+		CreatedAt: clock.Now(),
+	}
+
+	otpResult := db.DB.Select("otp_id", "user_id", "otp_type", "otp", "otp_status", "api_path", "mobile_no", "email", "ip", "created_at").Create(&userOtpRecord)
+	if otpResult.Error != nil {
+		return response.ErrorResponse{
+			ErrorCode:       constant.INTERNAL_SERVER_ERROR,
+			Message:         "Failed to generate OTP",
+			StatusCode:      http.StatusInternalServerError,
+			MaybeInnerError: errtrace.Wrap(otpResult.Error),
+		}
+	}
+
+	return c.JSON(http.StatusOK, response.OtpResponse{
+		OtpId:             userOtpRecord.OtpId,
+		OtpExpiryDuration: config.Config.Otp.OtpExpiryDuration,
+	})
+}
+
+// @summary Challenge Device Registration OTP
+// @description Verifies the user-provided OTP and registers the device by generating a key if successful
+// @tags auth
+// @accept json
+// @produce json
+// @param challengeDeviceRegistrationOtpRequest body request.ChallengeDeviceRegistrationOtpRequest true "Challenge Device Registration Otp payload"
+// @param Authorization header string true "Bearer token for user authentication"
+// @success 200 {object} response.ChallengeDeviceRegistrationOtpResponse "Successful otp verification for device registration"
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @failure 400 {object} response.BadRequestErrors
+// @failure 401 {object} response.ErrorResponse
+// @failure 404 {object} response.ErrorResponse
+// @failure 409 {object} response.ErrorResponse
+// @failure 500 {object} response.ErrorResponse
+// @router /register-device/otp/verify [post]
+func (h *Handler) ChallengeDeviceRegistrationOtp(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInUnregisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c).With("userId", userId)
+
+	var requestData request.ChallengeDeviceRegistrationOtpRequest
+	err := c.Bind(&requestData)
+	if err != nil {
+		return response.BadRequestInvalidBody
+	}
+
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	user, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	// Checked before the OTP is used up and the ledger issues a key, so a user at their
+	// limit can retry once they've revoked a device
+	if errResponse := dao.RequireBelowDeviceLimit(user); errResponse != nil {
+		return *errResponse
+	}
+
+	userOtp, err := utils.VerifyOTP(requestData.OtpId, requestData.OtpValue, deviceRegistrationOtpApiPath)
+	if err != nil {
+		logger.Error("error verifying otp for device registration", "error", err.Error())
+		return response.GenerateOTPErrResponse(errtrace.Wrap(err))
+	}
+	if userOtp.UserId != userId {
+		logger.Error("device registration otp belongs to another user", "otpId", requestData.OtpId)
+		return response.GenerateOTPErrResponse(errtrace.New("device registration otp belongs to another user"))
+	}
+
+	// The ledger verifies the device's signatures with the key and issues the api key its
+	// signed requests are sent with
+	ledgerClient := ledger.CreateLedgerApiClient(config.Config.Ledger)
+	ledgerResponse, err := ledgerClient.AddUserKey(ledger.BuildAddUserKeyRequest(user.Email, requestData.PublicKey))
+	if err != nil {
+		return response.InternalServerError(fmt.Sprintf("Error while calling AddUserKey: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if ledgerResponse.Error != nil {
+		return response.InternalServerError(fmt.Sprintf("Ledger AddUserKey responded with error: %s %s", ledgerResponse.Error.Code, ledgerResponse.Error.Message), errtrace.New(""))
+	}
+	if ledgerResponse.Result == nil || ledgerResponse.Result.KeyID == "" {
+		return response.InternalServerError("Ledger AddUserKey responded without a key id", errtrace.New(""))
+	}
+
+	kmsEncryptedApiKey, err := utils.EncryptKmsBinary(ledgerResponse.Result.ApiKey)
+	if err != nil {
+		return response.InternalServerError(fmt.Sprintf("Error encrypting ledger api key: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	registeredIP := c.RealIP()
+	userPublicKey := dao.UserPublicKey{
+		UserId:             userId,
+		KeyId:              ledgerResponse.Result.KeyID,
+		KmsEncryptedApiKey: kmsEncryptedApiKey,
+		PublicKey:          requestData.PublicKey,
+		RegisteredIP:       &registeredIP,
+	}
+	if requestData.DeviceName != "" {
+		userPublicKey.DeviceName = &requestData.DeviceName
+	}
+	if requestData.DevicePlatform != "" {
+		userPublicKey.DevicePlatform = &requestData.DevicePlatform
+	}
+
+	errResponse = h.RegisterUserDevice(c.Request().Context(), user, &userPublicKey)
+	if errResponse != nil {
+		return *errResponse
+	}
+	logger.Info("Registered device", "userPublicKeyId", userPublicKey.ID)
+
+	now := clock.Now()
+	token, err := security.GenerateOnboardedJwt(userId, userPublicKey.PublicKey, &now)
+	if err != nil {
+		logger.Error("Error generating JWT", "error", err.Error())
+		return response.InternalServerError("Could not generate token", errtrace.Wrap(err))
+	}
+	c.Set("SkipTokenReset", true)
+	c.Response().Header().Set("Authorization", "Bearer "+token)
+
+	return c.JSON(http.StatusOK, response.ChallengeDeviceRegistrationOtpResponse{KeyId: userPublicKey.KeyId})
+}
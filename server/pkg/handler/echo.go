@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"process-api/pkg/admin"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
 	"process-api/pkg/logging"
@@ -13,8 +14,11 @@ import (
 	"process-api/pkg/security"
 	"process-api/pkg/utils"
 	"process-api/pkg/validators"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/sessions"
+	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
@@ -89,12 +93,17 @@ func NewEcho() *echo.Echo {
 	e.Use(middleware.BodyDumpWithConfig(bodyDumpConfig))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     config.Config.Cors.AllowOrigins,
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, security.HeaderIdempotencyKey},
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
 		AllowCredentials: true,
 		ExposeHeaders: []string{
 			echo.HeaderAuthorization,
 			"X-Auth-Token",
+			echo.HeaderRetryAfter,
+			security.HeaderRateLimitLimit,
+			security.HeaderRateLimitRemaining,
+			security.HeaderRateLimitReset,
+			security.HeaderIdempotentReplayed,
 		},
 	}))
 
@@ -102,19 +111,22 @@ func NewEcho() *echo.Echo {
 }
 
 func (h *Handler) BuildRoutes(e *echo.Echo, clientUrl string, env string) {
-	accountGroup := e.Group(clientUrl+"account", security.LoggedInRegisteredUserMiddleware, security.ResetTokenMiddleware)
+	accountGroup := e.Group(clientUrl+"account", security.LoggedInRegisteredUserMiddleware, security.RegisteredDeviceMiddleware, security.ResetTokenMiddleware)
 	onboardingGroup := e.Group(clientUrl+"onboarding", security.OnboardingUserMiddleware, security.ResetTokenMiddleware)
 
 	// Endpoints for users who need to authenticate to recover onboarding
 	recoverOnboardingGroup := e.Group(clientUrl+"recover-onboarding", security.RecoverOnboardingUserMiddleware, security.ResetTokenMiddleware)
 
-	refreshSessionGroup := e.Group(clientUrl+"refresh-session", security.RefreshSessionMiddleware, security.ResetTokenMiddleware)
+	// Endpoints for logged in users registering a new device
+	registerDeviceGroup := e.Group(clientUrl+"register-device", security.LoggedInUnregisteredUserMiddleware, security.ResetTokenMiddleware)
+
+	refreshSessionGroup := e.Group(clientUrl+"refresh-session", security.RefreshSessionMiddleware, security.RegisteredDeviceMiddleware, security.ResetTokenMiddleware)
 
 	refreshSessionGroup.GET("", RefreshSession)
 
 	e.PUT(clientUrl+"onboarding/customer", CreateUser)
 
-	e.POST(clientUrl+"onboarding/emailDuplicate", IsEmailDuplicate)
+	e.POST(clientUrl+"onboarding/emailDuplicate", IsEmailDuplicate, security.RateLimit("email_duplicate", security.RateLimitByIP))
 	e.PUT(clientUrl+"onboarding/customer/:userId/mobile", SendMobileVerificationOtp)
 	e.POST(clientUrl+"onboarding/customer/:userId/mobile", VerifyMobileVerificationOtp)
 	// Todo: To remove this API once new onboarding is in place, as we will not use for the new flow
@@ -123,7 +135,7 @@ func (h *Handler) BuildRoutes(e *echo.Echo, clientUrl string, env string) {
 	e.POST(clientUrl+"onboarding/customer/:userId/password", UpdateCustomerPassword)
 	e.POST(clientUrl+"onboarding/customer/:userId", UpdateCustomer)
 
-	e.POST(clientUrl+"login", Login)
+	e.POST(clientUrl+"login", Login, security.RateLimit("login", security.RateLimitByIP))
 	e.GET(clientUrl+"version", GetApplicationVersion)
 
 	// Endpoint that generates and returns a TwiML XML response for OTP voice calls
@@ -153,7 +165,7 @@ func (h *Handler) BuildRoutes(e *echo.Echo, clientUrl string, env string) {
 	// dashboard API's
 	accountGroup.GET("/dashboard/accounts", ListAccounts)
 
-	accountGroup.POST("/accounts/ach/pull", h.TransactionAchPull)
+	accountGroup.POST("/accounts/ach/pull", h.TransactionAchPull, security.ComplianceHoldMiddleware, security.IdempotencyMiddleware)
 
 	// Handler to suspend an account for 60 days
 
@@ -162,35 +174,48 @@ func (h *Handler) BuildRoutes(e *echo.Echo, clientUrl string, env string) {
 	accountGroup.GET("/status/jobs/:jobId", h.JobStatusHandler)
 
 	accountGroup.GET("/personal-details", GetPersonalDetails)
-	accountGroup.POST("/plaid/link/token", h.PlaidCreateLinkToken)
-	accountGroup.POST("/plaid/link/token/update", h.PlaidUpdateLinkToken)
+	accountGroup.POST("/plaid/link/token", h.PlaidCreateLinkToken, security.ComplianceHoldMiddleware)
+	accountGroup.POST("/plaid/link/token/update", h.PlaidUpdateLinkToken, security.ComplianceHoldMiddleware)
 
-	accountGroup.POST("/plaid/public_token/exchange", h.PlaidExchangePublicToken)
+	accountGroup.POST("/plaid/public_token/exchange", h.PlaidExchangePublicToken, security.ComplianceHoldMiddleware)
 	accountGroup.DELETE("/plaid/account", h.PlaidAccountUnlink)
 	accountGroup.POST("/plaid/accounts/reconnected", h.PlaidAccountsReconnected)
-	accountGroup.POST("/balance/refresh", h.BalanceRefresh)
+	accountGroup.POST("/balance/refresh", h.BalanceRefresh, security.RateLimit("balance_refresh", security.RateLimitByUserId))
 	accountGroup.GET("/balance/refresh/status/:jobId", h.BalanceRefreshStatus)
 
 	// Demographic update APIs
 	accountGroup.POST("/customer/demographic-update/mobile", DemographicUpdateSendOtp)
 	accountGroup.POST("/customer/demographic-update/mobile/verify", DemographicUpdateVerifyOtp)
-	accountGroup.POST("/customer/demographic-update/full-name", SubmitFullNameDemographicUpdates)
-	accountGroup.POST("/customer/demographic-update/address", SubmitAddressDemographicUpdates)
-	accountGroup.GET("/customer/demographic-update/address-autocomplete", DemographicUpdateAddressAutoComplete)
-	accountGroup.POST("/customer/demographic-update/secondary-address-autocomplete", DemographicUpdateSecondaryAddressAutoComplete)
+	accountGroup.POST("/customer/demographic-update/full-name", SubmitFullNameDemographicUpdates, security.IdempotencyMiddleware)
+	accountGroup.POST("/customer/demographic-update/address", SubmitAddressDemographicUpdates, security.IdempotencyMiddleware)
+	accountGroup.GET("/customer/demographic-update/address-autocomplete", DemographicUpdateAddressAutoComplete, security.RateLimit("address_autocomplete", security.RateLimitByUserId))
+	accountGroup.POST("/customer/demographic-update/secondary-address-autocomplete", DemographicUpdateSecondaryAddressAutoComplete, security.RateLimit("address_autocomplete", security.RateLimitByUserId))
 	accountGroup.GET("/customer/demographic-update", GetUserDetailsAndDemographicUpdateStatus)
 
 	// Transaction dispute APIs
-	accountGroup.POST("/customer/transaction/:referenceId/dispute", SubmitTransactionDispute)
+	accountGroup.POST("/customer/transaction/:referenceId/dispute", h.SubmitTransactionDispute, security.IdempotencyMiddleware)
+	accountGroup.GET("/disputes", ListDisputes)
+	accountGroup.GET("/disputes/:id", GetDispute)
+	accountGroup.POST("/disputes/:id/messages", AddDisputeMessage, security.IdempotencyMiddleware)
+	accountGroup.POST("/disputes/:id/evidence", UploadDisputeEvidence, security.IdempotencyMiddleware)
+	accountGroup.POST("/disputes/:id/withdraw", h.WithdrawDispute, security.IdempotencyMiddleware)
 
 	// Membership APIs
 	accountGroup.GET("/membership/status", GetMemberShipStatus)
-	accountGroup.PUT("/membership/status", UpdateMembershipStatus)
+	accountGroup.PUT("/membership/status", UpdateMembershipStatus, security.IdempotencyMiddleware)
 
 	accountGroup.GET("/customer/disclosure-accepted-date", GetDisclosuresAcceptedDate)
 
+	// Registered device APIs
+	accountGroup.GET("/devices", ListDevices)
+	accountGroup.DELETE("/devices/:id", RevokeDevice)
+	accountGroup.GET("/security-activity", ListSecurityActivity)
+
 	recoverOnboardingGroup.POST("/send-otp", RecoverOnboardingOTP)
 	recoverOnboardingGroup.POST("/verify-otp", ChallengeRecoverOnboardingOTP)
+
+	registerDeviceGroup.POST("/otp", GetDeviceRegistrationOtp)
+	registerDeviceGroup.POST("/otp/verify", h.ChallengeDeviceRegistrationOtp)
 }
 
 func (h *Handler) BuildSalesForceRoutes(e *echo.Echo) {
@@ -201,4 +226,82 @@ func (h *Handler) BuildSalesForceRoutes(e *echo.Echo) {
 	salesforceGroup := e.Group("/api/salesforce", salesforce.SalesforceAuth0Middleware())
 	salesforceGroup.GET("/accounts/:ledgerAccountID/transactions", salesforce.SalesforceGetTransactions)
 	salesforceGroup.GET("/accounts/:ledgerAccountID/balance", salesforce.SalesforceGetBalance)
+	salesforceGroup.GET("/customers", salesforce.SalesforceFindCustomer)
+	salesforceGroup.GET("/customers/:userId/card", salesforce.SalesforceGetCustomerCard)
+	salesforceGroup.GET("/customers/:userId/linked-accounts", salesforce.SalesforceGetCustomerLinkedAccounts)
+	salesforceGroup.GET("/customers/:userId/disputes", salesforce.SalesforceGetCustomerDisputes)
+	salesforceGroup.GET("/customers/:userId/demographic-updates", salesforce.SalesforceGetCustomerDemographicUpdates)
+	salesforceGroup.GET("/accounts/:ledgerAccountID/compliance-hold", salesforce.SalesforceGetComplianceHold)
+
+	// Agent actions
+	salesforceHandler := salesforce.Handler{RiverClient: h.RiverClient, Env: h.Env}
+	salesforceGroup.POST("/customers/:userId/card/lock", salesforce.SalesforceLockCustomerCard, salesforce.AgentAction("write:card"))
+	salesforceGroup.POST("/customers/:userId/card/unlock", salesforce.SalesforceUnlockCustomerCard, salesforce.AgentAction("write:card"))
+	salesforceGroup.POST("/customers/:userId/disputes", salesforceHandler.SalesforceSubmitCustomerDispute, salesforce.AgentAction("write:disputes"))
+	salesforceGroup.POST("/customers/:userId/statements/resend", salesforceHandler.SalesforceResendCustomerStatementEmail, salesforce.AgentAction("write:statements"))
+	salesforceGroup.PUT("/accounts/:ledgerAccountID/compliance-hold", salesforce.SalesforcePlaceComplianceHold, salesforce.AgentAction("write:compliance_hold"))
+	salesforceGroup.DELETE("/accounts/:ledgerAccountID/compliance-hold", salesforce.SalesforceReleaseComplianceHold, salesforce.AgentAction("write:compliance_hold"))
+}
+
+func (h *Handler) BuildAdminRoutes(e *echo.Echo, sessionStore sessions.Store) {
+	adminGroup := e.Group("/admin", middleware.RequestID(), session.Middleware(sessionStore), middleware.CSRFWithConfig(middleware.CSRFConfig{
+		TokenLookup:    "form:_csrf",
+		CookiePath:     "/admin",
+		CookieHTTPOnly: true,
+		CookieSecure:   strings.HasPrefix(config.Config.Server.BaseUrl, "https://"),
+		CookieSameSite: http.SameSiteStrictMode,
+	}))
+	adminGroup.Static("/static", "static")
+
+	adminGroup.GET("/login", admin.Login)
+	adminGroup.GET("/callback", admin.Callback)
+	adminGroup.GET("/logout", admin.Logout)
+
+	adminGroup.GET("", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/admin/customers")
+	})
+	// Every route declares the permission it needs; see security.AdminPermissionsForRoles
+	// for the roles that grant each
+	customersRead := security.RequireAdminPermission(security.ADMIN_PERMISSION_CUSTOMERS_READ)
+	auditRead := security.RequireAdminPermission(security.ADMIN_PERMISSION_AUDIT_READ)
+	adminGroup.GET("/customers", admin.SearchCustomers, security.AdminAuthMiddleware, customersRead)
+	adminGroup.GET("/customers/:userId", admin.CustomerDetail, security.AdminAuthMiddleware, customersRead)
+	adminGroup.GET("/customers/:userId/timeline", admin.CustomerTimeline, security.AdminAuthMiddleware, customersRead)
+	adminGroup.POST("/customers/:userId/devices/:deviceId/revoke", admin.RevokeCustomerDevice, security.AdminAuthMiddleware, security.RequireAdminPermission(security.ADMIN_PERMISSION_DEVICES_REVOKE))
+	complianceHold := security.RequireAdminPermission(security.ADMIN_PERMISSION_COMPLIANCE_HOLD)
+	adminGroup.POST("/customers/:userId/compliance-hold", admin.PlaceComplianceHold, security.AdminAuthMiddleware, complianceHold)
+	adminGroup.POST("/customers/:userId/compliance-hold/release", admin.ReleaseComplianceHold, security.AdminAuthMiddleware, complianceHold)
+	identityReview := security.RequireAdminPermission(security.ADMIN_PERMISSION_IDENTITY_REVIEW)
+	adminGroup.POST("/customers/:userId/plaid-accounts/:id/approve", admin.ApprovePlaidAccountOwner, security.AdminAuthMiddleware, identityReview)
+	adminGroup.POST("/customers/:userId/plaid-accounts/:id/reject", admin.RejectPlaidAccountOwner, security.AdminAuthMiddleware, identityReview)
+
+	adminHandler := admin.Handler{RiverClient: h.RiverClient, AuditClient: h.AuditClient, Plaid: h.Plaid}
+	demographicsApprove := security.RequireAdminPermission(security.ADMIN_PERMISSION_DEMOGRAPHICS_APPROVE)
+	adminGroup.GET("/demographic-updates", admin.ListDemographicUpdates, security.AdminAuthMiddleware, customersRead)
+	adminGroup.GET("/demographic-updates/:id", admin.DemographicUpdateDetail, security.AdminAuthMiddleware, customersRead)
+	adminGroup.POST("/demographic-updates/:id/approve", adminHandler.ApproveDemographicUpdate, security.AdminAuthMiddleware, demographicsApprove)
+	adminGroup.POST("/demographic-updates/:id/reject", adminHandler.RejectDemographicUpdate, security.AdminAuthMiddleware, demographicsApprove)
+	adminGroup.GET("/disputes", admin.ListDisputes, security.AdminAuthMiddleware, customersRead)
+	adminGroup.GET("/disputes/:id", admin.DisputeCase, security.AdminAuthMiddleware, customersRead)
+	// Crediting actions also need money:credit, checked by the handler
+	adminGroup.POST("/disputes/:id/:action", adminHandler.TransitionDispute, security.AdminAuthMiddleware, security.RequireAdminPermission(security.ADMIN_PERMISSION_DISPUTES_RESOLVE))
+	// Reviewing a request needs the permission of its action, checked by the handler
+	adminGroup.GET("/approvals", admin.ListApprovals, security.AdminAuthMiddleware, customersRead)
+	adminGroup.POST("/approvals/:id/approve", adminHandler.ApproveRequest, security.AdminAuthMiddleware, customersRead)
+	adminGroup.POST("/approvals/:id/reject", admin.RejectRequest, security.AdminAuthMiddleware, customersRead)
+	adminGroup.GET("/audit-log", admin.AuditLog, security.AdminAuthMiddleware, auditRead)
+	adminGroup.GET("/audit-log/export.csv", admin.ExportAuditLog, security.AdminAuthMiddleware, auditRead)
+	adminGroup.GET("/audit-log/verify", admin.VerifyAuditLog, security.AdminAuthMiddleware, auditRead)
+	adminGroup.GET("/gateway-audits", adminHandler.GatewayAudits, security.AdminAuthMiddleware, auditRead)
+	adminGroup.GET("/gateway-audits/export.csv", adminHandler.ExportGatewayAudits, security.AdminAuthMiddleware, auditRead)
+	webhooksManage := security.RequireAdminPermission(security.ADMIN_PERMISSION_WEBHOOKS_MANAGE)
+	adminGroup.GET("/webhooks", admin.ListWebhookSubscriptions, security.AdminAuthMiddleware, auditRead)
+	adminGroup.POST("/webhooks", admin.CreateWebhookSubscription, security.AdminAuthMiddleware, webhooksManage)
+	adminGroup.POST("/webhooks/:id/disable", admin.DisableWebhookSubscription, security.AdminAuthMiddleware, webhooksManage)
+	adminGroup.GET("/webhooks/deliveries", admin.ListWebhookDeliveries, security.AdminAuthMiddleware, auditRead)
+	adminGroup.GET("/webhooks/deliveries/:id", admin.WebhookDeliveryDetail, security.AdminAuthMiddleware, auditRead)
+	adminGroup.POST("/webhooks/deliveries/:id/redeliver", adminHandler.RedeliverWebhook, security.AdminAuthMiddleware, webhooksManage)
+	adminGroup.GET("/plaid-webhooks", admin.ListPlaidWebhookEvents, security.AdminAuthMiddleware, auditRead)
+	adminGroup.POST("/plaid-webhooks/:id/retry", adminHandler.RetryPlaidWebhookEvent, security.AdminAuthMiddleware, webhooksManage)
+	adminGroup.GET("/plaid-health", admin.PlaidItemHealth, security.AdminAuthMiddleware, auditRead)
 }
@@ -3,8 +3,10 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
+	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/ledger"
 	"process-api/pkg/logging"
@@ -41,6 +43,7 @@ func FreezeCard(c echo.Context) error {
 // @header 200 {string} Authorization "Bearer token for user authentication"
 // @Failure 400 {object} response.BadRequestErrors
 // @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Customer is on compliance hold"
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -62,6 +65,12 @@ func UpdateCardStatusMaybeInactiveReIssue(c echo.Context, statusAction string) e
 	if errResponse != nil {
 		return errResponse
 	}
+	// A customer on compliance hold can still freeze their card but not unfreeze it
+	if statusAction == ledger.UNLOCK {
+		if errResponse := dao.RequireNoComplianceHold(userId); errResponse != nil {
+			return errResponse
+		}
+	}
 	userAccountCard, errResponse := dao.RequireActiveCardHolderForUser(userId)
 	if errResponse != nil {
 		return errResponse
@@ -89,7 +98,7 @@ func UpdateCardStatusMaybeInactiveReIssue(c echo.Context, statusAction string) e
 
 	var cardId string
 
-	if MapLedgerCardStatus(getCardResponse.Result.Card.CardStatus, logger) == "inactive" && (getCardResponse.Result.Card.IsReIssue || userAccountCard.IsReissue) {
+	if ledger.MapCardStatus(getCardResponse.Result.Card.CardStatus, logger) == "inactive" && (getCardResponse.Result.Card.IsReIssue || userAccountCard.IsReissue) {
 		cardId = *userAccountCard.PreviousCardId
 	} else {
 		cardId = userAccountCard.CardId
@@ -126,8 +135,15 @@ func UpdateCardStatusMaybeInactiveReIssue(c echo.Context, statusAction string) e
 
 	if responseData.Result.Card.CardStatus != "" {
 		logger.Info("Updated cardStatus", "status", responseData.Result.Card.CardStatus)
+		if statusAction == ledger.LOCK {
+			// The card is locked in the ledger either way, so subscribers missing out is only logged
+			err := dao.WebhookEventDao{}.Create(db.DB, dao.WEBHOOK_EVENT_CARD_LOCKED, userId, dao.CardLockedEventData{UserId: userId, CardId: cardId, Via: dao.CARD_LOCKED_VIA_APP}, clock.Now())
+			if err != nil {
+				logger.Error("Failed to record card locked webhook event", "error", err.Error())
+			}
+		}
 		return c.JSON(http.StatusOK, response.UpdateCardStatusResponse{
-			UpdatedCardStatus: MapLedgerCardStatus(responseData.Result.Card.CardStatus, logger),
+			UpdatedCardStatus: ledger.MapCardStatus(responseData.Result.Card.CardStatus, logger),
 		})
 	}
 
@@ -10,6 +10,7 @@ import (
 	"process-api/pkg/logging"
 	"process-api/pkg/model/response"
 	"process-api/pkg/security"
+	"process-api/pkg/transaction"
 
 	"braces.dev/errtrace"
 	"github.com/labstack/echo/v4"
@@ -76,7 +77,7 @@ func GetAccountClosureStatus(c echo.Context) error {
 		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: "The ledger responded with an empty result object", MaybeInnerError: errtrace.New("")}
 	}
 
-	finalTransactions, _ := MergeTransactions(listTransactionsResponse.Result.AccountTransactions)
+	finalTransactions, _ := transaction.MergeTransactions(listTransactionsResponse.Result.AccountTransactions)
 
 	var pendingTransactions uint
 	for _, transaction := range finalTransactions {
@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"net/http"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
@@ -54,7 +53,7 @@ func GetCardDetails(c echo.Context) error {
 		return c.JSON(http.StatusOK, response.GetCardResponse{
 			Card: response.CardData{
 				CardId:                 userAccountCard.CardId,
-				CardStatus:             MapLedgerCardStatus("CLOSED", logger),
+				CardStatus:             ledger.MapCardStatus("CLOSED", logger),
 				CardStatusRaw:          "CLOSED",
 				CardMaskNumber:         userAccountCard.CardMaskNumber,
 				CardExpiryDate:         userAccountCard.CardExpirationDate,
@@ -104,7 +103,7 @@ func GetCardDetails(c echo.Context) error {
 				logger.Error("Ledger error getting previous card details", "code", previousCardResponse.Error.Code, "message", previousCardResponse.Error.Message)
 				isPreviousCardFrozen = false
 			} else {
-				previousCardMappedStatus := MapLedgerCardStatus(previousCardResponse.Result.Card.CardStatus, logger)
+				previousCardMappedStatus := ledger.MapCardStatus(previousCardResponse.Result.Card.CardStatus, logger)
 				isPreviousCardFrozen = (previousCardMappedStatus == "frozen")
 			}
 		}
@@ -119,7 +118,7 @@ func GetCardDetails(c echo.Context) error {
 	response := response.GetCardResponse{
 		Card: response.CardData{
 			CardId:                 getCardResponse.Result.Card.CardId,
-			CardStatus:             MapLedgerCardStatus(getCardResponse.Result.Card.CardStatus, logger),
+			CardStatus:             ledger.MapCardStatus(getCardResponse.Result.Card.CardStatus, logger),
 			CardStatusRaw:          getCardResponse.Result.Card.CardStatus,
 			OrderStatus:            getCardResponse.Result.Card.OrderStatus,
 			IsReIssue:              getCardResponse.Result.Card.IsReIssue || userAccountCard.IsReissue,
@@ -138,21 +137,6 @@ func GetCardDetails(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
-func MapLedgerCardStatus(s string, logger *slog.Logger) string {
-	switch s {
-	case "RETURNED_UNDELIVERED", "CARD_IS_NOT_ACTIVATED":
-		return "inactive"
-	case "ACTIVE", "ACTIVATED":
-		return "active"
-	case "TEMPRORY_BLOCKED_BY_CLIENT", "TEMPRORY_BLOCKED_BY_ADMIN":
-		return "frozen"
-	case "DEACTIVATED", "CLOSED", "LOST_STOLEN", "EXPIRED_CARD", "CARD_REQUEST_NOT_PROCESSED":
-		return "cancelled"
-	}
-	logger.Info("Unknown cardStatus in card details", "status", s)
-	return ""
-}
-
 func CreatePayloadResponse(payload interface{}) (*response.BuildPayloadResponse, error) {
 	jsonPayloadBytes, marshallErr := json.Marshal(payload)
 	if marshallErr != nil {
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"process-api/pkg/constant"
@@ -64,7 +63,7 @@ func GetCardLimit(c echo.Context) error {
 		return errResponse
 	}
 
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.GetCardLimitPayload, requestData.PayloadId, requestData.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
@@ -79,14 +78,7 @@ func GetCardLimit(c echo.Context) error {
 
 	var responseData ledger.NetXDApiResponse[ledger.GetCardLimitResult]
 
-	var request ledger.GetCardLimitRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error while unmarshaling payload: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
-	}
-
-	responseData, err = userClient.GetCardLimit(request)
+	responseData, err = userClient.GetCardLimit(*request)
 	if err != nil {
 		logger.Error("Error from GetCardLimit", "error", err.Error())
 		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error from GetCardLimit: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
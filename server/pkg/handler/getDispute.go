@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary GetDispute
+// @Description Gets a transaction dispute with its status timeline, messages and evidence
+// @Tags Transaction Disputes
+// @Produce json
+// @Param id path string true "Dispute id"
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 200 {object} response.DisputeDetailResponse
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/disputes/{id} [get]
+func GetDispute(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	transactionDispute, err := requireCustomerDispute(c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+
+	events, err := dao.TransactionDisputeEventDao{}.FindByTransactionDisputeId(transactionDispute.Id)
+	if err != nil {
+		logger.Error("Failed to find dispute events", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find dispute events: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	messages, err := dao.TransactionDisputeMessageDao{}.FindByTransactionDisputeId(transactionDispute.Id)
+	if err != nil {
+		logger.Error("Failed to find dispute messages", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find dispute messages: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	evidence, err := dao.TransactionDisputeEvidenceDao{}.FindByTransactionDisputeId(transactionDispute.Id)
+	if err != nil {
+		logger.Error("Failed to find dispute evidence", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find dispute evidence: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	// Only status changes are shown to the customer. Ledger entries, escalations and
+	// notifications are operator history.
+	timeline := make([]response.DisputeTimelineEntryResponse, 0, len(events))
+	for _, event := range events {
+		if event.Status == nil {
+			continue
+		}
+		timeline = append(timeline, response.DisputeTimelineEntryResponse{
+			Status:    *event.Status,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	messageResponses := make([]response.DisputeMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		messageResponses = append(messageResponses, toDisputeMessageResponse(message))
+	}
+
+	evidenceResponses := make([]response.DisputeEvidenceResponse, 0, len(evidence))
+	for _, file := range evidence {
+		evidenceResponse, err := toDisputeEvidenceResponse(file)
+		if err != nil {
+			logger.Error("Failed to generate pre-signed url for dispute evidence", "evidenceId", file.Id, "error", err.Error())
+			return response.InternalServerError(fmt.Sprintf("Failed to generate pre-signed url for dispute evidence: %s", err.Error()), errtrace.Wrap(err))
+		}
+		evidenceResponses = append(evidenceResponses, evidenceResponse)
+	}
+
+	return c.JSON(http.StatusOK, response.DisputeDetailResponse{
+		DisputeResponse: toDisputeResponse(*transactionDispute),
+		Timeline:        timeline,
+		Messages:        messageResponses,
+		Evidence:        evidenceResponses,
+	})
+}
+
+// requireCustomerDispute finds a dispute submitted by the user, returning a not found error
+// response for disputes of other users
+func requireCustomerDispute(disputeId string, userId string) (*dao.TransactionDisputeDao, error) {
+	transactionDispute, err := dao.TransactionDisputeDao{}.FindByIdForUser(disputeId, userId)
+	if err != nil {
+		return nil, response.InternalServerError(fmt.Sprintf("Failed to find dispute: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if transactionDispute == nil {
+		return nil, response.NotFoundError(fmt.Sprintf("could not find dispute %s", disputeId), errtrace.New(""))
+	}
+	return transactionDispute, nil
+}
+
+func toDisputeMessageResponse(message dao.TransactionDisputeMessageDao) response.DisputeMessageResponse {
+	// Operators are not named to the customer
+	author := "support"
+	if message.Author == dao.DISPUTE_ACTOR_CUSTOMER {
+		author = dao.DISPUTE_ACTOR_CUSTOMER
+	}
+	return response.DisputeMessageResponse{
+		Id:        message.Id,
+		Author:    author,
+		Body:      message.Body,
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+func toDisputeEvidenceResponse(evidence dao.TransactionDisputeEvidenceDao) (response.DisputeEvidenceResponse, error) {
+	url, err := utils.GeneratePreSignedUrl(evidence.S3Key)
+	if err != nil {
+		return response.DisputeEvidenceResponse{}, errtrace.Wrap(err)
+	}
+	return response.DisputeEvidenceResponse{
+		Id:          evidence.Id,
+		FileName:    evidence.FileName,
+		ContentType: evidence.ContentType,
+		SizeBytes:   evidence.SizeBytes,
+		Url:         url,
+		CreatedAt:   evidence.CreatedAt,
+	}, nil
+}
+
+func disputeClosedError(disputeId string, status string) response.ErrorResponse {
+	return response.ErrorResponse{
+		ErrorCode:       constant.DISPUTE_CLOSED,
+		Message:         constant.DISPUTE_CLOSED_MSG,
+		StatusCode:      http.StatusConflict,
+		LogMessage:      fmt.Sprintf("dispute %s is %s", disputeId, status),
+		MaybeInnerError: errtrace.New(""),
+	}
+}
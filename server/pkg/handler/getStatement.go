@@ -2,7 +2,6 @@ package handler
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"process-api/pkg/config"
@@ -66,7 +65,7 @@ func GetStatement(c echo.Context) error {
 		return errResponse
 	}
 
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.GetStatementPayload, requestData.PayloadId, requestData.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
@@ -80,15 +79,8 @@ func GetStatement(c echo.Context) error {
 	userParamsBuilder := ledger.NewPreSignedParamsBuilder(userPublicKey.PublicKey, requestData.Signature, payloadRecord.Payload, user.Email, decryptedLedgerPassword, userPublicKey.KeyId, decryptedApiKey)
 	userClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, userParamsBuilder)
 
-	var request ledger.GetStatementRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.InternalServerError(fmt.Sprintf("Error while unmarshaling payload: %s", err.Error()), errtrace.Wrap(err))
-	}
-
 	var responseData ledger.NetXDApiResponse[ledger.GetStatementResult]
-	responseData, err = userClient.GetStatement(request)
+	responseData, err = userClient.GetStatement(*request)
 	if err != nil {
 		logger.Error("Error from GetStatement", "error", err.Error())
 		return response.InternalServerError(fmt.Sprintf("Error from GetStatement: %s", err.Error()), errtrace.Wrap(err))
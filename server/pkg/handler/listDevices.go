@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary ListDevices
+// @Description Lists the devices registered to the user's account
+// @Tags devices
+// @Produce json
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 200 {object} response.ListDevicesResponse
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/devices [get]
+func ListDevices(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.ErrorResponse{ErrorCode: constant.UNAUTHORIZED_ACCESS_ERROR, Message: constant.UNAUTHORIZED_ACCESS_ERROR_MSG, StatusCode: http.StatusUnauthorized, LogMessage: "Failed to get user Id from custom context"}
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	user, errResponse := dao.RequireUserWithState(
+		userId, constant.ACTIVE,
+	)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	userPublicKeys, err := dao.UserPublicKey{}.FindActiveByUserId(userId)
+	if err != nil {
+		logger.Error("Failed to find user devices", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find user devices: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	devices := make([]response.DeviceResponse, 0, len(userPublicKeys))
+	for _, userPublicKey := range userPublicKeys {
+		devices = append(devices, response.DeviceResponse{
+			Id:              userPublicKey.ID,
+			Name:            userPublicKey.DeviceName,
+			Platform:        userPublicKey.DevicePlatform,
+			FirstUsedAt:     userPublicKey.CreatedAt,
+			FirstUsedIp:     userPublicKey.RegisteredIP,
+			LastUsedAt:      userPublicKey.LastUsedAt,
+			LastUsedIp:      userPublicKey.LastUsedIP,
+			IsCurrentDevice: userPublicKey.PublicKey == cc.PublicKey,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response.ListDevicesResponse{
+		Devices:     devices,
+		DeviceLimit: dao.DeviceLimitForUser(user),
+	})
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary ListDisputes
+// @Description Lists the transaction disputes the user has submitted, newest first
+// @Tags Transaction Disputes
+// @Produce json
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 200 {object} response.ListDisputesResponse
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/disputes [get]
+func ListDisputes(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	transactionDisputes, err := dao.TransactionDisputeDao{}.FindByUserId(userId)
+	if err != nil {
+		logger.Error("Failed to find user disputes", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find user disputes: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	disputes := make([]response.DisputeResponse, 0, len(transactionDisputes))
+	for _, transactionDispute := range transactionDisputes {
+		disputes = append(disputes, toDisputeResponse(transactionDispute))
+	}
+
+	return c.JSON(http.StatusOK, response.ListDisputesResponse{Disputes: disputes})
+}
+
+func toDisputeResponse(transactionDispute dao.TransactionDisputeDao) response.DisputeResponse {
+	disputeResponse := response.DisputeResponse{
+		Id:                    transactionDispute.Id,
+		TransactionIdentifier: transactionDispute.TransactionIdentifier,
+		Reason:                transactionDispute.Reason,
+		Details:               transactionDispute.Details,
+		Status:                transactionDispute.Status,
+		AmountCents:           transactionDispute.AmountCents,
+		SubmittedAt:           transactionDispute.CreatedAt,
+		ResolutionDueAt:       transactionDispute.ResolutionDueAt,
+		ResolvedAt:            transactionDispute.ResolvedAt,
+		CanWithdraw:           dispute.IsOpen(transactionDispute.Status),
+	}
+	// Other resolution reasons are operator notes, so only the one the customer was
+	// emailed is shared
+	if transactionDispute.Status == constant.DISPUTE_RESOLVED_MERCHANT {
+		disputeResponse.ResolutionReason = transactionDispute.ResolutionReason
+	}
+	return disputeResponse
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/timeline"
+	"slices"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary ListSecurityActivity
+// @Description Lists the security activity on the user's account, newest first: logins, verification codes, device changes and changes to their personal details
+// @Tags devices
+// @Produce json
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Param category query string false "Only list activity of this category" Enums(login,otp,device,demographic_update)
+// @Param before query string false "Only list activity before this time, the nextBefore of the previous page" format(date-time)
+// @Success 200 {object} response.ListSecurityActivityResponse
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/security-activity [get]
+func ListSecurityActivity(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	filter := timeline.Filter{Categories: timeline.SecurityCategories}
+	if category := c.QueryParam("category"); category != "" {
+		if !slices.Contains(timeline.SecurityCategories, timeline.Category(category)) {
+			return response.BadRequestErrors{
+				Errors: []response.BadRequestError{{FieldName: "category", Error: "unknown category"}},
+			}
+		}
+		filter.Categories = []timeline.Category{timeline.Category(category)}
+	}
+	if before := c.QueryParam("before"); before != "" {
+		beforeTime, err := time.Parse(time.RFC3339Nano, before)
+		if err != nil {
+			return response.BadRequestErrors{
+				Errors: []response.BadRequestError{{FieldName: "before", Error: "must be an RFC 3339 time"}},
+			}
+		}
+		filter.Before = beforeTime
+	}
+
+	user, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	page, err := timeline.ForUser(*user, filter)
+	if err != nil {
+		logger.Error("Failed to load user security activity", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to load user security activity: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	// Who took an action is left out, since it may name an operator
+	activity := make([]response.SecurityActivityResponse, 0, len(page.Events))
+	for _, event := range page.Events {
+		activity = append(activity, response.SecurityActivityResponse{
+			At:       event.At,
+			Category: string(event.Category),
+			Type:     event.Type,
+			Summary:  event.Summary,
+			Ip:       event.IP,
+		})
+	}
+
+	listResponse := response.ListSecurityActivityResponse{Activity: activity}
+	if page.HasMore {
+		listResponse.NextBefore = &page.Events[len(page.Events)-1].At
+	}
+	return c.JSON(http.StatusOK, listResponse)
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"process-api/pkg/config"
@@ -64,7 +63,7 @@ func ListStatements(c echo.Context) error {
 		return errResponse
 	}
 
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.LedgerApiRequest.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.ListStatementsPayload, requestData.LedgerApiRequest.PayloadId, requestData.LedgerApiRequest.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
@@ -83,20 +82,8 @@ func ListStatements(c echo.Context) error {
 	userParamsBuilder := ledger.NewPreSignedParamsBuilder(userPublicKey.PublicKey, requestData.LedgerApiRequest.Signature, payloadRecord.Payload, user.Email, decryptedLedgerPassword, userPublicKey.KeyId, decryptedApiKey)
 	userClient := ledger.NewNetXDLedgerApiClient(config.Config.Ledger, userParamsBuilder)
 
-	var request ledger.ListStatementRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.ErrorResponse{
-			ErrorCode:       constant.INTERNAL_SERVER_ERROR,
-			StatusCode:      http.StatusInternalServerError,
-			LogMessage:      fmt.Sprintf("Error while unmarshaling payload: %s", err.Error()),
-			MaybeInnerError: errtrace.Wrap(err),
-		}
-	}
-
 	var responseData ledger.NetXDApiResponse[ledger.ListStatementResult]
-	responseData, err = userClient.ListStatement(request)
+	responseData, err = userClient.ListStatement(*request)
 	if err != nil {
 		logger.Error("Error from ListStatement", "error", err.Error())
 		return response.ErrorResponse{
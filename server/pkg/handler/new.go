@@ -2,6 +2,7 @@ package handler
 
 import (
 	"database/sql"
+	"process-api/pkg/audit"
 	"process-api/pkg/config"
 
 	"github.com/plaid/plaid-go/v34/plaid"
@@ -13,4 +14,5 @@ type Handler struct {
 	Plaid       *plaid.APIClient
 	Env         string
 	RiverClient *river.Client[*sql.Tx]
+	AuditClient *audit.AuditServiceClient
 }
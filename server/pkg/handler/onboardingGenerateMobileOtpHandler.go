@@ -35,6 +35,7 @@ import (
 // @failure 401 {object} response.ErrorResponse
 // @failure 404 {object} response.ErrorResponse
 // @failure 412 {object} response.ErrorResponse
+// @failure 429 {object} response.ErrorResponse
 // @failure 500 {object} response.ErrorResponse
 // @router /onboarding/customer/{userId}/mobile [put]
 // @router /onboarding/customer/mobile [put]
@@ -77,6 +78,11 @@ func SendMobileVerificationOtp(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
+	apiPath := "/onboarding/customer/mobile"
+	if err := utils.EnforceOtpSendRateLimit(c, utils.NewOtpSend(c, userId, mobileNo, apiPath, requestData.Type)); err != nil {
+		return err
+	}
+
 	otp, err := utils.GenerateOTP(nil)
 	if err != nil {
 		return response.ErrorResponse{ErrorCode: constant.ERROR_IN_GENERATING_OTP, Message: constant.OTP_GENERATING_ERROR_MSG, StatusCode: http.StatusInternalServerError, MaybeInnerError: errtrace.Wrap(err)}
@@ -101,7 +107,6 @@ func SendMobileVerificationOtp(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	apiPath := "/onboarding/customer/mobile"
 	otpId, err := updateOtpRecord(c.RealIP(), otp, mobileNo, user.Email, apiPath, userId, requestData.Type)
 	if err != nil {
 		logger.Error("Error in updating/creating record in user_otp table", "error", err.Error())
@@ -162,12 +167,12 @@ func updateUserStatus(userId string, userStatus string) error {
 		UserStatus: userStatus,
 	}
 
-	result := db.DB.Model(dao.MasterUserRecordDao{}).Where("id=?", userId).Update(data)
-	if result.Error != nil {
-		return errtrace.Wrap(result.Error)
+	rowsAffected, err := dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, data, clock.Now())
+	if err != nil {
+		return errtrace.Wrap(err)
 	}
 
-	if result.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		return errtrace.Wrap(fmt.Errorf("user's status not updated"))
 	}
 
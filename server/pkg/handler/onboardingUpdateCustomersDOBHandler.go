@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"process-api/pkg/clock"
 	"process-api/pkg/constant"
-	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/request"
@@ -72,11 +71,11 @@ func UpdateCustomerDOB(c echo.Context) error {
 		UserStatus: ageStatus,
 		DOB:        dob,
 	}
-	result := db.DB.Model(&dao.MasterUserRecordDao{}).Where("id=?", userId).Updates(updateData)
+	rowsAffected, err := dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, updateData, clock.Now())
 	// Handle DB errors
-	if result.Error != nil {
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and DOB: %s", result.Error.Error()), MaybeInnerError: errtrace.Wrap(result.Error)}
-	} else if result.RowsAffected <= 0 {
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and DOB: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
+	} else if rowsAffected <= 0 {
 		logger.Error("Error in updating user's state and DOB. No record found")
 		return c.NoContent(http.StatusNotFound)
 	}
@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
-	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/request"
@@ -60,11 +60,11 @@ func UpdateCustomerPassword(c echo.Context) error {
 		UserStatus: constant.PASSWORD_SET,
 	}
 
-	result := db.DB.Model(&dao.MasterUserRecordDao{}).Where("id=?", userId).Updates(updateData)
+	rowsAffected, err := dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, updateData, clock.Now())
 	// Handle DB errors's
-	if result.Error != nil {
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and password: %s", result.Error.Error()), MaybeInnerError: errtrace.Wrap(result.Error)}
-	} else if result.RowsAffected <= 0 {
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and password: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
+	} else if rowsAffected <= 0 {
 		logger.Error("Error in updating user's state and password. No record found")
 		return c.NoContent(http.StatusNotFound)
 	}
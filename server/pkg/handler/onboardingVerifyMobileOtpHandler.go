@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
-	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/request"
@@ -107,9 +107,6 @@ func updateUser(userId string, mobileNo string) error {
 		MobileNo:   mobileNo,
 	}
 
-	result := db.DB.Model(dao.MasterUserRecordDao{}).Where("id=?", userId).Updates(data)
-	if result.Error != nil {
-		return errtrace.Wrap(result.Error)
-	}
-	return nil
+	_, err := dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, data, clock.Now())
+	return errtrace.Wrap(err)
 }
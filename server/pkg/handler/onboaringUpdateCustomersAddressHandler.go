@@ -3,8 +3,8 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
-	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/response"
@@ -75,10 +75,10 @@ func UpdateCustomerAddress(c echo.Context) error {
 		updateData["user_status"] = constant.ADDRESS_CONFIRMED
 	}
 
-	result := db.DB.Model(&dao.MasterUserRecordDao{}).Where("id=?", userId).Updates(updateData)
+	_, err = dao.MasterUserRecordDao{}.UpdateTrackingStatus(userId, updateData, clock.Now())
 	// Handle DB errors
-	if result.Error != nil {
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and address: %s", result.Error.Error()), MaybeInnerError: errtrace.Wrap(result.Error)}
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, Message: constant.INTERNAL_SERVER_ERROR_MSG, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error in updating user's state and address: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
 	}
 
 	logger.Info("Address updated successfully for user", "userId", userId)
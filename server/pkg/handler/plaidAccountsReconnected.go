@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/logging"
@@ -92,5 +93,22 @@ func (h *Handler) PlaidAccountsReconnected(c echo.Context) error {
 		}
 	}
 
+	// Stops the item health monitor's relink reminders
+	err = dao.PlaidItemDao{}.MarkRelinked(item.PlaidItemID, clock.Now())
+	if err != nil {
+		logger.Error("DB error attempting to mark Plaid item as relinked", "plaidItemID", item.PlaidItemID, "plaidAccountID", plaidAccountID, "error", err.Error())
+		return response.ErrorResponse{
+			ErrorCode:       constant.INTERNAL_SERVER_ERROR,
+			StatusCode:      http.StatusInternalServerError,
+			LogMessage:      fmt.Sprintf("DB error attempting to mark Plaid item as relinked. plaidItemID: %s, error: %s", item.PlaidItemID, err.Error()),
+			MaybeInnerError: errtrace.Wrap(err),
+		}
+	}
+
+	err = plaid.QueueSardineShare(c.Request().Context(), h.RiverClient, item.PlaidItemID, plaid.SardineRelinkScopes...)
+	if err != nil {
+		logger.Error("Failed to queue resyncing reconnected accounts with sardine", "plaidItemID", item.PlaidItemID, "error", err.Error())
+	}
+
 	return cc.NoContent(http.StatusOK)
 }
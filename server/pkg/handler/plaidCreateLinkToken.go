@@ -43,7 +43,7 @@ func (h *Handler) PlaidCreateLinkToken(c echo.Context) error {
 		return err
 	}
 
-	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB, WebhookURL: h.Config.Plaid.WebhookURL}
+	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB, WebhookURL: h.Config.Plaid.WebhookURL, TransactionsEnabled: h.Config.Plaid.TransactionsEnabled}
 	linkToken, err := ps.LinkTokenCreateRequest(userId, requestData.Platform, h.Config.Plaid.LinkRedirectURI, h.Env, nil)
 	if err != nil {
 		return response.ErrorResponse{
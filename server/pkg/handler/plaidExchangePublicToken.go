@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"process-api/pkg/logging"
@@ -107,5 +108,21 @@ func (h *Handler) PlaidExchangePublicToken(c echo.Context) error {
 		}
 	}
 
+	// The item is linked by now, so failing to match its owners is only logged. Without an
+	// owner name, e.g. for micro-deposit accounts that Identity can't see, the account is
+	// sent for review.
+	err = ps.GetIdentity(userId, plaidItemId, accessToken)
+	if err != nil {
+		logger.Warn("failed to get identity of linked accounts", "plaidItemId", plaidItemId, "error", err.Error())
+	}
+	err = ps.MatchAccountOwners(userId, plaidItemId, plaid.IdentityMatchPolicyFromConfig(h.Config.Plaid), clock.Now())
+	if err != nil {
+		logger.Error("failed to match owners of linked accounts", "plaidItemId", plaidItemId, "error", err.Error())
+	}
+	err = plaid.QueueSardineShare(c.Request().Context(), h.RiverClient, plaidItemId)
+	if err != nil {
+		logger.Error("failed to queue sharing linked accounts with sardine", "plaidItemId", plaidItemId, "error", err.Error())
+	}
+
 	return cc.NoContent(http.StatusCreated)
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/db"
 	"process-api/pkg/logging"
 	"process-api/pkg/plaid"
@@ -29,34 +30,22 @@ func (h *Handler) PlaidWebhookHandler(c echo.Context) error {
 		return c.NoContent(http.StatusBadRequest)
 	}
 
-	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB, WebhookURL: h.Config.Plaid.WebhookURL}
+	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB, WebhookURL: h.Config.Plaid.WebhookURL, TransactionsEnabled: h.Config.Plaid.TransactionsEnabled}
 
 	if !ps.VerifyWebhook(webhookBody, c.Request().Header.Get("Plaid-Verification")) {
 		logger.Error("Webhook signature verification failed")
 		return c.NoContent(http.StatusUnauthorized)
 	}
 
-	webhookType := payload.WebhookType
-	webhookCode := payload.WebhookCode
-	webhookError := payload.Error
-	itemID := payload.ItemID
-	logger.Debug("Received Plaid webhook", "webhookType", webhookType, "webhookCode", webhookCode, "itemID", itemID)
-
-	// NOTE: webhooks are not sent for items linked through the "Instant Micro-deposits" flow (DT-1480)
-	switch payload.WebhookType {
-	case "ITEM":
-		if err := ps.HandleItemWebhook(webhookCode, itemID, webhookError); err != nil {
-			logger.Error("Failed handle item webhook", "error", err.Error(), "webhookCode", webhookCode, "itemID", itemID)
-		}
-	case "AUTH":
-		if payload.AccountID != nil {
-			if err := ps.HandleAuthWebhook(webhookCode, *payload.AccountID); err != nil {
-				logger.Error("Failed handle auth webhook", "error", err.Error(), "webhookCode", webhookCode, "itemID", itemID)
-			}
-		} else {
-			logger.Error("Failed handle auth webhook", "error", "payload missing account_id", "webhookCode", webhookCode, "itemID", itemID)
-		}
+	logger.Debug("Received Plaid webhook", "webhookType", payload.WebhookType, "webhookCode", payload.WebhookCode, "itemID", payload.ItemID)
+
+	event, err := ps.HandleWebhook(c.Request().Context(), h.RiverClient, payload, webhookBody, clock.Now())
+	if err != nil {
+		// Plaid retries webhooks that aren't acknowledged, so it is sent again once it can be stored
+		logger.Error("Failed to store plaid webhook", "error", err.Error(), "webhookType", payload.WebhookType, "webhookCode", payload.WebhookCode, "itemID", payload.ItemID)
+		return c.NoContent(http.StatusInternalServerError)
 	}
+	logger.Debug("Processed Plaid webhook", "eventId", event.Id, "status", event.Status)
 
 	return c.NoContent(http.StatusOK)
 }
@@ -43,6 +43,7 @@ var OnboardingUserStatuses = []string{
 // @failure 404 {object} response.ErrorResponse
 // @failure 409 {object} response.ErrorResponse
 // @Failure 412 {object} response.ErrorResponse
+// @Failure 429 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @router /recover-onboarding/send-otp [post]
 func RecoverOnboardingOTP(c echo.Context) error {
@@ -70,6 +71,11 @@ func RecoverOnboardingOTP(c echo.Context) error {
 		return errResponse
 	}
 
+	apiPath := "/recover-onboarding/send-otp"
+	if err := utils.EnforceOtpSendRateLimit(c, utils.NewOtpSend(c, userId, user.MobileNo, apiPath, requestData.Type)); err != nil {
+		return err
+	}
+
 	otp, err := utils.GenerateOTP(nil)
 	if err != nil {
 		logger.Error("Error generating OTP", "error", err.Error())
@@ -87,7 +93,7 @@ func RecoverOnboardingOTP(c echo.Context) error {
 		OtpType:   requestData.Type,
 		Otp:       otp,
 		OtpStatus: constant.OTP_SENT,
-		ApiPath:   "/recover-onboarding/send-otp",
+		ApiPath:   apiPath,
 		MobileNo:  user.MobileNo,
 		Email:     user.Email,
 		IP:        c.RealIP(),
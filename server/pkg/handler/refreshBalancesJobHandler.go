@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"process-api/pkg/logging"
 	"process-api/pkg/model/response"
 	"process-api/pkg/plaid"
+	"process-api/pkg/sardine"
 	"process-api/pkg/security"
 	"process-api/pkg/utils"
 	"sort"
@@ -281,6 +283,10 @@ func (w *RefreshBalancesWorker) Work(ctx context.Context, job *river.Job[Refresh
 			itemLogger.Error("failed to refresh balances from Plaid", "error", err.Error())
 			continue
 		}
+		err = plaid.QueueSardineShare(ctx, river.ClientFromContext[*sql.Tx](ctx), plaidItemId, sardine.Balance)
+		if err != nil {
+			itemLogger.Error("failed to queue resyncing balances with sardine", "error", err.Error())
+		}
 
 		var accounts []dao.PlaidAccountDao
 		err = db.DB.Where("plaid_item_id = ? AND user_id = ?", plaidItemId, job.Args.UserID).Find(&accounts).Error
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+// RegisterUserDevice stores a newly registered device key, enforcing the user's
+// device limit, and alerts the user by email that a new device was added.
+func (h *Handler) RegisterUserDevice(ctx context.Context, user *dao.MasterUserRecordDao, userPublicKey *dao.UserPublicKey) *response.ErrorResponse {
+	errResponse := dao.CreateUserPublicKey(user, userPublicKey)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	deviceName := "Unknown device"
+	if userPublicKey.DeviceName != nil && *userPublicKey.DeviceName != "" {
+		deviceName = *userPublicKey.DeviceName
+	}
+	ipAddress := "Unknown"
+	if userPublicKey.RegisteredIP != nil {
+		ipAddress = *userPublicKey.RegisteredIP
+	}
+
+	// The device is registered at this point, so failing to enqueue the alert must not fail the registration
+	_, err := h.RiverClient.Insert(ctx, NewDeviceAlertEmailJobArgs{
+		FirstName:    user.FirstName,
+		Email:        user.Email,
+		DeviceName:   deviceName,
+		IpAddress:    ipAddress,
+		RegisteredAt: userPublicKey.CreatedAt,
+	}, nil)
+	if err != nil {
+		logging.Logger.Error("Failed to enqueue new device alert email", "userId", user.Id, "error", err.Error())
+	}
+
+	return nil
+}
+
+func sendNewDeviceAlertEmail(args NewDeviceAlertEmailJobArgs) error {
+	emailData := response.NewDeviceAlertEmailTemplateData{
+		FirstName:    args.FirstName,
+		DeviceName:   args.DeviceName,
+		RegisteredAt: args.RegisteredAt.UTC().Format("January 2, 2006 3:04 PM MST"),
+		IpAddress:    args.IpAddress,
+	}
+
+	templateName := config.Config.Email.TemplateDirectory + constant.NEW_DEVICE_ALERT_TEMPLATE_NAME
+	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	emailSubject := "A new device was added to your DreamFi account"
+	err = utils.SendEmail(args.FirstName, args.Email, emailSubject, htmlBody)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return nil
+}
+
+type NewDeviceAlertEmailJobArgs struct {
+	FirstName    string    `json:"firstName"`
+	Email        string    `json:"email"`
+	DeviceName   string    `json:"deviceName"`
+	IpAddress    string    `json:"ipAddress"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+func (NewDeviceAlertEmailJobArgs) Kind() string { return "new_device_alert_email" }
+
+func (NewDeviceAlertEmailJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "sendgrid",
+	}
+}
+
+type NewDeviceAlertWorker struct {
+	river.WorkerDefaults[NewDeviceAlertEmailJobArgs]
+}
+
+func RegisterNewDeviceAlertWorker(workers *river.Workers) {
+	river.AddWorker(workers, &NewDeviceAlertWorker{})
+}
+
+func (w *NewDeviceAlertWorker) Work(ctx context.Context, job *river.Job[NewDeviceAlertEmailJobArgs]) error {
+	err := sendNewDeviceAlertEmail(job.Args)
+	if err != nil {
+		logging.Logger.Error("Error sending new device alert email", "err", err)
+		return err
+	}
+	return nil
+}
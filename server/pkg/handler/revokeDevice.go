@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"strconv"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+const DEVICE_REVOKED_BY_CUSTOMER = "customer"
+
+// @Summary RevokeDevice
+// @Description Revokes a registered device's key. Payloads signed by a revoked key are refused.
+// @Tags devices
+// @Produce json
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Param id path string true "Device id"
+// @Success 200 "OK"
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/devices/{id} [delete]
+func RevokeDevice(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.ErrorResponse{ErrorCode: constant.UNAUTHORIZED_ACCESS_ERROR, Message: constant.UNAUTHORIZED_ACCESS_ERROR_MSG, StatusCode: http.StatusUnauthorized, LogMessage: "Failed to get user Id from custom context"}
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(
+		userId, constant.ACTIVE,
+	)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	deviceId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: constant.INVALID_REQUEST, Message: constant.INVALID_REQUEST_MSG, StatusCode: http.StatusBadRequest, LogMessage: fmt.Sprintf("Invalid device id: %s", c.Param("id")), MaybeInnerError: errtrace.Wrap(err)}
+	}
+
+	userPublicKey, err := dao.UserPublicKey{}.FindByIdForUser(deviceId, userId)
+	if err != nil {
+		logger.Error("Failed to find user device", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to find user device: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if userPublicKey == nil || userPublicKey.IsRevoked() {
+		return response.NotFoundError("could not find device", errtrace.New(""))
+	}
+
+	err = dao.UserPublicKey{}.Revoke(userPublicKey.ID, DEVICE_REVOKED_BY_CUSTOMER, clock.Now())
+	if err != nil {
+		logger.Error("Failed to revoke user device", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to revoke user device: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	logger.Info("Revoked user device", "deviceId", userPublicKey.ID)
+
+	return cc.NoContent(http.StatusOK)
+}
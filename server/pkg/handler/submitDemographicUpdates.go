@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
 	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
@@ -14,18 +15,19 @@ import (
 
 	"braces.dev/errtrace"
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo/v4"
 )
 
 const (
-	PENDING  = "pending"
-	ACCEPTED = "accepted"
-	REJECTED = "rejected"
+	PENDING  = constant.DEMOGRAPHIC_UPDATE_PENDING
+	ACCEPTED = constant.DEMOGRAPHIC_UPDATE_ACCEPTED
+	REJECTED = constant.DEMOGRAPHIC_UPDATE_REJECTED
 )
 
 const (
-	FULL_NAME = "full_name"
-	ADDRESS   = "address"
+	FULL_NAME = constant.DEMOGRAPHIC_UPDATE_FULL_NAME
+	ADDRESS   = constant.DEMOGRAPHIC_UPDATE_ADDRESS
 )
 
 // @summary SubmitFullNameDemographicUpdates
@@ -159,13 +161,22 @@ func createDemographicUpdateData(value json.RawMessage, demographicUpdateType, u
 		return response.ErrorResponse{ErrorCode: constant.DEMOGRAPHIC_UPDATE_REQUEST_ALREADY_EXISTS, Message: constant.DEMOGRAPHIC_UPDATE_REQUEST_ALREADY_EXISTS_MSG, StatusCode: http.StatusConflict, MaybeInnerError: errtrace.New("")}
 	}
 
-	result := db.DB.Select("id", "type", "status", "updated_value", "user_id").Create(&demographicUpdate)
-	if result.Error != nil {
-		logger.Error("Error while creating demographic update record", "error", result.Error.Error())
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id", "type", "status", "updated_value", "user_id").Create(&demographicUpdate).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		return errtrace.Wrap(dao.WebhookEventDao{}.Create(tx, dao.WEBHOOK_EVENT_DEMOGRAPHIC_UPDATE_SUBMITTED, userId, dao.DemographicUpdateSubmittedEventData{
+			UserId:              userId,
+			DemographicUpdateId: id,
+			Type:                demographicUpdateType,
+		}, clock.Now()))
+	})
+	if err != nil {
+		logger.Error("Error while creating demographic update record", "error", err.Error())
 		return response.ErrorResponse{
 			ErrorCode:       constant.INTERNAL_SERVER_ERROR,
 			StatusCode:      http.StatusInternalServerError,
-			LogMessage:      fmt.Sprintf("Error while creating demographic update record: %s", result.Error.Error()),
+			LogMessage:      fmt.Sprintf("Error while creating demographic update record: %s", err.Error()),
 			MaybeInnerError: errtrace.Wrap(err),
 		}
 	}
@@ -1,16 +1,19 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/constant"
+	"process-api/pkg/db"
 	"process-api/pkg/db/dao"
 	"process-api/pkg/ledger"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/response"
+	"process-api/pkg/plaid"
 	"process-api/pkg/security"
 	"process-api/pkg/utils"
+	"strconv"
 
 	"braces.dev/errtrace"
 	"github.com/labstack/echo/v4"
@@ -25,11 +28,14 @@ import (
 // @success 200 {object} TransactionAchPullResponse
 // @failure 400 {object} response.BadRequestErrors
 // @failure 401 {object} response.ErrorResponse
+// @failure 403 {object} response.ErrorResponse
 // @failure 404 {object} response.ErrorResponse
 // @failure 409 {object} response.ErrorResponse
 // @failure 410 {object} response.ErrorResponse
 // @failure 412 {object} response.ErrorResponse
+// @failure 422 {object} response.ErrorResponse
 // @failure 500 {object} response.ErrorResponse
+// @failure 503 {object} response.ErrorResponse
 // @router /account/accounts/ach/pull [post]
 func (h *Handler) TransactionAchPull(c echo.Context) error {
 	cc, ok := c.(*security.LoggedInRegisteredUserContext)
@@ -63,10 +69,26 @@ func (h *Handler) TransactionAchPull(c echo.Context) error {
 	if errResponse != nil {
 		return errResponse
 	}
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.AchDebitPayload, requestData.PayloadId, requestData.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
+	if errResponse := dao.RequireAccountOwnerVerified(userId, request.DebtorAccount.Identification); errResponse != nil {
+		return errResponse
+	}
+
+	amountCents, err := strconv.ParseInt(request.TransactionAmount.Amount, 10, 64)
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: "PAYLOAD_SCHEMA_INVALID", StatusCode: http.StatusUnprocessableEntity, LogMessage: fmt.Sprintf("Invalid ACH pull amount: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
+	}
+	ps := plaid.PlaidService{Logger: logger, Plaid: h.Plaid, DB: db.DB}
+	balanceCheck, err := ps.CheckPullBalance(c.Request().Context(), h.RiverClient, userId, payloadRecord.Id, request.DebtorAccount.Identification, amountCents, plaid.PullBalancePolicyFromConfig(h.Config.Plaid), clock.Now())
+	if err != nil {
+		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Failed to check balance for ACH pull: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
+	}
+	if balanceCheck.Decision == dao.ACH_PULL_BALANCE_REJECTED {
+		return balanceCheckErrorResponse(balanceCheck)
+	}
 
 	decryptedLedgerPassword, decryptedApiKey, err := utils.DecryptApiKeyAndLedgerPassword(user.LedgerPassword, user.KmsEncryptedLedgerPassword, userPublicKey.ApiKey, userPublicKey.KmsEncryptedApiKey, logger)
 	if err != nil {
@@ -76,14 +98,7 @@ func (h *Handler) TransactionAchPull(c echo.Context) error {
 
 	userClient := ledger.CreatePaymentApiClient(userPublicKey.PublicKey, requestData.Signature, payloadRecord.Payload, user.Email, decryptedLedgerPassword, userPublicKey.KeyId, decryptedApiKey)
 
-	var request ledger.OutboundAchDebitRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error while unmarshaling payload: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
-	}
-
-	responseData, err := userClient.OutboundAchDebit(request)
+	responseData, err := userClient.OutboundAchDebit(*request)
 	if err != nil {
 		logger.Error("Error from callLedgerOutboundAchDebit", "error", err.Error())
 		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error from callLedgerOutboundAchDebit: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
@@ -109,6 +124,10 @@ func (h *Handler) TransactionAchPull(c echo.Context) error {
 		})
 	}
 
+	if err := (dao.AchPullBalanceCheckDao{}).SetTransactionNumber(balanceCheck.Id, responseData.Result.TransactionNumber); err != nil {
+		logger.Error("Failed to record ACH pull transaction on its balance check", "balanceCheckId", balanceCheck.Id, "error", err.Error())
+	}
+
 	transactionResponse := TransactionAchPullResponse{
 		Reference:         responseData.Result.Api.Reference,
 		Status:            responseData.Result.TransactionStatus,
@@ -119,6 +138,17 @@ func (h *Handler) TransactionAchPull(c echo.Context) error {
 	return c.JSON(http.StatusOK, transactionResponse)
 }
 
+func balanceCheckErrorResponse(check *dao.AchPullBalanceCheckDao) response.ErrorResponse {
+	logMessage := fmt.Sprintf("ACH pull of %d cents rejected by balance check %s", check.AmountCents, check.Id)
+	if check.Reason != nil && *check.Reason == dao.ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE {
+		return response.ErrorResponse{ErrorCode: constant.EXTERNAL_ACCOUNT_BALANCE_UNAVAILABLE, Message: constant.EXTERNAL_ACCOUNT_BALANCE_UNAVAILABLE_MSG, StatusCode: http.StatusServiceUnavailable, LogMessage: logMessage, MaybeInnerError: errtrace.New("")}
+	}
+	if check.Reason != nil && *check.Reason == dao.ACH_PULL_BALANCE_REASON_NOT_LINKED {
+		return response.ErrorResponse{ErrorCode: constant.EXTERNAL_ACCOUNT_NOT_LINKED, Message: constant.EXTERNAL_ACCOUNT_NOT_LINKED_MSG, StatusCode: http.StatusForbidden, LogMessage: logMessage, MaybeInnerError: errtrace.New("")}
+	}
+	return response.ErrorResponse{ErrorCode: constant.INSUFFICIENT_FUNDS, Message: constant.INSUFFICIENT_FUNDS_MSG, StatusCode: http.StatusUnprocessableEntity, LogMessage: logMessage, MaybeInnerError: errtrace.New("")}
+}
+
 type TransactionAchPullRequest struct {
 	Signature string `json:"signature" validate:"required"`
 	PayloadId string `json:"payloadId" validate:"required"`
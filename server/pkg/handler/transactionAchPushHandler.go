@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"process-api/pkg/constant"
@@ -65,7 +64,7 @@ func (h *Handler) TransactionAchPush(c echo.Context) error {
 		return errResponse
 	}
 
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.AchCreditPayload, requestData.PayloadId, requestData.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
@@ -78,14 +77,7 @@ func (h *Handler) TransactionAchPush(c echo.Context) error {
 
 	userClient := ledger.CreatePaymentApiClient(userPublicKey.PublicKey, requestData.Signature, payloadRecord.Payload, user.Email, decryptedLedgerPassword, userPublicKey.KeyId, decryptedApiKey)
 
-	var request ledger.OutboundAchCreditRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error while unmarshaling payload: error: %s", err.Error())}
-	}
-
-	responseData, err := userClient.OutboundAchCredit(request)
+	responseData, err := userClient.OutboundAchCredit(*request)
 	if err != nil {
 		logger.Error("Error from callLedgerOutboundAchCredit", "error", err.Error())
 		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error from callLedgerOutboundAchCredit: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"process-api/pkg/clock"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo/v4"
+)
+
+// disputeEvidenceExtensions are the file types accepted as evidence, by detected content type
+var disputeEvidenceExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// @Summary UploadDisputeEvidence
+// @Description Uploads a file, such as a receipt or screenshot, as evidence for an open transaction dispute
+// @Tags Transaction Disputes
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Dispute id"
+// @Param file formData file true "JPEG, PNG, WebP or PDF file of at most 10 MB"
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 201 {object} response.DisputeEvidenceResponse
+// @header 201 {string} Authorization "Bearer token for user authentication"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/disputes/{id}/evidence [post]
+func UploadDisputeEvidence(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	transactionDispute, err := requireCustomerDispute(c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+	if !dispute.IsOpen(transactionDispute.Status) {
+		return disputeClosedError(transactionDispute.Id, transactionDispute.Status)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return invalidDisputeEvidenceError(fmt.Sprintf("Failed to read evidence file: %s", err.Error()))
+	}
+	if file.Size <= 0 || file.Size > constant.DISPUTE_EVIDENCE_MAX_BYTES {
+		return invalidDisputeEvidenceError(fmt.Sprintf("Evidence file is %d bytes", file.Size))
+	}
+
+	contentType, err := detectContentType(file)
+	if err != nil {
+		return invalidDisputeEvidenceError(fmt.Sprintf("Failed to read evidence file: %s", err.Error()))
+	}
+	extension, ok := disputeEvidenceExtensions[contentType]
+	if !ok {
+		return invalidDisputeEvidenceError(fmt.Sprintf("Evidence file has unsupported content type %s", contentType))
+	}
+
+	count, err := dao.TransactionDisputeEvidenceDao{}.CountByTransactionDisputeId(transactionDispute.Id)
+	if err != nil {
+		logger.Error("Failed to count dispute evidence", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to count dispute evidence: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if count >= constant.DISPUTE_EVIDENCE_MAX_FILES {
+		return response.ErrorResponse{ErrorCode: constant.DISPUTE_EVIDENCE_LIMIT_REACHED, Message: constant.DISPUTE_EVIDENCE_LIMIT_REACHED_MSG, StatusCode: http.StatusUnprocessableEntity, LogMessage: fmt.Sprintf("dispute %s already has %d evidence files", transactionDispute.Id, count), MaybeInnerError: errtrace.New("")}
+	}
+
+	evidenceId := uuid.New().String()
+	s3Key := fmt.Sprintf("disputes/%s/%s%s", transactionDispute.Id, evidenceId, extension)
+	if _, err := utils.UploadFileToS3(file, s3Key); err != nil {
+		logger.Error("Failed to upload dispute evidence to S3", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to upload dispute evidence to S3: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	evidence := dao.TransactionDisputeEvidenceDao{
+		Id:                   evidenceId,
+		TransactionDisputeId: transactionDispute.Id,
+		FileName:             evidenceFileName(file.Filename, extension),
+		ContentType:          contentType,
+		SizeBytes:            file.Size,
+		S3Key:                s3Key,
+		UploadedBy:           dao.DISPUTE_ACTOR_CUSTOMER,
+		CreatedAt:            clock.Now(),
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := (dao.TransactionDisputeEvidenceDao{}).Create(tx, &evidence); err != nil {
+			return errtrace.Wrap(err)
+		}
+		return dao.TransactionDisputeEventDao{}.Create(tx, transactionDispute.Id, dao.DISPUTE_EVENT_EVIDENCE_UPLOADED, nil, dao.DISPUTE_ACTOR_CUSTOMER, &evidence.FileName, evidence.CreatedAt)
+	})
+	if err != nil {
+		logger.Error("Failed to save dispute evidence", "s3Key", s3Key, "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to save dispute evidence: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	logger.Info("Customer uploaded dispute evidence", "disputeId", transactionDispute.Id, "evidenceId", evidenceId, "contentType", contentType, "sizeBytes", file.Size)
+
+	evidenceResponse, err := toDisputeEvidenceResponse(evidence)
+	if err != nil {
+		logger.Error("Failed to generate pre-signed url for dispute evidence", "evidenceId", evidenceId, "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to generate pre-signed url for dispute evidence: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	return c.JSON(http.StatusCreated, evidenceResponse)
+}
+
+func invalidDisputeEvidenceError(logMessage string) response.ErrorResponse {
+	return response.ErrorResponse{ErrorCode: constant.DISPUTE_EVIDENCE_INVALID_FILE, Message: constant.DISPUTE_EVIDENCE_INVALID_FILE_MSG, StatusCode: http.StatusBadRequest, LogMessage: logMessage, MaybeInnerError: errtrace.New("")}
+}
+
+// detectContentType sniffs the file's content rather than trusting the type the client sent
+func detectContentType(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", errtrace.Wrap(err)
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", errtrace.Wrap(err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// evidenceFileName keeps the name the customer gave the file, without any directories, for
+// display. Files without a usable name are named after their type.
+func evidenceFileName(name string, extension string) string {
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		return "evidence" + extension
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		return string(runes[:255])
+	}
+	return name
+}
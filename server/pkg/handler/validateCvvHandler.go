@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"process-api/pkg/constant"
@@ -63,7 +62,7 @@ func ValidateCvv(c echo.Context) error {
 		return errResponse
 	}
 
-	payloadRecord, errResponse := dao.ConsumePayload(user.Id, requestData.PayloadId)
+	payloadRecord, request, errResponse := consumeSignedPayload(c, userPublicKey, ledger.ValidateCvvPayload, requestData.PayloadId, requestData.Signature)
 	if errResponse != nil {
 		return errResponse
 	}
@@ -78,14 +77,7 @@ func ValidateCvv(c echo.Context) error {
 
 	var responseData ledger.NetXDApiResponse[ledger.ValidateCvvResult]
 
-	var request ledger.ValidateCvvRequest
-	err = json.Unmarshal([]byte(payloadRecord.Payload), &request)
-	if err != nil {
-		logger.Error("Error while unmarshaling payload", "error", err.Error())
-		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error while unmarshaling payload: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
-	}
-
-	responseData, err = userClient.ValidateCvv(request)
+	responseData, err = userClient.ValidateCvv(*request)
 	if err != nil {
 		logger.Error("Error from callLedgerGetCard", "error", err.Error())
 		return response.ErrorResponse{ErrorCode: constant.INTERNAL_SERVER_ERROR, StatusCode: http.StatusInternalServerError, LogMessage: fmt.Sprintf("Error from callLedgerGetCard: error: %s", err.Error()), MaybeInnerError: errtrace.Wrap(err)}
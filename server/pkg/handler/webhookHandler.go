@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"process-api/pkg/clock"
 	"process-api/pkg/config"
 	"process-api/pkg/constant"
 	"process-api/pkg/db"
@@ -16,13 +17,14 @@ import (
 	"process-api/pkg/ledger"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/request"
-	"process-api/pkg/model/response"
 	"process-api/pkg/sardine"
+	"process-api/pkg/statement"
 	"process-api/pkg/utils"
 	"time"
 
 	"braces.dev/errtrace"
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo/v4"
 	"github.com/riverqueue/river"
 )
@@ -148,17 +150,12 @@ func (h *Handler) LedgerWebhookHandler(c echo.Context) error {
 		year := fmt.Sprint(statementDate.Year())
 		monthName := statementDate.Month().String()
 
-		baseUrl := "https://middleware.production.dreamfi.com"
-		if h.Env != constant.PROD {
-			baseUrl = "https://middleware.sandbox.dreamfi.com"
-		}
-
 		ctx := context.Background()
 		_, err = h.RiverClient.Insert(ctx, StatementNotificationEmailEnqueueBatchJobArgs{
 			AccountIds: internalStatementPayload.AccountIds,
 			Month:      monthName,
 			Year:       year,
-			BaseUrl:    baseUrl,
+			BaseUrl:    statement.BaseUrl(h.Env),
 		}, nil)
 		if err != nil {
 			logger.Error("Failed to start river job", "err", err)
@@ -267,7 +264,22 @@ func SaveTransactionEventRecord(ledgerTransactionEventRecord dao.LedgerTransacti
 	}
 	ledgerTransactionEventRecord.RawPayload = encryptedPayload
 
-	err = db.DB.Create(&ledgerTransactionEventRecord).Error
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ledgerTransactionEventRecord).Error; err != nil {
+			return errtrace.Wrap(err)
+		}
+		if ledgerTransactionEventRecord.TransactionType != "RETURN" {
+			return nil
+		}
+		return errtrace.Wrap(dao.WebhookEventDao{}.Create(tx, dao.WEBHOOK_EVENT_TRANSFER_RETURNED, accountRecord.UserId, dao.TransferReturnedEventData{
+			UserId:            accountRecord.UserId,
+			EventId:           ledgerTransactionEventRecord.EventId,
+			TransactionNumber: ledgerTransactionEventRecord.TransactionNumber,
+			AmountCents:       ledgerTransactionEventRecord.InstructedAmount,
+			Currency:          ledgerTransactionEventRecord.InstructedCurrency,
+			Channel:           ledgerTransactionEventRecord.Channel,
+		}, clock.Now()))
+	})
 	if err != nil {
 		return errtrace.Wrap(fmt.Errorf("unable to save ledger transaction event record for ach out: %w", err))
 	}
@@ -644,30 +656,6 @@ func (w *TransactionMonitoringWorker) Work(ctx context.Context, job *river.Job[T
 	return nil
 }
 
-func notifyUserOfStatement(notificationEmailArgs StatementNotificationEmailJobArgs) error {
-	appLink := fmt.Sprintf("%s/web/account/%s/statements", notificationEmailArgs.BaseUrl, notificationEmailArgs.AccountId)
-	emailData := response.StatementEmailTemplateData{
-		FirstName: notificationEmailArgs.FirstName,
-		Month:     notificationEmailArgs.Month,
-		Year:      notificationEmailArgs.Year,
-		AppLink:   appLink,
-	}
-
-	templateName := "../email-templates/statementNotificationTemplate.html"
-	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
-	if err != nil {
-		return errtrace.Wrap(err)
-	}
-
-	emailSubject := "Your Monthly DreamFi Statement is Ready"
-	err = utils.SendEmail(notificationEmailArgs.FirstName, notificationEmailArgs.Email, emailSubject, htmlBody)
-	if err != nil {
-		return errtrace.Wrap(err)
-	}
-
-	return nil
-}
-
 type StatementNotificationEmailEnqueueBatchJobArgs struct {
 	AccountIds []string `json:"accountIds"`
 	Month      string   `json:"month"`
@@ -716,7 +704,7 @@ func (w *StatementNotificationEmailEnqueueBatchWorker) Work(ctx context.Context,
 
 	for _, cardWithUser := range *cardsWithUsers {
 		batchParams = append(batchParams, river.InsertManyParams{
-			Args: StatementNotificationEmailJobArgs{
+			Args: statement.NotificationEmailJobArgs{
 				FirstName: cardWithUser.FirstName,
 				Email:     cardWithUser.Email,
 				Month:     job.Args.Month,
@@ -740,37 +728,3 @@ func (w *StatementNotificationEmailEnqueueBatchWorker) Work(ctx context.Context,
 
 	return nil
 }
-
-type StatementNotificationEmailJobArgs struct {
-	FirstName string `json:"firstName"`
-	Email     string `json:"email"`
-	Month     string `json:"month"`
-	Year      string `json:"year"`
-	BaseUrl   string `json:"baseUrl"`
-	AccountId string `json:"accountId"`
-}
-
-func (StatementNotificationEmailJobArgs) Kind() string { return "statement_notification_email" }
-
-func (StatementNotificationEmailJobArgs) InsertOpts() river.InsertOpts {
-	return river.InsertOpts{
-		Queue: "sendgrid",
-	}
-}
-
-type StatementNotificationWorker struct {
-	river.WorkerDefaults[StatementNotificationEmailJobArgs]
-}
-
-func RegisterStatementNotificationWorker(workers *river.Workers) {
-	river.AddWorker(workers, &StatementNotificationWorker{})
-}
-
-func (w *StatementNotificationWorker) Work(ctx context.Context, job *river.Job[StatementNotificationEmailJobArgs]) error {
-	err := notifyUserOfStatement(job.Args)
-	if err != nil {
-		logging.Logger.Error("Error sending new statement notification email", "err", err)
-		return err
-	}
-	return nil
-}
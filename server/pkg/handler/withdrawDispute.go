@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/request"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary WithdrawDispute
+// @Description Withdraws a transaction dispute that has not been resolved. A provisional credit issued for the dispute is reversed.
+// @Tags Transaction Disputes
+// @Accept json
+// @Produce json
+// @Param id path string true "Dispute id"
+// @Param payload body request.WithdrawDisputeRequest false "Withdrawal payload"
+// @Param Authorization header string true "Bearer token for user authentication"
+// @Success 200 {object} response.DisputeResponse
+// @header 200 {string} Authorization "Bearer token for user authentication"
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /account/disputes/{id}/withdraw [post]
+func (h *Handler) WithdrawDispute(c echo.Context) error {
+	cc, ok := c.(*security.LoggedInRegisteredUserContext)
+	if !ok {
+		return response.UnauthorizedError("Failed to get user Id from custom context")
+	}
+
+	userId := cc.UserId
+
+	logger := logging.GetEchoContextLogger(c)
+
+	_, errResponse := dao.RequireUserWithState(userId, constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	requestData := new(request.WithdrawDisputeRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	requestData.Reason = strings.TrimSpace(requestData.Reason)
+
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	transactionDispute, err := requireCustomerDispute(c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+
+	disputeService := dispute.Service{RiverClient: h.RiverClient}
+	withdrawn, err := disputeService.Transition(c.Request().Context(), logger, transactionDispute.Id, dispute.ActionWithdraw, dao.DISPUTE_ACTOR_CUSTOMER, requestData.Reason)
+	if errors.Is(err, dispute.ErrInvalidTransition) {
+		return disputeClosedError(transactionDispute.Id, transactionDispute.Status)
+	}
+	if err != nil {
+		logger.Error("Failed to withdraw dispute", "disputeId", transactionDispute.Id, "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to withdraw dispute: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	return c.JSON(http.StatusOK, toDisputeResponse(*withdrawn))
+}
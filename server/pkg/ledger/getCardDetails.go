@@ -2,6 +2,7 @@ package ledger
 
 import (
 	"fmt"
+	"log/slog"
 	"process-api/pkg/clock"
 
 	"braces.dev/errtrace"
@@ -66,3 +67,19 @@ func (c *NetXDCardApiClient) GetCardDetails(req GetCardDetailsRequest) (NetXDApi
 	err := c.call("ledger.CARD.request", c.url, req, &response)
 	return response, errtrace.Wrap(err)
 }
+
+// MapCardStatus maps the ledger's card status to the status shown to customers
+func MapCardStatus(s string, logger *slog.Logger) string {
+	switch s {
+	case "RETURNED_UNDELIVERED", "CARD_IS_NOT_ACTIVATED":
+		return "inactive"
+	case "ACTIVE", "ACTIVATED":
+		return "active"
+	case "TEMPRORY_BLOCKED_BY_CLIENT", "TEMPRORY_BLOCKED_BY_ADMIN":
+		return "frozen"
+	case "DEACTIVATED", "CLOSED", "LOST_STOLEN", "EXPIRED_CARD", "CARD_REQUEST_NOT_PROCESSED":
+		return "cancelled"
+	}
+	logger.Info("Unknown cardStatus in card details", "status", s)
+	return ""
+}
@@ -0,0 +1,54 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"process-api/pkg/config"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+// SignablePayloadType ties a payload type name stored on signable_payloads to the
+// ledger request it must decode into. Each endpoint that forwards a signed payload
+// consumes exactly one type, so e.g. an ACH credit payload cannot be replayed
+// against the ACH pull route.
+type SignablePayloadType[T any] struct {
+	Name string
+}
+
+var (
+	AchDebitPayload       = SignablePayloadType[OutboundAchDebitRequest]{Name: "ach_debit"}
+	AchCreditPayload      = SignablePayloadType[OutboundAchCreditRequest]{Name: "ach_credit"}
+	ValidateCvvPayload    = SignablePayloadType[ValidateCvvRequest]{Name: "validate_cvv"}
+	GetCardLimitPayload   = SignablePayloadType[GetCardLimitRequest]{Name: "get_card_limit"}
+	GetStatementPayload   = SignablePayloadType[GetStatementRequest]{Name: "get_statement"}
+	ListStatementsPayload = SignablePayloadType[ListStatementRequest]{Name: "list_statements"}
+)
+
+func (t SignablePayloadType[T]) TTL() time.Duration {
+	ttl, ok := config.Config.SignablePayloads.Ttls[t.Name]
+	if !ok {
+		ttl = config.Config.SignablePayloads.DefaultTtl
+	}
+	return time.Duration(ttl) * time.Millisecond
+}
+
+func (t SignablePayloadType[T]) CreateForUser(userId string, payload T) (*response.BuildPayloadResponse, *response.ErrorResponse) {
+	return dao.CreateSignablePayloadForUser(userId, t.Name, t.TTL(), payload)
+}
+
+// Decode strictly parses a stored payload, rejecting fields that are not part of
+// the type's ledger request.
+func (t SignablePayloadType[T]) Decode(rawPayload string) (*T, error) {
+	var payload T
+	decoder := json.NewDecoder(bytes.NewReader([]byte(rawPayload)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("payload does not match %s schema: %w", t.Name, err))
+	}
+	return &payload, nil
+}
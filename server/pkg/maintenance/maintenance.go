@@ -23,6 +23,53 @@ func DeleteExpiredOTPs() {
 	logging.Logger.Info("Count of deleted OTPs", "rowsAffected", result.RowsAffected)
 }
 
+// DeleteExpiredSignablePayloads purges payloads that expired without being signed
+// and consumed. Consumed payloads are kept as a record of what the user signed.
+func DeleteExpiredSignablePayloads() {
+	rowsAffected, err := dao.DeleteExpiredUnconsumedPayloads(clock.Now())
+	if err != nil {
+		logging.Logger.Error("Error while deleting expired signable payloads from db", "error", err)
+		return
+	}
+
+	logging.Logger.Info("Count of deleted signable payloads", "rowsAffected", rowsAffected)
+}
+
+// DeleteOldOtpSendEvents removes send events that have aged out of every OTP send rate limit window
+func DeleteOldOtpSendEvents() {
+	limits := config.Config.Otp.SendRateLimits
+	longestWindow := max(limits.PerUser.Window, limits.PerMobileNo.Window, limits.PerIp.Window, limits.PerCountryPrefix.Window)
+	cutoff := clock.Now().Add(-time.Duration(longestWindow) * time.Millisecond)
+
+	rowsAffected, err := dao.OtpSendEventDao{}.DeleteOlderThan(cutoff)
+	if err != nil {
+		logging.Logger.Error("Error while deleting old OTP send events from db", "error", err)
+		return
+	}
+
+	logging.Logger.Info("Count of deleted OTP send events", "rowsAffected", rowsAffected)
+}
+
+func DeleteExpiredRateLimitCounters() {
+	rowsAffected, err := dao.RateLimitCounterDao{}.DeleteExpired(clock.Now())
+	if err != nil {
+		logging.Logger.Error("Error while deleting expired rate limit counters from db", "error", err)
+		return
+	}
+
+	logging.Logger.Info("Count of deleted rate limit counters", "rowsAffected", rowsAffected)
+}
+
+func DeleteExpiredIdempotencyKeys() {
+	rowsAffected, err := dao.IdempotencyKeyDao{}.DeleteExpired(clock.Now())
+	if err != nil {
+		logging.Logger.Error("Error while deleting expired idempotency keys from db", "error", err)
+		return
+	}
+
+	logging.Logger.Info("Count of deleted idempotency keys", "rowsAffected", rowsAffected)
+}
+
 func DeleteLedgerTokenRecords() {
 	// Calculate the cutoff time for 60 minutes(3600000ms) ago
 	cutoff := clock.Now().Add(-time.Duration(config.Config.Jwt.LedgerTokenExpTime) * time.Millisecond)
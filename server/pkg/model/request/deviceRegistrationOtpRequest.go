@@ -5,7 +5,9 @@ type GetDeviceRegistrationOtpRequest struct {
 }
 
 type ChallengeDeviceRegistrationOtpRequest struct {
-	OtpId     string `json:"otpId" validate:"required"`
-	OtpValue  string `json:"otpValue" validate:"required"`
-	PublicKey string `json:"publicKey" validate:"required"`
+	OtpId          string `json:"otpId" validate:"required"`
+	OtpValue       string `json:"otpValue" validate:"required"`
+	PublicKey      string `json:"publicKey" validate:"required"`
+	DeviceName     string `json:"deviceName" validate:"omitempty,max=100"`
+	DevicePlatform string `json:"devicePlatform" validate:"omitempty,oneof=ios android"`
 }
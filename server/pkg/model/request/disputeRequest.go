@@ -0,0 +1,9 @@
+package request
+
+type AddDisputeMessageRequest struct {
+	Message string `json:"message" validate:"required,max=2000"`
+}
+
+type WithdrawDisputeRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type DeviceResponse struct {
+	Id              uint64     `json:"id" validate:"required"`
+	Name            *string    `json:"name"`
+	Platform        *string    `json:"platform" enums:"ios,android"`
+	FirstUsedAt     time.Time  `json:"firstUsedAt" validate:"required"`
+	FirstUsedIp     *string    `json:"firstUsedIp"`
+	LastUsedAt      *time.Time `json:"lastUsedAt"`
+	LastUsedIp      *string    `json:"lastUsedIp"`
+	IsCurrentDevice bool       `json:"isCurrentDevice" validate:"required"`
+}
+
+type ListDevicesResponse struct {
+	Devices []DeviceResponse `json:"devices" validate:"required"`
+	// 0 means the user may register any number of devices
+	DeviceLimit int `json:"deviceLimit" validate:"required"`
+}
@@ -0,0 +1,51 @@
+package response
+
+import "time"
+
+type DisputeResponse struct {
+	Id                    string     `json:"id" validate:"required"`
+	TransactionIdentifier string     `json:"transactionIdentifier" validate:"required"`
+	Reason                string     `json:"reason" validate:"required"`
+	Details               string     `json:"details"`
+	Status                string     `json:"status" validate:"required" enums:"submitted,under_review,provisionally_credited,resolved_customer,resolved_merchant,withdrawn"`
+	AmountCents           *int64     `json:"amountCents"`
+	SubmittedAt           time.Time  `json:"submittedAt" validate:"required"`
+	ResolutionDueAt       *time.Time `json:"resolutionDueAt"`
+	ResolvedAt            *time.Time `json:"resolvedAt"`
+	// Only given when the dispute is resolved in the merchant's favor
+	ResolutionReason *string `json:"resolutionReason"`
+	CanWithdraw      bool    `json:"canWithdraw" validate:"required"`
+}
+
+type ListDisputesResponse struct {
+	Disputes []DisputeResponse `json:"disputes" validate:"required"`
+}
+
+type DisputeTimelineEntryResponse struct {
+	Status    string    `json:"status" validate:"required" enums:"submitted,under_review,provisionally_credited,resolved_customer,resolved_merchant,withdrawn"`
+	CreatedAt time.Time `json:"createdAt" validate:"required"`
+}
+
+type DisputeMessageResponse struct {
+	Id        uint64    `json:"id" validate:"required"`
+	Author    string    `json:"author" validate:"required" enums:"customer,support"`
+	Body      string    `json:"body" validate:"required"`
+	CreatedAt time.Time `json:"createdAt" validate:"required"`
+}
+
+type DisputeEvidenceResponse struct {
+	Id          string `json:"id" validate:"required"`
+	FileName    string `json:"fileName" validate:"required"`
+	ContentType string `json:"contentType" validate:"required"`
+	SizeBytes   int64  `json:"sizeBytes" validate:"required"`
+	// Pre-signed URL of the file, valid for a limited time
+	Url       string    `json:"url" validate:"required"`
+	CreatedAt time.Time `json:"createdAt" validate:"required"`
+}
+
+type DisputeDetailResponse struct {
+	DisputeResponse
+	Timeline []DisputeTimelineEntryResponse `json:"timeline" validate:"required"`
+	Messages []DisputeMessageResponse       `json:"messages" validate:"required"`
+	Evidence []DisputeEvidenceResponse      `json:"evidence" validate:"required"`
+}
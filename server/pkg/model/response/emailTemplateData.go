@@ -1,5 +1,7 @@
 package response
 
+import "html/template"
+
 type OtpEmailTemplateData struct {
 	FirstName         string `json:"firstName"`
 	LastName          string `json:"lastName"`
@@ -14,3 +16,30 @@ type StatementEmailTemplateData struct {
 	Year      string `json:"year"`
 	AppLink   string `json:"appLink"`
 }
+
+type NewDeviceAlertEmailTemplateData struct {
+	FirstName    string `json:"firstName"`
+	DeviceName   string `json:"deviceName"`
+	RegisteredAt string `json:"registeredAt"`
+	IpAddress    string `json:"ipAddress"`
+}
+
+type DemographicUpdateOutcomeEmailTemplateData struct {
+	FirstName         string `json:"firstName"`
+	UpdateDescription string `json:"updateDescription"`
+	Approved          bool   `json:"approved"`
+	Reason            string `json:"reason"`
+}
+
+type DisputeStatusEmailTemplateData struct {
+	FirstName string `json:"firstName"`
+	Message   string `json:"message"`
+	Reason    string `json:"reason"`
+}
+
+type PlaidRelinkEmailTemplateData struct {
+	FirstName string `json:"firstName"`
+	Message   string `json:"message"`
+	// A deep link into the app, whose scheme html/template would otherwise strip
+	AppLink template.URL `json:"appLink"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type SecurityActivityResponse struct {
+	At       time.Time `json:"at" validate:"required"`
+	Category string    `json:"category" validate:"required" enums:"login,otp,device,demographic_update"`
+	Type     string    `json:"type" validate:"required" enums:"login,otp_sent,device_registered,device_revoked,demographic_update_submitted,demographic_update_reviewed"`
+	Summary  string    `json:"summary" validate:"required"`
+	Ip       *string   `json:"ip"`
+}
+
+type ListSecurityActivityResponse struct {
+	Activity []SecurityActivityResponse `json:"activity" validate:"required"`
+	// Pass as before to read older activity. Omitted on the last page.
+	NextBefore *time.Time `json:"nextBefore"`
+}
@@ -0,0 +1,281 @@
+package plaid
+
+import (
+	"fmt"
+	"process-api/pkg/config"
+	"process-api/pkg/db/dao"
+	"strings"
+	"time"
+	"unicode"
+
+	"braces.dev/errtrace"
+)
+
+// IdentityMatchPolicy is how well an account's owner has to match the customer linking it
+// for money to be pulled from it. Scores run from 0 to 1.
+type IdentityMatchPolicy struct {
+	// Accounts scoring at least this are approved
+	ApproveScore float64
+	// Accounts scoring at least this, but less than ApproveScore, are sent for review. Lower
+	// scoring accounts are rejected.
+	ReviewScore float64
+}
+
+func IdentityMatchPolicyFromConfig(plaidConfig config.PlaidConfigs) IdentityMatchPolicy {
+	return IdentityMatchPolicy{
+		ApproveScore: plaidConfig.IdentityMatchApproveScore,
+		ReviewScore:  plaidConfig.IdentityMatchReviewScore,
+	}
+}
+
+// Decide returns whether an account is approved, sent for review or rejected by how well
+// its owner's name matches. A name in the review range stays there whatever the address
+// scores, since a family member shares both the last name and the address. An account
+// without an owner name, such as one linked with micro-deposits, can't be matched and is
+// reviewed.
+func (p IdentityMatchPolicy) Decide(nameScore *float64) string {
+	switch {
+	case nameScore == nil:
+		return dao.IDENTITY_MATCH_REVIEW
+	case *nameScore >= p.ApproveScore:
+		return dao.IDENTITY_MATCH_APPROVED
+	case *nameScore < p.ReviewScore:
+		return dao.IDENTITY_MATCH_REJECTED
+	default:
+		return dao.IDENTITY_MATCH_REVIEW
+	}
+}
+
+// Words that don't tell people apart
+var nameNoiseWords = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "miss": true, "dr": true,
+	"jr": true, "sr": true, "ii": true, "iii": true, "iv": true, "esq": true, "md": true, "phd": true,
+}
+
+// Common abbreviations in street addresses, so that "Street" and "St" match
+var addressAbbreviations = map[string]string{
+	"street": "st", "avenue": "ave", "av": "ave", "road": "rd", "drive": "dr", "boulevard": "blvd",
+	"lane": "ln", "court": "ct", "place": "pl", "terrace": "ter", "highway": "hwy", "parkway": "pkwy",
+	"circle": "cir", "square": "sq", "trail": "trl", "way": "wy",
+	"apartment": "apt", "suite": "ste", "unit": "apt",
+	"north": "n", "south": "s", "east": "e", "west": "w",
+	"northeast": "ne", "northwest": "nw", "southeast": "se", "southwest": "sw",
+}
+
+// Two words this similar are taken as the same word spelled differently
+const minWordSimilarity = 0.85
+
+// Last names tell people apart better than first names, which are often shortened
+const lastNameWeight = 0.6
+
+// normalizeWords lowercases s and splits it into words. Apostrophes and periods are
+// dropped, so "O'Brien" is "obrien", and other punctuation separates words, so
+// "Smith-Jones" is "smith jones".
+func normalizeWords(s string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '\'' || r == '’' || r == '.':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+func normalizeNameWords(name string) []string {
+	var words []string
+	for _, word := range normalizeWords(name) {
+		if !nameNoiseWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func normalizeAddressWords(address string) []string {
+	words := normalizeWords(address)
+	for i, word := range words {
+		if abbreviation, ok := addressAbbreviations[word]; ok {
+			words[i] = abbreviation
+		}
+	}
+	return words
+}
+
+// wordSimilarity scores how alike two normalized words are. An initial matches any word
+// it begins. Numbers, such as house numbers and zip codes, have to be equal.
+func wordSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if isNumber(a) || isNumber(b) {
+		return 0
+	}
+	if len([]rune(a)) == 1 || len([]rune(b)) == 1 {
+		if []rune(a)[0] == []rune(b)[0] {
+			return 0.9
+		}
+		return 0
+	}
+	similarity := jaroWinkler(a, b)
+	if similarity < minWordSimilarity {
+		return 0
+	}
+	return similarity
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// bestWordSimilarity is how well word matches the closest of words
+func bestWordSimilarity(word string, words []string) float64 {
+	best := 0.0
+	for _, other := range words {
+		best = max(best, wordSimilarity(word, other))
+	}
+	return best
+}
+
+// NameMatchScore scores from 0 to 1 how likely ownerName, as reported by the bank, names
+// the customer. Titles, suffixes, middle names and word order are ignored, so "SMITH,
+// JOHN A JR" matches John Smith. Only one word of a compound last name has to match.
+func NameMatchScore(firstName, lastName, ownerName string) float64 {
+	owner := normalizeNameWords(ownerName)
+	first := normalizeNameWords(firstName)
+	last := normalizeNameWords(lastName)
+	if len(owner) == 0 || len(first) == 0 || len(last) == 0 {
+		return 0
+	}
+
+	firstScore := bestWordSimilarity(first[0], owner)
+	lastScore := 0.0
+	for _, word := range last {
+		lastScore = max(lastScore, bestWordSimilarity(word, owner))
+	}
+	return (1-lastNameWeight)*firstScore + lastNameWeight*lastScore
+}
+
+// AddressMatchScore scores from 0 to 1 how much of the customer's address appears in
+// ownerAddress, as reported by the bank
+func AddressMatchScore(customerAddress, ownerAddress string) float64 {
+	customer := normalizeAddressWords(customerAddress)
+	owner := normalizeAddressWords(ownerAddress)
+	if len(customer) == 0 || len(owner) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, word := range customer {
+		total += bestWordSimilarity(word, owner)
+	}
+	return total / float64(len(customer))
+}
+
+// jaroWinkler is the Jaro-Winkler similarity of a and b, from 0 to 1, which favours
+// strings that share a prefix
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(max(len(ra), len(rb))/2-1, 0)
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		for j := max(0, i-window); j < min(len(rb), i+window+1); j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// customerAddress is the customer's address on one line, as compared with the bank's
+func customerAddress(user dao.MasterUserRecordDao) string {
+	return strings.Join([]string{user.StreetAddress, user.ApartmentNo, user.City, user.State, user.ZipCode}, " ")
+}
+
+// MatchAccountOwners compares the owner of each of the user's accounts at the item with the
+// user, and stores the scores and whether money can be pulled from the account
+func (ps *PlaidService) MatchAccountOwners(userId, plaidItemId string, policy IdentityMatchPolicy, now time.Time) error {
+	logger := ps.Logger.WithGroup("MatchAccountOwners").With("userId", userId, "plaidItemId", plaidItemId)
+
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(userId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if user == nil {
+		return errtrace.Wrap(fmt.Errorf("could not find user %s", userId))
+	}
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForItem(userId, plaidItemId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	for _, account := range accounts {
+		var nameScore, addressScore *float64
+		if account.PrimaryOwnerName != nil {
+			score := NameMatchScore(user.FirstName, user.LastName, *account.PrimaryOwnerName)
+			nameScore = &score
+		}
+		if account.PrimaryOwnerAddress != nil {
+			score := AddressMatchScore(customerAddress(*user), *account.PrimaryOwnerAddress)
+			addressScore = &score
+		}
+		// The address score only informs reviewers
+		status := policy.Decide(nameScore)
+
+		if err := (dao.PlaidAccountDao{}).RecordIdentityMatch(account.ID, nameScore, addressScore, status, now); err != nil {
+			return errtrace.Wrap(err)
+		}
+		accountLogger := logger.With("plaidAccountId", account.PlaidAccountID, "status", status)
+		if nameScore != nil {
+			accountLogger = accountLogger.With("nameScore", *nameScore)
+		}
+		if addressScore != nil {
+			accountLogger = accountLogger.With("addressScore", *addressScore)
+		}
+		accountLogger.Info("matched account owner")
+	}
+	return nil
+}
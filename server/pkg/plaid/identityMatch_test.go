@@ -0,0 +1,41 @@
+package plaid
+
+import (
+	"process-api/pkg/db/dao"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMatchScore(t *testing.T) {
+	assert.Equal(t, 1.0, NameMatchScore("John", "Smith", "John Smith"))
+	assert.Equal(t, 1.0, NameMatchScore("John", "Smith", "SMITH, JOHN"), "Word order and case should be ignored")
+	assert.Equal(t, 1.0, NameMatchScore("John", "Smith", "Mr. John Andrew Smith Jr."), "Titles, suffixes and middle names should be ignored")
+	assert.Equal(t, 1.0, NameMatchScore("Sean", "O'Brien", "SEAN OBRIEN"))
+	assert.Equal(t, 1.0, NameMatchScore("Maria", "Garcia Lopez", "Maria Garcia"), "One word of a compound last name should do")
+	assert.Equal(t, 1.0, NameMatchScore("Ana", "Smith-Jones", "Ana Smith Jones"))
+	assert.Equal(t, 1.0, NameMatchScore("John", "Smith", "Jane Doe and John Smith"), "Either owner of a joint account should match")
+	assert.InDelta(t, 0.96, NameMatchScore("John", "Smith", "J Smith"), 0.001, "An initial should nearly match")
+	assert.Greater(t, NameMatchScore("Jonathan", "Smith", "Jonathon Smith"), 0.95, "A misspelling should nearly match")
+
+	assert.InDelta(t, 0.6, NameMatchScore("John", "Smith", "Jane Smith"), 0.001, "A family member should only match the last name")
+	assert.Equal(t, 0.0, NameMatchScore("John", "Smith", "Acme Holdings LLC"))
+	assert.Equal(t, 0.0, NameMatchScore("John", "Smith", ""))
+}
+
+func TestAddressMatchScore(t *testing.T) {
+	assert.Equal(t, 1.0, AddressMatchScore("123 Main Street Apt 4 Springfield IL 62701", "123 MAIN ST, APT 4, SPRINGFIELD, IL, 62701-1234"), "Abbreviations and zip+4 should match")
+	assert.Less(t, AddressMatchScore("123 Main Street Springfield IL 62701", "125 Main St, Springfield, IL, 62701"), 1.0, "House numbers should have to be equal")
+	assert.Equal(t, 0.0, AddressMatchScore("123 Main Street Springfield IL 62701", "9 Elm Ave, Portland, OR, 97201"))
+	assert.Equal(t, 0.0, AddressMatchScore("123 Main Street", ""))
+}
+
+func TestIdentityMatchPolicyDecide(t *testing.T) {
+	policy := IdentityMatchPolicy{ApproveScore: 0.85, ReviewScore: 0.5}
+	score := func(s float64) *float64 { return &s }
+
+	assert.Equal(t, dao.IDENTITY_MATCH_APPROVED, policy.Decide(score(0.9)))
+	assert.Equal(t, dao.IDENTITY_MATCH_REVIEW, policy.Decide(score(0.6)), "A family member sharing the last name and address should be reviewed")
+	assert.Equal(t, dao.IDENTITY_MATCH_REJECTED, policy.Decide(score(0.4)))
+	assert.Equal(t, dao.IDENTITY_MATCH_REVIEW, policy.Decide(nil), "An account without an owner name should be reviewed")
+}
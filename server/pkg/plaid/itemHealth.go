@@ -0,0 +1,196 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+// What the item health monitor finds wrong with an item
+const (
+	ITEM_HEALTH_LOGIN_REQUIRED     = "login_required"
+	ITEM_HEALTH_PENDING_DISCONNECT = "pending_disconnect"
+	ITEM_HEALTH_CONSENT_EXPIRING   = "consent_expiring"
+	ITEM_HEALTH_STALE_BALANCE      = "stale_balance"
+)
+
+// ItemHealthPolicy is when the item health monitor considers an item unhealthy and how
+// often it reminds the item's user to relink it
+type ItemHealthPolicy struct {
+	ConsentExpiryWarning time.Duration
+	StaleBalanceAfter    time.Duration
+	ReminderInterval     time.Duration
+	MaxReminders         int
+}
+
+func ItemHealthPolicyFromConfig(plaidConfig config.PlaidConfigs) ItemHealthPolicy {
+	day := 24 * time.Hour
+	return ItemHealthPolicy{
+		ConsentExpiryWarning: time.Duration(plaidConfig.ConsentExpiryWarningDays) * day,
+		StaleBalanceAfter:    time.Duration(plaidConfig.StaleBalanceDays) * day,
+		ReminderInterval:     time.Duration(plaidConfig.RelinkReminderIntervalDays) * day,
+		MaxReminders:         plaidConfig.MaxRelinkReminders,
+	}
+}
+
+// itemProblems returns what is wrong with the item, most urgent first. balanceRefreshedAt
+// is when the item's balances were last refreshed, if they ever were.
+func (p ItemHealthPolicy) itemProblems(item dao.PlaidItemDao, balanceRefreshedAt *time.Time, now time.Time) []string {
+	var problems []string
+	if item.ItemError != nil {
+		problems = append(problems, ITEM_HEALTH_LOGIN_REQUIRED)
+	}
+	if item.IsPendingDisconnect {
+		problems = append(problems, ITEM_HEALTH_PENDING_DISCONNECT)
+	}
+	if item.ConsentExpirationTime != nil && item.ConsentExpirationTime.Before(now.Add(p.ConsentExpiryWarning)) {
+		problems = append(problems, ITEM_HEALTH_CONSENT_EXPIRING)
+	}
+	// An item reconnected since its balances were last refreshed gets a fresh start
+	staleBefore := now.Add(-p.StaleBalanceAfter)
+	if balanceRefreshedAt != nil && balanceRefreshedAt.Before(staleBefore) && (item.RelinkedAt == nil || item.RelinkedAt.Before(staleBefore)) {
+		problems = append(problems, ITEM_HEALTH_STALE_BALANCE)
+	}
+	return problems
+}
+
+// relinkReasonFor is why the user is asked to relink an item with these problems, if
+// relinking fixes any of them. A stale balance alone isn't worth a reminder: the user may
+// just not have opened the app, and a refresh that fails on the connection sets an error.
+func relinkReasonFor(problems []string) (string, bool) {
+	for _, problem := range problems {
+		switch problem {
+		case ITEM_HEALTH_LOGIN_REQUIRED:
+			return RELINK_REASON_LOGIN_REQUIRED, true
+		case ITEM_HEALTH_PENDING_DISCONNECT, ITEM_HEALTH_CONSENT_EXPIRING:
+			return RELINK_REASON_EXPIRING, true
+		}
+	}
+	return "", false
+}
+
+// reminderDue reports whether the item's user should be reminded to relink it now. The
+// reminders stop after MaxReminders until the user reconnects the item.
+func (p ItemHealthPolicy) reminderDue(item dao.PlaidItemDao, now time.Time) bool {
+	if item.RelinkRemindersSent >= p.MaxReminders {
+		return false
+	}
+	return item.RelinkRemindedAt == nil || !now.Before(item.RelinkRemindedAt.Add(p.ReminderInterval))
+}
+
+// CheckItemHealth looks for problems with every item, reminds the users of items that need
+// relinking, and stores how many items had each problem today for ops. A reminder that
+// fails to send is logged and tried again on the next run.
+func (ps *PlaidService) CheckItemHealth(ctx context.Context, riverClient *river.Client[*sql.Tx], policy ItemHealthPolicy, now time.Time) (*dao.PlaidItemHealthSnapshotDao, error) {
+	logger := ps.Logger.WithGroup("CheckItemHealth")
+
+	items, err := dao.PlaidItemDao{}.FindAll()
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	balanceRefreshes, err := dao.PlaidAccountDao{}.FindLatestBalanceRefreshByItem()
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	snapshot := &dao.PlaidItemHealthSnapshotDao{
+		Id:           uuid.NewString(),
+		SnapshotDate: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		TotalItems:   len(items),
+		CreatedAt:    now,
+	}
+	for i := range items {
+		item := &items[i]
+		var balanceRefreshedAt *time.Time
+		if refreshedAt, ok := balanceRefreshes[item.PlaidItemID]; ok {
+			balanceRefreshedAt = &refreshedAt
+		}
+
+		problems := policy.itemProblems(*item, balanceRefreshedAt, now)
+		if len(problems) == 0 {
+			snapshot.HealthyItems++
+			continue
+		}
+		for _, problem := range problems {
+			switch problem {
+			case ITEM_HEALTH_LOGIN_REQUIRED:
+				snapshot.LoginRequiredItems++
+			case ITEM_HEALTH_PENDING_DISCONNECT:
+				snapshot.PendingDisconnectItems++
+			case ITEM_HEALTH_CONSENT_EXPIRING:
+				snapshot.ConsentExpiringItems++
+			case ITEM_HEALTH_STALE_BALANCE:
+				snapshot.StaleBalanceItems++
+			}
+		}
+
+		reason, ok := relinkReasonFor(problems)
+		if !ok || !policy.reminderDue(*item, now) {
+			continue
+		}
+		err := ps.sendRelinkReminder(ctx, riverClient, item, reason, now)
+		if err != nil {
+			logger.Error("Failed to send relink reminder", "plaidItemId", item.PlaidItemID, "reason", reason, "error", err.Error())
+			continue
+		}
+		snapshot.RemindersSent++
+	}
+
+	if err := (dao.PlaidItemHealthSnapshotDao{}).Save(snapshot); err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	return snapshot, nil
+}
+
+// ItemHealthArgs is a periodic job that checks the health of every item
+type ItemHealthArgs struct{}
+
+func (ItemHealthArgs) Kind() string { return "plaid_item_health" }
+
+func (ItemHealthArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{Queue: "plaid"}
+}
+
+type ItemHealthWorker struct {
+	river.WorkerDefaults[ItemHealthArgs]
+}
+
+func RegisterItemHealthWorker(workers *river.Workers) {
+	river.AddWorker(workers, &ItemHealthWorker{})
+}
+
+// NewItemHealthPeriodicJob schedules the item health job. It isn't run on start, so that
+// deploys don't each run it again.
+func NewItemHealthPeriodicJob(schedule river.PeriodicSchedule) *river.PeriodicJob {
+	return river.NewPeriodicJob(schedule, func() (river.JobArgs, *river.InsertOpts) {
+		return ItemHealthArgs{}, nil
+	}, nil)
+}
+
+func (w *ItemHealthWorker) Work(ctx context.Context, job *river.Job[ItemHealthArgs]) error {
+	logger := logging.Logger.WithGroup("ItemHealthWorker").With("jobId", job.ID)
+	ps := PlaidService{Logger: logger, DB: db.DB}
+	snapshot, err := ps.CheckItemHealth(ctx, river.ClientFromContext[*sql.Tx](ctx), ItemHealthPolicyFromConfig(config.Config.Plaid), clock.Now())
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	logger.Info("checked plaid item health",
+		"totalItems", snapshot.TotalItems,
+		"healthyItems", snapshot.HealthyItems,
+		"loginRequiredItems", snapshot.LoginRequiredItems,
+		"pendingDisconnectItems", snapshot.PendingDisconnectItems,
+		"consentExpiringItems", snapshot.ConsentExpiringItems,
+		"staleBalanceItems", snapshot.StaleBalanceItems,
+		"remindersSent", snapshot.RemindersSent,
+	)
+	return nil
+}
@@ -2,6 +2,7 @@ package plaid
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -11,11 +12,14 @@ import (
 	"process-api/pkg/db/dao"
 	"process-api/pkg/ledger"
 	"process-api/pkg/utils"
+	"slices"
+	"strings"
 	"time"
 
 	"braces.dev/errtrace"
 	"github.com/jinzhu/gorm"
 	"github.com/plaid/plaid-go/v34/plaid"
+	"github.com/riverqueue/river"
 )
 
 func NewPlaid(config *config.Configs) *plaid.APIClient {
@@ -31,6 +35,8 @@ type PlaidService struct {
 	Logger     *slog.Logger
 	DB         *gorm.DB
 	WebhookURL string
+	// TransactionsEnabled adds Transactions to newly linked items and syncs their transactions
+	TransactionsEnabled bool
 }
 
 type ItemPublicTokenExchangeResponse struct {
@@ -65,6 +71,10 @@ func (ps *PlaidService) LinkTokenCreateRequest(userId, platform, redirectURI, en
 		auth.SetAutomatedMicrodepositsEnabled(true)
 		request.SetAuth(*auth)
 		request.SetProducts([]plaid.Products{plaid.PRODUCTS_AUTH, plaid.PRODUCTS_IDENTITY})
+		// Optional, so institutions without Transactions can still be linked for Auth
+		if ps.TransactionsEnabled {
+			request.SetOptionalProducts([]plaid.Products{plaid.PRODUCTS_TRANSACTIONS})
+		}
 		if ps.WebhookURL != "" {
 			request.SetWebhook(ps.WebhookURL)
 		}
@@ -176,8 +186,12 @@ func (rec *PlaidService) InitialAccountsGetRequest(userId, plaidItemId, accessTo
 }
 
 func (rec *PlaidService) AccountsBalanceGetRequest(userId, plaidItemId, accessToken string) error {
+	return rec.accountsBalanceGet(context.Background(), userId, plaidItemId, accessToken)
+}
+
+// accountsBalanceGet refreshes the item's balances, giving up when ctx is done
+func (rec *PlaidService) accountsBalanceGet(ctx context.Context, userId, plaidItemId, accessToken string) error {
 	logger := rec.Logger.WithGroup("AccountsBalanceGet").With("userId", userId, "plaidItemId", plaidItemId)
-	ctx := context.Background()
 	resp, _, err := rec.Plaid.PlaidApi.AccountsBalanceGet(ctx).AccountsBalanceGetRequest(
 		*plaid.NewAccountsBalanceGetRequest(accessToken),
 	).Execute()
@@ -421,10 +435,13 @@ func (ps *PlaidService) GetIdentity(userId, plaidItemId, accessToken string) err
 
 	var errs []error
 	for _, account := range resp.Accounts {
-		var primaryOwnerName *string
+		var primaryOwnerName, primaryOwnerAddress *string
 		if len(account.Owners) > 0 && len(account.Owners[0].Names) > 0 {
 			primaryOwnerName = &account.Owners[0].Names[0]
 		}
+		if len(account.Owners) > 0 {
+			primaryOwnerAddress = primaryAddress(account.Owners[0].Addresses)
+		}
 
 		if primaryOwnerName != nil {
 			record, err := dao.PlaidAccountDao{}.GetAccountForUser(userId, plaidItemId, account.AccountId)
@@ -435,7 +452,7 @@ func (ps *PlaidService) GetIdentity(userId, plaidItemId, accessToken string) err
 				logger.Error("failed to get account for user", "plaidAccountId", account.AccountId, "error", "record not found")
 				errs = append(errs, errtrace.Wrap(fmt.Errorf("failed to update primary owner name; could not find account record")))
 			} else {
-				err = ps.DB.Model(&record).Update("primary_owner_name", primaryOwnerName).Error
+				err = ps.DB.Model(&record).Updates(map[string]any{"primary_owner_name": primaryOwnerName, "primary_owner_address": primaryOwnerAddress}).Error
 				if err != nil {
 					logger.Error("failed to update primary owner name", "plaidAccountId", account.AccountId, "error", err.Error())
 					errs = append(errs, errtrace.Wrap(err))
@@ -449,6 +466,27 @@ func (ps *PlaidService) GetIdentity(userId, plaidItemId, accessToken string) err
 	return errors.Join(errs...)
 }
 
+// primaryAddress formats the address the bank marks as primary, or its first one, on one line
+func primaryAddress(addresses []plaid.Address) *string {
+	if len(addresses) == 0 {
+		return nil
+	}
+	address := addresses[0]
+	for _, a := range addresses {
+		if a.GetPrimary() {
+			address = a
+			break
+		}
+	}
+
+	parts := []string{address.Data.Street, address.Data.GetCity(), address.Data.GetRegion(), address.Data.GetPostalCode()}
+	formatted := strings.Join(slices.DeleteFunc(parts, func(part string) bool { return part == "" }), ", ")
+	if formatted == "" {
+		return nil
+	}
+	return &formatted
+}
+
 func (ps *PlaidService) CheckForDuplicateAccounts(userID string, institutionID *string, accounts *[]PlaidLinkAccount) (bool, error) {
 	var errs []error
 	hasDuplicates := false
@@ -524,7 +562,12 @@ func (ps *PlaidService) UnlinkItem(userId, plaidItemId, accessToken string) erro
 	}
 
 	err = ps.DB.Transaction(func(tx *gorm.DB) error {
-		err := tx.Where("plaid_item_id=?", plaidItemId).Delete(&dao.PlaidAccountDao{}).Error
+		err := tx.Where("plaid_item_id=?", plaidItemId).Delete(&dao.PlaidTransactionDao{}).Error
+		if err != nil {
+			logger.Error("error deleting plaid transactions", "error", err.Error())
+			return errtrace.Wrap(err)
+		}
+		err = tx.Where("plaid_item_id=?", plaidItemId).Delete(&dao.PlaidAccountDao{}).Error
 		if err != nil {
 			logger.Error("error deleting plaid accounts", "error", err.Error())
 			return errtrace.Wrap(err)
@@ -534,6 +577,12 @@ func (ps *PlaidService) UnlinkItem(userId, plaidItemId, accessToken string) erro
 			logger.Error("error deleting plaid item", "error", err.Error())
 			return errtrace.Wrap(err)
 		}
+		// Removing the item invalidated its processor tokens, so Sardine can't use them anymore
+		err = dao.SardineProcessorTokenDao{}.RevokeForItem(tx, plaidItemId, clock.Now())
+		if err != nil {
+			logger.Error("error revoking sardine processor tokens", "error", err.Error())
+			return errtrace.Wrap(err)
+		}
 		return nil
 	})
 	if err != nil {
@@ -605,25 +654,27 @@ type WebhookPayload struct {
 	ItemID      string               `json:"item_id"`
 	AccountID   *string              `json:"account_id,omitempty"`
 	Error       *WebhookPayloadError `json:"error,omitempty"`
+	// Sent with PENDING_EXPIRATION
+	ConsentExpirationTime *time.Time `json:"consent_expiration_time,omitempty"`
 }
 
-// TODO: [Use river](https://dreamfi.atlassian.net/browse/DT-1365) - plaid docs:
-// It's best to keep your receiver as simple as possible, such as a receiver whose
-// only job is to write the webhook into a queue or reliable storage. This is
-// important for two reasons. First, if the receiver does not respond within 10
-// seconds, the delivery is considered failed. Second, because webhooks can arrive
-// at unpredictable rates. Therefore if you do a lot of work in your receiver -
-// e.g. generating and sending an email - spikes are likely to overwhelm your
-// downstream services, or cause you to be rate-limited if the downstream is a
-// third-party.
-func (ps *PlaidService) HandleItemWebhook(webhookCode, itemID string, webhookError *WebhookPayloadError) error {
-	switch webhookCode {
+func (ps *PlaidService) HandleItemWebhook(ctx context.Context, riverClient *river.Client[*sql.Tx], payload WebhookPayload, item *dao.PlaidItemDao) error {
+	itemID := item.PlaidItemID
+	switch payload.WebhookCode {
 	case "ERROR":
-		itemError := webhookCode
-		if webhookError != nil {
-			itemError = webhookError.ErrorCode
+		itemError := payload.WebhookCode
+		if payload.Error != nil {
+			itemError = payload.Error.ErrorCode
+		}
+		err := dao.PlaidItemDao{}.SetItemError(itemID, itemError)
+		if err != nil {
+			return err
 		}
-		return dao.PlaidItemDao{}.SetItemError(itemID, itemError)
+		// Plaid repeats the webhook while the error lasts; the user only needs telling once
+		if item.ItemError == nil {
+			ps.notifyRelink(ctx, riverClient, item, RELINK_REASON_LOGIN_REQUIRED)
+		}
+		return nil
 	case "LOGIN_REPAIRED":
 		var errs []error
 		err := dao.PlaidItemDao{}.ClearItemError(itemID)
@@ -635,34 +686,44 @@ func (ps *PlaidService) HandleItemWebhook(webhookCode, itemID string, webhookErr
 			errs = append(errs, err)
 		}
 		return errors.Join(errs...)
+	case "NEW_ACCOUNTS_AVAILABLE":
+		return dao.PlaidItemDao{}.SetNewAccountsAvailable(itemID, true)
 	case "USER_ACCOUNT_REVOKED", "USER_PERMISSION_REVOKED":
-		return ps.revokeItem(itemID)
+		return ps.revokeItem(item)
 	case "PENDING_EXPIRATION", "PENDING_DISCONNECT":
-		return dao.PlaidItemDao{}.SetIsPendingDisconnect(itemID, true)
+		err := dao.PlaidItemDao{}.SetIsPendingDisconnect(itemID, true)
+		if err != nil {
+			return err
+		}
+		if payload.ConsentExpirationTime != nil {
+			err = dao.PlaidItemDao{}.SetConsentExpirationTime(itemID, payload.ConsentExpirationTime)
+			if err != nil {
+				return err
+			}
+		}
+		if !item.IsPendingDisconnect {
+			ps.notifyRelink(ctx, riverClient, item, RELINK_REASON_EXPIRING)
+		}
+		return nil
 	}
 	return nil
 }
 
-func (ps *PlaidService) revokeItem(itemID string) error {
-	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(itemID)
-	if err != nil {
-		return errtrace.Wrap(fmt.Errorf("revokeItem: error retrieving item %s: %w", itemID, err))
-	}
-	if item == nil {
-		return errtrace.Wrap(fmt.Errorf("revokeItem: item not found %s", itemID))
-	}
-
+func (ps *PlaidService) revokeItem(item *dao.PlaidItemDao) error {
 	accessToken, err := utils.DecryptPlaidAccessToken(item.EncryptedAccessToken, item.KmsEncryptedAccessToken)
 	if err != nil {
-		return errtrace.Wrap(fmt.Errorf("revokeItem: could not decrypt access token for plaid_item_id %s: %w", itemID, err))
+		return errtrace.Wrap(fmt.Errorf("revokeItem: could not decrypt access token for plaid_item_id %s: %w", item.PlaidItemID, err))
 	}
 
-	return ps.UnlinkItem(item.UserId, itemID, accessToken)
+	return ps.UnlinkItem(item.UserId, item.PlaidItemID, accessToken)
 }
 
-func (ps *PlaidService) HandleAuthWebhook(webhookCode, plaidAccountID string) error {
+func (ps *PlaidService) HandleAuthWebhook(webhookCode string, item *dao.PlaidItemDao, plaidAccountID *string) error {
 	switch webhookCode {
 	case "AUTOMATICALLY_VERIFIED", "VERIFICATION_EXPIRED":
+		if plaidAccountID == nil {
+			return errtrace.Wrap(errors.New("payload missing account_id"))
+		}
 		balanceStatus := PlaidBalanceOmitted
 		// The first call after an automatically_verified verification status update means
 		// that micro-deposits were verified by Plaid, and the balance from an accounts/get
@@ -670,7 +731,11 @@ func (ps *PlaidService) HandleAuthWebhook(webhookCode, plaidAccountID string) er
 		if webhookCode == "AUTOMATICALLY_VERIFIED" {
 			balanceStatus = PlaidBalanceUpdated
 		}
-		return ps.updateAccountsAssociatedWithPlaidAccountID(plaidAccountID, balanceStatus)
+		return ps.updateAccountsAssociatedWithPlaidAccountID(*plaidAccountID, balanceStatus)
+	case "DEFAULT_UPDATE":
+		// The institution changed the account or routing numbers of some of the item's
+		// accounts. We don't store numbers, but their masks and names may have changed too.
+		return ps.refreshItemAccounts(item, PlaidBalanceOmitted)
 	}
 	return nil
 }
@@ -679,7 +744,6 @@ func (ps *PlaidService) HandleAuthWebhook(webhookCode, plaidAccountID string) er
 // If we know that the status of one of the accounts changed, it's likely that the status of any other
 // accounts associated through the item have changed. This fn allows us to update everything at once.
 func (rec *PlaidService) updateAccountsAssociatedWithPlaidAccountID(plaidAccountID string, balanceStatus PlaidBalanceUpdateStatus) error {
-	logger := rec.Logger.WithGroup("updateAccountsAssociatedWithPlaidAccountID").With("plaidAccountID", plaidAccountID)
 	item, err := dao.PlaidItemDao{}.FindFirstItemByPlaidAccountID(plaidAccountID)
 	if err != nil {
 		return errtrace.Wrap(fmt.Errorf("error querying for associated plaid_item given plaid_account_id %s: %w", plaidAccountID, err))
@@ -687,6 +751,12 @@ func (rec *PlaidService) updateAccountsAssociatedWithPlaidAccountID(plaidAccount
 	if item == nil {
 		return errtrace.Wrap(fmt.Errorf("could not find for associated plaid_item given plaid_account_id %s", plaidAccountID))
 	}
+	return rec.refreshItemAccounts(item, balanceStatus)
+}
+
+// refreshItemAccounts updates the item's accounts from /accounts/get
+func (rec *PlaidService) refreshItemAccounts(item *dao.PlaidItemDao, balanceStatus PlaidBalanceUpdateStatus) error {
+	logger := rec.Logger.WithGroup("refreshItemAccounts").With("plaidItemID", item.PlaidItemID)
 	plaidItemID := item.PlaidItemID
 	accessToken, err := utils.DecryptPlaidAccessToken(item.EncryptedAccessToken, item.KmsEncryptedAccessToken)
 	if err != nil {
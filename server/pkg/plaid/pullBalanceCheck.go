@@ -0,0 +1,147 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"process-api/pkg/config"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/sardine"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/riverqueue/river"
+)
+
+// PullBalancePolicy is how fresh the balance of an account debited by an ACH pull has to
+// be, and how much of it the pull may take
+type PullBalancePolicy struct {
+	MaxAge         time.Duration
+	RefreshTimeout time.Duration
+	BufferCents    int64
+}
+
+func PullBalancePolicyFromConfig(plaidConfig config.PlaidConfigs) PullBalancePolicy {
+	return PullBalancePolicy{
+		MaxAge:         time.Duration(plaidConfig.PullBalanceMaxAgeMinutes) * time.Minute,
+		RefreshTimeout: time.Duration(plaidConfig.PullBalanceRefreshTimeoutSeconds) * time.Second,
+		BufferCents:    plaidConfig.PullBalanceBufferCents,
+	}
+}
+
+// decide returns whether a pull of amountCents from an account with the available balance
+// is allowed, and why not
+func (p PullBalancePolicy) decide(amountCents int64, availableBalanceCents *int64) (string, *string) {
+	if availableBalanceCents == nil {
+		reason := dao.ACH_PULL_BALANCE_REASON_NO_BALANCE
+		return dao.ACH_PULL_BALANCE_UNCHECKED, &reason
+	}
+	if amountCents > *availableBalanceCents-p.BufferCents {
+		reason := dao.ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS
+		return dao.ACH_PULL_BALANCE_REJECTED, &reason
+	}
+	return dao.ACH_PULL_BALANCE_ALLOWED, nil
+}
+
+// CheckPullBalance decides whether the linked account with accountNumber has enough
+// available money for a pull of amountCents, refreshing its balance from Plaid first if it
+// is older than the policy allows. The check is stored against the pull's payload.
+//
+// Pulls from accounts that aren't linked are rejected. Linked accounts that Plaid has no
+// balance for, such as those verified with micro-deposits, are let through unchecked. A
+// balance that can't be refreshed in time, or that could belong to more than one linked
+// account with the same mask, rejects the pull. A refreshed balance is also resynced with
+// Sardine.
+func (ps *PlaidService) CheckPullBalance(ctx context.Context, riverClient *river.Client[*sql.Tx], userId, payloadId, accountNumber string, amountCents int64, policy PullBalancePolicy, now time.Time) (*dao.AchPullBalanceCheckDao, error) {
+	logger := ps.Logger.WithGroup("CheckPullBalance").With("userId", userId, "payloadId", payloadId)
+
+	check := &dao.AchPullBalanceCheckDao{
+		Id:          uuid.NewString(),
+		PayloadId:   payloadId,
+		UserId:      userId,
+		AmountCents: amountCents,
+		BufferCents: policy.BufferCents,
+		CreatedAt:   now,
+	}
+
+	account, err := dao.PlaidAccountDao{}.FindAccountForUserByAccountNumber(userId, accountNumber)
+	ambiguous := errors.Is(err, dao.ErrAmbiguousAccountNumber)
+	if err != nil && !ambiguous {
+		return nil, errtrace.Wrap(err)
+	}
+	switch {
+	case ambiguous:
+		// Reading a balance from the wrong bank account is worse than not pulling
+		logger.Warn("more than one linked account matches the debited account number")
+		reason := dao.ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE
+		check.Decision, check.Reason = dao.ACH_PULL_BALANCE_REJECTED, &reason
+	case account == nil:
+		reason := dao.ACH_PULL_BALANCE_REASON_NOT_LINKED
+		check.Decision, check.Reason = dao.ACH_PULL_BALANCE_REJECTED, &reason
+	case account.BalanceRefreshedAt == nil:
+		check.PlaidAccountId = &account.ID
+		reason := dao.ACH_PULL_BALANCE_REASON_NO_BALANCE
+		check.Decision, check.Reason = dao.ACH_PULL_BALANCE_UNCHECKED, &reason
+	default:
+		check.PlaidAccountId = &account.ID
+		if account.BalanceRefreshedAt.Before(now.Add(-policy.MaxAge)) {
+			check.Refreshed = true
+			account, err = ps.refreshPullBalance(ctx, account, policy)
+			if err != nil {
+				logger.Warn("failed to refresh balance for ACH pull", "plaidAccountId", *check.PlaidAccountId, "error", err.Error())
+				reason := dao.ACH_PULL_BALANCE_REASON_BALANCE_UNAVAILABLE
+				check.Decision, check.Reason = dao.ACH_PULL_BALANCE_REJECTED, &reason
+				break
+			}
+			if err := QueueSardineShare(ctx, riverClient, account.PlaidItemID, sardine.Balance); err != nil {
+				logger.Error("failed to queue resyncing balance with sardine", "plaidAccountId", *check.PlaidAccountId, "error", err.Error())
+			}
+		}
+		check.AvailableBalanceCents = account.AvailableBalanceCents
+		check.BalanceRefreshedAt = account.BalanceRefreshedAt
+		check.Decision, check.Reason = policy.decide(amountCents, account.AvailableBalanceCents)
+	}
+
+	if err := (dao.AchPullBalanceCheckDao{}).Create(check); err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	logger.Info("checked balance for ACH pull", "decision", check.Decision, "amountCents", amountCents, "refreshed", check.Refreshed)
+	return check, nil
+}
+
+// refreshPullBalance refreshes the balances of the account's item within the policy's
+// time budget and returns the account as refreshed
+func (ps *PlaidService) refreshPullBalance(ctx context.Context, account *dao.PlaidAccountDao, policy PullBalancePolicy) (*dao.PlaidAccountDao, error) {
+	item, err := dao.PlaidItemDao{}.GetItemForUserByItemID(account.UserID, account.PlaidItemID)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if item == nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not find plaid item %s", account.PlaidItemID))
+	}
+	if item.ItemError != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("plaid item has error %s", *item.ItemError))
+	}
+	accessToken, err := utils.DecryptPlaidAccessToken(item.EncryptedAccessToken, item.KmsEncryptedAccessToken)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, policy.RefreshTimeout)
+	defer cancel()
+	if err := ps.accountsBalanceGet(ctx, account.UserID, account.PlaidItemID, accessToken); err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	refreshed, err := dao.PlaidAccountDao{}.GetAccountForUserByID(account.UserID, account.ID)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	if refreshed == nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not find plaid account %s after refreshing its balance", account.ID))
+	}
+	return refreshed, nil
+}
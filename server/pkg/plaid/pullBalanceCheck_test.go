@@ -0,0 +1,29 @@
+package plaid
+
+import (
+	"process-api/pkg/db/dao"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullBalancePolicyDecide(t *testing.T) {
+	policy := PullBalancePolicy{BufferCents: 5_00}
+	cents := func(c int64) *int64 { return &c }
+
+	decision, reason := policy.decide(45_00, cents(50_00))
+	assert.Equal(t, dao.ACH_PULL_BALANCE_ALLOWED, decision)
+	assert.Nil(t, reason)
+
+	decision, reason = policy.decide(45_01, cents(50_00))
+	assert.Equal(t, dao.ACH_PULL_BALANCE_REJECTED, decision, "The buffer should be kept in the account")
+	assert.Equal(t, dao.ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS, *reason)
+
+	decision, reason = policy.decide(1_00, cents(-10_00))
+	assert.Equal(t, dao.ACH_PULL_BALANCE_REJECTED, decision, "An overdrawn account can't be pulled from")
+	assert.Equal(t, dao.ACH_PULL_BALANCE_REASON_INSUFFICIENT_FUNDS, *reason)
+
+	decision, reason = policy.decide(1_00, nil)
+	assert.Equal(t, dao.ACH_PULL_BALANCE_UNCHECKED, decision, "Some banks don't report an available balance")
+	assert.Equal(t, dao.ACH_PULL_BALANCE_REASON_NO_BALANCE, *reason)
+}
@@ -0,0 +1,157 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"net/url"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+// Why the user is asked to relink an item
+const (
+	// The institution needs the user to log in again before we can use the item
+	RELINK_REASON_LOGIN_REQUIRED = "login_required"
+	// The user's consent or the institution's access for the item is about to run out
+	RELINK_REASON_EXPIRING = "expiring"
+)
+
+type RelinkEmailJobArgs struct {
+	FirstName       string `json:"firstName"`
+	Email           string `json:"email"`
+	InstitutionName string `json:"institutionName"`
+	Reason          string `json:"reason"`
+	RelinkURL       string `json:"relinkUrl"`
+}
+
+func (RelinkEmailJobArgs) Kind() string { return "plaid_relink_email" }
+
+func (RelinkEmailJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "sendgrid",
+	}
+}
+
+type RelinkEmailWorker struct {
+	river.WorkerDefaults[RelinkEmailJobArgs]
+}
+
+func RegisterRelinkEmailWorker(workers *river.Workers) {
+	river.AddWorker(workers, &RelinkEmailWorker{})
+}
+
+func (w *RelinkEmailWorker) Work(ctx context.Context, job *river.Job[RelinkEmailJobArgs]) error {
+	err := sendRelinkEmail(job.Args)
+	if err != nil {
+		logging.Logger.Error("Error sending plaid relink email", "err", err)
+		return err
+	}
+	return nil
+}
+
+// notifyRelink enqueues an email asking the item's user to relink it in the app. Failing
+// to enqueue it is logged rather than failing the webhook, since the item's state is
+// already updated and the app shows it needs relinking either way.
+func (ps *PlaidService) notifyRelink(ctx context.Context, riverClient *river.Client[*sql.Tx], item *dao.PlaidItemDao, reason string) {
+	err := ps.sendRelinkReminder(ctx, riverClient, item, reason, clock.Now())
+	if err != nil {
+		ps.Logger.Error("Failed to send plaid relink email", "plaidItemId", item.PlaidItemID, "reason", reason, "error", err.Error())
+	}
+}
+
+// sendRelinkReminder enqueues an email asking the item's user to relink it, linking to the
+// app's reconnect flow for one of the item's accounts, and records the reminder on the item
+func (ps *PlaidService) sendRelinkReminder(ctx context.Context, riverClient *river.Client[*sql.Tx], item *dao.PlaidItemDao, reason string, now time.Time) error {
+	logger := ps.Logger.WithGroup("sendRelinkReminder").With("plaidItemId", item.PlaidItemID, "reason", reason)
+
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(item.UserId)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("failed to find user %s: %w", item.UserId, err))
+	}
+	if user == nil {
+		return errtrace.Wrap(fmt.Errorf("user %s not found", item.UserId))
+	}
+
+	institutionName := "your bank"
+	var relinkURL string
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForUser(item.UserId)
+	if err != nil {
+		logger.Warn("Failed to find accounts of item to relink", "error", err.Error())
+	}
+	for _, account := range accounts {
+		if account.PlaidItemID != item.PlaidItemID {
+			continue
+		}
+		if account.InstitutionName != nil {
+			institutionName = *account.InstitutionName
+		}
+		relinkURL = relinkURLForAccount(account.ID)
+		break
+	}
+
+	_, err = riverClient.Insert(ctx, RelinkEmailJobArgs{
+		FirstName:       user.FirstName,
+		Email:           user.Email,
+		InstitutionName: institutionName,
+		Reason:          reason,
+		RelinkURL:       relinkURL,
+	}, nil)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return errtrace.Wrap(dao.PlaidItemDao{}.MarkRelinkReminded(item.PlaidItemID, now))
+}
+
+// relinkURLForAccount is the deep link into the app's reconnect flow for the account. The
+// app creates an update mode link token for it with PlaidUpdateLinkToken.
+func relinkURLForAccount(accountID string) string {
+	if config.Config.Plaid.RelinkURL == "" {
+		return ""
+	}
+	return config.Config.Plaid.RelinkURL + "?" + url.Values{"accountId": {accountID}}.Encode()
+}
+
+func sendRelinkEmail(args RelinkEmailJobArgs) error {
+	var subject, message string
+	switch args.Reason {
+	case RELINK_REASON_LOGIN_REQUIRED:
+		subject = "Reconnect your bank to DreamFi"
+		message = fmt.Sprintf("Your connection to %s has stopped working, usually because your login details changed. Open the DreamFi app and reconnect it to keep moving money to and from this account.", args.InstitutionName)
+	case RELINK_REASON_EXPIRING:
+		subject = "Your bank connection to DreamFi is about to expire"
+		message = fmt.Sprintf("Your connection to %s will expire soon. Open the DreamFi app and reconnect it to keep moving money to and from this account.", args.InstitutionName)
+	default:
+		return errtrace.Wrap(fmt.Errorf("no relink email for reason %s", args.Reason))
+	}
+
+	emailData := response.PlaidRelinkEmailTemplateData{
+		FirstName: args.FirstName,
+		Message:   message,
+		AppLink:   template.URL(args.RelinkURL),
+	}
+
+	templateName := config.Config.Email.TemplateDirectory + constant.PLAID_RELINK_TEMPLATE_NAME
+	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	err = utils.SendEmail(args.FirstName, args.Email, subject, htmlBody)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return nil
+}
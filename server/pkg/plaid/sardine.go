@@ -0,0 +1,250 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/sardine"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/plaid/plaid-go/v34/plaid"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// sardineProcessor is the name Plaid knows Sardine by when creating processor tokens
+const sardineProcessor = "sardine"
+
+// SardineRelinkScopes is what Sardine pulls again once an item is reconnected, since the
+// user may have changed what they share with us
+var SardineRelinkScopes = []sardine.PlaidDataSyncJSONBodyScope{sardine.Auth, sardine.Identity, sardine.Balance}
+
+// QueueSardineShare queues a job registering processor tokens with Sardine for the item's
+// accounts that don't have one yet, and asking Sardine to pull the scopes again for those
+// that do. It does nothing unless sharing Plaid data with Sardine is enabled.
+func QueueSardineShare(ctx context.Context, riverClient *river.Client[*sql.Tx], plaidItemId string, scopes ...sardine.PlaidDataSyncJSONBodyScope) error {
+	if !config.Config.Sardine.SharePlaidData {
+		return nil
+	}
+	_, err := riverClient.Insert(ctx, ShareWithSardineArgs{PlaidItemId: plaidItemId, Scopes: scopes}, nil)
+	return errtrace.Wrap(err)
+}
+
+// ShareWithSardine creates a Sardine processor token for each of the item's accounts that
+// doesn't have one and registers them with Sardine, then asks Sardine to pull the scopes
+// again with the tokens it already had. Items that need relinking are skipped, since
+// Plaid won't hand out their data until they are reconnected.
+func (ps *PlaidService) ShareWithSardine(ctx context.Context, plaidItemId string, scopes []sardine.PlaidDataSyncJSONBodyScope, now time.Time) error {
+	logger := ps.Logger.WithGroup("ShareWithSardine").With("plaidItemId", plaidItemId)
+
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(plaidItemId)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error retrieving item %s: %w", plaidItemId, err))
+	}
+	// Unlinking the item revoked its tokens
+	if item == nil {
+		logger.Info("plaid item not found; not sharing it with sardine")
+		return nil
+	}
+	if item.ItemError != nil {
+		logger.Info("plaid item needs relinking; not sharing it with sardine", "itemError", *item.ItemError)
+		return nil
+	}
+	accessToken, err := utils.DecryptPlaidAccessToken(item.EncryptedAccessToken, item.KmsEncryptedAccessToken)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForItem(item.UserId, plaidItemId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	tokens, err := dao.SardineProcessorTokenDao{}.FindActiveForItem(plaidItemId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	var unregistered, registered []dao.SardineProcessorTokenDao
+	processorTokens := map[string]string{}
+	var errs []error
+	for _, account := range accounts {
+		token, ok := tokens[account.PlaidAccountID]
+		if ok && token.RegisteredAt != nil {
+			registered = append(registered, token)
+			continue
+		}
+		// A token Sardine turned down earlier is offered again rather than replaced
+		var processorToken string
+		if ok {
+			processorToken, err = utils.DecryptKmsBinary(token.KmsEncryptedProcessorToken)
+		} else {
+			token, processorToken, err = ps.createSardineProcessorToken(ctx, item, account.PlaidAccountID, accessToken, now)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		unregistered = append(unregistered, token)
+		processorTokens[token.Id] = processorToken
+	}
+
+	if len(unregistered) > 0 || (len(registered) > 0 && len(scopes) > 0) {
+		client, err := utils.NewSardineClient(config.Config.Sardine)
+		if err != nil {
+			return errtrace.Wrap(errors.Join(append(errs, fmt.Errorf("failed to create sardine client: %w", err))...))
+		}
+		if len(unregistered) > 0 {
+			errs = append(errs, registerSardineProcessorTokens(ctx, client, item.UserId, unregistered, processorTokens, now))
+		}
+		for _, token := range registered {
+			errs = append(errs, syncSardineProcessorToken(ctx, client, item.UserId, token, scopes, now))
+		}
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	logger.Info("shared plaid item with sardine", "registered", len(unregistered), "synced", len(registered), "scopes", scopes)
+	return nil
+}
+
+// createSardineProcessorToken creates a processor token for the account with Plaid and
+// stores it, encrypted, until Sardine accepts it
+func (ps *PlaidService) createSardineProcessorToken(ctx context.Context, item *dao.PlaidItemDao, plaidAccountId, accessToken string, now time.Time) (dao.SardineProcessorTokenDao, string, error) {
+	logger := ps.Logger.WithGroup("createSardineProcessorToken").With("plaidItemId", item.PlaidItemID, "plaidAccountId", plaidAccountId)
+	resp, _, err := ps.Plaid.PlaidApi.ProcessorTokenCreate(ctx).ProcessorTokenCreateRequest(
+		*plaid.NewProcessorTokenCreateRequest(accessToken, plaidAccountId, sardineProcessor),
+	).Execute()
+	if err != nil {
+		logger.Error("plaid /processor/token/create call failed", "error", err.Error())
+		ps.handlePlaidErrorSideEffects(err, item.PlaidItemID)
+		return dao.SardineProcessorTokenDao{}, "", errtrace.Wrap(err)
+	}
+	processorToken := resp.GetProcessorToken()
+
+	encryptedProcessorToken, err := utils.EncryptKmsBinary(processorToken)
+	if err != nil {
+		return dao.SardineProcessorTokenDao{}, "", errtrace.Wrap(err)
+	}
+	token := dao.SardineProcessorTokenDao{
+		Id:                         uuid.NewString(),
+		UserId:                     item.UserId,
+		PlaidItemId:                item.PlaidItemID,
+		PlaidAccountId:             plaidAccountId,
+		KmsEncryptedProcessorToken: encryptedProcessorToken,
+		CreatedAt:                  now,
+		UpdatedAt:                  now,
+	}
+	if err := (dao.SardineProcessorTokenDao{}).Create(&token); err != nil {
+		return dao.SardineProcessorTokenDao{}, "", errtrace.Wrap(err)
+	}
+	return token, processorToken, nil
+}
+
+// registerSardineProcessorTokens hands the tokens to Sardine for the user's customer. A
+// token Sardine already has is taken as registered.
+func registerSardineProcessorTokens(ctx context.Context, client *sardine.ClientWithResponses, userId string, tokens []dao.SardineProcessorTokenDao, processorTokens map[string]string, now time.Time) error {
+	body := sardine.PostPlaidProcessorTokenJSONRequestBody{CustomerId: userId}
+	ids := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		body.Accounts = append(body.Accounts, struct {
+			AccountId      string `json:"accountId"`
+			ProcessorToken string `json:"processorToken"`
+		}{AccountId: token.PlaidAccountId, ProcessorToken: processorTokens[token.Id]})
+		ids = append(ids, token.Id)
+	}
+
+	var registerErr error
+	resp, err := client.PostPlaidProcessorTokenWithResponse(ctx, body)
+	switch {
+	case err != nil:
+		registerErr = fmt.Errorf("error occurred while registering processor tokens with sardine: %w", err)
+	case resp.JSON200 != nil, resp.JSON409 != nil:
+		return errtrace.Wrap(dao.SardineProcessorTokenDao{}.MarkRegistered(ids, now))
+	default:
+		registerErr = fmt.Errorf("received %d response from sardine registering processor tokens: %s", resp.StatusCode(), string(resp.Body))
+	}
+	if err := (dao.SardineProcessorTokenDao{}).SetLastError(ids, registerErr.Error(), now); err != nil {
+		return errtrace.Wrap(errors.Join(registerErr, err))
+	}
+	return errtrace.Wrap(registerErr)
+}
+
+// syncSardineProcessorToken asks Sardine to pull each scope again with the token
+func syncSardineProcessorToken(ctx context.Context, client *sardine.ClientWithResponses, userId string, token dao.SardineProcessorTokenDao, scopes []sardine.PlaidDataSyncJSONBodyScope, now time.Time) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	for _, scope := range scopes {
+		var syncErr error
+		resp, err := client.PlaidDataSyncWithResponse(ctx, sardine.PlaidDataSyncJSONRequestBody{
+			AccountId:  token.PlaidAccountId,
+			CustomerId: userId,
+			Scope:      scope,
+		})
+		switch {
+		case err != nil:
+			syncErr = fmt.Errorf("error occurred while syncing %s with sardine: %w", scope, err)
+		case resp.JSON200 == nil:
+			syncErr = fmt.Errorf("received %d response from sardine syncing %s: %s", resp.StatusCode(), scope, string(resp.Body))
+		}
+		if syncErr != nil {
+			if err := (dao.SardineProcessorTokenDao{}).SetLastError([]string{token.Id}, syncErr.Error(), now); err != nil {
+				return errtrace.Wrap(errors.Join(syncErr, err))
+			}
+			return errtrace.Wrap(syncErr)
+		}
+	}
+	return errtrace.Wrap(dao.SardineProcessorTokenDao{}.MarkSynced(token.Id, now))
+}
+
+// ShareWithSardineArgs is a job that shares one item's accounts with Sardine
+type ShareWithSardineArgs struct {
+	PlaidItemId string `json:"plaidItemId"`
+	// What Sardine should pull again for accounts it already has tokens for
+	Scopes []sardine.PlaidDataSyncJSONBodyScope `json:"scopes,omitempty"`
+}
+
+func (ShareWithSardineArgs) Kind() string { return "plaid_sardine_share" }
+
+func (ShareWithSardineArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "plaid",
+		// Refreshes close together only need Sardine to pull the item once
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+			ByState: []rivertype.JobState{
+				rivertype.JobStateAvailable,
+				rivertype.JobStatePending,
+				rivertype.JobStateRetryable,
+				rivertype.JobStateRunning,
+				rivertype.JobStateScheduled,
+			},
+		},
+	}
+}
+
+type ShareWithSardineWorker struct {
+	river.WorkerDefaults[ShareWithSardineArgs]
+	Plaid *plaid.APIClient
+}
+
+func (w *ShareWithSardineWorker) Work(ctx context.Context, job *river.Job[ShareWithSardineArgs]) error {
+	logger := logging.Logger.WithGroup("ShareWithSardineWorker").With("plaidItemId", job.Args.PlaidItemId, "jobId", job.ID)
+	ps := PlaidService{Logger: logger, Plaid: w.Plaid, DB: db.DB}
+	return errtrace.Wrap(ps.ShareWithSardine(ctx, job.Args.PlaidItemId, job.Args.Scopes, clock.Now()))
+}
+
+func RegisterShareWithSardineWorker(workers *river.Workers, plaid *plaid.APIClient) {
+	river.AddWorker(workers, &ShareWithSardineWorker{Plaid: plaid})
+}
@@ -0,0 +1,246 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"process-api/pkg/clock"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/utils"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/plaid/plaid-go/v34/plaid"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	// transactionsSyncPageSize is the most /transactions/sync will return in one page
+	transactionsSyncPageSize = 500
+	// maxTransactionsSyncRestarts bounds how many times a sync starts over because the
+	// item's transactions changed while it was paging through them
+	maxTransactionsSyncRestarts = 3
+)
+
+var errTransactionsSyncMutation = errors.New("transactions changed during pagination")
+
+// TransactionsSyncResult counts what one sync changed
+type TransactionsSyncResult struct {
+	Upserted int
+	Removed  int
+}
+
+// HandleTransactionsWebhook queues a sync when Plaid has new transactions for an item.
+// Plaid sends SYNC_UPDATES_AVAILABLE once an item's first transactions are ready and
+// again whenever they change. The update codes meant for /transactions/get are handled
+// the same way, since a sync picks up whatever they describe and a sync already queued
+// for the item isn't queued again.
+//
+// A sync that is already running may have fetched its last page before Plaid's update,
+// so another one is queued to follow it. The follow-up is keyed to the running job, which
+// keeps webhooks from queueing more than one.
+func (ps *PlaidService) HandleTransactionsWebhook(ctx context.Context, riverClient *river.Client[*sql.Tx], webhookCode, itemID string) error {
+	if !ps.TransactionsEnabled {
+		ps.Logger.Debug("Plaid transactions are disabled; ignoring transactions webhook", "itemID", itemID, "webhookCode", webhookCode)
+		return errtrace.Wrap(errWebhookIgnored)
+	}
+	args := SyncTransactionsArgs{PlaidItemId: itemID}
+	for {
+		result, err := riverClient.Insert(ctx, args, nil)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if !result.UniqueSkippedAsDuplicate || result.Job.State != rivertype.JobStateRunning {
+			return nil
+		}
+		ps.Logger.Debug("Queueing a transactions sync to follow a running one", "itemID", itemID, "runningJobId", result.Job.ID)
+		args.FollowsJobId = result.Job.ID
+	}
+}
+
+// SyncTransactions pages through /transactions/sync from the item's stored cursor and
+// stores the changes in plaid_transactions along with the new cursor
+func (ps *PlaidService) SyncTransactions(plaidItemId string, now time.Time) (*TransactionsSyncResult, error) {
+	logger := ps.Logger.WithGroup("SyncTransactions").With("plaidItemId", plaidItemId)
+
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(plaidItemId)
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("error retrieving item %s: %w", plaidItemId, err))
+	}
+	// The item may have been unlinked since Plaid told us about its transactions
+	if item == nil {
+		logger.Info("item no longer linked; skipping transactions sync")
+		return &TransactionsSyncResult{}, nil
+	}
+	// Plaid can't return transactions until the user re-links the item
+	if item.ItemError != nil {
+		logger.Info("skipping transactions sync due to item error", "item_error", *item.ItemError)
+		return &TransactionsSyncResult{}, nil
+	}
+
+	accessToken, err := utils.DecryptPlaidAccessToken(item.EncryptedAccessToken, item.KmsEncryptedAccessToken)
+	if err != nil {
+		return nil, errtrace.Wrap(fmt.Errorf("could not decrypt access token for plaid_item_id %s: %w", plaidItemId, err))
+	}
+
+	var cursor string
+	if item.TransactionsCursor != nil {
+		cursor = *item.TransactionsCursor
+	}
+
+	// Plaid docs: if the item's transactions change while paging through an update, the
+	// whole update must be fetched again from the cursor it started at
+	var update *transactionsUpdate
+	for restarts := 0; ; restarts++ {
+		update, err = ps.fetchTransactionsUpdate(item.UserId, accessToken, cursor)
+		if errors.Is(err, errTransactionsSyncMutation) && restarts < maxTransactionsSyncRestarts {
+			logger.Info("transactions changed during pagination; restarting sync", "restarts", restarts+1)
+			continue
+		}
+		break
+	}
+	if err != nil {
+		ps.handlePlaidErrorSideEffects(err, plaidItemId)
+		return nil, errtrace.Wrap(err)
+	}
+
+	err = dao.PlaidTransactionDao{}.ApplySync(plaidItemId, update.upserted, update.removed, update.nextCursor, now)
+	if err != nil {
+		logger.Error("error storing synced transactions", "error", err.Error())
+		return nil, errtrace.Wrap(err)
+	}
+
+	result := &TransactionsSyncResult{Upserted: len(update.upserted), Removed: len(update.removed)}
+	logger.Debug("synced transactions", "upserted", result.Upserted, "removed", result.Removed)
+	return result, nil
+}
+
+// transactionsUpdate is every page of one /transactions/sync update
+type transactionsUpdate struct {
+	upserted   []dao.PlaidTransactionDao
+	removed    []string
+	nextCursor string
+}
+
+func (ps *PlaidService) fetchTransactionsUpdate(userId, accessToken, cursor string) (*transactionsUpdate, error) {
+	ctx := context.Background()
+	update := &transactionsUpdate{nextCursor: cursor}
+	for {
+		request := plaid.NewTransactionsSyncRequest(accessToken)
+		if update.nextCursor != "" {
+			request.SetCursor(update.nextCursor)
+		}
+		request.SetCount(transactionsSyncPageSize)
+		resp, _, err := ps.Plaid.PlaidApi.TransactionsSync(ctx).TransactionsSyncRequest(*request).Execute()
+		if err != nil {
+			plaidErr, plaidErrErr := plaid.ToPlaidError(err)
+			if plaidErrErr == nil && plaidErr.ErrorCode == "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION" {
+				return nil, errtrace.Wrap(errTransactionsSyncMutation)
+			}
+			return nil, errtrace.Wrap(err)
+		}
+
+		for _, transaction := range append(resp.GetAdded(), resp.GetModified()...) {
+			record, err := toPlaidTransactionDao(userId, transaction)
+			if err != nil {
+				return nil, errtrace.Wrap(err)
+			}
+			update.upserted = append(update.upserted, record)
+		}
+		for _, removed := range resp.GetRemoved() {
+			update.removed = append(update.removed, removed.TransactionId)
+		}
+		update.nextCursor = resp.GetNextCursor()
+
+		if !resp.GetHasMore() {
+			return update, nil
+		}
+	}
+}
+
+func toPlaidTransactionDao(userId string, transaction plaid.Transaction) (dao.PlaidTransactionDao, error) {
+	date, err := time.Parse(time.DateOnly, transaction.Date)
+	if err != nil {
+		return dao.PlaidTransactionDao{}, errtrace.Wrap(fmt.Errorf("invalid date for transaction %s: %w", transaction.TransactionId, err))
+	}
+	var authorizedDate *time.Time
+	if value := transaction.AuthorizedDate.Get(); value != nil {
+		parsed, err := time.Parse(time.DateOnly, *value)
+		if err != nil {
+			return dao.PlaidTransactionDao{}, errtrace.Wrap(fmt.Errorf("invalid authorized date for transaction %s: %w", transaction.TransactionId, err))
+		}
+		authorizedDate = &parsed
+	}
+
+	record := dao.PlaidTransactionDao{
+		UserId:             userId,
+		PlaidAccountId:     transaction.AccountId,
+		PlaidTransactionId: transaction.TransactionId,
+		// Plaid reports money out of the account as positive
+		AmountCents:          -utils.USDtoCents(transaction.Amount),
+		IsoCurrencyCode:      transaction.IsoCurrencyCode.Get(),
+		Date:                 date,
+		AuthorizedDate:       authorizedDate,
+		Name:                 transaction.Name,
+		MerchantName:         transaction.MerchantName.Get(),
+		Pending:              transaction.Pending,
+		PendingTransactionId: transaction.PendingTransactionId.Get(),
+	}
+	if transaction.PaymentChannel != "" {
+		record.PaymentChannel = &transaction.PaymentChannel
+	}
+	if category := transaction.PersonalFinanceCategory.Get(); category != nil {
+		record.CategoryPrimary = &category.Primary
+		record.CategoryDetailed = &category.Detailed
+	}
+	return record, nil
+}
+
+// SyncTransactionsArgs is a job that syncs one item's transactions
+type SyncTransactionsArgs struct {
+	PlaidItemId string `json:"plaidItemId"`
+	// The running sync this one was queued to follow, which is only set to tell the two
+	// apart for uniqueness
+	FollowsJobId int64 `json:"followsJobId,omitempty"`
+}
+
+func (SyncTransactionsArgs) Kind() string { return "plaid_transactions_sync" }
+
+func (SyncTransactionsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "plaid",
+		// A queued sync pages through everything Plaid has for the item, so webhooks that
+		// arrive before it starts don't need a sync of their own. River requires running
+		// jobs to count too, which HandleTransactionsWebhook works around.
+		UniqueOpts: river.UniqueOpts{
+			ByArgs: true,
+			ByState: []rivertype.JobState{
+				rivertype.JobStateAvailable,
+				rivertype.JobStatePending,
+				rivertype.JobStateRetryable,
+				rivertype.JobStateRunning,
+				rivertype.JobStateScheduled,
+			},
+		},
+	}
+}
+
+type SyncTransactionsWorker struct {
+	river.WorkerDefaults[SyncTransactionsArgs]
+	Plaid *plaid.APIClient
+}
+
+func (w *SyncTransactionsWorker) Work(ctx context.Context, job *river.Job[SyncTransactionsArgs]) error {
+	logger := logging.Logger.WithGroup("SyncTransactionsWorker").With("plaidItemId", job.Args.PlaidItemId, "jobId", job.ID)
+	ps := PlaidService{Logger: logger, Plaid: w.Plaid, DB: db.DB, TransactionsEnabled: true}
+	_, err := ps.SyncTransactions(job.Args.PlaidItemId, clock.Now())
+	return errtrace.Wrap(err)
+}
+
+func RegisterSyncTransactionsWorker(workers *river.Workers, plaid *plaid.APIClient) {
+	river.AddWorker(workers, &SyncTransactionsWorker{Plaid: plaid})
+}
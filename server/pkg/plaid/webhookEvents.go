@@ -0,0 +1,229 @@
+package plaid
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"slices"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/plaid/plaid-go/v34/plaid"
+	"github.com/riverqueue/river"
+)
+
+// ErrUnknownItem is returned for a webhook about an item that isn't linked. Plaid can send
+// an item's first webhooks before the public token exchange that stores the item is done.
+var ErrUnknownItem = errors.New("plaid item not found")
+
+// errWebhookIgnored is returned for a webhook there is nothing to do for
+var errWebhookIgnored = errors.New("plaid webhook ignored")
+
+// ErrWebhookEventNotRetryable is returned when retrying an event that was processed
+var ErrWebhookEventNotRetryable = errors.New("only failed and unknown item webhook events can be retried")
+
+// handledWebhookCodes are the webhooks we act on, by type. Others are stored as ignored.
+var handledWebhookCodes = map[string][]string{
+	"ITEM": {
+		"ERROR",
+		"LOGIN_REPAIRED",
+		"NEW_ACCOUNTS_AVAILABLE",
+		"PENDING_DISCONNECT",
+		"PENDING_EXPIRATION",
+		"USER_ACCOUNT_REVOKED",
+		"USER_PERMISSION_REVOKED",
+	},
+	"AUTH": {
+		"AUTOMATICALLY_VERIFIED",
+		"DEFAULT_UPDATE",
+		"VERIFICATION_EXPIRED",
+	},
+	"TRANSACTIONS": {
+		"SYNC_UPDATES_AVAILABLE",
+		"INITIAL_UPDATE",
+		"HISTORICAL_UPDATE",
+		"DEFAULT_UPDATE",
+		"TRANSACTIONS_REMOVED",
+	},
+}
+
+// PlaidWebhookTypes are the webhook types we act on
+var PlaidWebhookTypes = []string{"ITEM", "AUTH", "TRANSACTIONS"}
+
+// unknownItemRetryDelays are how long to wait before each retry of an event whose item
+// wasn't found. Once they are used up the event is failed.
+var unknownItemRetryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+}
+
+// HandleWebhook stores a verified webhook and processes it. Only failing to store the
+// webhook is returned as an error, since Plaid sends it again unless it is acknowledged;
+// what came of processing it is recorded on the returned event.
+func (ps *PlaidService) HandleWebhook(ctx context.Context, riverClient *river.Client[*sql.Tx], payload WebhookPayload, body string, now time.Time) (*dao.PlaidWebhookEventDao, error) {
+	event := &dao.PlaidWebhookEventDao{
+		Id:             uuid.NewString(),
+		WebhookType:    payload.WebhookType,
+		WebhookCode:    payload.WebhookCode,
+		PlaidAccountId: payload.AccountID,
+		Payload:        body,
+		Status:         dao.PLAID_WEBHOOK_EVENT_RECEIVED,
+		ReceivedAt:     now,
+	}
+	if payload.ItemID != "" {
+		event.PlaidItemId = &payload.ItemID
+	}
+	if err := (dao.PlaidWebhookEventDao{}).Create(event); err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	if err := ps.ProcessWebhookEvent(ctx, riverClient, event, now); err != nil {
+		ps.Logger.Error("Failed to process plaid webhook", "error", err.Error(), "eventId", event.Id, "webhookType", event.WebhookType, "webhookCode", event.WebhookCode)
+	}
+	return event, nil
+}
+
+// ProcessWebhookEvent processes a stored webhook and records the outcome on it, returning
+// why it failed, if it did
+func (ps *PlaidService) ProcessWebhookEvent(ctx context.Context, riverClient *river.Client[*sql.Tx], event *dao.PlaidWebhookEventDao, now time.Time) error {
+	var payload WebhookPayload
+	err := json.Unmarshal([]byte(event.Payload), &payload)
+	if err == nil {
+		err = ps.processWebhook(ctx, riverClient, payload)
+	}
+
+	attempts := event.Attempts + 1
+	var outcome dao.PlaidWebhookEventOutcome
+	switch {
+	case err == nil:
+		outcome.Status = dao.PLAID_WEBHOOK_EVENT_PROCESSED
+	case errors.Is(err, errWebhookIgnored):
+		outcome.Status = dao.PLAID_WEBHOOK_EVENT_IGNORED
+		err = nil
+	case errors.Is(err, ErrUnknownItem) && attempts <= len(unknownItemRetryDelays):
+		nextAttemptAt := now.Add(unknownItemRetryDelays[attempts-1])
+		outcome.Status = dao.PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM
+		outcome.NextAttemptAt = &nextAttemptAt
+	default:
+		message := err.Error()
+		outcome.Status = dao.PLAID_WEBHOOK_EVENT_FAILED
+		outcome.Error = &message
+	}
+
+	if recordErr := (dao.PlaidWebhookEventDao{}).RecordOutcome(event.Id, outcome, now); recordErr != nil {
+		return errtrace.Wrap(errors.Join(err, recordErr))
+	}
+	event.Status = outcome.Status
+	event.Error = outcome.Error
+	event.Attempts = attempts
+	event.ProcessedAt = &now
+	event.NextAttemptAt = outcome.NextAttemptAt
+	return errtrace.Wrap(err)
+}
+
+// NOTE: webhooks are not sent for items linked through the "Instant Micro-deposits" flow (DT-1480)
+func (ps *PlaidService) processWebhook(ctx context.Context, riverClient *river.Client[*sql.Tx], payload WebhookPayload) error {
+	if !slices.Contains(handledWebhookCodes[payload.WebhookType], payload.WebhookCode) {
+		return errtrace.Wrap(errWebhookIgnored)
+	}
+
+	item, err := dao.PlaidItemDao{}.GetItemByPlaidItemID(payload.ItemID)
+	if err != nil {
+		return errtrace.Wrap(fmt.Errorf("error retrieving item %s: %w", payload.ItemID, err))
+	}
+	if item == nil {
+		return errtrace.Wrap(ErrUnknownItem)
+	}
+
+	switch payload.WebhookType {
+	case "ITEM":
+		return ps.HandleItemWebhook(ctx, riverClient, payload, item)
+	case "AUTH":
+		return ps.HandleAuthWebhook(payload.WebhookCode, item, payload.AccountID)
+	case "TRANSACTIONS":
+		return ps.HandleTransactionsWebhook(ctx, riverClient, payload.WebhookCode, item.PlaidItemID)
+	}
+	return nil
+}
+
+// RetryWebhookEvent processes a failed or unknown item event again
+func (ps *PlaidService) RetryWebhookEvent(ctx context.Context, riverClient *river.Client[*sql.Tx], event *dao.PlaidWebhookEventDao, now time.Time) error {
+	if event.Status != dao.PLAID_WEBHOOK_EVENT_FAILED && event.Status != dao.PLAID_WEBHOOK_EVENT_UNKNOWN_ITEM {
+		return errtrace.Wrap(ErrWebhookEventNotRetryable)
+	}
+	return ps.ProcessWebhookEvent(ctx, riverClient, event, now)
+}
+
+// webhookRetryBatchSize is how many unknown item events are retried per run
+const webhookRetryBatchSize = 100
+
+// WebhookRetryInterval is how often unknown item events are checked for due retries
+const WebhookRetryInterval = time.Minute
+
+// webhookRetryClaimDuration is how long a run has to retry the events it claimed before
+// another run may pick them up. Processing an event sets its real next attempt.
+const webhookRetryClaimDuration = 10 * time.Minute
+
+// RetryDueWebhookEvents processes again the unknown item events whose retry is due,
+// returning how many it retried
+func (ps *PlaidService) RetryDueWebhookEvents(ctx context.Context, riverClient *river.Client[*sql.Tx], now time.Time) (int, error) {
+	events, err := dao.PlaidWebhookEventDao{}.ClaimDueForRetry(now, webhookRetryBatchSize, now.Add(webhookRetryClaimDuration))
+	if err != nil {
+		return 0, errtrace.Wrap(err)
+	}
+	for i := range events {
+		err := ps.ProcessWebhookEvent(ctx, riverClient, &events[i], now)
+		if err != nil && !errors.Is(err, ErrUnknownItem) {
+			ps.Logger.Error("Failed to retry plaid webhook", "error", err.Error(), "eventId", events[i].Id)
+		}
+	}
+	return len(events), nil
+}
+
+// RetryWebhookEventsArgs is a periodic job that retries unknown item events that are due
+type RetryWebhookEventsArgs struct{}
+
+func (RetryWebhookEventsArgs) Kind() string { return "plaid_webhook_retry" }
+
+func (RetryWebhookEventsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{Queue: "plaid"}
+}
+
+type RetryWebhookEventsWorker struct {
+	river.WorkerDefaults[RetryWebhookEventsArgs]
+	Plaid *plaid.APIClient
+}
+
+func RegisterRetryWebhookEventsWorker(workers *river.Workers, plaid *plaid.APIClient) {
+	river.AddWorker(workers, &RetryWebhookEventsWorker{Plaid: plaid})
+}
+
+// NewRetryWebhookEventsPeriodicJob schedules the retry job. A slow run can overlap the
+// next one, which is why due events are claimed before they are retried.
+func NewRetryWebhookEventsPeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(river.PeriodicInterval(WebhookRetryInterval), func() (river.JobArgs, *river.InsertOpts) {
+		return RetryWebhookEventsArgs{}, nil
+	}, &river.PeriodicJobOpts{RunOnStart: true})
+}
+
+func (w *RetryWebhookEventsWorker) Work(ctx context.Context, job *river.Job[RetryWebhookEventsArgs]) error {
+	logger := logging.Logger.WithGroup("RetryWebhookEventsWorker").With("jobId", job.ID)
+	ps := PlaidService{Logger: logger, Plaid: w.Plaid, DB: db.DB, TransactionsEnabled: config.Config.Plaid.TransactionsEnabled}
+	retried, err := ps.RetryDueWebhookEvents(ctx, river.ClientFromContext[*sql.Tx](ctx), clock.Now())
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	logger.Debug("retried plaid webhooks", "count", retried)
+	return nil
+}
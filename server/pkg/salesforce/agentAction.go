@@ -0,0 +1,123 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/admin"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/security"
+	"regexp"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderSalesforceAgent names the agent acting in Salesforce when the token was not
+// issued for one
+const HeaderSalesforceAgent = "X-Salesforce-Agent"
+
+// salesforceAuditRole is recorded as the role of Salesforce agents in the admin audit log
+const salesforceAuditRole = "salesforce"
+
+var agentEmailRegex = regexp.MustCompile(constant.EMAIL_REGX)
+
+// agentEmail returns the agent the token was issued for, or else the agent named by
+// the request's header
+func agentEmail(c echo.Context, claims *validator.ValidatedClaims) string {
+	if customClaims, ok := claims.CustomClaims.(*SalesforceClaims); ok && customClaims.AgentEmail != "" {
+		return customClaims.AgentEmail
+	}
+	return strings.TrimSpace(c.Request().Header.Get(HeaderSalesforceAgent))
+}
+
+// AgentAction guards a Salesforce agent's change to a customer. The token must grant
+// scope, the request must identify the agent and carry an Idempotency-Key, and the
+// customer is resolved from the path's userId or ledgerAccountID so retries of the
+// change are deduplicated per customer.
+func AgentAction(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		idempotent := security.IdempotencyMiddleware(next)
+		return func(c echo.Context) error {
+			logger := logging.GetEchoContextLogger(c)
+			claims, err := requireScope(c, logger, scope)
+			if err != nil {
+				return err
+			}
+
+			agent := agentEmail(c, claims)
+			if agent == "" {
+				return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: HeaderSalesforceAgent, Error: "required"}}}
+			}
+			if !agentEmailRegex.MatchString(agent) {
+				return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: HeaderSalesforceAgent, Error: "validateEmail"}}}
+			}
+			if c.Request().Header.Get(security.HeaderIdempotencyKey) == "" {
+				return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: security.HeaderIdempotencyKey, Error: "required"}}}
+			}
+
+			userId, err := agentActionCustomerId(c)
+			if err != nil {
+				logger.Error("Failed to resolve customer of agent action", "error", err.Error())
+				return response.InternalServerError(fmt.Sprintf("Failed to resolve customer of agent action: %s", err.Error()), errtrace.Wrap(err))
+			}
+			if userId == "" {
+				logger.Info("Customer of agent action not found")
+				return response.NotFoundError("Customer not found", errtrace.New(""))
+			}
+
+			c.Set("salesforce_agent", agent)
+			c.Set("user_id", userId)
+			return idempotent(c)
+		}
+	}
+}
+
+// agentActionCustomerId returns the id of the customer the path names, or an empty
+// string when there is no such customer
+func agentActionCustomerId(c echo.Context) (string, error) {
+	if ledgerAccountId := c.Param("ledgerAccountID"); ledgerAccountId != "" {
+		account, err := dao.UserAccountCardDao{}.FindOneByAccountID(db.DB, ledgerAccountId)
+		if err != nil || account == nil {
+			return "", errtrace.Wrap(err)
+		}
+		return account.UserId, nil
+	}
+
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(c.Param("userId"))
+	if err != nil || user == nil {
+		return "", errtrace.Wrap(err)
+	}
+	return user.Id, nil
+}
+
+// requireAgent returns the agent AgentAction identified along with the token's claims
+func requireAgent(c echo.Context) (*validator.ValidatedClaims, string, error) {
+	claims, ok := c.Get("salesforce_claims").(*validator.ValidatedClaims)
+	agent, _ := c.Get("salesforce_agent").(string)
+	if !ok || agent == "" {
+		return nil, "", response.ErrorResponse{
+			ErrorCode:       constant.INTERNAL_SERVER_ERROR,
+			StatusCode:      http.StatusInternalServerError,
+			LogMessage:      "Agent action handled without the AgentAction middleware",
+			MaybeInnerError: errtrace.New(""),
+		}
+	}
+	return claims, agent, nil
+}
+
+// recordAgentAudit records the agent's change in the admin audit log, attributed to
+// both the agent and the token that made the request
+func recordAgentAudit(c echo.Context, claims *validator.ValidatedClaims, agent string, action, entityType, entityId, userId string, before, after map[string]any) {
+	admin.RecordExternalAudit(c, salesforceAuditActorId(claims), agent, []string{salesforceAuditRole}, action, entityType, entityId, userId, before, after)
+}
+
+// salesforceAuditActorId identifies the Salesforce integration's token in the audit log
+func salesforceAuditActorId(claims *validator.ValidatedClaims) string {
+	return "salesforce|" + claims.RegisteredClaims.Subject
+}
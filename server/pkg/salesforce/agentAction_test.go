@@ -0,0 +1,59 @@
+package salesforce
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		claimAgent string
+		header     string
+		want       string
+	}{
+		{
+			name:   "agent from header",
+			header: "agent@dreamfi.com",
+			want:   "agent@dreamfi.com",
+		},
+		{
+			name:   "header is trimmed",
+			header: "  agent@dreamfi.com ",
+			want:   "agent@dreamfi.com",
+		},
+		{
+			name:       "agent from token",
+			claimAgent: "token-agent@dreamfi.com",
+			want:       "token-agent@dreamfi.com",
+		},
+		{
+			name:       "token takes precedence over header",
+			claimAgent: "token-agent@dreamfi.com",
+			header:     "agent@dreamfi.com",
+			want:       "token-agent@dreamfi.com",
+		},
+		{
+			name: "no agent",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderSalesforceAgent, tt.header)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			claims := &validator.ValidatedClaims{CustomClaims: &SalesforceClaims{Scope: "write:card", AgentEmail: tt.claimAgent}}
+
+			assert.Equal(t, tt.want, agentEmail(c, claims))
+		})
+	}
+}
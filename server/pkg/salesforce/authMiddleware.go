@@ -3,7 +3,9 @@ package salesforce
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"process-api/pkg/config"
@@ -24,6 +26,8 @@ import (
 
 type SalesforceClaims struct {
 	Scope string `json:"scope"`
+	// The Salesforce agent the token was issued for, when it was issued for one
+	AgentEmail string `json:"agent_email"`
 }
 
 // Validate does nothing, but we need it to satisfy validator.CustomClaims interface.
@@ -102,3 +106,25 @@ func extractBearerToken(authHeader string) (string, error) {
 func (c SalesforceClaims) HasScope(scope string) bool {
 	return slices.Contains(strings.Split(c.Scope, " "), scope)
 }
+
+// requireScope returns the validated claims of the request's token, refusing it unless
+// it grants scope
+func requireScope(c echo.Context, logger *slog.Logger, scope string) (*validator.ValidatedClaims, error) {
+	claims, ok := c.Get("salesforce_claims").(*validator.ValidatedClaims)
+	if !ok {
+		logger.Error("Could not get custom salesforce claims")
+		return nil, response.UnauthorizedError("Could not get custom salesforce claims")
+	}
+
+	customClaims, ok := claims.CustomClaims.(*SalesforceClaims)
+	if !ok {
+		logger.Error("Could not cast to CustomClaims")
+		return nil, response.UnauthorizedError("Could not cast to CustomClaims")
+	}
+
+	if !customClaims.HasScope(scope) {
+		logger.Error(fmt.Sprintf("Custom claims missing %s scope", scope))
+		return nil, response.ForbiddenError(fmt.Sprintf("Custom claims missing %s scope", scope), errtrace.New(""))
+	}
+	return claims, nil
+}
@@ -2,8 +2,14 @@ package salesforce
 
 import (
 	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"process-api/pkg/model/response"
 	"testing"
 
+	"github.com/auth0/go-jwt-middleware/v2/validator"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -173,3 +179,35 @@ func TestSalesforceClaimsHasScope(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireScope(t *testing.T) {
+	newContext := func(claims any) echo.Context {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		if claims != nil {
+			c.Set("salesforce_claims", claims)
+		}
+		return c
+	}
+
+	t.Run("token with the scope", func(t *testing.T) {
+		claims := &validator.ValidatedClaims{CustomClaims: &SalesforceClaims{Scope: "read:customers read:card"}}
+		got, err := requireScope(newContext(claims), slog.Default(), "read:card")
+		assert.NoError(t, err)
+		assert.Same(t, claims, got)
+	})
+
+	t.Run("token without the scope", func(t *testing.T) {
+		claims := &validator.ValidatedClaims{CustomClaims: &SalesforceClaims{Scope: "read:customers"}}
+		_, err := requireScope(newContext(claims), slog.Default(), "read:card")
+		var errResponse response.ErrorResponse
+		assert.ErrorAs(t, err, &errResponse)
+		assert.Equal(t, http.StatusForbidden, errResponse.StatusCode)
+	})
+
+	t.Run("no claims", func(t *testing.T) {
+		_, err := requireScope(newContext(nil), slog.Default(), "read:card")
+		var errResponse response.ErrorResponse
+		assert.ErrorAs(t, err, &errResponse)
+		assert.Equal(t, http.StatusUnauthorized, errResponse.StatusCode)
+	})
+}
@@ -0,0 +1,147 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type CardStatusResponse struct {
+	// The card status as reported by the ledger: ACTIVATED, TEMPRORY_BLOCKED_BY_CLIENT, etc
+	CardStatus string `json:"cardStatus" example:"TEMPRORY_BLOCKED_BY_CLIENT" validate:"required"`
+}
+
+// @Summary SalesforceLockCustomerCard
+// @Description Locks a customer's card on their behalf, as the customer can by freezing it in the app
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Success 200 {object} CardStatusResponse
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer or card"
+// @Failure 409 {object} response.ErrorResponse "A request with the Idempotency-Key is in progress"
+// @Failure 412 {object} response.ErrorResponse "The customer's account is not active"
+// @Failure 422 {object} response.ErrorResponse "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/card/lock [post]
+func SalesforceLockCustomerCard(c echo.Context) error {
+	return updateCustomerCardStatus(c, ledger.LOCK)
+}
+
+// @Summary SalesforceUnlockCustomerCard
+// @Description Unlocks a customer's card on their behalf. A card cannot be unlocked while the customer is on compliance hold.
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Success 200 {object} CardStatusResponse
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope), or the customer is on compliance hold"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer or card"
+// @Failure 409 {object} response.ErrorResponse "A request with the Idempotency-Key is in progress"
+// @Failure 412 {object} response.ErrorResponse "The customer's account is not active"
+// @Failure 422 {object} response.ErrorResponse "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/card/unlock [post]
+func SalesforceUnlockCustomerCard(c echo.Context) error {
+	return updateCustomerCardStatus(c, ledger.UNLOCK)
+}
+
+func updateCustomerCardStatus(c echo.Context, statusAction string) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"), "statusAction", statusAction)
+	claims, agent, err := requireAgent(c)
+	if err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	// As in the app, a customer on compliance hold can have their card locked but not unlocked
+	if statusAction == ledger.UNLOCK {
+		if errResponse := dao.RequireNoComplianceHold(user.Id); errResponse != nil {
+			return errResponse
+		}
+	}
+	userAccountCard, errResponse := dao.RequireActiveCardHolderForUser(user.Id)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	ledgerClient := ledger.NewNetXDCardApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	getCardResponse, err := ledgerClient.GetCardDetails(ledgerClient.BuildGetCardDetailsRequest(user.LedgerCustomerNumber, userAccountCard.AccountNumber, userAccountCard.CardId))
+	if err != nil {
+		logger.Error("error while calling ledger's GetCardDetails", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("error while calling ledger's GetCardDetails: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if getCardResponse.Error != nil {
+		logger.Error("error from ledger's GetCardDetails", "error", getCardResponse.Error)
+		return response.InternalServerError(fmt.Sprintf("error from ledger's GetCardDetails: %s", getCardResponse.Error.Message), errtrace.New(""))
+	}
+	if getCardResponse.Result == nil {
+		logger.Error("no error was reported from ledger GetCardDetails, but Result is missing from response")
+		return response.InternalServerError("no error was reported from ledger GetCardDetails, but Result is missing from response", errtrace.New(""))
+	}
+
+	// A reissued card is not active until the customer activates it, so the card they
+	// still hold is the previous one
+	cardId := userAccountCard.CardId
+	if ledger.MapCardStatus(getCardResponse.Result.Card.CardStatus, logger) == "inactive" && (getCardResponse.Result.Card.IsReIssue || userAccountCard.IsReissue) {
+		cardId = *userAccountCard.PreviousCardId
+	}
+
+	payload, err := ledgerClient.BuildUpdateStatusRequest(user.LedgerCustomerNumber, cardId, userAccountCard.AccountNumber, statusAction, "", false)
+	if err != nil {
+		logger.Error("Error while generating updateStatus request payload", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Error while generating updateStatus request payload: %s", err.Error()), errtrace.Wrap(err))
+	}
+	responseData, err := ledgerClient.UpdateStatus(*payload)
+	if err != nil {
+		logger.Error("Error from updateCardStatus", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Error from updateCardStatus: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if responseData.Error != nil {
+		if responseData.Error.Code == "1018" {
+			return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "status", Error: "invalid"}}}
+		}
+		logger.Error("The ledger responded with an error", "code", responseData.Error.Code, "msg", responseData.Error.Message)
+		return response.InternalServerError(fmt.Sprintf("The ledger responded with an error: %s", responseData.Error.Message), errtrace.New(""))
+	}
+	if responseData.Result == nil || responseData.Result.Card.CardStatus == "" {
+		logger.Error("ledger returned unexpected response")
+		return response.InternalServerError("ledger returned unexpected response", errtrace.New(""))
+	}
+	logger.Info("Salesforce agent updated card status", "agent", agent, "status", responseData.Result.Card.CardStatus)
+
+	auditAction := admin.AUDIT_ACTION_CARD_LOCKED
+	if statusAction == ledger.UNLOCK {
+		auditAction = admin.AUDIT_ACTION_CARD_UNLOCKED
+	} else {
+		// Not failing the request, since the ledger has already locked the card
+		err := dao.WebhookEventDao{}.Create(db.DB, dao.WEBHOOK_EVENT_CARD_LOCKED, user.Id, dao.CardLockedEventData{UserId: user.Id, CardId: cardId, Via: dao.CARD_LOCKED_VIA_SALESFORCE}, clock.Now())
+		if err != nil {
+			logger.Error("Failed to record card locked webhook event", "error", err.Error())
+		}
+	}
+	recordAgentAudit(c, claims, agent, auditAction, dao.ADMIN_AUDIT_ENTITY_CARD, cardId, user.Id,
+		map[string]any{"card_status": getCardResponse.Result.Card.CardStatus},
+		map[string]any{"card_status": responseData.Result.Card.CardStatus})
+	return c.JSON(http.StatusOK, CardStatusResponse{CardStatus: responseData.Result.Card.CardStatus})
+}
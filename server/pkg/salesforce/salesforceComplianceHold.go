@@ -0,0 +1,217 @@
+package salesforce
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/compliance"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type ComplianceHoldResponse struct {
+	// Whether money movement is on hold for the customer
+	Active           bool       `json:"active" example:"true" validate:"required"`
+	Reason           string     `json:"reason,omitempty" example:"Suspected account takeover"`
+	PlacedVia        string     `json:"placedVia,omitempty" example:"salesforce"`
+	PlacedBy         string     `json:"placedBy,omitempty" example:"agent@dreamfi.com"`
+	PlacedAt         *time.Time `json:"placedAt,omitempty"`
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty"`
+	LedgerMirroredAt *time.Time `json:"ledgerMirroredAt,omitempty"`
+	LedgerError      *string    `json:"ledgerError,omitempty"`
+}
+
+type PlaceComplianceHoldRequest struct {
+	Reason    string     `json:"reason" validate:"required,max=500"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// Also suspend the customer's ledger account while the hold is active
+	MirrorToLedger bool `json:"mirrorToLedger"`
+}
+
+type ReleaseComplianceHoldRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func complianceHoldResponse(hold *dao.ComplianceHoldDao) ComplianceHoldResponse {
+	if hold == nil {
+		return ComplianceHoldResponse{}
+	}
+	return ComplianceHoldResponse{
+		Active:           hold.IsActive(clock.Now()),
+		Reason:           hold.Reason,
+		PlacedVia:        hold.PlacedVia,
+		PlacedBy:         hold.PlacedBy,
+		PlacedAt:         &hold.PlacedAt,
+		ExpiresAt:        hold.ExpiresAt,
+		LedgerMirroredAt: hold.LedgerMirroredAt,
+		LedgerError:      hold.LedgerError,
+	}
+}
+
+// requireComplianceHoldAccount checks the token has scope and returns the ledger account
+// of the customer
+func requireComplianceHoldAccount(c echo.Context, logger *slog.Logger, scope string) (*dao.UserAccountCardDao, error) {
+	if _, err := requireScope(c, logger, scope); err != nil {
+		return nil, err
+	}
+
+	account, err := dao.UserAccountCardDao{}.FindOneByAccountID(db.DB, c.Param("ledgerAccountID"))
+	if err != nil {
+		logger.Error("Failed to fetch account from database", "error", err.Error())
+		return nil, response.InternalServerError(fmt.Sprintf("Failed to fetch account from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if account == nil {
+		logger.Error("Account not found")
+		return nil, response.NotFoundError("Account not found", errtrace.New(""))
+	}
+	return account, nil
+}
+
+// @Summary SalesforceGetComplianceHold
+// @Description Gets the compliance hold on money movement for the customer owning a given ledger account id
+// @Tags salesforce
+// @Produce json
+// @Param ledgerAccountID path int true "ledger account id"
+// @Success 200 {object} ComplianceHoldResponse
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find account"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/accounts/{ledgerAccountID}/compliance-hold [get]
+func SalesforceGetComplianceHold(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("ledgerAccountID", c.Param("ledgerAccountID"))
+	account, err := requireComplianceHoldAccount(c, logger, "read:compliance_hold")
+	if err != nil {
+		return err
+	}
+
+	hold, err := dao.ComplianceHoldDao{}.FindActiveForUser(account.UserId, clock.Now())
+	if err != nil {
+		logger.Error("Failed to fetch compliance hold from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch compliance hold from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	return c.JSON(http.StatusOK, complianceHoldResponse(hold))
+}
+
+// @Summary SalesforcePlaceComplianceHold
+// @Description Stops all money movement for the customer owning a given ledger account id
+// @Tags salesforce
+// @Accept json
+// @Produce json
+// @Param ledgerAccountID path int true "ledger account id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Param request body PlaceComplianceHoldRequest true "The hold to place"
+// @Success 200 {object} ComplianceHoldResponse
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find account"
+// @Failure 409 {object} response.ErrorResponse "The customer is already on compliance hold, or a request with the Idempotency-Key is in progress"
+// @Failure 422 {object} response.ErrorResponse "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/accounts/{ledgerAccountID}/compliance-hold [put]
+func SalesforcePlaceComplianceHold(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("ledgerAccountID", c.Param("ledgerAccountID"))
+	claims, agent, err := requireAgent(c)
+	if err != nil {
+		return err
+	}
+	account, err := requireComplianceHoldAccount(c, logger, "write:compliance_hold")
+	if err != nil {
+		return err
+	}
+
+	requestData := new(PlaceComplianceHoldRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	requestData.Reason = strings.TrimSpace(requestData.Reason)
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	hold, err := compliance.Place(logger, account.UserId, compliance.PlaceRequest{
+		Reason:         requestData.Reason,
+		ExpiresAt:      requestData.ExpiresAt,
+		MirrorToLedger: requestData.MirrorToLedger,
+		Via:            constant.COMPLIANCE_HOLD_VIA_SALESFORCE,
+		PlacedBy:       agent,
+	}, clock.Now())
+	switch {
+	case errors.Is(err, compliance.ErrExpiryNotInFuture):
+		return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "expiresAt", Error: "must be in the future"}}}
+	case errors.Is(err, compliance.ErrHoldAlreadyPlaced):
+		return response.ErrorResponse{ErrorCode: constant.COMPLIANCE_HOLD_ALREADY_PLACED, Message: constant.COMPLIANCE_HOLD_ALREADY_PLACED_MSG, StatusCode: http.StatusConflict, MaybeInnerError: errtrace.Wrap(err)}
+	case err != nil:
+		logger.Error("Failed to place compliance hold", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to place compliance hold: %s", err.Error()), errtrace.Wrap(err))
+	}
+	logger.Info("Salesforce agent placed compliance hold", "agent", agent, "holdId", hold.Id)
+
+	recordAgentAudit(c, claims, agent, admin.AUDIT_ACTION_COMPLIANCE_HOLD_PLACED, dao.ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD, hold.Id, account.UserId, compliance.AuditSnapshot(nil), compliance.AuditSnapshot(hold))
+	return c.JSON(http.StatusOK, complianceHoldResponse(hold))
+}
+
+// @Summary SalesforceReleaseComplianceHold
+// @Description Lifts the compliance hold on the customer owning a given ledger account id
+// @Tags salesforce
+// @Accept json
+// @Produce json
+// @Param ledgerAccountID path int true "ledger account id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Param request body ReleaseComplianceHoldRequest true "Why the hold is released"
+// @Success 200 {object} ComplianceHoldResponse
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find account, or the customer has no compliance hold"
+// @Failure 409 {object} response.ErrorResponse "A request with the Idempotency-Key is in progress"
+// @Failure 422 {object} response.ErrorResponse "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/accounts/{ledgerAccountID}/compliance-hold [delete]
+func SalesforceReleaseComplianceHold(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("ledgerAccountID", c.Param("ledgerAccountID"))
+	claims, agent, err := requireAgent(c)
+	if err != nil {
+		return err
+	}
+	account, err := requireComplianceHoldAccount(c, logger, "write:compliance_hold")
+	if err != nil {
+		return err
+	}
+
+	requestData := new(ReleaseComplianceHoldRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	requestData.Reason = strings.TrimSpace(requestData.Reason)
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+
+	before, after, err := compliance.Release(logger, account.UserId, agent, requestData.Reason, clock.Now())
+	switch {
+	case errors.Is(err, compliance.ErrNoHold):
+		return response.NotFoundError("Customer has no compliance hold", errtrace.Wrap(err))
+	case err != nil:
+		logger.Error("Failed to release compliance hold", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to release compliance hold: %s", err.Error()), errtrace.Wrap(err))
+	}
+	logger.Info("Salesforce agent released compliance hold", "agent", agent, "holdId", after.Id)
+
+	recordAgentAudit(c, claims, agent, admin.AUDIT_ACTION_COMPLIANCE_HOLD_RELEASED, dao.ADMIN_AUDIT_ENTITY_COMPLIANCE_HOLD, after.Id, account.UserId, compliance.AuditSnapshot(before), compliance.AuditSnapshot(after))
+	return c.JSON(http.StatusOK, complianceHoldResponse(after))
+}
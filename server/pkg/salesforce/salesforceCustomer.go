@@ -0,0 +1,162 @@
+package salesforce
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"strings"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type CustomerResponse struct {
+	// The customer's id, used to fetch the rest of their details
+	UserId    string `json:"userId" example:"7f1e2b9c-2d4a-4b8e-9a57-0c1d2e3f4a5b" validate:"required"`
+	FirstName string `json:"firstName" example:"Alberta" validate:"required"`
+	LastName  string `json:"lastName" example:"Charleson" validate:"required"`
+	Suffix    string `json:"suffix,omitempty" example:"Jr"`
+	// The email with all but its first two characters and domain masked
+	MaskedEmail string `json:"maskedEmail" example:"al*****@example.com" validate:"required"`
+	// The mobile number with all but its last four digits masked
+	MaskedMobileNo string `json:"maskedMobileNo" example:"********1234" validate:"required"`
+	// The year of the customer's date of birth
+	BirthYear int    `json:"birthYear" example:"1990" validate:"required"`
+	City      string `json:"city" example:"Oakland" validate:"required"`
+	State     string `json:"state" example:"CA" validate:"required"`
+	ZipCode   string `json:"zipCode" example:"94612" validate:"required"`
+	// The customer's step in onboarding, ACTIVE once onboarded
+	OnboardingStatus     string `json:"onboardingStatus" example:"ACTIVE" validate:"required"`
+	LedgerCustomerNumber string `json:"ledgerCustomerNumber,omitempty" example:"100000000006001"`
+	// The ledger account id to fetch the customer's balance and transactions by
+	LedgerAccountId string `json:"ledgerAccountId,omitempty" example:"11522031"`
+	// The customer's membership status, empty until they have a membership
+	MembershipStatus string `json:"membershipStatus,omitempty" example:"active"`
+}
+
+// maskEmail hides all but the first two characters of the email's local part
+func maskEmail(email string) string {
+	at := strings.Index(email, "@")
+	if at < 1 {
+		return strings.Repeat("*", len(email))
+	}
+	visible := min(at-1, 2)
+	return email[:visible] + strings.Repeat("*", at-visible) + email[at:]
+}
+
+// maskTrailing hides all but the last visible characters of value
+func maskTrailing(value string, visible int) string {
+	if len(value) <= visible {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-visible) + value[len(value)-visible:]
+}
+
+// requireCustomer returns the customer with the path's userId
+func requireCustomer(c echo.Context, logger *slog.Logger) (*dao.MasterUserRecordDao, error) {
+	user, err := dao.MasterUserRecordDao{}.FindOneByUserId(c.Param("userId"))
+	if err != nil {
+		logger.Error("Failed to fetch customer from database", "error", err.Error())
+		return nil, response.InternalServerError(fmt.Sprintf("Failed to fetch customer from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if user == nil {
+		logger.Error("Customer not found")
+		return nil, response.NotFoundError("Customer not found", errtrace.New(""))
+	}
+	return user, nil
+}
+
+// @Summary SalesforceFindCustomer
+// @Description Finds a customer by exactly one of email, mobile number or ledger customer number, and returns their masked profile with onboarding and membership status
+// @Tags salesforce
+// @Produce json
+// @Param email query string false "email"
+// @Param mobileNo query string false "10 digit US mobile number"
+// @Param ledgerCustomerNumber query string false "ledger customer number"
+// @Success 200 {object} CustomerResponse
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers [get]
+func SalesforceFindCustomer(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c)
+	if _, err := requireScope(c, logger, "read:customers"); err != nil {
+		return err
+	}
+
+	email := strings.TrimSpace(c.QueryParam("email"))
+	mobileNo := strings.TrimPrefix(strings.TrimSpace(c.QueryParam("mobileNo")), "+1")
+	ledgerCustomerNumber := strings.TrimSpace(c.QueryParam("ledgerCustomerNumber"))
+
+	var lookups int
+	for _, value := range []string{email, mobileNo, ledgerCustomerNumber} {
+		if value != "" {
+			lookups++
+		}
+	}
+	if lookups != 1 {
+		return response.BadRequestErrors{Errors: []response.BadRequestError{{Error: "exactly one of email, mobileNo or ledgerCustomerNumber is required"}}}
+	}
+
+	var user *dao.MasterUserRecordDao
+	var err error
+	switch {
+	case email != "":
+		user, err = dao.MasterUserRecordDao{}.FindUserByEmail(email)
+	case mobileNo != "":
+		user, err = dao.MasterUserRecordDao{}.FindUserByMobileNumber(mobileNo)
+	default:
+		user, err = dao.MasterUserRecordDao{}.FindUserByLedgerCustomerNumber(ledgerCustomerNumber)
+	}
+	if err != nil {
+		logger.Error("Failed to fetch customer from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch customer from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if user == nil {
+		logger.Info("Customer not found")
+		return response.NotFoundError("Customer not found", errtrace.New(""))
+	}
+	logger = logger.With("userId", user.Id)
+
+	customer := CustomerResponse{
+		UserId:               user.Id,
+		FirstName:            user.FirstName,
+		LastName:             user.LastName,
+		Suffix:               user.Suffix,
+		MaskedEmail:          maskEmail(user.Email),
+		MaskedMobileNo:       maskTrailing(user.MobileNo, 4),
+		BirthYear:            user.DOB.Year(),
+		City:                 user.City,
+		State:                user.State,
+		ZipCode:              user.ZipCode,
+		OnboardingStatus:     user.UserStatus,
+		LedgerCustomerNumber: user.LedgerCustomerNumber,
+	}
+
+	account, err := dao.UserAccountCardDao{}.FindOneByUserId(db.DB, user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch account from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch account from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if account != nil {
+		customer.LedgerAccountId = account.AccountId
+	}
+
+	membership, err := dao.UserMembershipDao{}.FindOneByUserId(user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch membership from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch membership from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if membership != nil {
+		customer.MembershipStatus = membership.MembershipStatus
+	}
+
+	logger.Debug("Returning customer for Salesforce")
+	return c.JSON(http.StatusOK, customer)
+}
@@ -0,0 +1,19 @@
+package salesforce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskEmail(t *testing.T) {
+	assert.Equal(t, "al*****@example.com", maskEmail("alberta@example.com"))
+	assert.Equal(t, "a*@example.com", maskEmail("ab@example.com"))
+	assert.Equal(t, "*@example.com", maskEmail("a@example.com"))
+	assert.Equal(t, "*********", maskEmail("not-email"))
+}
+
+func TestMaskTrailing(t *testing.T) {
+	assert.Equal(t, "********1234", maskTrailing("+14159871234", 4))
+	assert.Equal(t, "***", maskTrailing("123", 4))
+}
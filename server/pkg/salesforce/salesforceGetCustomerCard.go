@@ -0,0 +1,105 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/config"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/ledger"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type CardResponse struct {
+	// The last digits of the card number
+	CardMaskNumber string `json:"cardMaskNumber" example:"XXXXXXXXXXXX1234" validate:"required"`
+	// The card status as reported by the ledger: ACTIVATED, TEMPRORY_BLOCKED_BY_CLIENT, LOST_STOLEN, etc
+	CardStatus string `json:"cardStatus" example:"ACTIVATED" validate:"required"`
+	// The status of the card's order with the card printer
+	OrderStatus    string `json:"orderStatus,omitempty" example:"SHIPPED"`
+	CardExpiryDate string `json:"cardExpiryDate" example:"2029-09" validate:"required"`
+	// Whether the card replaces an expired card
+	IsReIssue bool `json:"isReIssue"`
+	// Whether the card replaces a lost, stolen or damaged card
+	IsReplace bool `json:"isReplace"`
+	// The status of the account the card draws on: ACTIVE, SUSPENDED, CLOSED
+	AccountStatus string `json:"accountStatus" example:"ACTIVE" validate:"required"`
+}
+
+// @Summary SalesforceGetCustomerCard
+// @Description Gets the status of a customer's card, from the ledger while their account is active
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Success 200 {object} CardResponse
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer or card"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/card [get]
+func SalesforceGetCustomerCard(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	if _, err := requireScope(c, logger, "read:card"); err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	account, err := dao.UserAccountCardDao{}.FindOneByUserId(db.DB, user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch card from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch card from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if account == nil || account.CardId == "" {
+		logger.Info("Card not found")
+		return response.NotFoundError("Card not found", errtrace.New(""))
+	}
+
+	card := CardResponse{
+		CardMaskNumber: account.CardMaskNumber,
+		CardStatus:     "CLOSED",
+		CardExpiryDate: account.CardExpirationDate,
+		IsReIssue:      account.IsReissue,
+		IsReplace:      account.IsReplace,
+		AccountStatus:  account.AccountStatus,
+	}
+	// The ledger still reports the card of a suspended or closed account, so only an active
+	// account's card is read from it
+	if !account.IsActive() {
+		return c.JSON(http.StatusOK, card)
+	}
+
+	ledgerClient := ledger.NewNetXDCardApiClient(config.Config.Ledger, ledger.NewLedgerSigningParamsBuilderFromConfig(config.Config.Ledger))
+	getCardResponse, err := ledgerClient.GetCardDetails(ledgerClient.BuildGetCardDetailsRequest(user.LedgerCustomerNumber, account.AccountNumber, account.CardId))
+	if err != nil {
+		logger.Error("error while calling ledger's GetCardDetails", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("error while calling ledger's GetCardDetails: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if getCardResponse.Error != nil {
+		logger.Error("error from ledger's GetCardDetails", "error", getCardResponse.Error)
+		return response.InternalServerError(fmt.Sprintf("error from ledger's GetCardDetails: %s", getCardResponse.Error.Message), errtrace.New(""))
+	}
+	if getCardResponse.Result == nil {
+		logger.Error("no error was reported from ledger GetCardDetails, but Result is missing from response")
+		return response.InternalServerError("no error was reported from ledger GetCardDetails, but Result is missing from response", errtrace.New(""))
+	}
+
+	ledgerCard := getCardResponse.Result.Card
+	card.CardMaskNumber = ledgerCard.CardMaskNumber
+	card.CardStatus = ledgerCard.CardStatus
+	card.OrderStatus = ledgerCard.OrderStatus
+	card.IsReIssue = card.IsReIssue || ledgerCard.IsReIssue
+	card.IsReplace = card.IsReplace || ledgerCard.IsReplace
+	if card.CardExpiryDate == "" {
+		card.CardExpiryDate = ledgerCard.CardExpiryDate
+	}
+
+	logger.Debug("Returning card for Salesforce", "cardStatus", card.CardStatus)
+	return c.JSON(http.StatusOK, card)
+}
@@ -0,0 +1,68 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type DemographicUpdate struct {
+	Id string `json:"id" example:"9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a" validate:"required"`
+	// What the customer asked to change: full_name or address
+	Type string `json:"type" example:"address" validate:"required"`
+	// The status of the request: pending, approved or rejected
+	Status string `json:"status" example:"pending" validate:"required"`
+	// Why the request was approved or rejected
+	ReviewReason *string    `json:"reviewReason,omitempty" example:"Proof of address did not match"`
+	SubmittedAt  time.Time  `json:"submittedAt" example:"2025-09-28T12:21:53Z" validate:"required"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// @Summary SalesforceGetCustomerDemographicUpdates
+// @Description Gets the changes of name or address a customer requested, newest first. The requested values are not included.
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Success 200 {object} []DemographicUpdate
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/demographic-updates [get]
+func SalesforceGetCustomerDemographicUpdates(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	if _, err := requireScope(c, logger, "read:demographic_updates"); err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	updates, err := dao.DemographicUpdatesDao{}.FindAllByUserId(user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch demographic updates from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch demographic updates from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	demographicUpdates := make([]DemographicUpdate, 0, len(updates))
+	for _, update := range updates {
+		demographicUpdates = append(demographicUpdates, DemographicUpdate{
+			Id:           update.Id,
+			Type:         update.Type,
+			Status:       update.Status,
+			ReviewReason: update.ReviewReason,
+			SubmittedAt:  update.CreatedAt,
+			ReviewedAt:   update.ReviewedAt,
+		})
+	}
+
+	logger.Debug("Returning demographic updates for Salesforce", "count", len(demographicUpdates))
+	return c.JSON(http.StatusOK, demographicUpdates)
+}
@@ -0,0 +1,80 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type Dispute struct {
+	Id string `json:"id" example:"5b0c8e7a-4f8e-4f4e-9a3b-2b1c0d9e8f7a" validate:"required"`
+	// The reference id of the disputed transaction
+	TransactionId string `json:"transactionId" example:"ledger.ach.transfer_ach_pull_1756829941484592000" validate:"required"`
+	// The status of the dispute: submitted, under_review or provisionally_credited
+	Status      string `json:"status" example:"submitted" validate:"required"`
+	Reason      string `json:"reason" example:"Unauthorized transaction" validate:"required"`
+	AmountCents *int64 `json:"amountCents,omitempty" example:"10000"`
+	// Whether the customer holds a provisional credit for the dispute
+	IsCredited bool `json:"isCredited"`
+	// When Reg E requires a provisional credit, unless the dispute is resolved first
+	ProvisionalCreditDueAt *time.Time `json:"provisionalCreditDueAt,omitempty"`
+	// When Reg E requires the dispute to be resolved
+	ResolutionDueAt *time.Time `json:"resolutionDueAt,omitempty"`
+	SubmittedAt     time.Time  `json:"submittedAt" example:"2025-09-28T12:21:53Z" validate:"required"`
+}
+
+// @Summary SalesforceGetCustomerDisputes
+// @Description Gets a customer's open transaction disputes, newest first
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Success 200 {object} []Dispute
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/disputes [get]
+func SalesforceGetCustomerDisputes(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	if _, err := requireScope(c, logger, "read:disputes"); err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	transactionDisputes, err := dao.TransactionDisputeDao{}.FindByUserId(user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch disputes from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch disputes from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	disputes := []Dispute{}
+	for _, transactionDispute := range transactionDisputes {
+		if !dispute.IsOpen(transactionDispute.Status) {
+			continue
+		}
+		disputes = append(disputes, Dispute{
+			Id:                     transactionDispute.Id,
+			TransactionId:          transactionDispute.TransactionIdentifier,
+			Status:                 transactionDispute.Status,
+			Reason:                 transactionDispute.Reason,
+			AmountCents:            transactionDispute.AmountCents,
+			IsCredited:             dispute.IsCredited(transactionDispute),
+			ProvisionalCreditDueAt: transactionDispute.ProvisionalCreditDueAt,
+			ResolutionDueAt:        transactionDispute.ResolutionDueAt,
+			SubmittedAt:            transactionDispute.CreatedAt,
+		})
+	}
+
+	logger.Debug("Returning open disputes for Salesforce", "count", len(disputes))
+	return c.JSON(http.StatusOK, disputes)
+}
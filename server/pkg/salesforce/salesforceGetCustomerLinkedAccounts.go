@@ -0,0 +1,93 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type LinkedAccount struct {
+	InstitutionName string `json:"institutionName,omitempty" example:"Chase"`
+	Name            string `json:"name" example:"Plaid Checking" validate:"required"`
+	// The last digits of the account number
+	Mask    string `json:"mask,omitempty" example:"0000"`
+	Subtype string `json:"subtype" example:"checking" validate:"required"`
+	// How the account was linked: INSTANT_AUTH, SAME_DAY_MICRODEPOSITS, etc
+	AuthMethod string `json:"authMethod,omitempty" example:"INSTANT_AUTH"`
+	// Plaid's verification of the account, empty for instantly linked accounts: pending_manual_verification, manually_verified, verification_failed, etc
+	VerificationStatus string `json:"verificationStatus,omitempty" example:"pending_manual_verification"`
+	// The error Plaid last reported for the link, e.g. ITEM_LOGIN_REQUIRED when the customer must reconnect
+	LinkError string    `json:"linkError,omitempty" example:"ITEM_LOGIN_REQUIRED"`
+	LinkedAt  time.Time `json:"linkedAt" example:"2025-09-28T12:21:53Z" validate:"required"`
+}
+
+// @Summary SalesforceGetCustomerLinkedAccounts
+// @Description Gets the external bank accounts a customer linked through Plaid, newest first
+// @Tags salesforce
+// @Produce json
+// @Param userId path string true "customer id"
+// @Success 200 {object} []LinkedAccount
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/linked-accounts [get]
+func SalesforceGetCustomerLinkedAccounts(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	if _, err := requireScope(c, logger, "read:linked_accounts"); err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := dao.PlaidAccountDao{}.FindAccountsForUser(user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch linked accounts from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch linked accounts from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	items, err := dao.PlaidItemDao{}.GetItemsByUserId(user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch Plaid items from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch Plaid items from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	linkErrors := map[string]string{}
+	for _, item := range items {
+		if item.ItemError != nil {
+			linkErrors[item.PlaidItemID] = *item.ItemError
+		}
+	}
+
+	linkedAccounts := make([]LinkedAccount, 0, len(accounts))
+	for _, account := range accounts {
+		linkedAccount := LinkedAccount{
+			Name:      account.Name,
+			Subtype:   string(account.Subtype),
+			LinkError: linkErrors[account.PlaidItemID],
+			LinkedAt:  account.CreatedAt,
+		}
+		if account.InstitutionName != nil {
+			linkedAccount.InstitutionName = *account.InstitutionName
+		}
+		if account.Mask != nil {
+			linkedAccount.Mask = *account.Mask
+		}
+		if account.AuthMethod != nil {
+			linkedAccount.AuthMethod = string(*account.AuthMethod)
+		}
+		if account.VerificationStatus != nil {
+			linkedAccount.VerificationStatus = *account.VerificationStatus
+		}
+		linkedAccounts = append(linkedAccounts, linkedAccount)
+	}
+
+	logger.Debug("Returning linked accounts for Salesforce", "count", len(linkedAccounts))
+	return c.JSON(http.StatusOK, linkedAccounts)
+}
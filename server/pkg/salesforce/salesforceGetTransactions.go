@@ -1,6 +1,7 @@
 package salesforce
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"process-api/pkg/config"
@@ -9,12 +10,18 @@ import (
 	"process-api/pkg/ledger"
 	"process-api/pkg/logging"
 	"process-api/pkg/model/response"
+	"process-api/pkg/transaction"
+	"strconv"
+	"strings"
+	"time"
 
 	"braces.dev/errtrace"
-	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/labstack/echo/v4"
 )
 
+// transactionDateLayout is the layout of the from and to filters
+const transactionDateLayout = "2006-01-02"
+
 type Transaction struct {
 	// The reference id for the transaction
 	Id string `json:"id" example:"ledger.ach.transfer_ach_pull_1756829941484592000" validate:"required"`
@@ -30,14 +37,73 @@ type Transaction struct {
 	Date string `json:"date" example:"2025-09-28T12:21:53Z" validate:"required"`
 	// The status of the transaction: ACTIVE, CLOSED, DORMANT, SUSPENDED
 	Status string `json:"status" example:"ACTIVE" validate:"required"`
+	// True when funds were credited to the account
+	Credit bool `json:"credit" example:"false" validate:"required"`
+	// The date a card purchase was completed, when it was authorized first
+	CompletionDate *string `json:"completionDate,omitempty" example:"2025-09-29T08:01:12Z"`
+	// The merchant category of the card transaction's MCC, empty when there is none
+	MerchantCategory string `json:"merchantCategory" example:"Grocery Stores And Supermarkets" validate:"required"`
+	// The merchant details of a card transaction
+	CardAcceptor *transaction.CardAcceptor `json:"cardAcceptor,omitempty"`
+	// The status of the customer's dispute of the transaction. Missing for transactions that cannot be disputed.
+	DisputeStatus *string `json:"disputeStatus,omitempty" example:"none" enums:"none,submitted,under_review,provisionally_credited,resolved_customer,resolved_merchant,withdrawn"`
+}
+
+// HeaderNextCursor holds the cursor of the next page of transactions. It is only set when
+// the transactions are paged and there are more of them.
+const HeaderNextCursor = "X-Next-Cursor"
+
+// transactionFilterFromQuery reads the transaction filters. The to date is inclusive.
+// Transactions are only paged when a limit or cursor is passed.
+func transactionFilterFromQuery(c echo.Context) (transaction.Filter, error) {
+	filter := transaction.Filter{Cursor: c.QueryParam("cursor")}
+	filter.Unpaged = filter.Cursor == "" && c.QueryParam("limit") == ""
+
+	for _, transactionType := range c.QueryParams()["type"] {
+		if transactionType = strings.TrimSpace(transactionType); transactionType != "" {
+			filter.Types = append(filter.Types, transactionType)
+		}
+	}
+	if from := c.QueryParam("from"); from != "" {
+		fromDate, err := time.Parse(transactionDateLayout, from)
+		if err != nil {
+			return filter, response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "from", Error: "must be a date formatted as YYYY-MM-DD"}}}
+		}
+		filter.From = fromDate
+	}
+	if to := c.QueryParam("to"); to != "" {
+		toDate, err := time.Parse(transactionDateLayout, to)
+		if err != nil {
+			return filter, response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "to", Error: "must be a date formatted as YYYY-MM-DD"}}}
+		}
+		filter.Before = toDate.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.Before.IsZero() && !filter.From.Before(filter.Before) {
+		return filter, response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "to", Error: "must not be before from"}}}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		limitValue, err := strconv.Atoi(limit)
+		if err != nil || limitValue < 1 || limitValue > transaction.MaxLimit {
+			return filter, response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "limit", Error: fmt.Sprintf("must be a number from 1 to %d", transaction.MaxLimit)}}}
+		}
+		filter.Limit = limitValue
+	}
+	return filter, nil
 }
 
 // @Summary SalesforceGetTransactions
-// @Description Gets a list of transactions given a ledger account id
+// @Description Gets a list of transactions given a ledger account id, newest first. Transactions are described as they are in the app, with their merchant category, card acceptor and dispute status. Passing a limit or cursor lists a page of them, and the X-Next-Cursor header holds the cursor of the next page.
 // @Tags salesforce
 // @Produce json
 // @Param ledgerAccountID path string true "ledger account id"
+// @Param from query string false "Only list transactions on or after this date" format(date)
+// @Param to query string false "Only list transactions on or before this date" format(date)
+// @Param type query []string false "Only list transactions of these ledger types or type details, such as PURCHASE" collectionFormat(multi)
+// @Param cursor query string false "The X-Next-Cursor of the previous page"
+// @Param limit query int false "The number of transactions per page, 50 when only a cursor is passed" minimum(1) maximum(200)
 // @Success 200 {object} []Transaction
+// @Header 200 {string} X-Next-Cursor "The cursor of the next page. Missing on the last page and when not paging."
+// @Failure 400 {object} response.BadRequestErrors
 // @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
 // @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
 // @Failure 404 {object} response.ErrorResponse "Could not find account"
@@ -46,21 +112,13 @@ type Transaction struct {
 func SalesforceGetTransactions(c echo.Context) error {
 	ledgerAccountID := c.Param("ledgerAccountID")
 	logger := logging.GetEchoContextLogger(c).With("ledgerAccountID", ledgerAccountID)
-	claims, ok := c.Get("salesforce_claims").(*validator.ValidatedClaims)
-	if !ok {
-		logger.Error("Could not get custom salesforce claims")
-		return response.UnauthorizedError("Could not get custom salesforce claims")
-	}
-
-	customClaims, ok := claims.CustomClaims.(*SalesforceClaims)
-	if !ok {
-		logger.Error("Could not cast to CustomClaims")
-		return response.UnauthorizedError("Could not cast to CustomClaims")
+	if _, err := requireScope(c, logger, "read:transactions"); err != nil {
+		return err
 	}
 
-	if !customClaims.HasScope("read:transactions") {
-		logger.Error("Custom claims missing read:transactions scope")
-		return response.ForbiddenError("Custom claims missing read:transactions scope", errtrace.New(""))
+	filter, err := transactionFilterFromQuery(c)
+	if err != nil {
+		return err
 	}
 
 	logger.Debug("Fetching transactions for Salesforce")
@@ -95,27 +153,43 @@ func SalesforceGetTransactions(c echo.Context) error {
 		return response.InternalServerError("The ledger responded with an empty result object", errtrace.New(""))
 	}
 
-	transactions := make([]Transaction, 0, len(ledgerResponse.Result.AccountTransactions))
+	mapped, err := transaction.ForUser(account.UserId, ledgerResponse.Result.AccountTransactions)
+	if err != nil {
+		logger.Error("Failed to get disputes for transactions", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to get disputes for transactions: %s", err.Error()), errtrace.Wrap(err))
+	}
 
-	for _, data := range ledgerResponse.Result.AccountTransactions {
-		var merchantAccount ledger.ListTransactionsByAccountResultTransactionAccount
-		if data.Credit {
-			merchantAccount = data.DebtorAccount
-		} else {
-			merchantAccount = data.CreditorAccount
-		}
-		merchantName := ledger.GetTransactionAccountMerchantName(merchantAccount)
+	page, err := filter.Apply(mapped)
+	if errors.Is(err, transaction.ErrUnknownCursor) {
+		return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "cursor", Error: "does not match a transaction"}}}
+	}
+	if err != nil {
+		logger.Error("Failed to page transactions", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to page transactions: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	transactions := make([]Transaction, 0, len(page.Transactions))
+	for _, t := range page.Transactions {
 		transactions = append(transactions, Transaction{
-			Id:          data.ReferenceID,
-			AccountId:   account.AccountId,
-			AmountCents: data.InstructedAmount.Amount,
-			Merchant:    merchantName,
-			Type:        data.Type,
-			Date:        data.TimeStamp,
-			Status:      data.Status,
+			Id:               t.ReferenceID,
+			AccountId:        account.AccountId,
+			AmountCents:      t.InstructedAmount.Amount,
+			Merchant:         t.MerchantName,
+			Type:             t.TypeRaw,
+			Date:             t.TimeStamp,
+			Status:           t.Status,
+			Credit:           t.Credit,
+			CompletionDate:   t.CompletionTimeStamp,
+			MerchantCategory: t.MerchantCategory,
+			CardAcceptor:     t.CardAcceptor,
+			DisputeStatus:    t.DisputeLifecycleStatus,
 		})
 	}
 
+	if page.HasMore {
+		c.Response().Header().Set(HeaderNextCursor, transactions[len(transactions)-1].Id)
+	}
+
 	logger.Debug("Returning transactions", "count", len(transactions))
 
 	return c.JSON(http.StatusOK, transactions)
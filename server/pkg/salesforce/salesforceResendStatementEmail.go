@@ -0,0 +1,96 @@
+package salesforce
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/admin"
+	"process-api/pkg/clock"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/statement"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+type ResendStatementEmailRequest struct {
+	// The month of the statement, in English
+	Month string `json:"month" example:"September" validate:"required,oneof=January February March April May June July August September October November December"`
+	Year  string `json:"year" example:"2025" validate:"required,numeric,len=4"`
+}
+
+// @Summary SalesforceResendCustomerStatementEmail
+// @Description Emails a customer again that their statement for a month is ready to view in the app
+// @Tags salesforce
+// @Accept json
+// @Param userId path string true "customer id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Param request body ResendStatementEmailRequest true "The statement's month"
+// @Success 202
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer or account"
+// @Failure 409 {object} response.ErrorResponse "A request with the Idempotency-Key is in progress"
+// @Failure 422 {object} response.ErrorResponse "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/statements/resend [post]
+func (h *Handler) SalesforceResendCustomerStatementEmail(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	claims, agent, err := requireAgent(c)
+	if err != nil {
+		return err
+	}
+	user, err := requireCustomer(c, logger)
+	if err != nil {
+		return err
+	}
+
+	requestData := new(ResendStatementEmailRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+	// A month's statement is issued once the month is over
+	statementMonth, _ := time.Parse("January 2006", requestData.Month+" "+requestData.Year)
+	now := clock.Now()
+	if !statementMonth.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return response.BadRequestErrors{Errors: []response.BadRequestError{{FieldName: "month", Error: "must be before the current month"}}}
+	}
+
+	account, err := dao.UserAccountCardDao{}.FindOneByUserId(db.DB, user.Id)
+	if err != nil {
+		logger.Error("Failed to fetch account from database", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to fetch account from database: %s", err.Error()), errtrace.Wrap(err))
+	}
+	if account == nil || account.AccountId == "" {
+		logger.Info("Account not found")
+		return response.NotFoundError("Account not found", errtrace.New(""))
+	}
+
+	_, err = h.RiverClient.Insert(c.Request().Context(), statement.NotificationEmailJobArgs{
+		FirstName: user.FirstName,
+		Email:     user.Email,
+		Month:     requestData.Month,
+		Year:      requestData.Year,
+		BaseUrl:   statement.BaseUrl(h.Env),
+		AccountId: account.AccountId,
+	}, nil)
+	if err != nil {
+		logger.Error("Failed to enqueue statement email", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to enqueue statement email: %s", err.Error()), errtrace.Wrap(err))
+	}
+	logger.Info("Salesforce agent resent statement email", "agent", agent, "month", requestData.Month, "year", requestData.Year)
+
+	recordAgentAudit(c, claims, agent, admin.AUDIT_ACTION_STATEMENT_EMAIL_RESENT, dao.ADMIN_AUDIT_ENTITY_STATEMENT, account.AccountId+"/"+statementMonth.Format("2006-01"), user.Id, map[string]any{}, map[string]any{
+		"month": requestData.Month,
+		"year":  requestData.Year,
+	})
+	return c.NoContent(http.StatusAccepted)
+}
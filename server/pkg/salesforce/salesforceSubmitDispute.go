@@ -0,0 +1,106 @@
+package salesforce
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"process-api/pkg/admin"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+	"github.com/riverqueue/river"
+)
+
+// Handler holds the dependencies of agent actions that enqueue background jobs
+type Handler struct {
+	RiverClient *river.Client[*sql.Tx]
+	Env         string
+}
+
+type SubmitDisputeRequest struct {
+	// The reference id of the transaction to dispute
+	TransactionId string `json:"transactionId" example:"ledger.ach.transfer_ach_pull_1756829941484592000" validate:"required"`
+	// One of the reasons customers can choose from in the app
+	Reason  string `json:"reason" example:"Duplicate transaction" validate:"required,oneof='Incorrect amount charged' 'Duplicate transaction' 'Goods not received' 'Goods not as described' 'Billing error' 'Unauthorized transaction' 'Identity theft' 'Card skimming' 'Online fraud'"`
+	Details string `json:"details" example:"Customer was charged twice for the same order"`
+}
+
+// @Summary SalesforceSubmitCustomerDispute
+// @Description Disputes one of a customer's transactions on their behalf. The customer is emailed that the dispute was received.
+// @Tags salesforce
+// @Accept json
+// @Produce json
+// @Param userId path string true "customer id"
+// @Param Idempotency-Key header string true "Retries with the same key replay the first response"
+// @Param X-Salesforce-Agent header string false "Email of the agent, unless the token was issued for one"
+// @Param request body SubmitDisputeRequest true "The transaction to dispute and why"
+// @Success 201 {object} Dispute
+// @Failure 400 {object} response.BadRequestErrors
+// @Failure 401 {object} response.ErrorResponse "Missing or invalid authentication"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions (missing required scope)"
+// @Failure 404 {object} response.ErrorResponse "Could not find customer or transaction"
+// @Failure 409 {object} response.ErrorResponse "The transaction is already disputed, or a request with the Idempotency-Key is in progress"
+// @Failure 412 {object} response.ErrorResponse "The customer is not active"
+// @Failure 422 {object} response.ErrorResponse "The transaction cannot be disputed, or the Idempotency-Key was used for a different request"
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/salesforce/customers/{userId}/disputes [post]
+func (h *Handler) SalesforceSubmitCustomerDispute(c echo.Context) error {
+	logger := logging.GetEchoContextLogger(c).With("userId", c.Param("userId"))
+	claims, agent, err := requireAgent(c)
+	if err != nil {
+		return err
+	}
+	user, errResponse := dao.RequireUserWithState(c.Param("userId"), constant.ACTIVE)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	requestData := new(SubmitDisputeRequest)
+	if err := c.Bind(requestData); err != nil {
+		return response.BadRequestInvalidBody
+	}
+	if err := c.Validate(requestData); err != nil {
+		return err
+	}
+	logger = logger.With("transactionId", requestData.TransactionId)
+
+	disputeService := dispute.Service{RiverClient: h.RiverClient}
+	transactionDispute, err := disputeService.Submit(c.Request().Context(), logger, *user, requestData.TransactionId, requestData.Reason, requestData.Details, agent)
+	switch {
+	case errors.Is(err, dispute.ErrTransactionNotFound), errors.Is(err, dispute.ErrTransactionNotOwned):
+		// The agent is not told whether the transaction belongs to another customer
+		logger.Info("Transaction to dispute not found for customer", "error", err.Error())
+		return response.ErrorResponse{ErrorCode: constant.TRANSACTION_DOES_NOT_EXIST, Message: constant.TRANSACTION_DOES_NOT_EXIST_MSG, StatusCode: http.StatusNotFound, MaybeInnerError: errtrace.Wrap(err)}
+	case errors.Is(err, dispute.ErrNotDisputable):
+		return response.ErrorResponse{ErrorCode: constant.DISPUTE_REQUEST_INVALID_TRANSACTION_TYPE, Message: constant.DISPUTE_REQUEST_INVALID_TRANSACTION_TYPE_MSG, StatusCode: http.StatusUnprocessableEntity, MaybeInnerError: errtrace.Wrap(err)}
+	case errors.Is(err, dispute.ErrAlreadyDisputed):
+		return response.ErrorResponse{ErrorCode: constant.DISPUTE_REQUEST_ALREADY_EXISTS, Message: constant.DISPUTE_REQUEST_ALREADY_EXISTS_MSG, StatusCode: http.StatusConflict, MaybeInnerError: errtrace.Wrap(err)}
+	case err != nil:
+		logger.Error("Failed to submit transaction dispute", "error", err.Error())
+		return response.InternalServerError(fmt.Sprintf("Failed to submit transaction dispute: %s", err.Error()), errtrace.Wrap(err))
+	}
+	logger.Info("Salesforce agent submitted dispute", "agent", agent, "disputeId", transactionDispute.Id)
+
+	recordAgentAudit(c, claims, agent, admin.AUDIT_ACTION_DISPUTE_SUBMITTED, dao.ADMIN_AUDIT_ENTITY_DISPUTE, transactionDispute.Id, user.Id, map[string]any{}, map[string]any{
+		"status":                 transactionDispute.Status,
+		"transaction_identifier": transactionDispute.TransactionIdentifier,
+		"reason":                 transactionDispute.Reason,
+		"amount_cents":           transactionDispute.AmountCents,
+	})
+	return c.JSON(http.StatusCreated, Dispute{
+		Id:                     transactionDispute.Id,
+		TransactionId:          transactionDispute.TransactionIdentifier,
+		Status:                 transactionDispute.Status,
+		Reason:                 transactionDispute.Reason,
+		AmountCents:            transactionDispute.AmountCents,
+		ProvisionalCreditDueAt: transactionDispute.ProvisionalCreditDueAt,
+		ResolutionDueAt:        transactionDispute.ResolutionDueAt,
+		SubmittedAt:            transactionDispute.CreatedAt,
+	})
+}
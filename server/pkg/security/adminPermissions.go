@@ -0,0 +1,101 @@
+package security
+
+import (
+	"net/http"
+	"process-api/pkg/logging"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminPermission is an action in the admin console that an operator's roles must grant
+type AdminPermission string
+
+const (
+	ADMIN_PERMISSION_CUSTOMERS_READ       AdminPermission = "customers:read"
+	ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL AdminPermission = "customers:pii:reveal"
+	ADMIN_PERMISSION_DEVICES_REVOKE       AdminPermission = "devices:revoke"
+	ADMIN_PERMISSION_DISPUTES_RESOLVE     AdminPermission = "disputes:resolve"
+	ADMIN_PERMISSION_MONEY_CREDIT         AdminPermission = "money:credit"
+	ADMIN_PERMISSION_DEMOGRAPHICS_APPROVE AdminPermission = "demographics:approve"
+	ADMIN_PERMISSION_AUDIT_READ           AdminPermission = "audit:read"
+	ADMIN_PERMISSION_COMPLIANCE_HOLD      AdminPermission = "compliance:hold"
+	ADMIN_PERMISSION_WEBHOOKS_MANAGE      AdminPermission = "webhooks:manage"
+	ADMIN_PERMISSION_IDENTITY_REVIEW      AdminPermission = "identity:review"
+)
+
+var allAdminPermissions = []AdminPermission{
+	ADMIN_PERMISSION_CUSTOMERS_READ,
+	ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL,
+	ADMIN_PERMISSION_DEVICES_REVOKE,
+	ADMIN_PERMISSION_DISPUTES_RESOLVE,
+	ADMIN_PERMISSION_MONEY_CREDIT,
+	ADMIN_PERMISSION_DEMOGRAPHICS_APPROVE,
+	ADMIN_PERMISSION_AUDIT_READ,
+	ADMIN_PERMISSION_COMPLIANCE_HOLD,
+	ADMIN_PERMISSION_WEBHOOKS_MANAGE,
+	ADMIN_PERMISSION_IDENTITY_REVIEW,
+}
+
+// adminRolePermissions maps Auth0 roles to the permissions they grant. The environment's
+// operations role only lets an operator into the console; what they can do there comes
+// from the functional roles they also hold.
+var adminRolePermissions = map[string][]AdminPermission{
+	"production-operations": {ADMIN_PERMISSION_CUSTOMERS_READ},
+	"sandbox-operations":    {ADMIN_PERMISSION_CUSTOMERS_READ},
+	"operations-support": {
+		ADMIN_PERMISSION_CUSTOMERS_READ,
+		ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL,
+		ADMIN_PERMISSION_DEVICES_REVOKE,
+	},
+	"operations-disputes": {
+		ADMIN_PERMISSION_CUSTOMERS_READ,
+		ADMIN_PERMISSION_DISPUTES_RESOLVE,
+		ADMIN_PERMISSION_MONEY_CREDIT,
+	},
+	"operations-compliance": {
+		ADMIN_PERMISSION_CUSTOMERS_READ,
+		ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL,
+		ADMIN_PERMISSION_DEMOGRAPHICS_APPROVE,
+		ADMIN_PERMISSION_AUDIT_READ,
+		ADMIN_PERMISSION_COMPLIANCE_HOLD,
+		ADMIN_PERMISSION_IDENTITY_REVIEW,
+	},
+	"operations-admin": allAdminPermissions,
+}
+
+// AdminPermissionsForRoles returns the permissions granted by any of roles
+func AdminPermissionsForRoles(roles []string) []AdminPermission {
+	var permissions []AdminPermission
+	for _, role := range roles {
+		for _, permission := range adminRolePermissions[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// Can reports whether the operator's roles grant permission
+func (c *AdminUserContext) Can(permission AdminPermission) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// RequireAdminPermission refuses the route to operators without permission. It runs
+// after AdminAuthMiddleware, which puts the operator on the context.
+func RequireAdminPermission(permission AdminPermission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			adminCtx, ok := c.(*AdminUserContext)
+			if !ok {
+				return c.Redirect(http.StatusFound, "/admin/login")
+			}
+			if !adminCtx.Can(permission) {
+				logging.GetEchoContextLogger(c).Info("Operator lacks permission for admin route", "email", adminCtx.Email, "roles", adminCtx.Roles, "permission", permission, "path", c.Path())
+				return c.HTML(http.StatusForbidden, "<h1>Access Denied</h1><p>You do not have permission to perform this action.</p>")
+			}
+			return next(c)
+		}
+	}
+}
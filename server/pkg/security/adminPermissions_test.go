@@ -0,0 +1,65 @@
+package security
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"process-api/pkg/logging"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminPermissionsForRoles(t *testing.T) {
+	assert.Equal(t, []AdminPermission{ADMIN_PERMISSION_CUSTOMERS_READ}, AdminPermissionsForRoles([]string{"sandbox-operations"}), "The operations role should only grant read access")
+	assert.Empty(t, AdminPermissionsForRoles([]string{"unknown-role"}))
+
+	permissions := AdminPermissionsForRoles([]string{"production-operations", "operations-support", "operations-disputes"})
+	assert.ElementsMatch(t, []AdminPermission{
+		ADMIN_PERMISSION_CUSTOMERS_READ,
+		ADMIN_PERMISSION_CUSTOMERS_PII_REVEAL,
+		ADMIN_PERMISSION_DEVICES_REVOKE,
+		ADMIN_PERMISSION_DISPUTES_RESOLVE,
+		ADMIN_PERMISSION_MONEY_CREDIT,
+	}, permissions, "Permissions of every role should be combined without duplicates")
+
+	assert.ElementsMatch(t, allAdminPermissions, AdminPermissionsForRoles([]string{"operations-admin"}))
+}
+
+func TestRequireAdminPermission(t *testing.T) {
+	logging.Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	requireMoneyCredit := RequireAdminPermission(ADMIN_PERMISSION_MONEY_CREDIT)(handler)
+	e := echo.New()
+
+	t.Run("Operator with the permission", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		roles := []string{"sandbox-operations", "operations-disputes"}
+		c := &AdminUserContext{Context: e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec), Roles: roles, Permissions: AdminPermissionsForRoles(roles)}
+
+		assert.NoError(t, requireMoneyCredit(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Operator without the permission", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		roles := []string{"sandbox-operations", "operations-support"}
+		c := &AdminUserContext{Context: e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec), Roles: roles, Permissions: AdminPermissionsForRoles(roles)}
+
+		assert.NoError(t, requireMoneyCredit(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("No operator", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+
+		assert.NoError(t, requireMoneyCredit(c))
+		assert.Equal(t, http.StatusFound, rec.Code, "Expected redirect to login")
+	})
+}
@@ -41,6 +41,8 @@ type AdminUserContext struct {
 	Email  string
 	Name   string
 	Roles  []string
+	// Granted by Roles, see AdminPermissionsForRoles
+	Permissions []AdminPermission
 }
 
 var auth0Client *Auth0Client
@@ -190,11 +192,12 @@ func AdminAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		adminCtx := &AdminUserContext{
-			Context: c,
-			UserID:  claims.Subject,
-			Email:   claims.Email,
-			Name:    claims.Name,
-			Roles:   claims.Roles,
+			Context:     c,
+			UserID:      claims.Subject,
+			Email:       claims.Email,
+			Name:        claims.Name,
+			Roles:       claims.Roles,
+			Permissions: AdminPermissionsForRoles(claims.Roles),
 		}
 
 		return next(adminCtx)
@@ -0,0 +1,29 @@
+package security
+
+import (
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ComplianceHoldMiddleware refuses routes that move money for a customer on compliance
+// hold. It runs after LoggedInRegisteredUserMiddleware and before IdempotencyMiddleware,
+// so a refusal is not replayed once the hold is released.
+func ComplianceHoldMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cc, ok := c.(*LoggedInRegisteredUserContext)
+		if !ok {
+			return response.UnauthorizedError("Failed to get user Id from custom context")
+		}
+		if errResponse := dao.RequireNoComplianceHold(cc.UserId); errResponse != nil {
+			if errResponse.ErrorCode == constant.COMPLIANCE_HOLD {
+				logging.GetEchoContextLogger(c).Info("Refused money movement for customer on compliance hold", "userId", cc.UserId, "path", c.Path())
+			}
+			return errResponse
+		}
+		return next(c)
+	}
+}
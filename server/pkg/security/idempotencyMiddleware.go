@@ -0,0 +1,127 @@
+package security
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type idempotencyResponseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyResponseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware makes a route safe to retry for clients that send an
+// Idempotency-Key header. The first request with a key runs normally and its final
+// response is stored for config.Config.Idempotency.KeyTtl. A retry with the same
+// key and body replays that response, while the same key with a different body is
+// rejected. Server errors are not stored so the request can be retried.
+//
+// It keys on the user id set by the JWT middlewares, so it must be used on routes
+// behind one of them. Requests without the header are not affected.
+func IdempotencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return response.BadRequestErrors{
+				Errors: []response.BadRequestError{{FieldName: HeaderIdempotencyKey, Error: fmt.Sprintf("max=%d", maxIdempotencyKeyLength)}},
+			}
+		}
+
+		userId, _ := c.Get("user_id").(string)
+		if userId == "" {
+			return response.UnauthorizedError("Idempotency-Key used on a route without a user id")
+		}
+
+		logger := logging.GetEchoContextLogger(c)
+
+		requestBody, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return response.BadRequestInvalidBody
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(requestBody))
+
+		now := clock.Now()
+		record := dao.IdempotencyKeyDao{
+			UserId:             userId,
+			Key:                key,
+			RequestFingerprint: requestFingerprint(c.Request(), requestBody),
+			CreatedAt:          now,
+			ExpiresAt:          now.Add(time.Duration(config.Config.Idempotency.KeyTtl) * time.Millisecond),
+		}
+		existing, claimed, err := dao.IdempotencyKeyDao{}.Claim(&record)
+		if err != nil {
+			return response.InternalServerError(fmt.Sprintf("Failed to claim idempotency key: %s", err.Error()), errtrace.Wrap(err))
+		}
+
+		if !claimed {
+			if existing.RequestFingerprint != record.RequestFingerprint {
+				return response.ErrorResponse{ErrorCode: constant.IDEMPOTENCY_KEY_REUSED, Message: constant.IDEMPOTENCY_KEY_REUSED_MSG, StatusCode: http.StatusUnprocessableEntity, LogMessage: "Idempotency key reused with a different request", MaybeInnerError: errtrace.New("")}
+			}
+			if !existing.IsCompleted() {
+				return response.ErrorResponse{ErrorCode: constant.IDEMPOTENCY_REQUEST_IN_PROGRESS, Message: constant.IDEMPOTENCY_REQUEST_IN_PROGRESS_MSG, StatusCode: http.StatusConflict, LogMessage: "Idempotency key is already being processed", MaybeInnerError: errtrace.New("")}
+			}
+
+			logger.Info("Replaying stored response for idempotency key", "userId", userId)
+			c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+			return c.Blob(*existing.ResponseStatus, *existing.ResponseContentType, existing.ResponseBody)
+		}
+
+		recorder := &idempotencyResponseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+
+		// Errors have to be rendered here rather than by echo afterwards so that the
+		// stored response is exactly what the client received
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		if status >= http.StatusInternalServerError {
+			if err := (dao.IdempotencyKeyDao{}).Release(userId, key); err != nil {
+				logger.Error("Failed to release idempotency key", "error", err.Error())
+			}
+			return nil
+		}
+
+		contentType := c.Response().Header().Get(echo.HeaderContentType)
+		if err := (dao.IdempotencyKeyDao{}).Complete(userId, key, status, contentType, recorder.body.Bytes(), clock.Now()); err != nil {
+			logger.Error("Failed to store response for idempotency key", "error", err.Error())
+		}
+		return nil
+	}
+}
+
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,90 @@
+package security
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"strconv"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// RateLimitKeyFunc returns the value requests are counted by. An empty key falls
+// back to the client IP.
+type RateLimitKeyFunc func(c echo.Context) string
+
+func RateLimitByIP(c echo.Context) string {
+	return c.RealIP()
+}
+
+// RateLimitByUserId keys on the user id set by the JWT middlewares, so it must be
+// used on routes behind one of them.
+func RateLimitByUserId(c echo.Context) string {
+	userId, _ := c.Get("user_id").(string)
+	return userId
+}
+
+// RateLimit limits requests using the rule named name in config.Config.RateLimits.
+// Counts are kept per fixed window in Postgres and the previous window is weighted
+// in, which approximates a sliding window. Routes without a configured rule are not limited.
+func RateLimit(name string, keyFunc RateLimitKeyFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rule, ok := config.Config.RateLimits[name]
+			if !ok || rule.Limit <= 0 || rule.Window <= 0 {
+				return next(c)
+			}
+
+			key := keyFunc(c)
+			if key == "" {
+				key = c.RealIP()
+			}
+
+			now := clock.Now()
+			window := time.Duration(rule.Window) * time.Millisecond
+			windowStart := now.Truncate(window)
+			current, previous, err := dao.RateLimitCounterDao{}.Increment(fmt.Sprintf("%s:%s", name, key), windowStart, window)
+			if err != nil {
+				// Fail open, a counter outage should not take the endpoint down with it
+				logging.GetEchoContextLogger(c).Error("Failed to increment rate limit counter", "rateLimit", name, "error", err.Error())
+				return next(c)
+			}
+
+			previousWeight := 1 - float64(now.Sub(windowStart))/float64(window)
+			count := current + int(math.Floor(float64(previous)*previousWeight))
+			resetSeconds := max(int(math.Ceil(windowStart.Add(window).Sub(now).Seconds())), 1)
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(rule.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(max(rule.Limit-count, 0)))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(resetSeconds))
+
+			if count > rule.Limit {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(resetSeconds))
+				return response.ErrorResponse{
+					ErrorCode:       constant.RATE_LIMIT_EXCEEDED,
+					Message:         constant.RATE_LIMIT_EXCEEDED_MSG,
+					StatusCode:      http.StatusTooManyRequests,
+					LogMessage:      fmt.Sprintf("Rate limit %s exceeded: %d requests for limit %d", name, count, rule.Limit),
+					MaybeInnerError: errtrace.New(""),
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
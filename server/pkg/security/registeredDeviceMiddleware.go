@@ -0,0 +1,39 @@
+package security
+
+import (
+	"fmt"
+	"net/http"
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+
+	"braces.dev/errtrace"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisteredDeviceMiddleware refuses sessions of devices that were revoked or are no
+// longer registered. It runs after LoggedInRegisteredUserMiddleware and before
+// ResetTokenMiddleware, so a revoked device's token is never re-issued. Other session
+// types pass through untouched.
+func RegisteredDeviceMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cc, ok := c.(*LoggedInRegisteredUserContext)
+		if !ok {
+			return next(c)
+		}
+
+		userPublicKey, err := dao.UserPublicKey{}.FindUserPublicRecordIncludingRevoked(cc.UserId, cc.PublicKey)
+		if err != nil {
+			return response.InternalServerError(fmt.Sprintf("Failed to find user device: %s", err.Error()), errtrace.Wrap(err))
+		}
+		if userPublicKey == nil {
+			return response.ErrorResponse{ErrorCode: constant.INVALID_TOKEN, Message: constant.INVALID_TOKEN_MSG, StatusCode: http.StatusUnauthorized, LogMessage: "userPublicKey record not found in DB", MaybeInnerError: errtrace.New("")}
+		}
+		if userPublicKey.IsRevoked() {
+			logging.GetEchoContextLogger(c).Info("Refused session of revoked device", "userId", cc.UserId, "deviceId", userPublicKey.ID)
+			return response.ErrorResponse{ErrorCode: constant.DEVICE_REVOKED, Message: constant.DEVICE_REVOKED_MSG, StatusCode: http.StatusUnauthorized, LogMessage: fmt.Sprintf("userPublicKey %d was revoked at %s", userPublicKey.ID, userPublicKey.RevokedAt), MaybeInnerError: errtrace.New("")}
+		}
+		return next(c)
+	}
+}
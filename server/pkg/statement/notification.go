@@ -0,0 +1,80 @@
+package statement
+
+import (
+	"context"
+	"fmt"
+	"process-api/pkg/constant"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"process-api/pkg/utils"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+// NotificationEmailJobArgs emails a customer that their statement for a month is ready
+type NotificationEmailJobArgs struct {
+	FirstName string `json:"firstName"`
+	Email     string `json:"email"`
+	Month     string `json:"month"`
+	Year      string `json:"year"`
+	BaseUrl   string `json:"baseUrl"`
+	AccountId string `json:"accountId"`
+}
+
+func (NotificationEmailJobArgs) Kind() string { return "statement_notification_email" }
+
+func (NotificationEmailJobArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "sendgrid",
+	}
+}
+
+// BaseUrl is where the statement email links to in env
+func BaseUrl(env string) string {
+	if env != constant.PROD {
+		return "https://middleware.sandbox.dreamfi.com"
+	}
+	return "https://middleware.production.dreamfi.com"
+}
+
+type NotificationWorker struct {
+	river.WorkerDefaults[NotificationEmailJobArgs]
+}
+
+func RegisterNotificationWorker(workers *river.Workers) {
+	river.AddWorker(workers, &NotificationWorker{})
+}
+
+func (w *NotificationWorker) Work(ctx context.Context, job *river.Job[NotificationEmailJobArgs]) error {
+	err := notifyUser(job.Args)
+	if err != nil {
+		logging.Logger.Error("Error sending new statement notification email", "err", err)
+		return err
+	}
+	return nil
+}
+
+func notifyUser(notificationEmailArgs NotificationEmailJobArgs) error {
+	appLink := fmt.Sprintf("%s/web/account/%s/statements", notificationEmailArgs.BaseUrl, notificationEmailArgs.AccountId)
+	emailData := response.StatementEmailTemplateData{
+		FirstName: notificationEmailArgs.FirstName,
+		Month:     notificationEmailArgs.Month,
+		Year:      notificationEmailArgs.Year,
+		AppLink:   appLink,
+	}
+
+	templateName := "../email-templates/statementNotificationTemplate.html"
+	htmlBody, err := utils.GenerateEmailBody(templateName, emailData)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	emailSubject := "Your Monthly DreamFi Statement is Ready"
+	err = utils.SendEmail(notificationEmailArgs.FirstName, notificationEmailArgs.Email, emailSubject, htmlBody)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package timeline
+
+import (
+	"fmt"
+	"process-api/pkg/db/dao"
+	"strings"
+
+	"braces.dev/errtrace"
+)
+
+const (
+	EVENT_LOGIN                        = "login"
+	EVENT_OTP_SENT                     = "otp_sent"
+	EVENT_OTP_CALL                     = "otp_call"
+	EVENT_DEVICE_REGISTERED            = "device_registered"
+	EVENT_DEVICE_REVOKED               = "device_revoked"
+	EVENT_DEMOGRAPHIC_UPDATE_SUBMITTED = "demographic_update_submitted"
+	EVENT_DEMOGRAPHIC_UPDATE_REVIEWED  = "demographic_update_reviewed"
+	EVENT_DISPUTE_PREFIX               = "dispute_"
+	EVENT_BANK_LINKED                  = "bank_linked"
+	EVENT_BANK_LINK_ERROR              = "bank_link_error"
+	EVENT_TRANSACTION                  = "transaction"
+)
+
+func init() {
+	Register(Source{Category: CategoryLogin, Events: loginEvents})
+	Register(Source{Category: CategoryOtp, Events: otpEvents})
+	Register(Source{Category: CategoryCall, Events: callEvents})
+	Register(Source{Category: CategoryDevice, Events: deviceEvents})
+	Register(Source{Category: CategoryDemographicUpdate, Events: demographicUpdateEvents})
+	Register(Source{Category: CategoryDispute, Events: disputeEvents})
+	Register(Source{Category: CategoryBankLink, Events: bankLinkEvents})
+	Register(Source{Category: CategoryTransaction, Events: transactionEvents})
+}
+
+func optionalIP(ip string) *string {
+	if ip == "" {
+		return nil
+	}
+	return &ip
+}
+
+func loginEvents(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+	logins, err := dao.MasterUserLoginDao{}.FindForTimeline(user.Id, window)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	events := make([]Event, 0, len(logins))
+	for _, login := range logins {
+		events = append(events, Event{
+			At:       login.CreatedAt,
+			Category: CategoryLogin,
+			Type:     EVENT_LOGIN,
+			Summary:  "Logged in",
+			IP:       optionalIP(login.IP),
+			EntityId: login.Id,
+		})
+	}
+	return events, nil
+}
+
+// otpEvents lists each one-time code when it was sent, with the status it ended in. The
+// code itself is never included.
+func otpEvents(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+	otps, err := dao.MasterUserOtpDao{}.FindForTimeline(user.Id, window)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	events := make([]Event, 0, len(otps))
+	for _, otp := range otps {
+		events = append(events, Event{
+			At:       otp.CreatedAt,
+			Category: CategoryOtp,
+			Type:     EVENT_OTP_SENT,
+			Summary:  fmt.Sprintf("Verification code sent by %s, %s", strings.ToLower(otp.OtpType), strings.ToLower(strings.TrimPrefix(otp.OtpStatus, "OTP_"))),
+			IP:       optionalIP(otp.IP),
+			EntityId: otp.OtpId,
+		})
+	}
+	return events, nil
+}
+
+func callEvents(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+	if user.MobileNo == "" {
+		return nil, nil
+	}
+	calls, err := dao.MasterUserCallRecordDao{}.FindForTimeline(user.MobileNo, window)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	events := make([]Event, 0, len(calls))
+	for _, call := range calls {
+		events = append(events, Event{
+			At:       call.CreatedAt,
+			Category: CategoryCall,
+			Type:     EVENT_OTP_CALL,
+			Summary:  fmt.Sprintf("Verification call, %s", call.CallStatus),
+			EntityId: call.CallSid,
+		})
+	}
+	return events, nil
+}
+
+// deviceEvents lists registrations and revocations. A customer has few devices, so all of
+// them are read and ForUser drops those outside the window.
+func deviceEvents(user dao.MasterUserRecordDao, _ dao.TimelineWindow) ([]Event, error) {
+	devices, err := dao.UserPublicKey{}.FindByUserId(user.Id)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	var events []Event
+	for _, device := range devices {
+		name := "Unnamed device"
+		if device.DeviceName != nil && *device.DeviceName != "" {
+			name = *device.DeviceName
+		}
+		entityId := fmt.Sprint(device.ID)
+		events = append(events, Event{
+			At:       device.CreatedAt,
+			Category: CategoryDevice,
+			Type:     EVENT_DEVICE_REGISTERED,
+			Summary:  fmt.Sprintf("Device registered: %s", name),
+			IP:       device.RegisteredIP,
+			EntityId: entityId,
+		})
+		if device.RevokedAt != nil {
+			events = append(events, Event{
+				At:       *device.RevokedAt,
+				Category: CategoryDevice,
+				Type:     EVENT_DEVICE_REVOKED,
+				Summary:  fmt.Sprintf("Device removed: %s", name),
+				Actor:    device.RevokedBy,
+				EntityId: entityId,
+			})
+		}
+	}
+	return events, nil
+}
+
+// demographicUpdateEvents lists submissions and reviews. A customer has few updates, so
+// all of them are read and ForUser drops those outside the window.
+func demographicUpdateEvents(user dao.MasterUserRecordDao, _ dao.TimelineWindow) ([]Event, error) {
+	updates, err := dao.DemographicUpdatesDao{}.FindAllByUserId(user.Id)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	var events []Event
+	for _, update := range updates {
+		kind := strings.ReplaceAll(update.Type, "_", " ")
+		events = append(events, Event{
+			At:       update.CreatedAt,
+			Category: CategoryDemographicUpdate,
+			Type:     EVENT_DEMOGRAPHIC_UPDATE_SUBMITTED,
+			Summary:  fmt.Sprintf("Requested a change of %s", kind),
+			EntityId: update.Id,
+		})
+		if update.ReviewedAt != nil {
+			events = append(events, Event{
+				At:       *update.ReviewedAt,
+				Category: CategoryDemographicUpdate,
+				Type:     EVENT_DEMOGRAPHIC_UPDATE_REVIEWED,
+				Summary:  fmt.Sprintf("Change of %s %s", kind, update.Status),
+				Actor:    update.ReviewedBy,
+				EntityId: update.Id,
+			})
+		}
+	}
+	return events, nil
+}
+
+func disputeEvents(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+	disputeEvents, err := dao.TransactionDisputeEventDao{}.FindForTimeline(user.Id, window)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	events := make([]Event, 0, len(disputeEvents))
+	for _, disputeEvent := range disputeEvents {
+		var actor *string
+		if disputeEvent.Actor != dao.DISPUTE_ACTOR_CUSTOMER {
+			actor = &disputeEvent.Actor
+		}
+		summary := fmt.Sprintf("Dispute of transaction %s: %s", disputeEvent.TransactionIdentifier, strings.ReplaceAll(disputeEvent.Action, "_", " "))
+		if disputeEvent.Detail != nil && *disputeEvent.Detail != "" {
+			summary += fmt.Sprintf(" (%s)", *disputeEvent.Detail)
+		}
+		events = append(events, Event{
+			At:       disputeEvent.CreatedAt,
+			Category: CategoryDispute,
+			Type:     EVENT_DISPUTE_PREFIX + disputeEvent.Action,
+			Summary:  summary,
+			Actor:    actor,
+			EntityId: disputeEvent.TransactionDisputeId,
+		})
+	}
+	return events, nil
+}
+
+// bankLinkEvents lists the Plaid items the customer linked, and the last error Plaid
+// reported for each. A customer has few items, so all of them are read and ForUser drops
+// those outside the window.
+func bankLinkEvents(user dao.MasterUserRecordDao, _ dao.TimelineWindow) ([]Event, error) {
+	items, err := dao.PlaidItemDao{}.GetItemsByUserId(user.Id)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	var events []Event
+	for _, item := range items {
+		events = append(events, Event{
+			At:       item.CreatedAt,
+			Category: CategoryBankLink,
+			Type:     EVENT_BANK_LINKED,
+			Summary:  "Bank linked through Plaid",
+			EntityId: item.PlaidItemID,
+		})
+		if item.ItemError != nil && *item.ItemError != "" {
+			events = append(events, Event{
+				At:       item.UpdatedAt,
+				Category: CategoryBankLink,
+				Type:     EVENT_BANK_LINK_ERROR,
+				Summary:  fmt.Sprintf("Bank link error: %s", *item.ItemError),
+				EntityId: item.PlaidItemID,
+			})
+		}
+	}
+	return events, nil
+}
+
+func transactionEvents(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+	ledgerEvents, err := dao.LedgerTransactionEventDao{}.FindForTimeline(user.Id, window)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+	events := make([]Event, 0, len(ledgerEvents))
+	for _, ledgerEvent := range ledgerEvents {
+		direction := "in"
+		if ledgerEvent.IsOutward {
+			direction = "out"
+		}
+		summary := fmt.Sprintf("%s %s %s $%d.%02d", ledgerEvent.Channel, ledgerEvent.TransactionType, direction, ledgerEvent.InstructedAmount/100, ledgerEvent.InstructedAmount%100)
+		if ledgerEvent.CardPayeeName != "" {
+			summary += fmt.Sprintf(" at %s", ledgerEvent.CardPayeeName)
+		} else if ledgerEvent.ExternalBankAccountName != "" {
+			summary += fmt.Sprintf(" with %s", ledgerEvent.ExternalBankAccountName)
+		}
+		events = append(events, Event{
+			At:       ledgerEvent.CreatedAt,
+			Category: CategoryTransaction,
+			Type:     EVENT_TRANSACTION,
+			Summary:  summary,
+			EntityId: ledgerEvent.TransactionNumber,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,130 @@
+package timeline
+
+import (
+	"process-api/pkg/db/dao"
+	"slices"
+	"sort"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+// Category groups the events a source contributes to a customer's timeline
+type Category string
+
+const (
+	CategoryLogin             Category = "login"
+	CategoryOtp               Category = "otp"
+	CategoryCall              Category = "call"
+	CategoryDevice            Category = "device"
+	CategoryDemographicUpdate Category = "demographic_update"
+	CategoryDispute           Category = "dispute"
+	CategoryBankLink          Category = "bank_link"
+	CategoryTransaction       Category = "transaction"
+)
+
+// SecurityCategories are the categories shown to the customer as their security activity
+var SecurityCategories = []Category{
+	CategoryLogin,
+	CategoryOtp,
+	CategoryDevice,
+	CategoryDemographicUpdate,
+}
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Event is one thing that happened on a customer's account
+type Event struct {
+	At       time.Time
+	Category Category
+	// Type names what happened within the category, such as device_revoked
+	Type    string
+	Summary string
+	// Who took the action when it was not the customer, such as the operator who revoked a
+	// device. Only shown to operators.
+	Actor    *string
+	IP       *string
+	EntityId string
+}
+
+// Source reads a customer's events in one category. It returns the newest events inside
+// the window, up to the window's limit; events outside the window are dropped by ForUser.
+type Source struct {
+	Category Category
+	Events   func(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error)
+}
+
+var sources []Source
+
+// Register adds a source to every customer's timeline. New tables of customer activity,
+// such as transfers or notifications, register a source from their package's init.
+func Register(source Source) {
+	sources = append(sources, source)
+}
+
+// Categories lists the categories of the registered sources in the order they registered
+func Categories() []Category {
+	categories := make([]Category, 0, len(sources))
+	for _, source := range sources {
+		if !slices.Contains(categories, source.Category) {
+			categories = append(categories, source.Category)
+		}
+	}
+	return categories
+}
+
+// Filter narrows a customer's timeline. Zero values do not filter.
+type Filter struct {
+	Categories []Category
+	From       time.Time
+	// Before pages through the timeline: pass the At of the last event of the previous page
+	Before time.Time
+	Limit  int
+}
+
+// Page is the newest events matching a filter, newest first
+type Page struct {
+	Events []Event
+	// HasMore is true when older events match the filter. Pass the At of the last event as
+	// Filter.Before to read them.
+	HasMore bool
+}
+
+// ForUser merges the user's events from every source matching the filter into one
+// timeline, newest first
+func ForUser(user dao.MasterUserRecordDao, filter Filter) (Page, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+	// One more than the limit tells whether there are older events
+	window := dao.TimelineWindow{From: filter.From, Before: filter.Before, Limit: limit + 1}
+
+	var events []Event
+	for _, source := range sources {
+		if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, source.Category) {
+			continue
+		}
+		sourceEvents, err := source.Events(user, window)
+		if err != nil {
+			return Page{}, errtrace.Wrap(err)
+		}
+		for _, event := range sourceEvents {
+			if window.Contains(event.At) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.After(events[j].At)
+	})
+	if len(events) > limit {
+		return Page{Events: events[:limit], HasMore: true}, nil
+	}
+	return Page{Events: events}, nil
+}
@@ -0,0 +1,89 @@
+package timeline
+
+import (
+	"process-api/pkg/db/dao"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSources replaces the registered sources for the length of the test
+func withSources(t *testing.T, replacements ...Source) {
+	registered := sources
+	sources = replacements
+	t.Cleanup(func() { sources = registered })
+}
+
+func fixedSource(category Category, ats ...time.Time) Source {
+	return Source{Category: category, Events: func(_ dao.MasterUserRecordDao, _ dao.TimelineWindow) ([]Event, error) {
+		events := make([]Event, 0, len(ats))
+		for _, at := range ats {
+			events = append(events, Event{At: at, Category: category})
+		}
+		return events, nil
+	}}
+}
+
+func TestForUserMergesSourcesNewestFirst(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, time.November, 1, hour, 0, 0, 0, time.UTC) }
+	withSources(t,
+		fixedSource(CategoryLogin, at(9), at(3)),
+		fixedSource(CategoryDevice, at(7), at(5)),
+	)
+
+	page, err := ForUser(dao.MasterUserRecordDao{}, Filter{})
+	require.NoError(t, err)
+	require.False(t, page.HasMore)
+	require.Len(t, page.Events, 4)
+	assert.Equal(t, []time.Time{at(9), at(7), at(5), at(3)}, []time.Time{page.Events[0].At, page.Events[1].At, page.Events[2].At, page.Events[3].At})
+	assert.Equal(t, CategoryDevice, page.Events[1].Category)
+}
+
+func TestForUserFiltersAndPages(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, time.November, 1, hour, 0, 0, 0, time.UTC) }
+	var requested dao.TimelineWindow
+	login := fixedSource(CategoryLogin, at(9), at(6), at(3), at(1))
+	events := login.Events
+	login.Events = func(user dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+		requested = window
+		return events(user, window)
+	}
+	withSources(t, login, fixedSource(CategoryTransaction, at(8)))
+
+	page, err := ForUser(dao.MasterUserRecordDao{}, Filter{Categories: []Category{CategoryLogin}, From: at(2), Before: at(9), Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, dao.TimelineWindow{From: at(2), Before: at(9), Limit: 2}, requested, "Sources should be asked for one more event than the limit")
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, at(6), page.Events[0].At, "Events outside the window and other categories should be dropped")
+	assert.True(t, page.HasMore)
+
+	page, err = ForUser(dao.MasterUserRecordDao{}, Filter{Categories: []Category{CategoryLogin}, From: at(2), Before: page.Events[0].At, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, at(3), page.Events[0].At)
+	assert.False(t, page.HasMore)
+}
+
+func TestForUserCapsLimit(t *testing.T) {
+	var requested dao.TimelineWindow
+	withSources(t, Source{Category: CategoryLogin, Events: func(_ dao.MasterUserRecordDao, window dao.TimelineWindow) ([]Event, error) {
+		requested = window
+		return nil, nil
+	}})
+
+	_, err := ForUser(dao.MasterUserRecordDao{}, Filter{Limit: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, MaxLimit+1, requested.Limit)
+
+	_, err = ForUser(dao.MasterUserRecordDao{}, Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLimit+1, requested.Limit)
+}
+
+func TestCategoriesIncludesSecurityCategories(t *testing.T) {
+	for _, category := range SecurityCategories {
+		assert.Contains(t, Categories(), category)
+	}
+}
@@ -0,0 +1,110 @@
+package transaction
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var ErrUnknownCursor = errors.New("cursor does not match a transaction")
+
+// Filter narrows a list of transactions. Zero values do not filter.
+type Filter struct {
+	From   time.Time
+	Before time.Time
+	// Types matches either the ledger's type, such as PURCHASE, or its type details
+	Types []string
+	// Cursor pages through the list: pass the ReferenceID of the last transaction of the
+	// previous page
+	Cursor string
+	Limit  int
+	// Unpaged lists every matching transaction, ignoring Limit
+	Unpaged bool
+}
+
+// Page is the newest transactions matching a filter, newest first
+type Page struct {
+	Transactions []Transaction
+	// HasMore is true when older transactions match the filter. Pass the ReferenceID of the
+	// last transaction as Filter.Cursor to read them.
+	HasMore bool
+}
+
+// Apply sorts the transactions newest first and returns the page of them matching the
+// filter
+func (filter Filter) Apply(transactions []Transaction) (Page, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	sorted := slices.Clone(transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return timeStamp(sorted[i]).After(timeStamp(sorted[j]))
+	})
+
+	if filter.Cursor != "" {
+		cursorIndex := slices.IndexFunc(sorted, func(t Transaction) bool { return t.ReferenceID == filter.Cursor })
+		if cursorIndex == -1 {
+			return Page{}, errtrace.Wrap(ErrUnknownCursor)
+		}
+		sorted = sorted[cursorIndex+1:]
+	}
+	if filter.Unpaged {
+		limit = len(sorted)
+	}
+
+	matching := make([]Transaction, 0, min(len(sorted), limit+1))
+	for _, t := range sorted {
+		if filter.matches(t) {
+			matching = append(matching, t)
+		}
+		// One more than the limit tells whether there are older transactions
+		if len(matching) > limit {
+			return Page{Transactions: matching[:limit], HasMore: true}, nil
+		}
+	}
+	return Page{Transactions: matching}, nil
+}
+
+func (filter Filter) matches(t Transaction) bool {
+	if len(filter.Types) > 0 && !slices.ContainsFunc(filter.Types, func(transactionType string) bool {
+		return strings.EqualFold(transactionType, t.TypeRaw) || strings.EqualFold(transactionType, t.Type)
+	}) {
+		return false
+	}
+	if filter.From.IsZero() && filter.Before.IsZero() {
+		return true
+	}
+	at := timeStamp(t)
+	if at.IsZero() {
+		return false
+	}
+	if !filter.From.IsZero() && at.Before(filter.From) {
+		return false
+	}
+	if !filter.Before.IsZero() && !at.Before(filter.Before) {
+		return false
+	}
+	return true
+}
+
+// timeStamp parses when the transaction was made, the zero time if the ledger's timestamp
+// is not RFC 3339
+func timeStamp(t Transaction) time.Time {
+	at, err := time.Parse(time.RFC3339Nano, t.TimeStamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
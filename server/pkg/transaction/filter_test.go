@@ -0,0 +1,77 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterApply(t *testing.T) {
+	transactions := []Transaction{
+		{ReferenceID: "ach", Type: "ACH_PULL", TypeRaw: "ACH_PULL", TimeStamp: "2025-08-12T17:58:28Z"},
+		{ReferenceID: "purchase", Type: "POS_PURCHASE", TypeRaw: "PURCHASE", TimeStamp: "2025-09-16T19:26:24.463Z"},
+		{ReferenceID: "void", Type: "VOID", TypeRaw: "VOID", TimeStamp: "2025-09-01T08:00:00Z"},
+	}
+	referenceIds := func(page Page) []string {
+		ids := make([]string, 0, len(page.Transactions))
+		for _, t := range page.Transactions {
+			ids = append(ids, t.ReferenceID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    []string
+		hasMore bool
+	}{
+		{
+			name:   "newest first",
+			filter: Filter{},
+			want:   []string{"purchase", "void", "ach"},
+		},
+		{
+			name:    "limited",
+			filter:  Filter{Limit: 2},
+			want:    []string{"purchase", "void"},
+			hasMore: true,
+		},
+		{
+			name:   "unpaged",
+			filter: Filter{Limit: 1, Unpaged: true},
+			want:   []string{"purchase", "void", "ach"},
+		},
+		{
+			name:   "after the cursor",
+			filter: Filter{Cursor: "void", Limit: 2},
+			want:   []string{"ach"},
+		},
+		{
+			name:   "by ledger type or type details, ignoring case",
+			filter: Filter{Types: []string{"purchase", "ACH_PULL"}},
+			want:   []string{"purchase", "ach"},
+		},
+		{
+			name:   "by date",
+			filter: Filter{From: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Before: time.Date(2025, 9, 16, 0, 0, 0, 0, time.UTC)},
+			want:   []string{"void"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tt.filter.Apply(transactions)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, referenceIds(page))
+			assert.Equal(t, tt.hasMore, page.HasMore)
+		})
+	}
+
+	t.Run("unknown cursor", func(t *testing.T) {
+		_, err := Filter{Cursor: "missing"}.Apply(transactions)
+		assert.ErrorIs(t, err, ErrUnknownCursor)
+	})
+}
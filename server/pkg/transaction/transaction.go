@@ -0,0 +1,242 @@
+// Package transaction maps the ledger's account transactions to the shape shown to
+// customers and agents, so the app and Salesforce describe transactions the same way
+package transaction
+
+import (
+	"process-api/pkg/constant"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/dispute"
+	"process-api/pkg/ledger"
+	"process-api/pkg/resource/mcc"
+	"process-api/pkg/utils"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+)
+
+type Transaction struct {
+	MerchantName        string           `json:"merchantName" validate:"required"`
+	MerchantCategory    string           `json:"merchantCategory" validate:"required"`
+	Type                string           `json:"type" validate:"required"`
+	TypeRaw             string           `json:"typeRaw" validate:"required"`
+	ReferenceID         string           `json:"referenceID" validate:"required"`
+	TimeStamp           string           `json:"timeStamp" validate:"required"`
+	CompletionTimeStamp *string          `json:"completionTimeStamp,omitempty"`
+	InstructedAmount    InstructedAmount `json:"instructedAmount" validate:"required"`
+	DisputeStatus       *string          `json:"disputeStatus" enums:"none,pending,credited,voided,rejected"`
+	// The dispute's case management status, which disputeStatus only summarizes
+	DisputeLifecycleStatus *string       `json:"disputeLifecycleStatus,omitempty" enums:"none,submitted,under_review,provisionally_credited,resolved_customer,resolved_merchant,withdrawn"`
+	DisputeCreatedAt       *string       `json:"disputeCreatedAt"`
+	DisputeUpdatedAt       *string       `json:"disputeUpdatedAt"`
+	Status                 string        `json:"status" validate:"required"`
+	Credit                 bool          `json:"credit" validate:"required"`
+	CardAcceptor           *CardAcceptor `json:"cardAcceptor,omitempty"`
+	RawCardAcceptor        *string       `json:"rawCardAcceptor,omitempty"`
+}
+type InstructedAmount struct {
+	Amount   int64  `json:"amount" validate:"required"`
+	Currency string `json:"currency" validate:"required"`
+}
+
+type CardAcceptor struct {
+	Merchant string `json:"merchant,omitempty"`
+	City     string `json:"city,omitempty"`
+	State    string `json:"state,omitempty"`
+	Country  string `json:"country"`
+	Phone    string `json:"phone,omitempty"`
+	Website  string `json:"website,omitempty"`
+}
+
+// ForUser merges the ledger's transactions for one of the customer's accounts and maps
+// them along with the customer's disputes of them
+func ForUser(userId string, ledgerTransactions []ledger.ListTransactionsByAccountResultTransaction) ([]Transaction, error) {
+	finalTransactions, completionTimeStamps := MergeTransactions(ledgerTransactions)
+
+	referenceIds := make([]string, 0, len(finalTransactions))
+	for _, data := range finalTransactions {
+		referenceIds = append(referenceIds, data.ReferenceID)
+	}
+
+	disputes, err := dao.TransactionDisputeDao{}.FindByUserIdAndReferenceIds(userId, referenceIds)
+	if err != nil {
+		return nil, errtrace.Wrap(err)
+	}
+
+	return Map(finalTransactions, completionTimeStamps, *disputes), nil
+}
+
+// Map maps merged ledger transactions, resolving the merchant, its category, the card
+// acceptor and the status of any dispute of each transaction
+func Map(finalTransactions []ledger.ListTransactionsByAccountResultTransaction, completionTimeStamps map[string]string, disputes []dao.TransactionDisputeDao) []Transaction {
+	transformedTransactions := make([]Transaction, 0, len(finalTransactions))
+
+	for _, data := range finalTransactions {
+		var disputeStatus, disputeLifecycleStatus, disputeCreatedAt, disputeUpdatedAt *string
+
+		if !slices.Contains(dispute.NonDisputableTransactionTypes, data.Type) {
+			disputeIndex := slices.IndexFunc(disputes, func(d dao.TransactionDisputeDao) bool { return d.TransactionIdentifier == data.ReferenceID })
+			if disputeIndex == -1 {
+				disputeStatus = utils.Pointer("none")
+				disputeLifecycleStatus = utils.Pointer("none")
+			} else {
+				dispute := disputes[disputeIndex]
+				disputeStatus = utils.Pointer(LegacyDisputeStatus(dispute))
+				disputeLifecycleStatus = utils.Pointer(dispute.Status)
+				disputeCreatedAt = utils.Pointer(dispute.CreatedAt.Format(time.RFC3339)) // Todo: Remove disputeCreatedAt as we can rely on disputeUpdatedAt
+				disputeUpdatedAt = utils.Pointer(dispute.UpdatedAt.Format(time.RFC3339))
+			}
+		}
+
+		var cardAcceptor *string
+		if data.CardAcceptor == "" {
+			cardAcceptor = nil
+		} else {
+			cardAcceptor = &data.CardAcceptor
+		}
+
+		var merchantAccount ledger.ListTransactionsByAccountResultTransactionAccount
+		if data.Credit {
+			merchantAccount = data.DebtorAccount
+		} else {
+			merchantAccount = data.CreditorAccount
+		}
+		merchantName := ledger.GetTransactionAccountMerchantName(merchantAccount)
+		var completionTimestamp *string
+		if timeStamp, ok := completionTimeStamps[data.ProcessID]; ok && timeStamp != "" {
+			completionTimestamp = &timeStamp
+		}
+
+		transactionType := data.TransactionTypeDetails
+		if transactionType == "" {
+			transactionType = data.Type
+		}
+
+		merchantCategory, _ := mcc.GetCategory(data.Mcc)
+
+		transformedTransactions = append(transformedTransactions, Transaction{
+			MerchantName:        merchantName,
+			MerchantCategory:    merchantCategory,
+			Type:                transactionType,
+			TypeRaw:             data.Type,
+			ReferenceID:         data.ReferenceID,
+			TimeStamp:           data.TimeStamp,
+			CompletionTimeStamp: completionTimestamp,
+			InstructedAmount: InstructedAmount{
+				Amount:   data.InstructedAmount.Amount,
+				Currency: data.InstructedAmount.Currency,
+			},
+			Status:                 data.Status,
+			Credit:                 data.Credit,
+			CardAcceptor:           parseCardAcceptor(data.CardAcceptor),
+			DisputeStatus:          disputeStatus,
+			DisputeLifecycleStatus: disputeLifecycleStatus,
+			DisputeCreatedAt:       disputeCreatedAt,
+			DisputeUpdatedAt:       disputeUpdatedAt,
+			RawCardAcceptor:        cardAcceptor,
+		})
+	}
+
+	return transformedTransactions
+}
+
+// LegacyDisputeStatus maps the dispute's status to the pending, credited, voided or
+// rejected that released app builds parse
+func LegacyDisputeStatus(dispute dao.TransactionDisputeDao) string {
+	switch dispute.Status {
+	case constant.DISPUTE_PROVISIONALLY_CREDITED, constant.DISPUTE_RESOLVED_CUSTOMER:
+		return constant.DISPUTE_LEGACY_CREDITED
+	case constant.DISPUTE_RESOLVED_MERCHANT, constant.DISPUTE_WITHDRAWN:
+		if dispute.VoidCreditTransaction != nil {
+			return constant.DISPUTE_LEGACY_VOIDED
+		}
+		return constant.DISPUTE_LEGACY_REJECTED
+	default:
+		return constant.DISPUTE_LEGACY_PENDING
+	}
+}
+
+var states = map[string]bool{
+	"AL": true, "AK": true, "AZ": true, "AR": true, "CA": true,
+	"CO": true, "CT": true, "DE": true, "FL": true, "GA": true,
+	"HI": true, "ID": true, "IL": true, "IN": true, "IA": true,
+	"KS": true, "KY": true, "LA": true, "ME": true, "MD": true,
+	"MA": true, "MI": true, "MN": true, "MS": true, "MO": true,
+	"MT": true, "NE": true, "NV": true, "NH": true, "NJ": true,
+	"NM": true, "NY": true, "NC": true, "ND": true, "OH": true,
+	"OK": true, "OR": true, "PA": true, "RI": true, "SC": true,
+	"SD": true, "TN": true, "TX": true, "UT": true, "VT": true,
+	"VA": true, "WA": true, "WV": true, "WI": true, "WY": true,
+}
+
+var (
+	mobileRegex  = regexp.MustCompile(`\d{10}|\d{3}[-.\s]?\d{3}[-.\s]?\d{4}`)
+	websiteRegex = regexp.MustCompile(`[a-zA-Z0-9.-]+\.[a-z]{2,}(?:/[A-Za-z0-9._~!$&'()*+,;=:@%-]*)?`)
+)
+
+func parseCardAcceptor(raw string) *CardAcceptor {
+	if len(raw) != 40 {
+		return nil
+	}
+
+	ca := CardAcceptor{}
+
+	merchant := strings.TrimSpace(raw[0:23])
+	middle := strings.TrimSpace(raw[23:36])
+	state := strings.TrimSpace(raw[36:38])
+	country := strings.TrimSpace(raw[38:40])
+
+	ca.Merchant = merchant
+	ca.Country = country
+
+	switch {
+	case mobileRegex.MatchString(middle):
+		ca.Phone = mobileRegex.FindString(middle)
+	case websiteRegex.MatchString(middle):
+		ca.Website = websiteRegex.FindString(middle)
+	default:
+		ca.City = middle
+	}
+
+	if states[state] {
+		ca.State = state
+	}
+
+	return &ca
+}
+
+func MergeTransactions(transactions []ledger.ListTransactionsByAccountResultTransaction) ([]ledger.ListTransactionsByAccountResultTransaction, map[string]string) {
+	processIDIndexMap := make(map[string]int)
+	var finalTransactions []ledger.ListTransactionsByAccountResultTransaction
+	completionTimeStamps := make(map[string]string)
+	for _, txn := range transactions {
+		if slices.Contains(dispute.AdministrativeTransactionTypes, txn.Type) {
+			continue
+		}
+		idx, exists := processIDIndexMap[txn.ProcessID]
+
+		if !exists {
+			finalTransactions = append(finalTransactions, txn)
+			processIDIndexMap[txn.ProcessID] = len(finalTransactions) - 1
+			continue
+		}
+
+		existing := finalTransactions[idx]
+
+		switch {
+		case txn.Type == "COMPLETION" && existing.Type == "PRE_AUTH":
+			txn.TimeStamp = existing.TimeStamp
+			completionTimeStamps[txn.ProcessID] = txn.TimeStamp
+			finalTransactions[idx] = txn
+
+		case txn.Type == "PRE_AUTH" && existing.Type == "COMPLETION":
+			completionTimeStamps[txn.ProcessID] = existing.TimeStamp
+			existing.TimeStamp = txn.TimeStamp
+			finalTransactions[idx] = existing
+		}
+	}
+
+	return finalTransactions, completionTimeStamps
+}
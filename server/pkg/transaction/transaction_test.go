@@ -1,8 +1,9 @@
-package handler
+package transaction
 
 import (
 	"log/slog"
 	"os"
+	"process-api/pkg/db/dao"
 	"process-api/pkg/ledger"
 	"process-api/pkg/logging"
 	"process-api/pkg/resource/mcc"
@@ -1203,3 +1204,52 @@ func TestMergeTransactions(t *testing.T) {
 	assert.Equal(t, "2025-10-24T08:06:01Z", merged[0].TimeStamp)             // timestamp is from PRE_AUTH, not COMPLETION
 	assert.Equal(t, "2025-10-24T08:06:01Z", completionTimestamps["txn-123"]) // completionTimestamps map stores the same timestamp as the final merged transaction
 }
+
+func TestMap(t *testing.T) {
+	purchase := ledger.ListTransactionsByAccountResultTransaction{
+		Type:                   "PURCHASE",
+		ReferenceID:            "purchase",
+		ProcessID:              "txn-123",
+		Mcc:                    "5411",
+		CardAcceptor:           "100492136              GRANADA        ES",
+		TransactionTypeDetails: "POS_PURCHASE",
+	}
+	void := ledger.ListTransactionsByAccountResultTransaction{Type: "VOID", ReferenceID: "void"}
+	disputes := []dao.TransactionDisputeDao{{TransactionIdentifier: "purchase", Status: "under_review"}}
+
+	transactions := Map([]ledger.ListTransactionsByAccountResultTransaction{purchase, void}, map[string]string{"txn-123": "2025-10-24T08:07:00Z"}, disputes)
+
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "POS_PURCHASE", transactions[0].Type)
+	assert.Equal(t, "PURCHASE", transactions[0].TypeRaw)
+	assert.Equal(t, "Grocery Stores And Supermarkets", transactions[0].MerchantCategory)
+	assert.Equal(t, "GRANADA", transactions[0].CardAcceptor.City)
+	assert.Equal(t, "pending", *transactions[0].DisputeStatus, "Released app builds only parse the legacy statuses")
+	assert.Equal(t, "under_review", *transactions[0].DisputeLifecycleStatus)
+	assert.Equal(t, "2025-10-24T08:07:00Z", *transactions[0].CompletionTimeStamp)
+	assert.Nil(t, transactions[1].DisputeStatus, "A void cannot be disputed")
+	assert.Nil(t, transactions[1].DisputeLifecycleStatus)
+	assert.Nil(t, transactions[1].CardAcceptor)
+}
+
+func TestLegacyDisputeStatus(t *testing.T) {
+	voidCredit := "void-credit"
+	tests := []struct {
+		name     string
+		dispute  dao.TransactionDisputeDao
+		expected string
+	}{
+		{"submitted", dao.TransactionDisputeDao{Status: "submitted"}, "pending"},
+		{"under review", dao.TransactionDisputeDao{Status: "under_review"}, "pending"},
+		{"provisionally credited", dao.TransactionDisputeDao{Status: "provisionally_credited"}, "credited"},
+		{"resolved for the customer", dao.TransactionDisputeDao{Status: "resolved_customer"}, "credited"},
+		{"resolved for the merchant", dao.TransactionDisputeDao{Status: "resolved_merchant"}, "rejected"},
+		{"resolved for the merchant after a credit", dao.TransactionDisputeDao{Status: "resolved_merchant", VoidCreditTransaction: &voidCredit}, "voided"},
+		{"withdrawn", dao.TransactionDisputeDao{Status: "withdrawn"}, "rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, LegacyDisputeStatus(tt.dispute))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/config"
+	"process-api/pkg/constant"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/model/response"
+	"strconv"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/dongri/phonenumber"
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo/v4"
+)
+
+const OTP_SEND_RATE_LIMITED_EVENT = "otp_send_rate_limited"
+
+// OtpSend describes an OTP that is about to be sent. MobileNo is empty for email
+// OTPs, which are then only limited per user and per IP.
+// Use NewOtpSend to fill MobileNo according to the OTP type.
+type OtpSend struct {
+	UserId   string
+	MobileNo string
+	IP       string
+	ApiPath  string
+	OtpType  string
+}
+
+func NewOtpSend(c echo.Context, userId, mobileNo, apiPath, otpType string) OtpSend {
+	if otpType == constant.EMAIL {
+		mobileNo = ""
+	}
+	return OtpSend{UserId: userId, MobileNo: mobileNo, IP: c.RealIP(), ApiPath: apiPath, OtpType: otpType}
+}
+
+type otpSendRateLimit struct {
+	name   string
+	column string
+	value  string
+	rule   config.RateLimitRule
+}
+
+func (s OtpSend) rateLimits() []otpSendRateLimit {
+	limits := config.Config.Otp.SendRateLimits
+	rateLimits := []otpSendRateLimit{
+		{name: "user", column: dao.OTP_SEND_EVENT_USER_ID, value: s.UserId, rule: limits.PerUser},
+		{name: "ip", column: dao.OTP_SEND_EVENT_IP, value: s.IP, rule: limits.PerIp},
+	}
+	if s.MobileNo != "" {
+		rateLimits = append(rateLimits,
+			otpSendRateLimit{name: "mobileNo", column: dao.OTP_SEND_EVENT_MOBILE_NO, value: s.MobileNo, rule: limits.PerMobileNo},
+			otpSendRateLimit{name: "countryPrefix", column: dao.OTP_SEND_EVENT_COUNTRY_PREFIX, value: OtpCountryPrefix(s.MobileNo), rule: limits.PerCountryPrefix},
+		)
+	}
+	return rateLimits
+}
+
+// OtpCountryPrefix returns the "+<country code>" an E.164 number is dialled to.
+// Numbers the phone number library cannot place fall back to their first three digits.
+func OtpCountryPrefix(mobileNo string) string {
+	digits := strings.TrimPrefix(mobileNo, "+")
+	if iso3166 := phonenumber.GetISO3166ByNumber(digits, true); iso3166.CountryCode != "" {
+		return "+" + iso3166.CountryCode
+	}
+	if len(digits) > 3 {
+		digits = digits[:3]
+	}
+	return "+" + digits
+}
+
+// EnforceOtpSendRateLimit must be called before an OTP is handed to Twilio or the
+// email provider. It counts previous sends over each configured sliding window and
+// records this send when no limit is exceeded. Otherwise it sets Retry-After, raises
+// an alert and returns a 429 without recording anything. Concurrent sends sharing a
+// user, IP or number wait on each other so they can't all slip under a limit.
+func EnforceOtpSendRateLimit(c echo.Context, send OtpSend) error {
+	logger := logging.GetEchoContextLogger(c)
+	now := clock.Now()
+
+	var tripped []string
+	var retryAfter time.Duration
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var limits []otpSendRateLimit
+		for _, limit := range send.rateLimits() {
+			if limit.rule.Limit <= 0 || limit.value == "" {
+				continue
+			}
+			// Locks are always taken in the same order, so sends can't deadlock
+			if err := (dao.OtpSendEventDao{}).Lock(tx, limit.column, limit.value); err != nil {
+				return errtrace.Wrap(fmt.Errorf("error locking OTP sends by %s: %w", limit.name, err))
+			}
+			limits = append(limits, limit)
+		}
+
+		for _, limit := range limits {
+			window := time.Duration(limit.rule.Window) * time.Millisecond
+			count, oldest, err := dao.OtpSendEventDao{}.CountSince(tx, limit.column, limit.value, now.Add(-window))
+			if err != nil {
+				return errtrace.Wrap(fmt.Errorf("error counting OTP sends by %s: %w", limit.name, err))
+			}
+			if count < limit.rule.Limit {
+				continue
+			}
+
+			tripped = append(tripped, limit.name)
+			if oldest != nil {
+				retryAfter = max(retryAfter, oldest.Add(window).Sub(now))
+			}
+		}
+		if len(tripped) > 0 {
+			return nil
+		}
+
+		event := dao.OtpSendEventDao{
+			UserId:    send.UserId,
+			IP:        send.IP,
+			ApiPath:   send.ApiPath,
+			OtpType:   send.OtpType,
+			CreatedAt: now,
+		}
+		if send.MobileNo != "" {
+			countryPrefix := OtpCountryPrefix(send.MobileNo)
+			event.MobileNo = &send.MobileNo
+			event.CountryPrefix = &countryPrefix
+		}
+		if err := (dao.OtpSendEventDao{}).Create(tx, &event); err != nil {
+			return errtrace.Wrap(fmt.Errorf("error recording OTP send: %w", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return response.InternalServerError(fmt.Sprintf("Error enforcing OTP send rate limit: %s", err.Error()), errtrace.Wrap(err))
+	}
+
+	if len(tripped) > 0 {
+		retryAfterSeconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds))
+
+		logger.Warn("OTP send rate limit exceeded", "userId", send.UserId, "apiPath", send.ApiPath, "limits", tripped, "retryAfterSeconds", retryAfterSeconds)
+		alertProperties := map[string]any{
+			"limits":  tripped,
+			"apiPath": send.ApiPath,
+			"otpType": send.OtpType,
+			"ip":      send.IP,
+		}
+		if send.MobileNo != "" {
+			alertProperties["countryPrefix"] = OtpCountryPrefix(send.MobileNo)
+		}
+		PosthogClient.CaptureAlert(send.UserId, OTP_SEND_RATE_LIMITED_EVENT, alertProperties)
+
+		return response.ErrorResponse{
+			ErrorCode:       constant.OTP_SEND_RATE_LIMITED,
+			Message:         constant.OTP_SEND_RATE_LIMITED_MSG,
+			StatusCode:      http.StatusTooManyRequests,
+			LogMessage:      fmt.Sprintf("OTP send rate limit exceeded for %s", strings.Join(tripped, ", ")),
+			MaybeInnerError: errtrace.New(""),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOtpCountryPrefix(t *testing.T) {
+	assert.Equal(t, "+1", OtpCountryPrefix("+14014567890"), "US number should resolve to +1")
+	assert.Equal(t, "+44", OtpCountryPrefix("+447911123456"), "UK mobile should resolve to +44")
+	assert.Equal(t, "+999", OtpCountryPrefix("+9991234567"), "Unknown numbers fall back to their first three digits")
+}
@@ -57,6 +57,29 @@ func (ph *Posthog) SendErrorToPosthog(c echo.Context, err error, trace string) {
 	}
 }
 
+// CaptureAlert records an operational event in PostHog, where alerts are configured
+func (ph *Posthog) CaptureAlert(distinctId string, event string, properties map[string]any) {
+	if ph == nil || ph.client == nil {
+		logging.Logger.Warn("PostHog client is not initialized, dropping alert", "event", event)
+		return
+	}
+
+	props := posthog.NewProperties()
+	for key, value := range properties {
+		props.Set(key, value)
+	}
+
+	err := ph.client.Enqueue(posthog.Capture{
+		DistinctId: distinctId,
+		Event:      event,
+		Properties: props,
+		Timestamp:  clock.Now(),
+	})
+	if err != nil {
+		logging.Logger.Error("Could not enqueue", "error", err)
+	}
+}
+
 func ClosePosthogClient() error {
 	if PosthogClient != nil && PosthogClient.client != nil {
 		return PosthogClient.client.Close()
@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"process-api/pkg/clock"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/logging"
+	"process-api/pkg/utils"
+	"strconv"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/riverqueue/river"
+)
+
+const (
+	// MaxDeliveryAttempts is how many times a delivery is attempted before it is dead. With
+	// the backoff below the last attempt is about four hours after the first.
+	MaxDeliveryAttempts = 10
+	firstRetryDelay     = 30 * time.Second
+	maxRetryDelay       = 4 * time.Hour
+
+	// maxResponseBodyLength is how much of the subscriber's response is kept with an attempt
+	maxResponseBodyLength = 2048
+)
+
+var errDeliveryRejected = errors.New("the subscriber did not accept the delivery")
+
+// httpClient sends deliveries. Subscribers are expected to acknowledge quickly and do
+// their work afterwards.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// DeliveryArgs is a job that sends one event to one subscriber
+type DeliveryArgs struct {
+	DeliveryId string `json:"deliveryId"`
+}
+
+func (DeliveryArgs) Kind() string { return "webhook_delivery" }
+
+func (DeliveryArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:       "webhooks",
+		MaxAttempts: MaxDeliveryAttempts,
+	}
+}
+
+type DeliveryWorker struct {
+	river.WorkerDefaults[DeliveryArgs]
+}
+
+func RegisterDeliveryWorker(workers *river.Workers) {
+	river.AddWorker(workers, &DeliveryWorker{})
+}
+
+func (w *DeliveryWorker) Work(ctx context.Context, job *river.Job[DeliveryArgs]) error {
+	return errtrace.Wrap(Deliver(ctx, job.Args.DeliveryId, job.Attempt >= job.MaxAttempts, clock.Now()))
+}
+
+// NextRetry backs off exponentially, from 30 seconds up to 4 hours between attempts
+func (w *DeliveryWorker) NextRetry(job *river.Job[DeliveryArgs]) time.Time {
+	return clock.Now().Add(RetryDelay(job.Attempt))
+}
+
+// RetryDelay is how long to wait after the given failed attempt, counting from 1
+func RetryDelay(attempt int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// Deliver POSTs the delivery's event to its subscriber and records the attempt. An error
+// is returned when the subscriber did not accept it, so it is retried; on the last
+// attempt the delivery is dead instead, whatever the error. Deliveries to subscriptions
+// disabled since the event was dispatched are dead without being attempted.
+func Deliver(ctx context.Context, deliveryId string, lastAttempt bool, now time.Time) error {
+	err := deliver(ctx, deliveryId, lastAttempt, now)
+	if err != nil && lastAttempt {
+		// River won't run the job again, so a delivery left pending would never be retried
+		// or show up to be redelivered
+		if markErr := (dao.WebhookDeliveryDao{}).MarkDead(deliveryId, now); markErr != nil {
+			logging.Logger.Error("Failed to mark webhook delivery dead", "deliveryId", deliveryId, "error", markErr.Error())
+		}
+	}
+	return errtrace.Wrap(err)
+}
+
+func deliver(ctx context.Context, deliveryId string, lastAttempt bool, now time.Time) error {
+	delivery, err := dao.WebhookDeliveryDao{}.FindById(deliveryId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if delivery == nil || delivery.Status != dao.WEBHOOK_DELIVERY_PENDING {
+		return nil
+	}
+	logger := logging.Logger.With("job", DeliveryArgs{}.Kind(), "deliveryId", deliveryId)
+
+	subscription, err := dao.WebhookSubscriptionDao{}.FindById(delivery.SubscriptionId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if subscription == nil || subscription.DisabledAt != nil {
+		logger.Info("Webhook subscription disabled, not delivering")
+		return errtrace.Wrap(dao.WebhookDeliveryDao{}.MarkDead(deliveryId, now))
+	}
+	event, err := dao.WebhookEventDao{}.FindById(delivery.EventId)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	if event == nil {
+		return errtrace.Wrap(fmt.Errorf("webhook event %s not found", delivery.EventId))
+	}
+	secret, err := utils.DecryptKmsBinary(subscription.Secret)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	body, err := json.Marshal(Envelope{
+		Id:        event.Id,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Data),
+	})
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	attempt := send(ctx, subscription.Url, event.EventType, deliveryId, secret, body, now)
+	attempt.DeliveryId = deliveryId
+
+	status := dao.WEBHOOK_DELIVERY_PENDING
+	var deliveryErr error
+	switch {
+	case attempt.ResponseStatus != nil && *attempt.ResponseStatus >= 200 && *attempt.ResponseStatus < 300:
+		status = dao.WEBHOOK_DELIVERY_DELIVERED
+	case attempt.Error != nil:
+		deliveryErr = errors.New(*attempt.Error)
+	default:
+		deliveryErr = fmt.Errorf("%w: responded %d", errDeliveryRejected, *attempt.ResponseStatus)
+	}
+	if deliveryErr != nil && lastAttempt {
+		status = dao.WEBHOOK_DELIVERY_DEAD
+	}
+
+	if err := (dao.WebhookDeliveryDao{}).RecordAttempt(attempt, status, now); err != nil {
+		return errtrace.Wrap(err)
+	}
+	if deliveryErr != nil {
+		logger.Warn("Webhook delivery failed", "subscriptionId", subscription.Id, "status", status, "error", deliveryErr.Error())
+	}
+	return errtrace.Wrap(deliveryErr)
+}
+
+// send makes one delivery request, describing its outcome as an attempt
+func send(ctx context.Context, url string, eventType string, deliveryId string, secret string, body []byte, now time.Time) dao.WebhookDeliveryAttemptDao {
+	attempt := dao.WebhookDeliveryAttemptDao{AttemptedAt: now}
+	failed := func(err error) dao.WebhookDeliveryAttemptDao {
+		message := err.Error()
+		attempt.Error = &message
+		return attempt
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return failed(err)
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	started := time.Now()
+	resp, err := httpClient.Do(req)
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		return failed(err)
+	}
+	defer resp.Body.Close()
+
+	attempt.ResponseStatus = &resp.StatusCode
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyLength))
+	if err == nil && len(responseBody) > 0 {
+		responseBodyString := string(responseBody)
+		attempt.ResponseBody = &responseBodyString
+	}
+	return attempt
+}
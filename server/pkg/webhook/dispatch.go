@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"process-api/pkg/clock"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"slices"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/riverqueue/river"
+)
+
+// DispatchInterval is how often the outbox is checked for new events
+const DispatchInterval = 15 * time.Second
+
+// dispatchBatchSize is how many events are fanned out per transaction
+const dispatchBatchSize = 100
+
+// DispatchArgs is a periodic job that fans new outbox events out to the subscriptions for
+// their type
+type DispatchArgs struct{}
+
+func (DispatchArgs) Kind() string { return "webhook_dispatch" }
+
+type DispatchWorker struct {
+	river.WorkerDefaults[DispatchArgs]
+}
+
+func RegisterDispatchWorker(workers *river.Workers) {
+	river.AddWorker(workers, &DispatchWorker{})
+}
+
+// NewDispatchPeriodicJob schedules the dispatch job. Periodic jobs run on the elected River
+// leader only, and events are locked while being dispatched, so each is dispatched once.
+func NewDispatchPeriodicJob() *river.PeriodicJob {
+	return river.NewPeriodicJob(river.PeriodicInterval(DispatchInterval), func() (river.JobArgs, *river.InsertOpts) {
+		return DispatchArgs{}, nil
+	}, &river.PeriodicJobOpts{RunOnStart: true})
+}
+
+func (w *DispatchWorker) Work(ctx context.Context, job *river.Job[DispatchArgs]) error {
+	_, err := Dispatch(ctx, river.ClientFromContext[*sql.Tx](ctx), clock.Now())
+	return errtrace.Wrap(err)
+}
+
+// Dispatch creates a delivery, and enqueues its job, for each subscription to each event
+// not yet dispatched, returning how many deliveries it created. Events are dispatched to
+// the subscriptions enabled at the time, so a subscription only gets events from after it
+// was created.
+func Dispatch(ctx context.Context, riverClient *river.Client[*sql.Tx], now time.Time) (int, error) {
+	total := 0
+	for {
+		dispatched := 0
+		created := 0
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			events, err := dao.WebhookEventDao{}.LockUndispatched(tx, dispatchBatchSize)
+			if err != nil {
+				return errtrace.Wrap(err)
+			}
+			if len(events) == 0 {
+				return nil
+			}
+			subscriptions, err := dao.WebhookSubscriptionDao{}.FindEnabled(tx)
+			if err != nil {
+				return errtrace.Wrap(err)
+			}
+
+			var jobs []river.InsertManyParams
+			eventIds := make([]string, 0, len(events))
+			for _, event := range events {
+				eventIds = append(eventIds, event.Id)
+				for _, subscription := range subscriptions {
+					if event.CreatedAt.Before(subscription.CreatedAt) || !slices.Contains(subscription.EventTypes, event.EventType) {
+						continue
+					}
+					delivery := dao.WebhookDeliveryDao{
+						Id:             uuid.New().String(),
+						EventId:        event.Id,
+						SubscriptionId: subscription.Id,
+						Status:         dao.WEBHOOK_DELIVERY_PENDING,
+						CreatedAt:      now,
+						UpdatedAt:      now,
+					}
+					if err := (dao.WebhookDeliveryDao{}).Create(tx, &delivery); err != nil {
+						return errtrace.Wrap(err)
+					}
+					jobs = append(jobs, river.InsertManyParams{Args: DeliveryArgs{DeliveryId: delivery.Id}})
+				}
+			}
+
+			if len(jobs) > 0 {
+				if _, err := riverClient.InsertManyTx(ctx, tx.CommonDB().(*sql.Tx), jobs); err != nil {
+					return errtrace.Wrap(err)
+				}
+			}
+			if err := (dao.WebhookEventDao{}).MarkDispatched(tx, eventIds, now); err != nil {
+				return errtrace.Wrap(err)
+			}
+			dispatched = len(events)
+			created = len(jobs)
+			return nil
+		})
+		if err != nil {
+			return total, errtrace.Wrap(err)
+		}
+		total += created
+		if dispatched < dispatchBatchSize {
+			return total, nil
+		}
+	}
+}
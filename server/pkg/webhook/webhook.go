@@ -0,0 +1,189 @@
+// Package webhook tells internal consumers, such as Salesforce, about changes to customers.
+// Changes write an event to the outbox in their own transaction, the dispatcher fans each
+// event out to the subscriptions for its type, and the delivery worker POSTs it to each
+// subscriber, signed and retried until it is accepted.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"process-api/pkg/db"
+	"process-api/pkg/db/dao"
+	"process-api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"braces.dev/errtrace"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/riverqueue/river"
+)
+
+var (
+	ErrNameRequired             = errors.New("a name is required")
+	ErrInvalidUrl               = errors.New("the URL must be an https URL")
+	ErrEventTypesRequired       = errors.New("at least one event type is required")
+	ErrUnknownEventType         = errors.New("unknown event type")
+	ErrSubscriptionNotFound     = errors.New("webhook subscription not found")
+	ErrDeliveryNotRedeliverable = errors.New("only dead deliveries can be redelivered")
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a
+	// period and the body, keyed with the subscription's secret
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Envelope is the body of a delivery
+type Envelope struct {
+	// The event's id, the same across deliveries and redeliveries of the event
+	Id        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// Sign returns the signature header value of a body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a body sent at timestamp, as a
+// subscriber would check it
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// SubscriptionRequest is a subscription to create
+type SubscriptionRequest struct {
+	Name       string
+	Url        string
+	EventTypes []string
+	CreatedBy  string
+}
+
+// CreateSubscription registers a subscriber, returning it along with its secret. The
+// secret is only stored encrypted, so this is the one time it can be shown.
+func CreateSubscription(req SubscriptionRequest, now time.Time) (*dao.WebhookSubscriptionDao, string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, "", errtrace.Wrap(ErrNameRequired)
+	}
+	req.Url = strings.TrimSpace(req.Url)
+	if !validUrl(req.Url) {
+		return nil, "", errtrace.Wrap(ErrInvalidUrl)
+	}
+	if len(req.EventTypes) == 0 {
+		return nil, "", errtrace.Wrap(ErrEventTypesRequired)
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(dao.WebhookEventTypes, eventType) {
+			return nil, "", errtrace.Wrap(ErrUnknownEventType)
+		}
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", errtrace.Wrap(err)
+	}
+	secret := "whsec_" + hex.EncodeToString(secretBytes)
+	encryptedSecret, err := utils.EncryptKmsBinary(secret)
+	if err != nil {
+		return nil, "", errtrace.Wrap(err)
+	}
+
+	subscription := dao.WebhookSubscriptionDao{
+		Id:         uuid.New().String(),
+		Name:       req.Name,
+		Url:        req.Url,
+		Secret:     encryptedSecret,
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(req.EventTypes))),
+		CreatedBy:  req.CreatedBy,
+		CreatedAt:  now,
+	}
+	if err := (dao.WebhookSubscriptionDao{}).Create(&subscription); err != nil {
+		return nil, "", errtrace.Wrap(err)
+	}
+	return &subscription, secret, nil
+}
+
+// validUrl accepts https URLs, and http ones to the local machine for development
+func validUrl(rawUrl string) bool {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || host == "127.0.0.1"
+	}
+	return false
+}
+
+// DisableSubscription stops sending events to the subscription, returning it as it was
+// before and after. Its pending deliveries are marked dead when next attempted.
+func DisableSubscription(id string, disabledBy string, now time.Time) (*dao.WebhookSubscriptionDao, *dao.WebhookSubscriptionDao, error) {
+	before, err := dao.WebhookSubscriptionDao{}.FindById(id)
+	if err != nil {
+		return nil, nil, errtrace.Wrap(err)
+	}
+	if before == nil {
+		return nil, nil, errtrace.Wrap(ErrSubscriptionNotFound)
+	}
+	if _, err := (dao.WebhookSubscriptionDao{}).Disable(id, disabledBy, now); err != nil {
+		return nil, nil, errtrace.Wrap(err)
+	}
+	after, err := dao.WebhookSubscriptionDao{}.FindById(id)
+	if err != nil {
+		return nil, nil, errtrace.Wrap(err)
+	}
+	return before, after, nil
+}
+
+// Redeliver sends a dead delivery again, with a fresh set of attempts
+func Redeliver(ctx context.Context, riverClient *river.Client[*sql.Tx], deliveryId string, now time.Time) error {
+	return errtrace.Wrap(db.DB.Transaction(func(tx *gorm.DB) error {
+		requeued, err := dao.WebhookDeliveryDao{}.Requeue(tx, deliveryId, now)
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+		if !requeued {
+			return errtrace.Wrap(ErrDeliveryNotRedeliverable)
+		}
+		_, err = riverClient.InsertTx(ctx, tx.CommonDB().(*sql.Tx), DeliveryArgs{DeliveryId: deliveryId}, nil)
+		return errtrace.Wrap(err)
+	}))
+}
+
+// AuditSnapshot captures the subscription for the admin audit log, without its secret
+func AuditSnapshot(subscription *dao.WebhookSubscriptionDao) map[string]any {
+	if subscription == nil {
+		return map[string]any{}
+	}
+	return map[string]any{
+		"name":        subscription.Name,
+		"url":         subscription.Url,
+		"event_types": []string(subscription.EventTypes),
+		"disabled_at": subscription.DisabledAt,
+		"disabled_by": subscription.DisabledBy,
+	}
+}
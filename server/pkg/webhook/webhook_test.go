@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+
+	signature := Sign("secret", 1700000000, body)
+	assert.Equal(t, "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54", signature)
+	assert.True(t, Verify("secret", 1700000000, body, signature))
+
+	assert.False(t, Verify("other", 1700000000, body, signature), "A different secret should not verify")
+	assert.False(t, Verify("secret", 1700000001, body, signature), "The timestamp should be signed")
+	assert.False(t, Verify("secret", 1700000000, []byte(`{"id":"2"}`), signature), "The body should be signed")
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, RetryDelay(1))
+	assert.Equal(t, time.Minute, RetryDelay(2))
+	assert.Equal(t, 2*time.Minute, RetryDelay(3))
+	assert.Equal(t, 4*time.Hour, RetryDelay(20), "The delay should be capped")
+}
+
+func TestValidUrl(t *testing.T) {
+	assert.True(t, validUrl("https://example.salesforce.com/webhooks"))
+	assert.True(t, validUrl("http://localhost:8080/webhooks"), "Local subscribers can use http")
+	assert.False(t, validUrl("http://example.com/webhooks"))
+	assert.False(t, validUrl("ftp://example.com"))
+	assert.False(t, validUrl("not a url"))
+}